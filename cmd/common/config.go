@@ -104,9 +104,12 @@ func setFlagsFromConfig(f *pflag.Flag, k string) error {
 			return nil
 		}
 		// Handle string slices in a way the params pkg understands it
-		if p, ok := f.Value.(*Param); ok && p.TypeHint == params.TypeStringSlice {
-			vals := config.Config.GetStringSlice(k)
-			return p.Set(strings.Join(vals, ","))
+		if p, ok := f.Value.(*Param); ok {
+			switch p.TypeHint {
+			case params.TypeStringSlice, params.TypeCIDRList, params.TypePortRangeList:
+				vals := config.Config.GetStringSlice(k)
+				return p.Set(strings.Join(vals, ","))
+			}
 		}
 
 		val := config.Config.GetString(k)
@@ -0,0 +1,177 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package image
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+
+	"github.com/inspektor-gadget/inspektor-gadget/cmd/common"
+	"github.com/inspektor-gadget/inspektor-gadget/cmd/common/utils"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/columns"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/columns/formatter/textcolumns"
+	gadgetcontext "github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-context"
+	gadgetdiff "github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-diff"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+	apihelpers "github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api-helpers"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators"
+	ocihandler "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/oci-handler"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/params"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/runtime"
+)
+
+const (
+	failOnNone     = "none"
+	failOnBreaking = "breaking"
+	failOnAny      = "any"
+)
+
+func NewDiffCmd(runtime runtime.Runtime) *cobra.Command {
+	var outputMode string
+	var failOn string
+
+	opGlobalParams := make(map[string]*params.Params)
+
+	outputModes := []string{utils.OutputModeColumns, utils.OutputModeJSON, utils.OutputModeJSONPretty, utils.OutputModeYAML}
+	failOnModes := []string{failOnNone, failOnBreaking, failOnAny}
+
+	cmd := &cobra.Command{
+		Use:          "diff IMAGE_A IMAGE_B",
+		Short:        "Compare metadata, params and data sources of two gadget images",
+		SilenceUsage: true,
+		Args:         cobra.ExactArgs(2),
+	}
+
+	ociParams := apihelpers.ToParamDescs(ocihandler.OciHandler.InstanceParams()).ToParams()
+
+	for _, op := range operators.GetDataOperators() {
+		opGlobalParams[op.Name()] = apihelpers.ToParamDescs(op.GlobalParams()).ToParams()
+	}
+
+	runtimeGlobalParams := runtime.GlobalParamDescs().ToParams()
+	runtimeParams := runtime.ParamDescs().ToParams()
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		switch failOn {
+		case failOnNone, failOnBreaking, failOnAny:
+		default:
+			return fmt.Errorf("invalid fail-on mode %q, valid values are: %s", failOn, strings.Join(failOnModes, ", "))
+		}
+
+		runtime.Init(runtimeGlobalParams)
+		defer runtime.Close()
+
+		ops, err := initDataOperators(cmd, opGlobalParams)
+		if err != nil {
+			return err
+		}
+
+		paramValueMap := make(map[string]string)
+		ociParams.CopyToMap(paramValueMap, "operator.oci.")
+
+		infos := make([]*api.GadgetInfo, 0, len(args))
+		for _, image := range args {
+			gadgetCtx := gadgetcontext.New(
+				context.Background(),
+				image,
+				gadgetcontext.WithDataOperators(ops...),
+				gadgetcontext.WithUseInstance(false),
+				gadgetcontext.IncludeExtraInfo(true),
+			)
+
+			info, err := runtime.GetGadgetInfo(gadgetCtx, runtimeParams, paramValueMap)
+			if err != nil {
+				return fmt.Errorf("getting gadget info for %q: %w", image, err)
+			}
+			infos = append(infos, info)
+		}
+
+		changes, err := gadgetdiff.Compare(infos[0], infos[1])
+		if err != nil {
+			return fmt.Errorf("comparing gadget images: %w", err)
+		}
+
+		switch outputMode {
+		case utils.OutputModeJSON:
+			bytes, err := json.Marshal(changes)
+			if err != nil {
+				return fmt.Errorf("marshalling changes to JSON: %w", err)
+			}
+			fmt.Fprint(cmd.OutOrStdout(), string(bytes), "\n")
+		case utils.OutputModeJSONPretty:
+			bytes, err := json.MarshalIndent(changes, "", "  ")
+			if err != nil {
+				return fmt.Errorf("marshalling changes to JSON (pretty): %w", err)
+			}
+			fmt.Fprint(cmd.OutOrStdout(), string(bytes), "\n")
+		case utils.OutputModeYAML:
+			bytes, err := yaml.Marshal(changes)
+			if err != nil {
+				return fmt.Errorf("marshalling changes to YAML: %w", err)
+			}
+			fmt.Fprint(cmd.OutOrStdout(), string(bytes))
+		case utils.OutputModeColumns:
+			if len(changes) == 0 {
+				cmd.Println("No differences found")
+				break
+			}
+			cols := columns.MustCreateColumns[gadgetdiff.Change]()
+			formatter := textcolumns.NewFormatter(cols.GetColumnMap())
+			formatter.WriteTable(cmd.OutOrStdout(), changes)
+		default:
+			return fmt.Errorf("invalid output mode %q, valid values are: %s", outputMode, strings.Join(outputModes, ", "))
+		}
+
+		switch {
+		case failOn == failOnAny && len(changes) > 0:
+			return fmt.Errorf("found %d change(s) between %q and %q", len(changes), args[0], args[1])
+		case failOn == failOnBreaking && gadgetdiff.HasBreaking(changes):
+			return fmt.Errorf("found breaking changes between %q and %q", args[0], args[1])
+		}
+
+		return nil
+	}
+
+	cmd.Flags().StringVarP(
+		&outputMode,
+		"output",
+		"o",
+		utils.OutputModeColumns,
+		fmt.Sprintf("Output mode, possible values are, %s", strings.Join(outputModes, ", ")),
+	)
+	cmd.Flags().StringVar(
+		&failOn,
+		"fail-on",
+		failOnNone,
+		fmt.Sprintf("Exit with an error if changes of the given kind are found, possible values are, %s", strings.Join(failOnModes, ", ")),
+	)
+
+	// We don't want to add the headless-related flags to the diff command
+	skipParams := []string{"!attach"}
+
+	for _, operatorParams := range opGlobalParams {
+		common.AddOCIFlags(cmd, operatorParams, skipParams, runtime)
+	}
+	common.AddOCIFlags(cmd, ociParams, skipParams, runtime)
+	common.AddOCIFlags(cmd, runtimeGlobalParams, skipParams, runtime)
+	common.AddOCIFlags(cmd, runtimeParams, skipParams, runtime)
+
+	return cmd
+}
@@ -43,7 +43,9 @@ func NewImageCmd(r runtime.Runtime, addCommands []*cobra.Command) *cobra.Command
 	cmd.AddCommand(NewTagCmd())
 	cmd.AddCommand(NewListCmd())
 	cmd.AddCommand(NewInspectCmd(r))
+	cmd.AddCommand(NewDiffCmd(r))
 	cmd.AddCommand(NewRemoveCmd())
+	cmd.AddCommand(NewPruneCmd())
 	cmd.AddCommand(NewVerifyCmd())
 
 	return cmd
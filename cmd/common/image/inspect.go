@@ -69,22 +69,9 @@ func NewInspectCmd(runtime runtime.Runtime) *cobra.Command {
 		runtime.Init(runtimeGlobalParams)
 		defer runtime.Close()
 
-		// set global operator flags from the config file
-		for o, p := range opGlobalParams {
-			err := common.SetFlagsForParams(cmd, p, config.OperatorKey+"."+o)
-			if err != nil {
-				return fmt.Errorf("setting operator %s flags: %w", o, err)
-			}
-		}
-
-		ops := make([]operators.DataOperator, 0)
-		for _, op := range operators.GetDataOperators() {
-			// Initialize operator
-			err := op.Init(opGlobalParams[op.Name()])
-			if err != nil {
-				continue
-			}
-			ops = append(ops, op)
+		ops, err := initDataOperators(cmd, opGlobalParams)
+		if err != nil {
+			return err
 		}
 
 		gadgetCtx := gadgetcontext.New(
@@ -262,3 +249,25 @@ func NewInspectCmd(runtime runtime.Runtime) *cobra.Command {
 
 	return cmd
 }
+
+// initDataOperators sets the global operator flags from the config file and
+// initializes all data operators that can be initialized
+func initDataOperators(cmd *cobra.Command, opGlobalParams map[string]*params.Params) ([]operators.DataOperator, error) {
+	for o, p := range opGlobalParams {
+		err := common.SetFlagsForParams(cmd, p, config.OperatorKey+"."+o)
+		if err != nil {
+			return nil, fmt.Errorf("setting operator %s flags: %w", o, err)
+		}
+	}
+
+	ops := make([]operators.DataOperator, 0)
+	for _, op := range operators.GetDataOperators() {
+		// Initialize operator
+		err := op.Init(opGlobalParams[op.Name()])
+		if err != nil {
+			continue
+		}
+		ops = append(ops, op)
+	}
+	return ops, nil
+}
@@ -0,0 +1,111 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package image
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/docker/go-units"
+	"github.com/spf13/cobra"
+
+	"github.com/inspektor-gadget/inspektor-gadget/cmd/common/utils"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/oci"
+)
+
+func NewPruneCmd() *cobra.Command {
+	var opts oci.PruneOptions
+	var maxSize string
+	var outputMode string
+
+	outputModes := []string{utils.OutputModeColumns, utils.OutputModeJSON, utils.OutputModeJSONPretty}
+
+	cmd := &cobra.Command{
+		Use:          "prune",
+		Short:        "Remove unused gadget images and their content from the host",
+		SilenceUsage: true,
+		Args:         cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if maxSize != "" {
+				size, err := units.RAMInBytes(maxSize)
+				if err != nil {
+					return fmt.Errorf("parsing max size %q: %w", maxSize, err)
+				}
+				opts.MaxStoreSize = size
+			}
+			if opts.KeepLast < 0 {
+				return fmt.Errorf("keep-last must be positive, got %d", opts.KeepLast)
+			}
+
+			report, err := oci.PruneGadgetImages(context.TODO(), &opts)
+			if err != nil {
+				return fmt.Errorf("pruning gadget images: %w", err)
+			}
+
+			switch outputMode {
+			case utils.OutputModeJSON:
+				bytes, err := json.Marshal(report)
+				if err != nil {
+					return fmt.Errorf("marshalling report to JSON: %w", err)
+				}
+				fmt.Fprint(cmd.OutOrStdout(), string(bytes))
+			case utils.OutputModeJSONPretty:
+				bytes, err := json.MarshalIndent(report, "", "  ")
+				if err != nil {
+					return fmt.Errorf("marshalling report to JSON: %w", err)
+				}
+				fmt.Fprint(cmd.OutOrStdout(), string(bytes))
+			case utils.OutputModeColumns:
+				action := "Removed"
+				if opts.DryRun {
+					action = "Would remove"
+				}
+				for _, image := range report.Images {
+					cmd.Printf("%s %s\n", action, image)
+				}
+				for _, dgst := range report.Untagged {
+					cmd.Printf("%s untagged image @%s\n", action, dgst)
+				}
+				reclaimed := "Reclaimed"
+				if opts.DryRun {
+					reclaimed = "Would reclaim"
+				}
+				cmd.Printf("%s %s\n", reclaimed, units.BytesSize(float64(report.ReclaimedBytes)))
+			default:
+				return fmt.Errorf("invalid output mode %q, valid values are: %s", outputMode, strings.Join(outputModes, ", "))
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&opts.AllUntagged, "all-untagged", false, "Remove images that aren't referenced by any tag")
+	cmd.Flags().DurationVar(&opts.OlderThan, "older-than", time.Duration(0), "Remove images created before the given duration, e.g. 720h")
+	cmd.Flags().IntVar(&opts.KeepLast, "keep-last", 0, "Keep only the given number of most recently created images per repository")
+	cmd.Flags().StringVar(&maxSize, "max-size", "", "Remove least recently used images until the store is smaller than the given size, e.g. 500MiB")
+	cmd.Flags().BoolVar(&opts.DryRun, "dry-run", false, "Only show what would be removed")
+	cmd.Flags().StringVarP(
+		&outputMode,
+		"output",
+		"o",
+		utils.OutputModeColumns,
+		fmt.Sprintf("Output mode, possible values are, %s", strings.Join(outputModes, ", ")),
+	)
+
+	return cmd
+}
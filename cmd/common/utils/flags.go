@@ -155,6 +155,13 @@ func AddRegistryAuthVariablesAndFlags(cmd *cobra.Command, authOptions *oci.AuthO
 		[]string{},
 		"List of registries to access over plain HTTP",
 	)
+
+	cmd.Flags().StringVar(
+		&authOptions.RegistriesConfig,
+		"registries-config",
+		oci.DefaultRegistriesConfig,
+		"Path of a containers-registries.conf file used to rewrite registry locations and to pull from mirrors",
+	)
 }
 
 // removeSplitSortArgs removes the --sort flag with its arg, if it isn't in the
@@ -97,9 +97,10 @@ func main() {
 		log.Fatalf("setting runtime flags from config: %v", err)
 	}
 
-	// add image subcommands to be added, for now only inspect is supported
+	// add image subcommands to be added, for now only inspect and diff are supported
 	imgCommands := []*cobra.Command{
 		image.NewInspectCmd(runtime),
+		image.NewDiffCmd(runtime),
 	}
 
 	hiddenColumnTags := []string{"kubernetes"}
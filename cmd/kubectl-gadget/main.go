@@ -147,9 +147,10 @@ func main() {
 		log.Warn(err.Error())
 	}
 
-	// add image subcommands to be added, for now only inspect is supported
+	// add image subcommands to be added, for now only inspect and diff are supported
 	imgCommands := []*cobra.Command{
 		img.NewInspectCmd(grpcRuntime),
+		img.NewDiffCmd(grpcRuntime),
 	}
 
 	hiddenColumnTags := []string{"runtime"}
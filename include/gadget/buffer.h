@@ -99,4 +99,182 @@ static __always_inline long __gadget_output_buf(void *ctx, void *map,
 	return bpf_perf_event_output(ctx, map, BPF_F_CURRENT_CPU, buf, size);
 }
 
+// Sampling: tracer maps declared with GADGET_SAMPLED_TRACER_MAP() only send 1
+// out of <map>_sample_rate events to userspace. The rate is set by the eBPF
+// operator, according to how fast userspace consumes the events.
+//
+// The rate can change while events wait in the buffer, so each record of these
+// maps starts with a header holding the rate the event was sampled with. Their
+// events have to be written with the *_sampled_buf() helpers below, and the
+// ones of GADGET_TRACER_MAP() with the other helpers.
+
+// It's 8 bytes long to keep the event aligned.
+struct gadget_sample_header {
+	__u32 sample_rate;
+	__u32 reserved;
+};
+
+#define GADGET_SAMPLED_TRACER_MAP(name, size)                          \
+	GADGET_TRACER_MAP(name, size)                                  \
+                                                                       \
+	struct {                                                       \
+		__uint(type, BPF_MAP_TYPE_PERCPU_ARRAY);               \
+		__uint(max_entries, 1);                                \
+		__uint(key_size, sizeof(__u32));                       \
+		__uint(value_size, sizeof(struct gadget_sample_header) \
+					   + GADGET_MAX_EVENT_SIZE);   \
+	} name##_sample_heap SEC(".maps");                             \
+                                                                       \
+	volatile __u32 name##_sample_rate = 1;
+
+// _sample_rate has to be suffixed because user will give &map as argument.
+#define gadget_sample(map) __gadget_sampled(*map##_sample_rate, false, 0)
+
+// gadget_sample_key() samples by key instead of randomly: all the events with
+// the same key are either kept or dropped, e.g. to keep whole flows or all the
+// events of a process.
+#define gadget_sample_key(map, key) \
+	__gadget_sampled(*map##_sample_rate, true, key)
+
+static __always_inline bool __gadget_sampled(__u32 rate, bool by_key,
+					     __u64 key)
+{
+	if (rate <= 1)
+		return true;
+
+	if (!by_key)
+		return bpf_get_prandom_u32() % rate == 0;
+
+	// Fibonacci hashing, so consecutive keys are spread evenly
+	return (__u32)((key * 0x9E3779B97F4A7C15ULL) >> 32) % rate == 0;
+}
+
+static __always_inline void
+__gadget_set_sample_header(struct gadget_sample_header *hdr, __u32 rate)
+{
+	hdr->sample_rate = rate > 1 ? rate : 1;
+	hdr->reserved = 0;
+}
+
+static __always_inline void __gadget_count_lost(void *lost_samples)
+{
+	const int zero = 0;
+	__u64 *cnt = bpf_map_lookup_elem(lost_samples, &zero);
+
+	if (cnt)
+		*cnt += 1;
+}
+
+// _lost_samples, _sample_heap and _sample_rate have to be suffixed because
+// user will give &map as argument.
+#define gadget_reserve_sampled_buf(map, size)                              \
+	__gadget_reserve_sampled_buf(map, map##_lost_samples,              \
+				     map##_sample_heap, map##_sample_rate, \
+				     false, 0, size)
+
+// Like gadget_reserve_sampled_buf(), but sampling by key. See
+// gadget_sample_key().
+#define gadget_reserve_sampled_buf_key(map, size, key)                     \
+	__gadget_reserve_sampled_buf(map, map##_lost_samples,              \
+				     map##_sample_heap, map##_sample_rate, \
+				     true, key, size)
+
+static __always_inline void *
+__gadget_reserve_sampled_buf(void *map, void *lost_samples, void *heap,
+			     volatile __u32 *sample_rate, bool by_key,
+			     __u64 key, __u64 size)
+{
+	struct gadget_sample_header *hdr;
+	const int zero = 0;
+	__u32 rate = *sample_rate;
+
+	if (!__gadget_sampled(rate, by_key, key))
+		return NULL;
+
+	if (bpf_core_enum_value_exists(enum bpf_func_id,
+				       BPF_FUNC_ringbuf_reserve)) {
+		hdr = bpf_ringbuf_reserve(map, sizeof(*hdr) + size, 0);
+		if (hdr == NULL) {
+			__gadget_count_lost(lost_samples);
+			return NULL;
+		}
+	} else {
+		hdr = bpf_map_lookup_elem(heap, &zero);
+		if (hdr == NULL)
+			return NULL;
+	}
+
+	__gadget_set_sample_header(hdr, rate);
+	return hdr + 1;
+}
+
+static __always_inline void gadget_discard_sampled_buf(void *buf)
+{
+	if (bpf_core_enum_value_exists(enum bpf_func_id,
+				       BPF_FUNC_ringbuf_discard))
+		bpf_ringbuf_discard((struct gadget_sample_header *)buf - 1, 0);
+}
+
+static __always_inline long gadget_submit_sampled_buf(void *ctx, void *map,
+						      void *buf, __u64 size)
+{
+	struct gadget_sample_header *hdr =
+		(struct gadget_sample_header *)buf - 1;
+
+	if (bpf_core_enum_value_exists(enum bpf_func_id,
+				       BPF_FUNC_ringbuf_submit)) {
+		bpf_ringbuf_submit(hdr, 0);
+		return 0;
+	}
+
+	return bpf_perf_event_output(ctx, map, BPF_F_CURRENT_CPU, hdr,
+				     sizeof(*hdr) + size);
+}
+
+// gadget_output_sampled_buf() copies the event after the sample header, so
+// events larger than GADGET_MAX_EVENT_SIZE are counted as lost.
+#define gadget_output_sampled_buf(ctx, map, buf, size)                    \
+	__gadget_output_sampled_buf(ctx, map, map##_lost_samples,         \
+				    map##_sample_heap, map##_sample_rate, \
+				    false, 0, buf, size)
+
+// Like gadget_output_sampled_buf(), but sampling by key. See
+// gadget_sample_key().
+#define gadget_output_sampled_buf_key(ctx, map, buf, size, key)           \
+	__gadget_output_sampled_buf(ctx, map, map##_lost_samples,         \
+				    map##_sample_heap, map##_sample_rate, \
+				    true, key, buf, size)
+
+static __always_inline long
+__gadget_output_sampled_buf(void *ctx, void *map, void *lost_samples,
+			    void *heap, volatile __u32 *sample_rate,
+			    bool by_key, __u64 key, void *buf, __u64 size)
+{
+	struct gadget_sample_header *hdr;
+	const int zero = 0;
+	__u32 rate = *sample_rate;
+
+	if (!__gadget_sampled(rate, by_key, key))
+		return 0;
+
+	hdr = bpf_map_lookup_elem(heap, &zero);
+	if (hdr == NULL || size > GADGET_MAX_EVENT_SIZE) {
+		__gadget_count_lost(lost_samples);
+		return 0;
+	}
+
+	__gadget_set_sample_header(hdr, rate);
+	bpf_probe_read_kernel(hdr + 1, size, buf);
+
+	if (bpf_core_enum_value_exists(enum bpf_func_id,
+				       BPF_FUNC_ringbuf_output)) {
+		if (bpf_ringbuf_output(map, hdr, sizeof(*hdr) + size, 0))
+			__gadget_count_lost(lost_samples);
+		return 0;
+	}
+
+	return bpf_perf_event_output(ctx, map, BPF_F_CURRENT_CPU, hdr,
+				     sizeof(*hdr) + size);
+}
+
 #endif /* __BUFFER_BPF_H */
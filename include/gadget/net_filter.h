@@ -0,0 +1,92 @@
+/* SPDX-License-Identifier: (GPL-2.0 WITH Linux-syscall-note) OR Apache-2.0 */
+
+// This file defines params taking lists of CIDRs and port ranges. Their values
+// are stored in maps by Inspektor Gadget before the programs are loaded, so
+// events can be filtered in eBPF instead of sending them to user space.
+
+#ifndef NET_FILTER_H
+#define NET_FILTER_H
+
+#include <bpf/bpf_helpers.h>
+#include <gadget/types.h>
+
+#ifndef GADGET_MAX_CIDRS
+#define GADGET_MAX_CIDRS 1024
+#endif
+
+// Keep this aligned with pkg/operators/ebpf/paramlists.go
+
+// Key of the LPM tries used by GADGET_PARAM_CIDR_LIST. IPv4 addresses are
+// stored as IPv4-mapped IPv6 addresses (::ffff:a.b.c.d).
+struct gadget_cidr_key {
+	__u32 prefixlen;
+	__u8 addr[16];
+};
+
+// GADGET_PARAM_CIDR_LIST defines a param called name that takes a list of
+// IPs or CIDRs, e.g. 10.0.0.0/8,fd00::/8. Use gadget_cidr_list_match() to
+// check if an address is part of the list.
+#define GADGET_PARAM_CIDR_LIST(name)                       \
+	struct {                                           \
+		__uint(type, BPF_MAP_TYPE_LPM_TRIE);       \
+		__uint(max_entries, GADGET_MAX_CIDRS);     \
+		__uint(map_flags, BPF_F_NO_PREALLOC);      \
+		__type(key, struct gadget_cidr_key);       \
+		__type(value, __u8);                       \
+	} name SEC(".maps");                               \
+	const volatile bool name##_set = false;            \
+	const void *gadget_paramlist_##name __attribute__((unused));
+
+// GADGET_PARAM_PORT_RANGE_LIST defines a param called name that takes a list
+// of ports or port ranges, e.g. 53,8000-8080. Use
+// gadget_port_range_list_match() to check if a port is part of the list.
+#define GADGET_PARAM_PORT_RANGE_LIST(name)                 \
+	struct {                                           \
+		__uint(type, BPF_MAP_TYPE_ARRAY);          \
+		__uint(max_entries, 65536);                \
+		__type(key, __u32);                        \
+		__type(value, __u8);                       \
+	} name SEC(".maps");                               \
+	const volatile bool name##_set = false;            \
+	const void *gadget_paramlist_##name __attribute__((unused));
+
+static __always_inline bool
+__gadget_cidr_list_lookup(void *map, const union gadget_ip_addr_t *addr,
+			  __u8 version)
+{
+	struct gadget_cidr_key key = {
+		.prefixlen = 128,
+	};
+
+	if (version == 4) {
+		key.addr[10] = 0xff;
+		key.addr[11] = 0xff;
+		__builtin_memcpy(&key.addr[12], &addr->v4, sizeof(addr->v4));
+	} else {
+		__builtin_memcpy(key.addr, addr->v6, sizeof(addr->v6));
+	}
+
+	return bpf_map_lookup_elem(map, &key) != NULL;
+}
+
+static __always_inline bool __gadget_port_range_list_lookup(void *map,
+							    __u16 port)
+{
+	__u32 key = port;
+	__u8 *val = bpf_map_lookup_elem(map, &key);
+
+	return val && *val;
+}
+
+// gadget_cidr_list_match returns true if the address (union gadget_ip_addr_t
+// in network byte order) belongs to one of the CIDRs of the param name or if
+// the param wasn't set.
+#define gadget_cidr_list_match(name, addr, version) \
+	(!name##_set || __gadget_cidr_list_lookup(&name, addr, version))
+
+// gadget_port_range_list_match returns true if the port (host byte order)
+// belongs to one of the ranges of the param name or if the param wasn't set.
+#define gadget_port_range_list_match(name, port) \
+	(!name##_set || __gadget_port_range_list_lookup(&name, port))
+
+#endif
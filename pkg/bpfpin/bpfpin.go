@@ -0,0 +1,136 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package bpfpin manages the directories in bpffs where the eBPF objects of gadget instances are
+// pinned. Pinned links and maps survive a restart of the process that created them, so a gadget
+// instance recreated from the store can take them over instead of starting from scratch.
+//
+// The layout of the directory of an instance is:
+//
+//	/sys/fs/bpf/ig/<instance-id>/digest       digest of the eBPF object that created the pins
+//	/sys/fs/bpf/ig/<instance-id>/maps/<name>  pinned maps
+//	/sys/fs/bpf/ig/<instance-id>/links/<name> pinned links, by program name
+package bpfpin
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/cilium/ebpf/link"
+)
+
+// Root is the directory where the objects of all gadget instances are pinned
+const Root = "/sys/fs/bpf/ig"
+
+const (
+	digestFile = "digest"
+	mapsDir    = "maps"
+	linksDir   = "links"
+)
+
+// root can be changed by tests
+var root = Root
+
+var validID = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+func instanceDir(instanceID string) (string, error) {
+	if !validID.MatchString(instanceID) {
+		return "", fmt.Errorf("invalid instance id %q", instanceID)
+	}
+	return filepath.Join(root, instanceID), nil
+}
+
+// Dir is the pin directory of a gadget instance
+type Dir struct {
+	path string
+}
+
+// Open opens (and creates if needed) the pin directory of the given instance. digest identifies
+// the eBPF object the pinned objects belong to. If the directory contains objects pinned for a
+// different digest, they are removed, as they can't be reused safely. The returned bool tells
+// whether objects pinned by a previous run can be reused.
+func Open(instanceID, digest string) (*Dir, bool, error) {
+	path, err := instanceDir(instanceID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	reuse := false
+	buf, err := os.ReadFile(filepath.Join(path, digestFile))
+	switch {
+	case err == nil && strings.TrimSpace(string(buf)) == digest:
+		reuse = true
+	case err == nil, !errors.Is(err, os.ErrNotExist):
+		// Written by a different image or unreadable: start from scratch
+		if err := os.RemoveAll(path); err != nil {
+			return nil, false, fmt.Errorf("removing stale pin directory: %w", err)
+		}
+	}
+
+	for _, dir := range []string{mapsDir, linksDir} {
+		if err := os.MkdirAll(filepath.Join(path, dir), 0o700); err != nil {
+			return nil, false, fmt.Errorf("creating pin directory: %w", err)
+		}
+	}
+	if !reuse {
+		if err := os.WriteFile(filepath.Join(path, digestFile), []byte(digest), 0o600); err != nil {
+			return nil, false, fmt.Errorf("writing digest: %w", err)
+		}
+	}
+
+	return &Dir{path: path}, reuse, nil
+}
+
+// MapsPath returns the directory to use as ebpf.MapOptions.PinPath
+func (d *Dir) MapsPath() string {
+	return filepath.Join(d.path, mapsDir)
+}
+
+// ResetMaps removes all pinned maps, e.g. because they're not compatible with the current
+// configuration of the instance anymore
+func (d *Dir) ResetMaps() error {
+	if err := os.RemoveAll(d.MapsPath()); err != nil {
+		return err
+	}
+	return os.MkdirAll(d.MapsPath(), 0o700)
+}
+
+func (d *Dir) linkPath(progName string) string {
+	return filepath.Join(d.path, linksDir, progName)
+}
+
+// LoadLink loads the link pinned for the given program. It returns an error wrapping
+// os.ErrNotExist if there is none.
+func (d *Dir) LoadLink(progName string) (link.Link, error) {
+	return link.LoadPinnedLink(d.linkPath(progName), nil)
+}
+
+// PinLink pins the link of the given program
+func (d *Dir) PinLink(progName string, l link.Link) error {
+	return l.Pin(d.linkPath(progName))
+}
+
+// Remove removes the pin directory of the given instance. Links that aren't used by any process
+// anymore are detached and maps are freed.
+func Remove(instanceID string) error {
+	path, err := instanceDir(instanceID)
+	if err != nil {
+		return err
+	}
+	return os.RemoveAll(path)
+}
@@ -0,0 +1,64 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bpfpin
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpen(t *testing.T) {
+	root = t.TempDir()
+	t.Cleanup(func() { root = Root })
+
+	const id = "4f5ae12c54bd7c2058c0484ebd13dbc2"
+
+	_, reuse, err := Open(id, "sha256:aaaa")
+	require.NoError(t, err)
+	require.False(t, reuse)
+
+	// Simulate a pinned map
+	marker := filepath.Join(root, id, mapsDir, "events")
+	require.NoError(t, os.WriteFile(marker, nil, 0o600))
+
+	d, reuse, err := Open(id, "sha256:aaaa")
+	require.NoError(t, err)
+	require.True(t, reuse)
+	require.Equal(t, filepath.Join(root, id, mapsDir), d.MapsPath())
+	require.FileExists(t, marker)
+
+	// A different digest removes the objects pinned before
+	_, reuse, err = Open(id, "sha256:bbbb")
+	require.NoError(t, err)
+	require.False(t, reuse)
+	require.NoFileExists(t, marker)
+	buf, err := os.ReadFile(filepath.Join(root, id, digestFile))
+	require.NoError(t, err)
+	require.Equal(t, "sha256:bbbb", string(buf))
+
+	require.NoError(t, Remove(id))
+	require.NoDirExists(t, filepath.Join(root, id))
+}
+
+func TestInvalidID(t *testing.T) {
+	for _, id := range []string{"", "..", "../foo", "a/b"} {
+		_, _, err := Open(id, "sha256:aaaa")
+		require.Error(t, err, id)
+		require.Error(t, Remove(id), id)
+	}
+}
@@ -34,6 +34,7 @@ const (
 	VerifyImage        = "verify-image"
 	PublicKeys         = "public-keys"
 	InsecureRegistries = "insecure-registries"
+	RegistriesConfig   = "registries-config"
 	DisallowPulling    = "disallow-pulling"
 	AllowedGadgets     = "allowed-gadgets"
 
@@ -87,7 +88,7 @@ func isRootKey(key string) bool {
 // TODO: Remove in the future once we remove the flags from kubectl-gadget deploy.
 func isOciKey(key string) bool {
 	switch key {
-	case VerifyImage, PublicKeys, AllowedGadgets, InsecureRegistries, DisallowPulling:
+	case VerifyImage, PublicKeys, AllowedGadgets, InsecureRegistries, RegistriesConfig, DisallowPulling:
 		return true
 	default:
 		return false
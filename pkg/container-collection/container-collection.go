@@ -28,6 +28,7 @@ import (
 	log "github.com/sirupsen/logrus"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	containerutils "github.com/inspektor-gadget/inspektor-gadget/pkg/container-utils"
 	eventtypes "github.com/inspektor-gadget/inspektor-gadget/pkg/types"
 )
 
@@ -274,6 +275,16 @@ func (cc *ContainerCollection) LookupContainerByMntns(mntnsid uint64) *Container
 	return container.(*Container)
 }
 
+// LookupContainerByPid returns the container the process with the given pid
+// runs in, by looking up its mount namespace. If not found nil is returned.
+func (cc *ContainerCollection) LookupContainerByPid(pid uint32) *Container {
+	mntnsid, err := containerutils.GetMntNs(int(pid))
+	if err != nil {
+		return nil
+	}
+	return cc.LookupContainerByMntns(mntnsid)
+}
+
 // LookupContainersByNetns returns a slice of containers that run in a given
 // network namespace. Or an empty slice if there are no containers running in
 // that network namespace.
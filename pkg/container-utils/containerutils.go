@@ -138,21 +138,27 @@ func GetIfacePeers(pid int) ([]*net.Interface, error) {
 		}
 
 		for _, link := range links {
-			veth, ok := link.(*netlink.Veth)
-			if !ok {
-				continue
+			switch l := link.(type) {
+			case *netlink.Veth:
+				if l.Flags&net.FlagUp == 0 {
+					continue
+				}
+
+				ifaceLink, err := netlink.VethPeerIndex(l)
+				if err != nil {
+					return fmt.Errorf("getting veth's pair index: %w", err)
+				}
+
+				ifaceLinks = append(ifaceLinks, ifaceLink)
+			case *netlink.Netkit:
+				if l.Flags&net.FlagUp == 0 {
+					continue
+				}
+
+				// The parent of the netkit peer in the container is the primary
+				// device on the host.
+				ifaceLinks = append(ifaceLinks, l.ParentIndex)
 			}
-
-			if veth.Flags&net.FlagUp == 0 {
-				continue
-			}
-
-			ifaceLink, err := netlink.VethPeerIndex(veth)
-			if err != nil {
-				return fmt.Errorf("getting veth's pair index: %w", err)
-			}
-
-			ifaceLinks = append(ifaceLinks, ifaceLink)
 		}
 
 		return nil
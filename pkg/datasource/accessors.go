@@ -98,6 +98,9 @@ type FieldAccessor interface {
 	// Type returns the underlying type of the field
 	Type() api.Kind
 
+	// ElementType returns the type of the elements of a List field or of the values of a Map field
+	ElementType() api.Kind
+
 	// Flags returns the flags of the field
 	Flags() uint32
 
@@ -164,6 +167,16 @@ type FieldAccessor interface {
 	PutString(Data, string) error
 	PutBytes(Data, []byte) error
 	PutBool(Data, bool) error
+
+	// List returns the elements of a List field; their types match ElementType()
+	List(Data) ([]any, error)
+	// Map returns the entries of a Map field; the types of the values match ElementType()
+	Map(Data) (map[string]any, error)
+	StringList(Data) ([]string, error)
+
+	PutList(Data, []any) error
+	PutMap(Data, map[string]any) error
+	PutStringList(Data, []string) error
 }
 
 type fieldAccessor struct {
@@ -306,6 +319,9 @@ func (a *fieldAccessor) AddSubField(name string, kind api.Kind, opts ...FieldOpt
 	for _, opt := range opts {
 		opt(nf)
 	}
+	if err := checkElementKind(nf); err != nil {
+		return nil, err
+	}
 
 	if _, ok := a.ds.fieldMap[nf.FullName]; ok {
 		return nil, fmt.Errorf("field with name %q already exists", nf.FullName)
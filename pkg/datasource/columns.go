@@ -188,6 +188,25 @@ func (ds *dataSource) Columns() (*columns.Columns[DataTuple], error) {
 			continue
 		}
 
+		if IsCompositeKind(f.Kind) {
+			acc := &fieldAccessor{
+				ds: ds,
+				f:  f,
+			}
+
+			err := cols.AddColumn(*df.Attributes, func(d *DataTuple) any {
+				if d.data == nil {
+					return ""
+				}
+				return acc.compositeString(d.data)
+			})
+			if err != nil {
+				return nil, fmt.Errorf("creating columns: %w", err)
+			}
+
+			continue
+		}
+
 		if f.ReflectType() == nil {
 			df.Type = reflect.TypeOf([]byte{})
 
@@ -0,0 +1,408 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datasource
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+)
+
+// Fields of kind List and Map store their elements in a single payload:
+//
+//   - elements of fixed size (numbers and bools) are concatenated using the
+//     byte order of the DataSource
+//   - strings and bytes are prefixed by their length as uint32
+//   - map entries are stored as the key (a string, prefixed by its length)
+//     followed by the value; entries are sorted by key
+
+// IsCompositeKind returns whether fields of the given kind hold multiple
+// elements of their element kind
+func IsCompositeKind(kind api.Kind) bool {
+	return kind == api.Kind_List || kind == api.Kind_Map
+}
+
+// validElementKind returns whether kind can be used as element kind of a
+// composite field
+func validElementKind(kind api.Kind) bool {
+	switch kind {
+	case api.Kind_Bool,
+		api.Kind_Int8, api.Kind_Int16, api.Kind_Int32, api.Kind_Int64,
+		api.Kind_Uint8, api.Kind_Uint16, api.Kind_Uint32, api.Kind_Uint64,
+		api.Kind_Float32, api.Kind_Float64,
+		api.Kind_String, api.Kind_CString, api.Kind_Bytes:
+		return true
+	}
+	return false
+}
+
+// checkElementKind verifies that composite fields have a valid element kind
+// and that other fields don't have one
+func checkElementKind(f *field) error {
+	if !IsCompositeKind(f.Kind) {
+		if f.ElementKind != api.Kind_Invalid {
+			return fmt.Errorf("field %q of kind %s can't have an element kind", f.Name, f.Kind)
+		}
+		return nil
+	}
+	if !validElementKind(f.ElementKind) {
+		return fmt.Errorf("invalid element kind %s for field %q of kind %s", f.ElementKind, f.Name, f.Kind)
+	}
+	return nil
+}
+
+func appendLength(bo binary.ByteOrder, b []byte, kind api.Kind, v any) ([]byte, error) {
+	var val []byte
+	switch v := v.(type) {
+	case string:
+		val = []byte(v)
+	case []byte:
+		val = v
+	default:
+		return nil, fmt.Errorf("invalid type %T for element of kind %s", v, kind)
+	}
+	if uint64(len(val)) > math.MaxUint32 {
+		return nil, fmt.Errorf("element too large: %d bytes", len(val))
+	}
+	b = appendUint32(bo, b, uint32(len(val)))
+	return append(b, val...), nil
+}
+
+func appendUint16(bo binary.ByteOrder, b []byte, v uint16) []byte {
+	b = append(b, make([]byte, 2)...)
+	bo.PutUint16(b[len(b)-2:], v)
+	return b
+}
+
+func appendUint32(bo binary.ByteOrder, b []byte, v uint32) []byte {
+	b = append(b, make([]byte, 4)...)
+	bo.PutUint32(b[len(b)-4:], v)
+	return b
+}
+
+func appendUint64(bo binary.ByteOrder, b []byte, v uint64) []byte {
+	b = append(b, make([]byte, 8)...)
+	bo.PutUint64(b[len(b)-8:], v)
+	return b
+}
+
+// appendElement appends the encoded value v of the given kind to b
+func appendElement(bo binary.ByteOrder, b []byte, kind api.Kind, v any) ([]byte, error) {
+	ok := true
+	switch kind {
+	case api.Kind_Bool:
+		var val bool
+		if val, ok = v.(bool); ok {
+			if val {
+				b = append(b, 1)
+			} else {
+				b = append(b, 0)
+			}
+		}
+	case api.Kind_Int8:
+		var val int8
+		if val, ok = v.(int8); ok {
+			b = append(b, uint8(val))
+		}
+	case api.Kind_Int16:
+		var val int16
+		if val, ok = v.(int16); ok {
+			b = appendUint16(bo, b, uint16(val))
+		}
+	case api.Kind_Int32:
+		var val int32
+		if val, ok = v.(int32); ok {
+			b = appendUint32(bo, b, uint32(val))
+		}
+	case api.Kind_Int64:
+		var val int64
+		if val, ok = v.(int64); ok {
+			b = appendUint64(bo, b, uint64(val))
+		}
+	case api.Kind_Uint8:
+		var val uint8
+		if val, ok = v.(uint8); ok {
+			b = append(b, val)
+		}
+	case api.Kind_Uint16:
+		var val uint16
+		if val, ok = v.(uint16); ok {
+			b = appendUint16(bo, b, val)
+		}
+	case api.Kind_Uint32:
+		var val uint32
+		if val, ok = v.(uint32); ok {
+			b = appendUint32(bo, b, val)
+		}
+	case api.Kind_Uint64:
+		var val uint64
+		if val, ok = v.(uint64); ok {
+			b = appendUint64(bo, b, val)
+		}
+	case api.Kind_Float32:
+		var val float32
+		if val, ok = v.(float32); ok {
+			b = appendUint32(bo, b, math.Float32bits(val))
+		}
+	case api.Kind_Float64:
+		var val float64
+		if val, ok = v.(float64); ok {
+			b = appendUint64(bo, b, math.Float64bits(val))
+		}
+	case api.Kind_String, api.Kind_CString, api.Kind_Bytes:
+		return appendLength(bo, b, kind, v)
+	default:
+		return nil, fmt.Errorf("invalid element kind %s", kind)
+	}
+	if !ok {
+		return nil, fmt.Errorf("invalid type %T for element of kind %s", v, kind)
+	}
+	return b, nil
+}
+
+// readElement decodes the first element of the given kind from b and returns
+// it together with the remaining bytes
+func readElement(bo binary.ByteOrder, b []byte, kind api.Kind) (any, []byte, error) {
+	var size int
+	switch kind {
+	case api.Kind_Bool, api.Kind_Int8, api.Kind_Uint8:
+		size = 1
+	case api.Kind_Int16, api.Kind_Uint16:
+		size = 2
+	case api.Kind_Int32, api.Kind_Uint32, api.Kind_Float32:
+		size = 4
+	case api.Kind_Int64, api.Kind_Uint64, api.Kind_Float64:
+		size = 8
+	case api.Kind_String, api.Kind_CString, api.Kind_Bytes:
+		if len(b) < 4 {
+			return nil, nil, invalidFieldLengthErr(len(b), 4)
+		}
+		l := bo.Uint32(b)
+		b = b[4:]
+		if uint64(len(b)) < uint64(l) {
+			return nil, nil, invalidFieldLengthErr(len(b), int(l))
+		}
+		if kind == api.Kind_Bytes {
+			return slices.Clone(b[:l]), b[l:], nil
+		}
+		return string(b[:l]), b[l:], nil
+	default:
+		return nil, nil, fmt.Errorf("invalid element kind %s", kind)
+	}
+	if len(b) < size {
+		return nil, nil, invalidFieldLengthErr(len(b), size)
+	}
+	var v any
+	switch kind {
+	case api.Kind_Bool:
+		v = b[0] != 0
+	case api.Kind_Int8:
+		v = int8(b[0])
+	case api.Kind_Uint8:
+		v = b[0]
+	case api.Kind_Int16:
+		v = int16(bo.Uint16(b))
+	case api.Kind_Uint16:
+		v = bo.Uint16(b)
+	case api.Kind_Int32:
+		v = int32(bo.Uint32(b))
+	case api.Kind_Uint32:
+		v = bo.Uint32(b)
+	case api.Kind_Float32:
+		v = math.Float32frombits(bo.Uint32(b))
+	case api.Kind_Int64:
+		v = int64(bo.Uint64(b))
+	case api.Kind_Uint64:
+		v = bo.Uint64(b)
+	case api.Kind_Float64:
+		v = math.Float64frombits(bo.Uint64(b))
+	}
+	return v, b[size:], nil
+}
+
+func (a *fieldAccessor) ElementType() api.Kind {
+	return a.f.ElementKind
+}
+
+func (a *fieldAccessor) checkKind(kind api.Kind) error {
+	if a.f.Kind != kind {
+		return fmt.Errorf("field %q is of kind %s, not %s", a.f.FullName, a.f.Kind, kind)
+	}
+	return nil
+}
+
+func (a *fieldAccessor) List(data Data) ([]any, error) {
+	if err := a.checkKind(api.Kind_List); err != nil {
+		return nil, err
+	}
+	b := a.Get(data)
+	res := make([]any, 0)
+	for len(b) > 0 {
+		var v any
+		var err error
+		v, b, err = readElement(a.ds.byteOrder, b, a.f.ElementKind)
+		if err != nil {
+			return nil, fmt.Errorf("reading element %d of field %q: %w", len(res), a.f.FullName, err)
+		}
+		res = append(res, v)
+	}
+	return res, nil
+}
+
+func (a *fieldAccessor) PutList(data Data, vals []any) error {
+	if err := a.checkKind(api.Kind_List); err != nil {
+		return err
+	}
+	b := make([]byte, 0)
+	for i, v := range vals {
+		var err error
+		b, err = appendElement(a.ds.byteOrder, b, a.f.ElementKind, v)
+		if err != nil {
+			return fmt.Errorf("encoding element %d of field %q: %w", i, a.f.FullName, err)
+		}
+	}
+	return a.Set(data, b)
+}
+
+func (a *fieldAccessor) Map(data Data) (map[string]any, error) {
+	if err := a.checkKind(api.Kind_Map); err != nil {
+		return nil, err
+	}
+	b := a.Get(data)
+	res := make(map[string]any)
+	for len(b) > 0 {
+		var k, v any
+		var err error
+		k, b, err = readElement(a.ds.byteOrder, b, api.Kind_String)
+		if err != nil {
+			return nil, fmt.Errorf("reading key of field %q: %w", a.f.FullName, err)
+		}
+		v, b, err = readElement(a.ds.byteOrder, b, a.f.ElementKind)
+		if err != nil {
+			return nil, fmt.Errorf("reading value of key %q of field %q: %w", k, a.f.FullName, err)
+		}
+		res[k.(string)] = v
+	}
+	return res, nil
+}
+
+func (a *fieldAccessor) PutMap(data Data, vals map[string]any) error {
+	if err := a.checkKind(api.Kind_Map); err != nil {
+		return err
+	}
+	keys := make([]string, 0, len(vals))
+	for k := range vals {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	b := make([]byte, 0)
+	for _, k := range keys {
+		var err error
+		b, err = appendElement(a.ds.byteOrder, b, api.Kind_String, k)
+		if err != nil {
+			return fmt.Errorf("encoding key %q of field %q: %w", k, a.f.FullName, err)
+		}
+		b, err = appendElement(a.ds.byteOrder, b, a.f.ElementKind, vals[k])
+		if err != nil {
+			return fmt.Errorf("encoding value of key %q of field %q: %w", k, a.f.FullName, err)
+		}
+	}
+	return a.Set(data, b)
+}
+
+func (a *fieldAccessor) StringList(data Data) ([]string, error) {
+	if a.f.ElementKind != api.Kind_String && a.f.ElementKind != api.Kind_CString {
+		return nil, fmt.Errorf("field %q doesn't hold a list of strings", a.f.FullName)
+	}
+	vals, err := a.List(data)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]string, 0, len(vals))
+	for _, v := range vals {
+		res = append(res, v.(string))
+	}
+	return res, nil
+}
+
+func (a *fieldAccessor) PutStringList(data Data, vals []string) error {
+	if a.f.ElementKind != api.Kind_String && a.f.ElementKind != api.Kind_CString {
+		return fmt.Errorf("field %q doesn't hold a list of strings", a.f.FullName)
+	}
+	l := make([]any, 0, len(vals))
+	for _, v := range vals {
+		l = append(l, v)
+	}
+	return a.PutList(data, l)
+}
+
+func formatElement(v any) string {
+	if b, ok := v.([]byte); ok {
+		return hex.EncodeToString(b)
+	}
+	return fmt.Sprint(v)
+}
+
+// formatList returns the elements of a list separated by commas
+func formatList(vals []any) string {
+	var sb strings.Builder
+	for i, v := range vals {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(formatElement(v))
+	}
+	return sb.String()
+}
+
+// formatMap returns the entries of a map as key=value pairs sorted by key and
+// separated by commas
+func formatMap(vals map[string]any) string {
+	var sb strings.Builder
+	keys := make([]string, 0, len(vals))
+	for k := range vals {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	for i, k := range keys {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(k)
+		sb.WriteByte('=')
+		sb.WriteString(formatElement(vals[k]))
+	}
+	return sb.String()
+}
+
+// compositeString returns the elements of a List field separated by commas
+// or the entries of a Map field as key=value pairs separated by commas
+func (a *fieldAccessor) compositeString(data Data) string {
+	switch a.f.Kind {
+	case api.Kind_List:
+		vals, _ := a.List(data)
+		return formatList(vals)
+	case api.Kind_Map:
+		vals, _ := a.Map(data)
+		return formatMap(vals)
+	}
+	return ""
+}
@@ -0,0 +1,156 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datasource
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+)
+
+func TestCompositeFieldElementKind(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		kind        api.Kind
+		elementKind api.Kind
+		expectedErr bool
+	}{
+		{name: "list of strings", kind: api.Kind_List, elementKind: api.Kind_String},
+		{name: "map of uint64", kind: api.Kind_Map, elementKind: api.Kind_Uint64},
+		{name: "list without element kind", kind: api.Kind_List, expectedErr: true},
+		{name: "list of lists", kind: api.Kind_List, elementKind: api.Kind_List, expectedErr: true},
+		{name: "list of arrays", kind: api.Kind_List, elementKind: api.ArrayOf(api.Kind_Uint8), expectedErr: true},
+		{name: "scalar with element kind", kind: api.Kind_Uint32, elementKind: api.Kind_String, expectedErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ds, err := New(TypeSingle, "composite")
+			require.NoError(t, err)
+
+			_, err = ds.AddField("field", test.kind, WithElementKind(test.elementKind))
+			if test.expectedErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestCompositeFieldAccessors(t *testing.T) {
+	t.Parallel()
+
+	elements := map[api.Kind][]any{
+		api.Kind_Bool:    {true, false},
+		api.Kind_Int8:    {int8(-1), int8(2)},
+		api.Kind_Int16:   {int16(-300), int16(4)},
+		api.Kind_Int32:   {int32(-70000), int32(6)},
+		api.Kind_Int64:   {int64(-1 << 40), int64(8)},
+		api.Kind_Uint8:   {uint8(255)},
+		api.Kind_Uint16:  {uint16(65535), uint16(0)},
+		api.Kind_Uint32:  {uint32(1 << 31)},
+		api.Kind_Uint64:  {uint64(1 << 63)},
+		api.Kind_Float32: {float32(1.5), float32(-2)},
+		api.Kind_Float64: {float64(3.25)},
+		api.Kind_String:  {"foo", "", "bar"},
+		api.Kind_Bytes:   {[]byte{1, 2, 3}, []byte{}},
+	}
+
+	for kind, vals := range elements {
+		t.Run(kind.String(), func(t *testing.T) {
+			t.Parallel()
+
+			ds, err := New(TypeSingle, "composite")
+			require.NoError(t, err)
+			list, err := ds.AddField("list", api.Kind_List, WithElementKind(kind))
+			require.NoError(t, err)
+			m, err := ds.AddField("map", api.Kind_Map, WithElementKind(kind))
+			require.NoError(t, err)
+			require.Equal(t, kind, list.ElementType())
+
+			data, err := ds.NewPacketSingle()
+			require.NoError(t, err)
+
+			require.NoError(t, list.PutList(data, vals))
+			res, err := list.List(data)
+			require.NoError(t, err)
+			require.Equal(t, vals, res)
+
+			mapVals := make(map[string]any)
+			for i, v := range vals {
+				mapVals[string(rune('a'+i))] = v
+			}
+			require.NoError(t, m.PutMap(data, mapVals))
+			resMap, err := m.Map(data)
+			require.NoError(t, err)
+			require.Equal(t, mapVals, resMap)
+		})
+	}
+}
+
+func TestCompositeFieldErrors(t *testing.T) {
+	t.Parallel()
+
+	ds, err := New(TypeSingle, "composite")
+	require.NoError(t, err)
+	list, err := ds.AddField("list", api.Kind_List, WithElementKind(api.Kind_Uint16))
+	require.NoError(t, err)
+	str, err := ds.AddField("str", api.Kind_String)
+	require.NoError(t, err)
+
+	data, err := ds.NewPacketSingle()
+	require.NoError(t, err)
+
+	// wrong element type
+	require.Error(t, list.PutList(data, []any{uint32(1)}))
+	require.Error(t, list.PutStringList(data, []string{"foo"}))
+	require.Error(t, list.PutMap(data, map[string]any{}))
+	require.Error(t, str.PutList(data, []any{"foo"}))
+
+	// truncated payload
+	require.NoError(t, list.Set(data, []byte{1, 2, 3}))
+	_, err = list.List(data)
+	require.Error(t, err)
+}
+
+func TestCompositeFieldString(t *testing.T) {
+	t.Parallel()
+
+	ds, err := New(TypeSingle, "composite")
+	require.NoError(t, err)
+	list, err := ds.AddField("list", api.Kind_List, WithElementKind(api.Kind_String))
+	require.NoError(t, err)
+	m, err := ds.AddField("map", api.Kind_Map, WithElementKind(api.Kind_Uint32))
+	require.NoError(t, err)
+
+	data, err := ds.NewPacketSingle()
+	require.NoError(t, err)
+	require.NoError(t, list.PutStringList(data, []string{"a", "b"}))
+	require.NoError(t, m.PutMap(data, map[string]any{"y": uint32(2), "x": uint32(1)}))
+
+	strs, err := list.StringList(data)
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "b"}, strs)
+
+	require.Equal(t, "a,b", list.(*fieldAccessor).compositeString(data))
+	require.Equal(t, "x=1,y=2", m.(*fieldAccessor).compositeString(data))
+}
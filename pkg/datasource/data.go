@@ -523,6 +523,9 @@ func (ds *dataSource) AddField(name string, kind api.Kind, opts ...FieldOption)
 	for _, opt := range opts {
 		opt(nf)
 	}
+	if err := checkElementKind(nf); err != nil {
+		return nil, err
+	}
 	if nf.Order == 0 {
 		// If no order is set, use the current index as the order
 		nf.Order = int32(nf.Index)
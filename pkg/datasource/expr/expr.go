@@ -116,6 +116,10 @@ func getFnAndReflectType(f datasource.FieldAccessor) (string, reflect.Type) {
 		return "GetFloat64", reflect.TypeOf(float64(0))
 	case api.Kind_Bool:
 		return "GetBool", reflect.TypeOf(false)
+	case api.Kind_List:
+		return "GetList", reflect.TypeOf([]any{})
+	case api.Kind_Map:
+		return "GetMap", reflect.TypeOf(map[string]any{})
 	}
 }
 
@@ -193,6 +197,20 @@ func getBuiltInExpressions() []expr.Option {
 			},
 			new(func(datasource.FieldAccessor, datasource.Data) bool),
 		),
+		// Lists and maps can be used with the built-in operators and functions,
+		// e.g. "1.1.1.1" in answers or any(answers, # startsWith "10.")
+		expr.Function("GetList",
+			func(params ...any) (any, error) {
+				return params[0].(datasource.FieldAccessor).List(params[1].(datasource.Data))
+			},
+			new(func(datasource.FieldAccessor, datasource.Data) []any),
+		),
+		expr.Function("GetMap",
+			func(params ...any) (any, error) {
+				return params[0].(datasource.FieldAccessor).Map(params[1].(datasource.Data))
+			},
+			new(func(datasource.FieldAccessor, datasource.Data) map[string]any),
+		),
 		expr.Function("cidr",
 			func(args ...any) (any, error) {
 				var cidrs []net.IPNet
@@ -406,3 +406,88 @@ func TestExpressionFilter(t *testing.T) {
 		})
 	}
 }
+
+func TestExpressionFilterComposite(t *testing.T) {
+	ds, err := datasource.New(datasource.TypeSingle, "filter")
+	require.NoError(t, err)
+	answers, err := ds.AddField("answers", api.Kind_List, datasource.WithElementKind(api.Kind_String))
+	require.NoError(t, err)
+	ports, err := ds.AddField("ports", api.Kind_List, datasource.WithElementKind(api.Kind_Uint16))
+	require.NoError(t, err)
+	labels, err := ds.AddField("labels", api.Kind_Map, datasource.WithElementKind(api.Kind_String))
+	require.NoError(t, err)
+
+	data, err := ds.NewPacketSingle()
+	require.NoError(t, err)
+	require.NoError(t, answers.PutStringList(data, []string{"10.0.0.1", "1.1.1.1"}))
+	require.NoError(t, ports.PutList(data, []any{uint16(53), uint16(8080)}))
+	require.NoError(t, labels.PutMap(data, map[string]any{"app": "nginx", "tier": "web"}))
+
+	testCases := []testCaseFilter{
+		{
+			name:         "list in positive",
+			filterString: "'1.1.1.1' in answers",
+			match:        true,
+		},
+		{
+			name:         "list in negative",
+			filterString: "'8.8.8.8' in answers",
+			match:        false,
+		},
+		{
+			name:         "list any positive",
+			filterString: "any(answers, # startsWith '10.')",
+			match:        true,
+		},
+		{
+			name:         "list any negative",
+			filterString: "any(answers, # startsWith '192.')",
+			match:        false,
+		},
+		{
+			name:         "list of numbers in",
+			filterString: "53 in ports",
+			match:        true,
+		},
+		{
+			name:         "list of numbers any",
+			filterString: "any(ports, # > 8000)",
+			match:        true,
+		},
+		{
+			name:         "list len",
+			filterString: "len(answers) == 2",
+			match:        true,
+		},
+		{
+			name:         "map key in",
+			filterString: "'app' in labels",
+			match:        true,
+		},
+		{
+			name:         "map value",
+			filterString: "labels.tier == 'web'",
+			match:        true,
+		},
+		{
+			name:         "map missing key",
+			filterString: "'version' in labels",
+			match:        false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			filter, err := CompileFilterProgram(ds, tc.filterString)
+			if tc.error {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			res, err := Run(filter, data)
+			require.NoError(t, err)
+			require.Equal(t, tc.match, res.(bool))
+		})
+	}
+}
@@ -133,8 +133,26 @@ func WithAnnotations(annotations map[string]string) FieldOption {
 	}
 }
 
+// WithAddedAnnotations adds annotations to the default ones of the field, unlike WithAnnotations
+// which replaces them
+func WithAddedAnnotations(annotations map[string]string) FieldOption {
+	return func(f *field) {
+		if f.Annotations == nil {
+			f.Annotations = map[string]string{}
+		}
+		maps.Copy(f.Annotations, annotations)
+	}
+}
+
 func WithOrder(order int32) FieldOption {
 	return func(f *field) {
 		f.Order = order
 	}
 }
+
+// WithElementKind sets the kind of the elements of a List field or of the values of a Map field
+func WithElementKind(kind api.Kind) FieldOption {
+	return func(f *field) {
+		f.ElementKind = kind
+	}
+}
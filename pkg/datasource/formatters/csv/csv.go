@@ -0,0 +1,124 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package csv formats the data of a DataSource as CSV (or TSV) following RFC 4180, one record per
+// event or, for array DataSources, one record per element. Records end with CRLF.
+package csv
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
+)
+
+type Formatter struct {
+	ds        datasource.DataSource
+	fields    []string
+	separator rune
+
+	header []string
+	fns    []func(datasource.Data) string
+
+	// mu protects record, buf and w, which are reused by all the calls
+	mu     sync.Mutex
+	record []string
+	buf    bytes.Buffer
+	w      *csv.Writer
+}
+
+// New creates a formatter for the given fields of ds, in that order
+func New(ds datasource.DataSource, fields []string, options ...Option) (*Formatter, error) {
+	f := &Formatter{
+		ds:        ds,
+		fields:    fields,
+		separator: ',',
+	}
+	for _, o := range options {
+		o(f)
+	}
+
+	switch f.separator {
+	case '"', '\r', '\n', utf8.RuneError:
+		return nil, fmt.Errorf("invalid separator %q", f.separator)
+	}
+
+	f.w = csv.NewWriter(&f.buf)
+	f.w.Comma = f.separator
+	f.w.UseCRLF = true
+	if err := f.init(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *Formatter) init() error {
+	for _, name := range f.fields {
+		acc := f.ds.GetField(name)
+		if acc == nil {
+			return fmt.Errorf("field %q not found", name)
+		}
+		fn, err := datasource.AsString(acc)
+		if err != nil {
+			return fmt.Errorf("field %q: %w", name, err)
+		}
+		f.header = append(f.header, name)
+		f.fns = append(f.fns, fn)
+	}
+	f.record = make([]string, len(f.fns))
+	return nil
+}
+
+func (f *Formatter) flush() []byte {
+	f.w.Flush()
+	res := bytes.Clone(f.buf.Bytes())
+	f.buf.Reset()
+	return res
+}
+
+// Header returns the header record, made of the names of the fields
+func (f *Formatter) Header() []byte {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.w.Write(f.header)
+	return f.flush()
+}
+
+func (f *Formatter) write(data datasource.Data) {
+	for i, fn := range f.fns {
+		f.record[i] = fn(data)
+	}
+	f.w.Write(f.record)
+}
+
+// Marshal returns the record of data, including its line break
+func (f *Formatter) Marshal(data datasource.Data) []byte {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.write(data)
+	return f.flush()
+}
+
+// MarshalArray returns one record per element of the array
+func (f *Formatter) MarshalArray(dataArray datasource.DataArray) []byte {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i := range dataArray.Len() {
+		f.write(dataArray.Get(i))
+	}
+	return f.flush()
+}
@@ -0,0 +1,135 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package csv
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+)
+
+func TestFormatter(t *testing.T) {
+	ds, err := datasource.New(datasource.TypeSingle, "csv")
+	require.NoError(t, err)
+	comm, err := ds.AddField("comm", api.Kind_String)
+	require.NoError(t, err)
+	pid, err := ds.AddField("pid", api.Kind_Uint32)
+	require.NoError(t, err)
+	ports, err := ds.AddField("ports", api.Kind_List, datasource.WithElementKind(api.Kind_Uint16))
+	require.NoError(t, err)
+
+	data, err := ds.NewPacketSingle()
+	require.NoError(t, err)
+	require.NoError(t, comm.PutString(data, `say "hi", again`))
+	require.NoError(t, pid.PutUint32(data, 42))
+	require.NoError(t, ports.PutList(data, []any{uint16(80), uint16(443)}))
+
+	tests := []struct {
+		name           string
+		fields         []string
+		options        []Option
+		expectedHeader string
+		expected       string
+		expectedErr    bool
+	}{
+		{
+			name:           "csv",
+			fields:         []string{"pid", "comm", "ports"},
+			expectedHeader: "pid,comm,ports\r\n",
+			expected:       "42,\"say \"\"hi\"\", again\",\"80,443\"\r\n",
+		},
+		{
+			name:           "tsv",
+			fields:         []string{"comm", "pid"},
+			options:        []Option{WithSeparator('\t')},
+			expectedHeader: "comm\tpid\r\n",
+			expected:       "\"say \"\"hi\"\", again\"\t42\r\n",
+		},
+		{
+			name:        "unknown field",
+			fields:      []string{"foo"},
+			expectedErr: true,
+		},
+		{
+			name:        "invalid separator",
+			fields:      []string{"pid"},
+			options:     []Option{WithSeparator('"')},
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			f, err := New(ds, test.fields, test.options...)
+			if test.expectedErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.expectedHeader, string(f.Header()))
+			require.Equal(t, test.expected, string(f.Marshal(data)))
+		})
+	}
+}
+
+func TestFormatterArray(t *testing.T) {
+	ds, err := datasource.New(datasource.TypeArray, "csv")
+	require.NoError(t, err)
+	comm, err := ds.AddField("comm", api.Kind_String)
+	require.NoError(t, err)
+	pid, err := ds.AddField("pid", api.Kind_Uint32)
+	require.NoError(t, err)
+
+	dataArray, err := ds.NewPacketArray()
+	require.NoError(t, err)
+	for i, c := range []string{"cat", "ls"} {
+		data := dataArray.New()
+		require.NoError(t, comm.PutString(data, c))
+		require.NoError(t, pid.PutUint32(data, uint32(i+1)))
+		dataArray.Append(data)
+	}
+
+	f, err := New(ds, []string{"pid", "comm"})
+	require.NoError(t, err)
+	require.Equal(t, "1,cat\r\n2,ls\r\n", string(f.MarshalArray(dataArray)))
+}
+
+func TestFormatterConcurrent(t *testing.T) {
+	ds, err := datasource.New(datasource.TypeSingle, "csv")
+	require.NoError(t, err)
+	pid, err := ds.AddField("pid", api.Kind_Uint32)
+	require.NoError(t, err)
+
+	f, err := New(ds, []string{"pid"})
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for i := range 8 {
+		data, err := ds.NewPacketSingle()
+		require.NoError(t, err)
+		require.NoError(t, pid.PutUint32(data, uint32(i)))
+		wg.Go(func() {
+			for range 100 {
+				assert.Equal(t, string(rune('0'+i))+"\r\n", string(f.Marshal(data)))
+			}
+		})
+	}
+	wg.Wait()
+}
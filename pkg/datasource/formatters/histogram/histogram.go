@@ -0,0 +1,254 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package histogram renders the exp-2 histograms held by the fields of a DataSource, like the
+// ones of the gadget_histogram_slot__u32 and gadget_histogram_slot__u64 types. Histograms are
+// rendered per key (made of the other fields) next to each other.
+package histogram
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/histogram"
+	ebpftypes "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/ebpf/types"
+)
+
+const (
+	// AnnotationSource is set on fields computed from a histogram field, like its percentiles,
+	// to the name of that field. These fields aren't used as keys.
+	AnnotationSource = "histogram.source"
+
+	// AnnotationUnit is the unit of the values of a histogram field, as used by the otel-metrics
+	// operator
+	AnnotationUnit = "metrics.unit"
+)
+
+// DefaultPercentiles are shown below the histograms
+var DefaultPercentiles = []float64{50, 90, 99}
+
+// IsHistogramField reports whether f holds the slots of an exp-2 histogram
+func IsHistogramField(f datasource.FieldAccessor) bool {
+	if !f.HasAnyTagsOf("type:"+ebpftypes.HistogramSlotU32TypeName, "type:"+ebpftypes.HistogramSlotU64TypeName) {
+		return false
+	}
+	return f.Type() == api.ArrayOf(api.Kind_Uint32) || f.Type() == api.ArrayOf(api.Kind_Uint64)
+}
+
+// HistogramFields returns the fields of ds holding the slots of an exp-2 histogram
+func HistogramFields(ds datasource.DataSource) []datasource.FieldAccessor {
+	var res []datasource.FieldAccessor
+	for _, f := range ds.Accessors(false) {
+		if datasource.FieldFlagUnreferenced.In(f.Flags()) {
+			continue
+		}
+		if IsHistogramField(f) {
+			res = append(res, f)
+		}
+	}
+	return res
+}
+
+// IsHistogram reports whether ds has fields holding histograms
+func IsHistogram(ds datasource.DataSource) bool {
+	return len(HistogramFields(ds)) > 0
+}
+
+// IntervalsFunc returns a function reading the intervals of the histogram held by f
+func IntervalsFunc(f datasource.FieldAccessor) (func(datasource.Data) []histogram.Interval, error) {
+	switch f.Type() {
+	case api.ArrayOf(api.Kind_Uint32):
+		return func(data datasource.Data) []histogram.Interval {
+			slots, _ := f.Uint32Array(data)
+			return histogram.NewIntervalsFromExp2Slots(slots)
+		}, nil
+	case api.ArrayOf(api.Kind_Uint64):
+		return func(data datasource.Data) []histogram.Interval {
+			slots, _ := f.Uint64Array(data)
+			return histogram.NewIntervalsFromExp2Slots(slots)
+		}, nil
+	}
+	return nil, fmt.Errorf("expected array of uint32 or uint64, got %s", f.Type())
+}
+
+type histogramField struct {
+	name      string
+	unit      histogram.Unit
+	intervals func(datasource.Data) []histogram.Interval
+}
+
+type keyField struct {
+	name string
+	fn   func(datasource.Data) string
+}
+
+type Formatter struct {
+	ds     datasource.DataSource
+	hists  []histogramField
+	keys   []keyField
+	render histogram.RenderOptions
+}
+
+// New creates a formatter for ds. Selected histogram fields are rendered, once per key made of
+// the other selected fields; all histogram fields are rendered if none is selected.
+func New(ds datasource.DataSource, fields []string, options ...Option) (*Formatter, error) {
+	f := &Formatter{
+		ds: ds,
+		render: histogram.RenderOptions{
+			Scale:       histogram.ScaleLinear,
+			Percentiles: DefaultPercentiles,
+		},
+	}
+	for _, o := range options {
+		o(f)
+	}
+
+	switch f.render.Scale {
+	case histogram.ScaleLinear, histogram.ScaleLog2:
+	default:
+		return nil, fmt.Errorf("invalid scale %q, expected %q or %q", f.render.Scale,
+			histogram.ScaleLinear, histogram.ScaleLog2)
+	}
+
+	for _, name := range fields {
+		acc := ds.GetField(name)
+		if acc == nil {
+			return nil, fmt.Errorf("field %q not found", name)
+		}
+		if IsHistogramField(acc) {
+			if err := f.addHistogram(acc); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if _, ok := acc.Annotations()[AnnotationSource]; ok {
+			continue
+		}
+		fn, err := datasource.AsString(acc)
+		if err != nil {
+			return nil, fmt.Errorf("field %q: %w", name, err)
+		}
+		f.keys = append(f.keys, keyField{name: name, fn: fn})
+	}
+
+	if len(f.hists) == 0 {
+		for _, acc := range HistogramFields(ds) {
+			if err := f.addHistogram(acc); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if len(f.hists) == 0 {
+		return nil, fmt.Errorf("no histogram field found")
+	}
+	return f, nil
+}
+
+func (f *Formatter) addHistogram(acc datasource.FieldAccessor) error {
+	fn, err := IntervalsFunc(acc)
+	if err != nil {
+		return fmt.Errorf("field %q: %w", acc.FullName(), err)
+	}
+	f.hists = append(f.hists, histogramField{
+		name:      acc.FullName(),
+		unit:      histogram.Unit(acc.Annotations()[AnnotationUnit]),
+		intervals: fn,
+	})
+	return nil
+}
+
+func (f *Formatter) title(data datasource.Data) string {
+	parts := make([]string, 0, len(f.keys))
+	for _, k := range f.keys {
+		parts = append(parts, k.name+"="+k.fn(data))
+	}
+	return strings.Join(parts, " ")
+}
+
+// group holds the summed up histograms of the elements with the same key
+type group struct {
+	title string
+	hists [][]histogram.Interval
+}
+
+func (g *group) add(i int, intervals []histogram.Interval) {
+	if n := len(g.hists[i]); len(intervals) > n {
+		g.hists[i] = append(g.hists[i], intervals[n:]...)
+		intervals = intervals[:n]
+	}
+	for j, interval := range intervals {
+		g.hists[i][j].Count += interval.Count
+	}
+}
+
+func (f *Formatter) marshal(groups []*group) []byte {
+	opts := f.render
+	if opts.Stars == 0 && len(groups) > 1 {
+		opts.Stars = 20
+	}
+
+	var sb strings.Builder
+	for i, hf := range f.hists {
+		titled := make([]histogram.Titled, 0, len(groups))
+		for _, g := range groups {
+			titled = append(titled, histogram.Titled{
+				Title:     g.title,
+				Histogram: &histogram.Histogram{Unit: hf.unit, Intervals: g.hists[i]},
+			})
+		}
+		fmt.Fprintf(&sb, "%s:\n", hf.name)
+		sb.WriteString(histogram.RenderSideBySide(titled, opts))
+		sb.WriteByte('\n')
+	}
+	return []byte(sb.String())
+}
+
+// Marshal renders the histograms of data
+func (f *Formatter) Marshal(data datasource.Data) []byte {
+	g := &group{title: f.title(data), hists: make([][]histogram.Interval, len(f.hists))}
+	for i, hf := range f.hists {
+		g.add(i, hf.intervals(data))
+	}
+	return f.marshal([]*group{g})
+}
+
+// MarshalArray renders the histograms of the elements of dataArray, summing up the ones of the
+// elements with the same key
+func (f *Formatter) MarshalArray(dataArray datasource.DataArray) []byte {
+	var groups []*group
+	index := make(map[string]*group)
+	for i := range dataArray.Len() {
+		data := dataArray.Get(i)
+		title := f.title(data)
+		g, ok := index[title]
+		if !ok {
+			g = &group{title: title, hists: make([][]histogram.Interval, len(f.hists))}
+			index[title] = g
+			groups = append(groups, g)
+		}
+		for j, hf := range f.hists {
+			g.add(j, hf.intervals(data))
+		}
+	}
+	if len(groups) == 0 {
+		return nil
+	}
+	slices.SortFunc(groups, func(a, b *group) int {
+		return strings.Compare(a.title, b.title)
+	})
+	return f.marshal(groups)
+}
@@ -0,0 +1,164 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package histogram
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/histogram"
+	ebpftypes "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/ebpf/types"
+)
+
+func newDataSource(t *testing.T, dsType datasource.Type) (datasource.DataSource, datasource.FieldAccessor, datasource.FieldAccessor) {
+	ds, err := datasource.New(dsType, "blockio")
+	require.NoError(t, err)
+	dev, err := ds.AddField("dev", api.Kind_String)
+	require.NoError(t, err)
+	latency, err := ds.AddField("latency", api.ArrayOf(api.Kind_Uint32),
+		datasource.WithTags("type:"+ebpftypes.HistogramSlotU32TypeName),
+		datasource.WithAnnotations(map[string]string{AnnotationUnit: "µs"}))
+	require.NoError(t, err)
+	_, err = ds.AddField("latency_p50", api.Kind_Uint64,
+		datasource.WithAnnotations(map[string]string{AnnotationSource: "latency"}))
+	require.NoError(t, err)
+	return ds, dev, latency
+}
+
+func putSlots(t *testing.T, ds datasource.DataSource, f datasource.FieldAccessor, data datasource.Data, slots ...uint32) {
+	b := make([]byte, 4*len(slots))
+	for i, slot := range slots {
+		ds.ByteOrder().PutUint32(b[4*i:], slot)
+	}
+	require.NoError(t, f.Set(data, b))
+}
+
+func TestIsHistogram(t *testing.T) {
+	ds, _, latency := newDataSource(t, datasource.TypeSingle)
+	require.True(t, IsHistogram(ds))
+	require.True(t, IsHistogramField(latency))
+	require.False(t, IsHistogramField(ds.GetField("dev")))
+
+	other, err := datasource.New(datasource.TypeSingle, "other")
+	require.NoError(t, err)
+	_, err = other.AddField("slots", api.ArrayOf(api.Kind_Uint32))
+	require.NoError(t, err)
+	require.False(t, IsHistogram(other))
+}
+
+func TestFormatter(t *testing.T) {
+	ds, dev, latency := newDataSource(t, datasource.TypeSingle)
+
+	data, err := ds.NewPacketSingle()
+	require.NoError(t, err)
+	require.NoError(t, dev.PutString(data, "sda"))
+	putSlots(t, ds, latency, data, 1, 3)
+
+	tests := []struct {
+		name        string
+		fields      []string
+		options     []Option
+		expected    string
+		expectedErr bool
+	}{
+		{
+			name:    "default",
+			fields:  []string{"dev", "latency", "latency_p50"},
+			options: []Option{WithStars(10), WithPercentiles(nil)},
+			expected: "" +
+				"latency:\n" +
+				"                           dev=sda\n" +
+				"        µs               : count    distribution\n" +
+				"         0 -> 1          : 1        |***       |\n" +
+				"         2 -> 3          : 3        |**********|\n" +
+				"\n",
+		},
+		{
+			name:    "no histogram selected",
+			fields:  []string{"latency_p50"},
+			options: []Option{WithStars(10), WithScale(histogram.ScaleLog2), WithPercentiles([]float64{50})},
+			expected: "" +
+				"latency:\n" +
+				"        µs               : count    distribution\n" +
+				"         0 -> 1          : 1        |*****     |\n" +
+				"         2 -> 3          : 3        |**********|\n" +
+				"                     p50 : 2\n" +
+				"                     max : 3\n" +
+				"\n",
+		},
+		{
+			name:        "unknown field",
+			fields:      []string{"foo"},
+			expectedErr: true,
+		},
+		{
+			name:        "invalid scale",
+			options:     []Option{WithScale("foo")},
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			f, err := New(ds, test.fields, test.options...)
+			if test.expectedErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.expected, string(f.Marshal(data)))
+		})
+	}
+}
+
+func TestFormatterArray(t *testing.T) {
+	ds, dev, latency := newDataSource(t, datasource.TypeArray)
+
+	dataArray, err := ds.NewPacketArray()
+	require.NoError(t, err)
+	for _, e := range []struct {
+		dev   string
+		slots []uint32
+	}{
+		{"sdb", []uint32{0, 0, 4}},
+		{"sda", []uint32{1, 2}},
+		{"sda", []uint32{1}},
+	} {
+		data := dataArray.New()
+		require.NoError(t, dev.PutString(data, e.dev))
+		putSlots(t, ds, latency, data, e.slots...)
+		dataArray.Append(data)
+	}
+
+	f, err := New(ds, []string{"dev", "latency"}, WithStars(10), WithPercentiles([]float64{50}))
+	require.NoError(t, err)
+	require.Equal(t, ""+
+		"latency:\n"+
+		"                           dev=sda                     dev=sdb\n"+
+		"        µs               : count    distribution     : count    distribution\n"+
+		"         0 -> 1          : 2        |**********|     : 0        |          |\n"+
+		"         2 -> 3          : 2        |**********|     : 0        |          |\n"+
+		"         4 -> 7          : 0        |          |     : 4        |**********|\n"+
+		"                     p50 : 1                         : 5\n"+
+		"                     max : 3                         : 7\n"+
+		"\n", string(f.MarshalArray(dataArray)))
+
+	empty, err := ds.NewPacketArray()
+	require.NoError(t, err)
+	require.Empty(t, f.MarshalArray(empty))
+}
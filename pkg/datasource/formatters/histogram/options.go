@@ -0,0 +1,51 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package histogram
+
+import (
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/histogram"
+)
+
+type Option func(*Formatter)
+
+// WithScale sets the scale of the bars, histogram.ScaleLinear by default
+func WithScale(scale histogram.Scale) Option {
+	return func(formatter *Formatter) {
+		formatter.render.Scale = scale
+	}
+}
+
+// WithWidth sets the maximum width of a line; histograms that don't fit are rendered below the
+// others
+func WithWidth(width int) Option {
+	return func(formatter *Formatter) {
+		formatter.render.Width = width
+	}
+}
+
+// WithStars sets the length of the longest bar; by default, it's 40 for a single key and 20 for
+// several ones
+func WithStars(stars int) Option {
+	return func(formatter *Formatter) {
+		formatter.render.Stars = stars
+	}
+}
+
+// WithPercentiles sets the percentiles shown below the histograms, DefaultPercentiles by default
+func WithPercentiles(percentiles []float64) Option {
+	return func(formatter *Formatter) {
+		formatter.render.Percentiles = percentiles
+	}
+}
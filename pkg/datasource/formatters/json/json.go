@@ -182,6 +182,89 @@ func writeFloatArrFn[T constraints.Float](
 	}
 }
 
+// writeElement writes an element of a List or Map field
+func writeElement(e *encodeState, v any) {
+	switch v := v.(type) {
+	case bool:
+		if v {
+			e.WriteString("true")
+		} else {
+			e.WriteString("false")
+		}
+	case int8:
+		e.Write(strconv.AppendInt(e.scratch[:0], int64(v), 10))
+	case int16:
+		e.Write(strconv.AppendInt(e.scratch[:0], int64(v), 10))
+	case int32:
+		e.Write(strconv.AppendInt(e.scratch[:0], int64(v), 10))
+	case int64:
+		e.Write(strconv.AppendInt(e.scratch[:0], v, 10))
+	case uint8:
+		e.Write(strconv.AppendUint(e.scratch[:0], uint64(v), 10))
+	case uint16:
+		e.Write(strconv.AppendUint(e.scratch[:0], uint64(v), 10))
+	case uint32:
+		e.Write(strconv.AppendUint(e.scratch[:0], uint64(v), 10))
+	case uint64:
+		e.Write(strconv.AppendUint(e.scratch[:0], v, 10))
+	case float32:
+		floatEncoder(32).writeFloat(e, float64(v))
+	case float64:
+		floatEncoder(64).writeFloat(e, v)
+	case string:
+		writeString(e, v)
+	case []byte:
+		writeString(e, hex.EncodeToString(v))
+	default:
+		e.WriteString("null")
+	}
+}
+
+func writeListFn(
+	accessor datasource.FieldAccessor,
+	fieldSep []byte,
+	newIndent string,
+) func(e *encodeState, data datasource.Data) {
+	return func(e *encodeState, data datasource.Data) {
+		vals, _ := accessor.List(data)
+		for i, v := range vals {
+			if i > 0 {
+				e.Write(fieldSep)
+			}
+			e.WriteString(newIndent)
+			writeElement(e, v)
+		}
+	}
+}
+
+func writeMapFn(
+	accessor datasource.FieldAccessor,
+	fieldSep []byte,
+	newIndent string,
+	pretty bool,
+) func(e *encodeState, data datasource.Data) {
+	return func(e *encodeState, data datasource.Data) {
+		vals, _ := accessor.Map(data)
+		keys := make([]string, 0, len(vals))
+		for k := range vals {
+			keys = append(keys, k)
+		}
+		slices.Sort(keys)
+		for i, k := range keys {
+			if i > 0 {
+				e.Write(fieldSep)
+			}
+			e.WriteString(newIndent)
+			writeString(e, k)
+			e.WriteByte(':')
+			if pretty {
+				e.WriteByte(' ')
+			}
+			writeElement(e, vals[k])
+		}
+	}
+}
+
 func (f *Formatter) addSubFields(accessors []datasource.FieldAccessor, prefix string, indent string) (fns []func(*encodeState, datasource.Data), fieldCounter int) {
 	if accessors == nil {
 		accessors = f.ds.Accessors(true)
@@ -315,6 +398,26 @@ func (f *Formatter) addSubFields(accessors []datasource.FieldAccessor, prefix st
 			continue
 		}
 
+		if datasource.IsCompositeKind(accessor.Type()) {
+			newIndent := ""
+			if f.pretty {
+				newIndent = indent + f.indent
+			}
+			fieldOpener, fieldCloser := f.openerArray, closerArray
+			fn = writeListFn(accessor, f.fieldSep, newIndent)
+			if accessor.Type() == api.Kind_Map {
+				fieldOpener, fieldCloser = f.opener, closer
+				fn = writeMapFn(accessor, f.fieldSep, newIndent, f.pretty)
+			}
+			fns = append(fns, func(e *encodeState, data datasource.Data) {
+				e.Write(fieldName)
+				e.Write(fieldOpener)
+				fn(e, data)
+				e.Write(fieldCloser)
+			})
+			continue
+		}
+
 		switch accessor.Type() {
 		case api.Kind_Int8:
 			fn = func(e *encodeState, data datasource.Data) {
@@ -0,0 +1,49 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package json
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+)
+
+func TestCompositeFields(t *testing.T) {
+	ds, err := datasource.New(datasource.TypeSingle, "composite")
+	require.NoError(t, err)
+	answers, err := ds.AddField("answers", api.Kind_List, datasource.WithElementKind(api.Kind_String))
+	require.NoError(t, err)
+	ports, err := ds.AddField("ports", api.Kind_List, datasource.WithElementKind(api.Kind_Uint16))
+	require.NoError(t, err)
+	labels, err := ds.AddField("labels", api.Kind_Map, datasource.WithElementKind(api.Kind_Bool))
+	require.NoError(t, err)
+
+	data, err := ds.NewPacketSingle()
+	require.NoError(t, err)
+	require.NoError(t, answers.PutStringList(data, []string{"1.1.1.1", "\"quoted\""}))
+	require.NoError(t, ports.PutList(data, []any{}))
+	require.NoError(t, labels.PutMap(data, map[string]any{"b": false, "a": true}))
+
+	f, err := New(ds)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"answers":["1.1.1.1","\"quoted\""],"labels":{"a":true,"b":false},"ports":[]}`, string(f.Marshal(data)))
+
+	f, err = New(ds, WithPretty(true, "  "))
+	require.NoError(t, err)
+	require.JSONEq(t, `{"answers":["1.1.1.1","\"quoted\""],"labels":{"a":true,"b":false},"ports":[]}`, string(f.Marshal(data)))
+}
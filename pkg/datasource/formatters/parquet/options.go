@@ -0,0 +1,46 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parquet
+
+type Option func(*Writer)
+
+// WithCompression sets the compression codec of the pages, one of Compressions; zstd by default
+func WithCompression(compression string) Option {
+	return func(w *Writer) {
+		w.compression = compression
+	}
+}
+
+// WithFields sets the fields to write; by default, all the fields holding a value are written,
+// including the hidden ones. Columns are ordered by name in the file
+func WithFields(fields []string) Option {
+	return func(w *Writer) {
+		w.fields = fields
+	}
+}
+
+// WithCreatedBy sets the name of the application writing the file
+func WithCreatedBy(createdBy string) Option {
+	return func(w *Writer) {
+		w.createdBy = createdBy
+	}
+}
+
+// WithMetadata adds a key/value pair to the metadata of the file
+func WithMetadata(key, value string) Option {
+	return func(w *Writer) {
+		w.metadata = append(w.metadata, [2]string{key, value})
+	}
+}
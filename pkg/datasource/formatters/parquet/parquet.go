@@ -0,0 +1,312 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package parquet writes the data of a DataSource to Apache Parquet files, one row per event or,
+// for array DataSources, one row per element. The schema is derived from the kinds of the fields:
+// integers, floats, booleans and strings keep their type, timestamps become nanosecond timestamps
+// and fields that have no Parquet counterpart (lists, maps and arrays) are written as text.
+package parquet
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/parquet-go/parquet-go/compress"
+	"github.com/parquet-go/parquet-go/compress/gzip"
+	"github.com/parquet-go/parquet-go/compress/snappy"
+	"github.com/parquet-go/parquet-go/compress/uncompressed"
+	"github.com/parquet-go/parquet-go/compress/zstd"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+	ebpftypes "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/ebpf/types"
+)
+
+const (
+	CompressionNone   = "none"
+	CompressionSnappy = "snappy"
+	CompressionGzip   = "gzip"
+	CompressionZstd   = "zstd"
+
+	// MetadataDataSource is the key of the file metadata holding the name of the DataSource
+	MetadataDataSource = "inspektor-gadget.datasource"
+)
+
+// ErrNoFields is returned by New if the DataSource has no fields that can be written
+var ErrNoFields = errors.New("no fields to write")
+
+var Compressions = []string{CompressionNone, CompressionSnappy, CompressionGzip, CompressionZstd}
+
+var codecs = map[string]compress.Codec{
+	CompressionNone:   &uncompressed.Codec{},
+	CompressionSnappy: &snappy.Codec{},
+	CompressionGzip:   &gzip.Codec{},
+	CompressionZstd:   &zstd.Codec{},
+}
+
+type column struct {
+	name  string
+	node  parquet.Node
+	value func(data datasource.Data) parquet.Value
+
+	// index is the position of the column in the schema, which orders columns by name
+	index int
+}
+
+// Writer writes the rows of a DataSource to a Parquet file. Rows are buffered and written as a
+// row group when Flush is called; the file is only valid after Close wrote its footer.
+type Writer struct {
+	w       *parquet.Writer
+	columns []*column
+	row     parquet.Row
+	rows    int
+
+	createdBy   string
+	compression string
+	fields      []string
+	metadata    [][2]string
+	closed      bool
+}
+
+// New creates a Writer for ds, writing the file to w
+func New(w io.Writer, ds datasource.DataSource, options ...Option) (*Writer, error) {
+	pw := &Writer{
+		createdBy:   "inspektor-gadget",
+		compression: CompressionZstd,
+		metadata:    [][2]string{{MetadataDataSource, ds.Name()}},
+	}
+	for _, o := range options {
+		o(pw)
+	}
+
+	codec, ok := codecs[pw.compression]
+	if !ok {
+		return nil, fmt.Errorf("unsupported compression %q", pw.compression)
+	}
+	if err := pw.initColumns(ds); err != nil {
+		return nil, err
+	}
+
+	group := parquet.Group{}
+	for _, c := range pw.columns {
+		group[c.name] = c.node
+	}
+	schema := parquet.NewSchema(ds.Name(), group)
+	for _, c := range pw.columns {
+		leaf, ok := schema.Lookup(c.name)
+		if !ok {
+			return nil, fmt.Errorf("column %q not found in schema", c.name)
+		}
+		c.index = leaf.ColumnIndex
+	}
+
+	writerOptions := []parquet.WriterOption{
+		schema,
+		parquet.Compression(codec),
+	}
+	for _, kv := range pw.metadata {
+		writerOptions = append(writerOptions, parquet.KeyValueMetadata(kv[0], kv[1]))
+	}
+	config, err := parquet.NewWriterConfig(writerOptions...)
+	if err != nil {
+		return nil, fmt.Errorf("configuring writer: %w", err)
+	}
+	// parquet.CreatedBy would append an empty version and build to the name
+	config.CreatedBy = pw.createdBy
+
+	pw.w = parquet.NewWriter(w, config)
+	pw.row = make(parquet.Row, len(pw.columns))
+	return pw, nil
+}
+
+func (pw *Writer) initColumns(ds datasource.DataSource) error {
+	var accessors []datasource.FieldAccessor
+	if pw.fields != nil {
+		for _, name := range pw.fields {
+			acc := ds.GetField(name)
+			if acc == nil {
+				return fmt.Errorf("field %q not found", name)
+			}
+			accessors = append(accessors, acc)
+		}
+	} else {
+		for _, acc := range ds.Accessors(false) {
+			if datasource.FieldFlagUnreferenced.In(acc.Flags()) || datasource.FieldFlagEmpty.In(acc.Flags()) ||
+				len(acc.SubFields()) > 0 {
+				continue
+			}
+			accessors = append(accessors, acc)
+		}
+	}
+
+	for _, acc := range accessors {
+		c, err := newColumn(acc)
+		if err != nil {
+			return fmt.Errorf("field %q: %w", acc.FullName(), err)
+		}
+		pw.columns = append(pw.columns, c)
+	}
+	if len(pw.columns) == 0 {
+		return ErrNoFields
+	}
+	return nil
+}
+
+func int32Value[T int8 | int16 | int32 | uint8 | uint16 | uint32](extract func(datasource.Data) (T, error)) func(datasource.Data) parquet.Value {
+	return func(data datasource.Data) parquet.Value {
+		v, _ := extract(data)
+		return parquet.Int32Value(int32(v))
+	}
+}
+
+func int64Value[T int64 | uint64](extract func(datasource.Data) (T, error)) func(datasource.Data) parquet.Value {
+	return func(data datasource.Data) parquet.Value {
+		v, _ := extract(data)
+		return parquet.Int64Value(int64(v))
+	}
+}
+
+func newColumn(acc datasource.FieldAccessor) (*column, error) {
+	c := &column{name: acc.FullName()}
+
+	switch acc.Type() {
+	case api.Kind_Bool:
+		c.node = parquet.Leaf(parquet.BooleanType)
+		c.value = func(data datasource.Data) parquet.Value {
+			v, _ := acc.Bool(data)
+			return parquet.BooleanValue(v)
+		}
+	case api.Kind_Int8:
+		c.node, c.value = parquet.Int(8), int32Value(acc.Int8)
+	case api.Kind_Int16:
+		c.node, c.value = parquet.Int(16), int32Value(acc.Int16)
+	case api.Kind_Int32:
+		c.node, c.value = parquet.Int(32), int32Value(acc.Int32)
+	case api.Kind_Uint8:
+		c.node, c.value = parquet.Uint(8), int32Value(acc.Uint8)
+	case api.Kind_Uint16:
+		c.node, c.value = parquet.Uint(16), int32Value(acc.Uint16)
+	case api.Kind_Uint32:
+		c.node, c.value = parquet.Uint(32), int32Value(acc.Uint32)
+	case api.Kind_Int64:
+		c.node, c.value = parquet.Int(64), int64Value(acc.Int64)
+	case api.Kind_Uint64:
+		c.node, c.value = parquet.Uint(64), int64Value(acc.Uint64)
+	case api.Kind_Float32:
+		c.node = parquet.Leaf(parquet.FloatType)
+		c.value = func(data datasource.Data) parquet.Value {
+			v, _ := acc.Float32(data)
+			return parquet.FloatValue(v)
+		}
+	case api.Kind_Float64:
+		c.node = parquet.Leaf(parquet.DoubleType)
+		c.value = func(data datasource.Data) parquet.Value {
+			v, _ := acc.Float64(data)
+			return parquet.DoubleValue(v)
+		}
+	case api.Kind_Bytes:
+		c.node = parquet.Leaf(parquet.ByteArrayType)
+		c.value = func(data datasource.Data) parquet.Value {
+			return parquet.ByteArrayValue(acc.Get(data))
+		}
+	default:
+		// Strings, and whatever has no Parquet counterpart as text
+		fn, err := datasource.AsString(acc)
+		if err != nil {
+			return nil, err
+		}
+		c.node = parquet.String()
+		c.value = func(data datasource.Data) parquet.Value {
+			return parquet.ByteArrayValue([]byte(fn(data)))
+		}
+	}
+
+	// Timestamps have been converted to wall clock time by the formatters operator
+	if (acc.Type() == api.Kind_Int64 || acc.Type() == api.Kind_Uint64) &&
+		slices.Contains(acc.Tags(), "type:"+ebpftypes.TimestampTypeName) {
+		c.node = parquet.Timestamp(parquet.Nanosecond)
+	}
+	c.node = parquet.Required(c.node)
+	return c, nil
+}
+
+// Write buffers a row with the values of data
+func (pw *Writer) Write(data datasource.Data) error {
+	if pw.closed {
+		return fmt.Errorf("writer is closed")
+	}
+	for _, c := range pw.columns {
+		pw.row[c.index] = c.value(data).Level(0, 0, c.index)
+	}
+	if _, err := pw.w.WriteRows([]parquet.Row{pw.row}); err != nil {
+		return fmt.Errorf("writing row: %w", err)
+	}
+	pw.rows++
+	return nil
+}
+
+// WriteArray writes the elements of dataArray as a row group of their own, along with the rows
+// buffered before
+func (pw *Writer) WriteArray(dataArray datasource.DataArray) error {
+	for i := range dataArray.Len() {
+		if err := pw.Write(dataArray.Get(i)); err != nil {
+			return err
+		}
+	}
+	return pw.Flush()
+}
+
+// Buffered returns the number of rows that haven't been written yet
+func (pw *Writer) Buffered() int {
+	return pw.rows
+}
+
+// Size returns the number of bytes written so far, plus the size of the buffered rows
+func (pw *Writer) Size() int64 {
+	return pw.w.Size()
+}
+
+// Flush writes the buffered rows as a row group
+func (pw *Writer) Flush() error {
+	if pw.closed {
+		return fmt.Errorf("writer is closed")
+	}
+	if pw.rows == 0 {
+		return nil
+	}
+	if err := pw.w.Flush(); err != nil {
+		return fmt.Errorf("writing row group: %w", err)
+	}
+	pw.rows = 0
+	return nil
+}
+
+// Close writes the buffered rows and the footer of the file; it doesn't close the underlying
+// io.Writer
+func (pw *Writer) Close() error {
+	if pw.closed {
+		return nil
+	}
+	if err := pw.Flush(); err != nil {
+		return err
+	}
+	pw.closed = true
+	if err := pw.w.Close(); err != nil {
+		return fmt.Errorf("writing footer: %w", err)
+	}
+	return nil
+}
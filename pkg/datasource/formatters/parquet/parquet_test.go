@@ -0,0 +1,215 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parquet
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math"
+	"testing"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/stretchr/testify/require"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+)
+
+// readFile opens a file written by Writer and returns its values by column name
+func readFile(t *testing.T, b []byte) (*parquet.File, map[string][]parquet.Value) {
+	f, err := parquet.OpenFile(bytes.NewReader(b), int64(len(b)))
+	require.NoError(t, err)
+
+	columns := map[string][]parquet.Value{}
+	paths := f.Schema().Columns()
+	r := parquet.NewReader(f)
+	defer r.Close()
+	rows := make([]parquet.Row, 4)
+	for {
+		n, err := r.ReadRows(rows)
+		for _, row := range rows[:n] {
+			for _, v := range row {
+				name := paths[v.Column()][0]
+				columns[name] = append(columns[name], v.Clone())
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+	}
+	return f, columns
+}
+
+func TestWriter(t *testing.T) {
+	ds, err := datasource.New(datasource.TypeSingle, "events")
+	require.NoError(t, err)
+	comm, err := ds.AddField("comm", api.Kind_String)
+	require.NoError(t, err)
+	pid, err := ds.AddField("pid", api.Kind_Uint32)
+	require.NoError(t, err)
+	delta, err := ds.AddField("delta", api.Kind_Int8)
+	require.NoError(t, err)
+	ts, err := ds.AddField("timestamp_raw", api.Kind_Uint64, datasource.WithTags("type:gadget_timestamp"),
+		datasource.WithFlags(datasource.FieldFlagHidden))
+	require.NoError(t, err)
+	ok, err := ds.AddField("ok", api.Kind_Bool)
+	require.NoError(t, err)
+	ratio, err := ds.AddField("ratio", api.Kind_Float64)
+	require.NoError(t, err)
+	load, err := ds.AddField("load", api.Kind_Float32)
+	require.NoError(t, err)
+	raw, err := ds.AddField("raw", api.Kind_Bytes)
+	require.NoError(t, err)
+	ports, err := ds.AddField("ports", api.Kind_List, datasource.WithElementKind(api.Kind_Uint16))
+	require.NoError(t, err)
+	container, err := ds.AddField("container", api.Kind_Invalid, datasource.WithFlags(datasource.FieldFlagEmpty))
+	require.NoError(t, err)
+	name, err := container.AddSubField("name", api.Kind_String)
+	require.NoError(t, err)
+
+	const rows = 10
+	data, err := ds.NewPacketSingle()
+	require.NoError(t, err)
+
+	for _, compression := range Compressions {
+		t.Run(compression, func(t *testing.T) {
+			var buf bytes.Buffer
+			w, err := New(&buf, ds, WithCompression(compression), WithMetadata("gadget", "trace_test"))
+			require.NoError(t, err)
+
+			for i := range rows {
+				require.NoError(t, comm.PutString(data, "cat"))
+				require.NoError(t, pid.PutUint32(data, math.MaxUint32-uint32(i)))
+				require.NoError(t, delta.PutInt8(data, int8(-i)))
+				require.NoError(t, ts.PutUint64(data, 1_700_000_000_000_000_000+uint64(i)))
+				require.NoError(t, ok.PutBool(data, i%3 == 0))
+				require.NoError(t, ratio.PutFloat64(data, float64(i)/4))
+				require.NoError(t, load.PutFloat32(data, float32(i)/2))
+				require.NoError(t, raw.PutBytes(data, []byte{byte(i), 0xff}))
+				require.NoError(t, ports.PutList(data, []any{uint16(80), uint16(i)}))
+				require.NoError(t, name.PutString(data, "c"+string(rune('a'+i))))
+				require.NoError(t, w.Write(data))
+				// two row groups
+				if i == 3 {
+					require.Equal(t, 4, w.Buffered())
+					require.NoError(t, w.Flush())
+				}
+			}
+			require.NoError(t, w.Close())
+			require.NoError(t, w.Close())
+			require.Error(t, w.Write(data))
+
+			f, columns := readFile(t, buf.Bytes())
+			require.Equal(t, int64(rows), f.NumRows())
+			require.Len(t, f.RowGroups(), 2)
+			require.Equal(t, "inspektor-gadget", f.Metadata().CreatedBy)
+			v, found := f.Lookup(MetadataDataSource)
+			require.True(t, found)
+			require.Equal(t, "events", v)
+			v, found = f.Lookup("gadget")
+			require.True(t, found)
+			require.Equal(t, "trace_test", v)
+
+			codec := codecs[compression].CompressionCodec()
+			for _, rg := range f.Metadata().RowGroups {
+				for _, c := range rg.Columns {
+					require.Equal(t, codec, c.MetaData.Codec)
+				}
+			}
+
+			types := map[string]string{}
+			for _, field := range f.Schema().Fields() {
+				require.True(t, field.Required())
+				types[field.Name()] = field.Type().String()
+			}
+			require.Equal(t, map[string]string{
+				"comm":           "STRING",
+				"pid":            "INT(32,false)",
+				"delta":          "INT(8,true)",
+				"timestamp_raw":  "TIMESTAMP(isAdjustedToUTC=true,unit=NANOS)",
+				"ok":             "BOOLEAN",
+				"ratio":          "DOUBLE",
+				"load":           "FLOAT",
+				"raw":            "BYTE_ARRAY",
+				"ports":          "STRING",
+				"container.name": "STRING",
+			}, types)
+
+			for i := range rows {
+				require.Equal(t, "cat", columns["comm"][i].String())
+				require.Equal(t, math.MaxUint32-uint32(i), columns["pid"][i].Uint32())
+				require.Equal(t, int32(-i), columns["delta"][i].Int32())
+				require.Equal(t, int64(1_700_000_000_000_000_000+i), columns["timestamp_raw"][i].Int64())
+				require.Equal(t, i%3 == 0, columns["ok"][i].Boolean())
+				require.Equal(t, float64(i)/4, columns["ratio"][i].Double())
+				require.Equal(t, float32(i)/2, columns["load"][i].Float())
+				require.Equal(t, []byte{byte(i), 0xff}, columns["raw"][i].ByteArray())
+				require.Equal(t, "80,"+string(rune('0'+i)), columns["ports"][i].String())
+				require.Equal(t, "c"+string(rune('a'+i)), columns["container.name"][i].String())
+			}
+		})
+	}
+}
+
+func TestWriterArray(t *testing.T) {
+	ds, err := datasource.New(datasource.TypeArray, "stats")
+	require.NoError(t, err)
+	count, err := ds.AddField("count", api.Kind_Int64)
+	require.NoError(t, err)
+	_, err = ds.AddField("hidden", api.Kind_Int64)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	w, err := New(&buf, ds, WithFields([]string{"count"}), WithCompression(CompressionNone))
+	require.NoError(t, err)
+
+	for _, n := range []int{3, 0, 2} {
+		arr, err := ds.NewPacketArray()
+		require.NoError(t, err)
+		for i := range n {
+			e := arr.New()
+			require.NoError(t, count.PutInt64(e, int64(i)))
+			arr.Append(e)
+		}
+		require.NoError(t, w.WriteArray(arr))
+		require.Zero(t, w.Buffered())
+	}
+	size := w.Size()
+	require.NoError(t, w.Close())
+	require.Greater(t, int64(buf.Len()), size)
+
+	f, columns := readFile(t, buf.Bytes())
+	require.Len(t, f.RowGroups(), 2) // empty arrays don't create row groups
+	require.Len(t, f.Schema().Fields(), 1)
+	var counts []int64
+	for _, v := range columns["count"] {
+		counts = append(counts, v.Int64())
+	}
+	require.Equal(t, []int64{0, 1, 2, 0, 1}, counts)
+}
+
+func TestWriterErrors(t *testing.T) {
+	ds, err := datasource.New(datasource.TypeSingle, "events")
+	require.NoError(t, err)
+	_, err = ds.AddField("comm", api.Kind_String)
+	require.NoError(t, err)
+
+	_, err = New(io.Discard, ds, WithCompression("lz4"))
+	require.Error(t, err)
+	_, err = New(io.Discard, ds, WithFields([]string{"foo"}))
+	require.Error(t, err)
+}
@@ -0,0 +1,95 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package profiles
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+)
+
+// WriteFolded writes the profile as folded stacks, as used by Brendan Gregg's FlameGraph tools:
+// one line per stack with the frames, root first, separated by ";" and followed by the value.
+// The container of the samples, if any, becomes the root frame.
+func (p *Profile) WriteFolded(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	stacks, values := p.folded()
+	for i, stack := range stacks {
+		frames := make([]string, 0, len(stack))
+		for _, frame := range stack {
+			frames = append(frames, strings.ReplaceAll(frame, ";", ":"))
+		}
+		fmt.Fprintf(bw, "%s %d\n", strings.Join(frames, ";"), values[i])
+	}
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("writing folded stacks: %w", err)
+	}
+	return nil
+}
+
+type flamegraphNode struct {
+	Name     string            `json:"n"`
+	Value    int64             `json:"v"`
+	Children []*flamegraphNode `json:"c,omitempty"`
+
+	index map[string]*flamegraphNode
+}
+
+func (n *flamegraphNode) child(name string) *flamegraphNode {
+	if c, ok := n.index[name]; ok {
+		return c
+	}
+	c := &flamegraphNode{Name: name, index: make(map[string]*flamegraphNode)}
+	n.Children = append(n.Children, c)
+	n.index[name] = c
+	return c
+}
+
+//go:embed flamegraph.html
+var flamegraphHTML string
+
+var flamegraphTemplate = template.Must(template.New("flamegraph").Parse(flamegraphHTML))
+
+// WriteFlamegraph writes the profile as a self-contained HTML file showing an interactive
+// flamegraph
+func (p *Profile) WriteFlamegraph(w io.Writer) error {
+	root := &flamegraphNode{Name: "all", index: make(map[string]*flamegraphNode)}
+	stacks, values := p.folded()
+	for i, stack := range stacks {
+		root.Value += values[i]
+		node := root
+		for _, frame := range stack {
+			node = node.child(frame)
+			node.Value += values[i]
+		}
+	}
+
+	err := flamegraphTemplate.Execute(w, struct {
+		Title string
+		Unit  string
+		Root  *flamegraphNode
+	}{
+		Title: p.Name,
+		Unit:  p.Unit,
+		Root:  root,
+	})
+	if err != nil {
+		return fmt.Errorf("writing flamegraph: %w", err)
+	}
+	return nil
+}
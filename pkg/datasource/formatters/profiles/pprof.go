@@ -0,0 +1,192 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package profiles
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Field numbers of profile.proto, see
+// https://github.com/google/pprof/blob/main/proto/profile.proto
+const (
+	profileSampleType    = 1
+	profileSample        = 2
+	profileLocation      = 4
+	profileFunction      = 5
+	profileStringTable   = 6
+	profileTimeNanos     = 9
+	profileDurationNanos = 10
+	profilePeriodType    = 11
+	profilePeriod        = 12
+
+	valueTypeType = 1
+	valueTypeUnit = 2
+
+	sampleLocationID = 1
+	sampleValue      = 2
+	sampleLabel      = 3
+
+	labelKey = 1
+	labelStr = 2
+	labelNum = 3
+
+	locationID   = 1
+	locationLine = 4
+
+	lineFunctionID = 1
+
+	functionID         = 1
+	functionName       = 2
+	functionSystemName = 3
+)
+
+type stringTable struct {
+	strings []string
+	index   map[string]int64
+}
+
+func newStringTable() *stringTable {
+	return &stringTable{
+		strings: []string{""},
+		index:   map[string]int64{"": 0},
+	}
+}
+
+func (t *stringTable) add(s string) int64 {
+	if idx, ok := t.index[s]; ok {
+		return idx
+	}
+	idx := int64(len(t.strings))
+	t.strings = append(t.strings, s)
+	t.index[s] = idx
+	return idx
+}
+
+func appendVarintField(b []byte, num protowire.Number, v uint64) []byte {
+	if v == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, v)
+}
+
+func appendMessageField(b []byte, num protowire.Number, msg []byte) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, msg)
+}
+
+func appendValueType(b []byte, num protowire.Number, typ, unit int64) []byte {
+	var msg []byte
+	msg = appendVarintField(msg, valueTypeType, uint64(typ))
+	msg = appendVarintField(msg, valueTypeUnit, uint64(unit))
+	return appendMessageField(b, num, msg)
+}
+
+// marshalPprof encodes the profile as an uncompressed pprof protobuf message
+func (p *Profile) marshalPprof() []byte {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	strs := newStringTable()
+	var b []byte
+
+	b = appendValueType(b, profileSampleType, strs.add(p.Type), strs.add(p.Unit))
+
+	// Functions and locations map one to one, both are identified by the index of the function
+	// name plus one
+	functions := make(map[string]uint64)
+	var functionNames []string
+	labelKeys := make([]int64, 0, len(p.labels))
+	for _, l := range p.labels {
+		labelKeys = append(labelKeys, strs.add(l.key))
+	}
+
+	for _, s := range p.samples {
+		var msg []byte
+
+		ids := make([]byte, 0, len(s.stack))
+		for _, frame := range s.stack {
+			id, ok := functions[frame]
+			if !ok {
+				functionNames = append(functionNames, frame)
+				id = uint64(len(functionNames))
+				functions[frame] = id
+			}
+			ids = protowire.AppendVarint(ids, id)
+		}
+		msg = appendMessageField(msg, sampleLocationID, ids)
+		msg = appendMessageField(msg, sampleValue, protowire.AppendVarint(nil, uint64(s.value)))
+
+		for i, l := range s.labels {
+			var lmsg []byte
+			lmsg = appendVarintField(lmsg, labelKey, uint64(labelKeys[i]))
+			if l.numeric {
+				lmsg = appendVarintField(lmsg, labelNum, uint64(l.num))
+			} else {
+				lmsg = appendVarintField(lmsg, labelStr, uint64(strs.add(l.str)))
+			}
+			msg = appendMessageField(msg, sampleLabel, lmsg)
+		}
+
+		b = appendMessageField(b, profileSample, msg)
+	}
+
+	for i, name := range functionNames {
+		id := uint64(i + 1)
+
+		var line []byte
+		line = appendVarintField(line, lineFunctionID, id)
+		var loc []byte
+		loc = appendVarintField(loc, locationID, id)
+		loc = appendMessageField(loc, locationLine, line)
+		b = appendMessageField(b, profileLocation, loc)
+
+		nameIdx := uint64(strs.add(name))
+		var fn []byte
+		fn = appendVarintField(fn, functionID, id)
+		fn = appendVarintField(fn, functionName, nameIdx)
+		fn = appendVarintField(fn, functionSystemName, nameIdx)
+		b = appendMessageField(b, profileFunction, fn)
+	}
+
+	b = appendVarintField(b, profileTimeNanos, uint64(p.start.UnixNano()))
+	b = appendVarintField(b, profileDurationNanos, uint64(time.Since(p.start).Nanoseconds()))
+	b = appendValueType(b, profilePeriodType, strs.add(p.Name), strs.add(p.Unit))
+	b = appendVarintField(b, profilePeriod, 1)
+
+	// The string table has to be complete, so it goes last
+	for _, s := range strs.strings {
+		b = protowire.AppendTag(b, profileStringTable, protowire.BytesType)
+		b = protowire.AppendString(b, s)
+	}
+	return b
+}
+
+// WritePprof writes the profile as a gzipped pprof protobuf, as read by "go tool pprof"
+func (p *Profile) WritePprof(w io.Writer) error {
+	gz := gzip.NewWriter(w)
+	if _, err := gz.Write(p.marshalPprof()); err != nil {
+		return fmt.Errorf("writing pprof profile: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("writing pprof profile: %w", err)
+	}
+	return nil
+}
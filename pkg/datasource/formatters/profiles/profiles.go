@@ -0,0 +1,300 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package profiles aggregates the stacks of data sources annotated with the profiles annotations
+// and writes them in local formats: pprof, folded stacks and flamegraph HTML.
+package profiles
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+)
+
+const (
+	// StackFieldsAnnotation is a comma-separated list of the fields holding the stacks of a sample,
+	// leaf first. Frames are separated by "; ".
+	StackFieldsAnnotation = "profiles.stack-fields"
+
+	// ValueFieldAnnotation is the integer field holding the value of a sample
+	ValueFieldAnnotation = "profiles.value-field"
+
+	// SampleAttributeAnnotation (if set to true) makes a field become an attribute (or label) of
+	// the samples
+	SampleAttributeAnnotation = "profiles.sample-attribute"
+
+	// NameAnnotation, TypeAnnotation and UnitAnnotation describe the profile; they default to the
+	// name of the data source, "samples" and "count"
+	NameAnnotation = "profiles.name"
+	TypeAnnotation = "profiles.type"
+	UnitAnnotation = "profiles.unit"
+
+	unknownFrame = "[unknown]"
+)
+
+// containerFields are added as labels to the samples if the data source has them, and make up the
+// root frame of the folded stacks and flamegraphs
+var containerFields = []string{"k8s.namespace", "k8s.podName", "k8s.containerName", "runtime.containerName"}
+
+type labelValue struct {
+	str     string
+	num     int64
+	numeric bool
+}
+
+func (l labelValue) String() string {
+	if l.numeric {
+		return fmt.Sprint(l.num)
+	}
+	return l.str
+}
+
+type label struct {
+	key string
+	fn  func(datasource.Data) labelValue
+}
+
+type sample struct {
+	labels    []labelValue
+	container string
+	stack     []string
+	value     int64
+}
+
+// Profile aggregates the samples of a data source
+type Profile struct {
+	Name string
+	Type string
+	Unit string
+
+	ds          datasource.DataSource
+	stackFields []datasource.FieldAccessor
+	valueFn     func(datasource.Data) int64
+	labels      []label
+	container   map[string]func(datasource.Data) string
+
+	mu      sync.Mutex
+	samples []*sample
+	index   map[string]*sample
+	start   time.Time
+}
+
+// IsProfile reports whether ds is annotated to carry profiles
+func IsProfile(ds datasource.DataSource) bool {
+	annotations := ds.Annotations()
+	return annotations[StackFieldsAnnotation] != "" && annotations[ValueFieldAnnotation] != ""
+}
+
+// New creates a profile for ds following its profiles annotations
+func New(ds datasource.DataSource) (*Profile, error) {
+	annotations := ds.Annotations()
+
+	p := &Profile{
+		Name:      ds.Name(),
+		Type:      "samples",
+		Unit:      "count",
+		ds:        ds,
+		container: make(map[string]func(datasource.Data) string),
+		index:     make(map[string]*sample),
+		start:     time.Now(),
+	}
+	if val := annotations[NameAnnotation]; val != "" {
+		p.Name = val
+	}
+	if val := annotations[TypeAnnotation]; val != "" {
+		p.Type = val
+	}
+	if val := annotations[UnitAnnotation]; val != "" {
+		p.Unit = val
+	}
+
+	for _, name := range strings.Split(annotations[StackFieldsAnnotation], ",") {
+		if name == "" {
+			continue
+		}
+		field := ds.GetField(name)
+		if field == nil {
+			return nil, fmt.Errorf("stack field %q not found", name)
+		}
+		p.stackFields = append(p.stackFields, field)
+	}
+	if len(p.stackFields) == 0 {
+		return nil, fmt.Errorf("no stack field set in annotation %q", StackFieldsAnnotation)
+	}
+
+	valueFieldName := annotations[ValueFieldAnnotation]
+	if valueFieldName == "" {
+		return nil, fmt.Errorf("no value field set in annotation %q", ValueFieldAnnotation)
+	}
+	valueField := ds.GetField(valueFieldName)
+	if valueField == nil {
+		return nil, fmt.Errorf("value field %q not found", valueFieldName)
+	}
+	valueFn, err := datasource.AsInt64(valueField)
+	if err != nil {
+		return nil, fmt.Errorf("value field %q: %w", valueFieldName, err)
+	}
+	p.valueFn = valueFn
+
+	for _, f := range ds.Fields() {
+		isAttribute := f.Annotations[SampleAttributeAnnotation] == "true"
+		if !isAttribute && !slices.Contains(containerFields, f.FullName) {
+			continue
+		}
+		acc := ds.GetField(f.FullName)
+		fn, err := labelFunc(acc)
+		if err != nil {
+			if isAttribute {
+				return nil, err
+			}
+			continue
+		}
+		p.labels = append(p.labels, label{key: f.FullName, fn: fn})
+		if slices.Contains(containerFields, f.FullName) {
+			p.container[f.FullName] = func(data datasource.Data) string {
+				return fn(data).String()
+			}
+		}
+	}
+	return p, nil
+}
+
+func labelFunc(f datasource.FieldAccessor) (func(datasource.Data) labelValue, error) {
+	switch f.Type() {
+	case api.Kind_Int8, api.Kind_Int16, api.Kind_Int32, api.Kind_Int64,
+		api.Kind_Uint8, api.Kind_Uint16, api.Kind_Uint32, api.Kind_Uint64:
+		fn, err := datasource.AsInt64(f)
+		if err != nil {
+			return nil, err
+		}
+		return func(data datasource.Data) labelValue {
+			return labelValue{num: fn(data), numeric: true}
+		}, nil
+	}
+	fn, err := datasource.AsString(f)
+	if err != nil {
+		return nil, fmt.Errorf("attribute field %q: %w", f.FullName(), err)
+	}
+	return func(data datasource.Data) labelValue {
+		return labelValue{str: fn(data)}
+	}, nil
+}
+
+// containerName returns the name of the container of a sample, as "namespace/pod/container" in
+// Kubernetes, or an empty string for samples from the host
+func (p *Profile) containerName(data datasource.Data) string {
+	get := func(name string) string {
+		if fn, ok := p.container[name]; ok {
+			return fn(data)
+		}
+		return ""
+	}
+	if pod := get("k8s.podName"); pod != "" {
+		return get("k8s.namespace") + "/" + pod + "/" + get("k8s.containerName")
+	}
+	return get("runtime.containerName")
+}
+
+// Add adds the sample in data to the profile. Samples with the same stack and labels are merged.
+func (p *Profile) Add(data datasource.Data) {
+	var stack []string
+	for _, field := range p.stackFields {
+		str, _ := field.String(data)
+		for _, frame := range strings.Split(str, "; ") {
+			frame = strings.TrimSpace(frame)
+			if frame != "" {
+				stack = append(stack, frame)
+			}
+		}
+	}
+	if len(stack) == 0 {
+		stack = []string{unknownFrame}
+	}
+
+	labels := make([]labelValue, 0, len(p.labels))
+	for _, l := range p.labels {
+		labels = append(labels, l.fn(data))
+	}
+
+	var key strings.Builder
+	for _, l := range labels {
+		key.WriteString(l.String())
+		key.WriteByte(0)
+	}
+	key.WriteByte(1)
+	key.WriteString(strings.Join(stack, "\x00"))
+
+	value := p.valueFn(data)
+	container := p.containerName(data)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if s, ok := p.index[key.String()]; ok {
+		s.value += value
+		return
+	}
+	s := &sample{
+		labels:    labels,
+		container: container,
+		stack:     stack,
+		value:     value,
+	}
+	p.samples = append(p.samples, s)
+	p.index[key.String()] = s
+}
+
+// AddArray adds all the samples of the array to the profile
+func (p *Profile) AddArray(dataArray datasource.DataArray) {
+	for i := range dataArray.Len() {
+		p.Add(dataArray.Get(i))
+	}
+}
+
+// folded returns the stacks of the profile, root first and with the container as root frame,
+// along with their summed up values, sorted by stack
+func (p *Profile) folded() ([][]string, []int64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	values := make(map[string]int64)
+	stacks := make(map[string][]string)
+	for _, s := range p.samples {
+		stack := make([]string, 0, len(s.stack)+1)
+		if s.container != "" {
+			stack = append(stack, s.container)
+		}
+		for _, frame := range slices.Backward(s.stack) {
+			stack = append(stack, frame)
+		}
+		key := strings.Join(stack, "\x00")
+		values[key] += s.value
+		stacks[key] = stack
+	}
+
+	keys := slices.Sorted(maps.Keys(stacks))
+	resStacks := make([][]string, 0, len(keys))
+	resValues := make([]int64, 0, len(keys))
+	for _, k := range keys {
+		resStacks = append(resStacks, stacks[k])
+		resValues = append(resValues, values[k])
+	}
+	return resStacks, resValues
+}
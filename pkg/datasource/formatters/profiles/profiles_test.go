@@ -0,0 +1,199 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package profiles
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+)
+
+type testSample struct {
+	container string
+	comm      string
+	kstack    string
+	ustack    string
+	value     uint64
+}
+
+func newTestProfile(t *testing.T, samples []testSample) *Profile {
+	t.Helper()
+
+	ds, err := datasource.New(datasource.TypeArray, "samples")
+	require.NoError(t, err)
+	ds.AddAnnotation(StackFieldsAnnotation, "kstack,ustack")
+	ds.AddAnnotation(ValueFieldAnnotation, "count")
+	ds.AddAnnotation(NameAnnotation, "cpu")
+
+	container, err := ds.AddField("runtime.containerName", api.Kind_String)
+	require.NoError(t, err)
+	comm, err := ds.AddField("comm", api.Kind_String,
+		datasource.WithAnnotations(map[string]string{SampleAttributeAnnotation: "true"}))
+	require.NoError(t, err)
+	kstack, err := ds.AddField("kstack", api.Kind_String)
+	require.NoError(t, err)
+	ustack, err := ds.AddField("ustack", api.Kind_String)
+	require.NoError(t, err)
+	count, err := ds.AddField("count", api.Kind_Uint64)
+	require.NoError(t, err)
+
+	require.True(t, IsProfile(ds))
+	p, err := New(ds)
+	require.NoError(t, err)
+	require.Equal(t, "cpu", p.Name)
+	require.Equal(t, "samples", p.Type)
+	require.Equal(t, "count", p.Unit)
+
+	pa, err := ds.NewPacketArray()
+	require.NoError(t, err)
+	for _, s := range samples {
+		data := pa.New()
+		require.NoError(t, container.PutString(data, s.container))
+		require.NoError(t, comm.PutString(data, s.comm))
+		require.NoError(t, kstack.PutString(data, s.kstack))
+		require.NoError(t, ustack.PutString(data, s.ustack))
+		require.NoError(t, count.PutUint64(data, s.value))
+		pa.Append(data)
+	}
+	p.AddArray(pa)
+	return p
+}
+
+var testSamples = []testSample{
+	{"", "bash", "schedule; ", "read; main; ", 3},
+	{"", "bash", "schedule; ", "read; main; ", 2},
+	{"web", "nginx", "", "accept; main; ", 4},
+	{"", "cat", "", "", 1},
+}
+
+func TestNew(t *testing.T) {
+	ds, err := datasource.New(datasource.TypeArray, "samples")
+	require.NoError(t, err)
+	require.False(t, IsProfile(ds))
+
+	ds.AddAnnotation(StackFieldsAnnotation, "stack")
+	ds.AddAnnotation(ValueFieldAnnotation, "count")
+	_, err = New(ds)
+	require.ErrorContains(t, err, "stack field")
+
+	_, err = ds.AddField("stack", api.Kind_String)
+	require.NoError(t, err)
+	_, err = New(ds)
+	require.ErrorContains(t, err, "value field")
+
+	_, err = ds.AddField("count", api.Kind_String)
+	require.NoError(t, err)
+	_, err = New(ds)
+	require.Error(t, err)
+}
+
+func TestWriteFolded(t *testing.T) {
+	p := newTestProfile(t, testSamples)
+
+	var buf bytes.Buffer
+	require.NoError(t, p.WriteFolded(&buf))
+	require.Equal(t, "[unknown] 1\nmain;read;schedule 5\nweb;main;accept 4\n", buf.String())
+}
+
+func TestWriteFlamegraph(t *testing.T) {
+	p := newTestProfile(t, testSamples)
+
+	var buf bytes.Buffer
+	require.NoError(t, p.WriteFlamegraph(&buf))
+	require.Contains(t, buf.String(), `"n":"all","v":10`)
+	require.Contains(t, buf.String(), `{"n":"web","v":4,"c":[{"n":"main","v":4,"c":[{"n":"accept","v":4}]}]}`)
+}
+
+// fields returns the values of the fields of a protobuf message, by field number
+func fields(t *testing.T, b []byte) map[protowire.Number][]any {
+	t.Helper()
+
+	res := make(map[protowire.Number][]any)
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		require.GreaterOrEqual(t, n, 0)
+		b = b[n:]
+		switch typ {
+		case protowire.VarintType:
+			v, n := protowire.ConsumeVarint(b)
+			require.GreaterOrEqual(t, n, 0)
+			res[num] = append(res[num], v)
+			b = b[n:]
+		case protowire.BytesType:
+			v, n := protowire.ConsumeBytes(b)
+			require.GreaterOrEqual(t, n, 0)
+			res[num] = append(res[num], v)
+			b = b[n:]
+		default:
+			t.Fatalf("unexpected wire type %v", typ)
+		}
+	}
+	return res
+}
+
+func TestWritePprof(t *testing.T) {
+	p := newTestProfile(t, testSamples)
+
+	var buf bytes.Buffer
+	require.NoError(t, p.WritePprof(&buf))
+
+	gz, err := gzip.NewReader(&buf)
+	require.NoError(t, err)
+	b, err := io.ReadAll(gz)
+	require.NoError(t, err)
+
+	profile := fields(t, b)
+
+	var strs []string
+	for _, s := range profile[profileStringTable] {
+		strs = append(strs, string(s.([]byte)))
+	}
+	require.Equal(t, "", strs[0])
+	require.Subset(t, strs, []string{"samples", "count", "cpu", "comm", "runtime.containerName",
+		"schedule", "read", "main", "accept", "[unknown]", "bash", "web"})
+
+	// Samples with the same stack and labels are merged
+	samples := profile[profileSample]
+	require.Len(t, samples, 3)
+
+	first := fields(t, samples[0].([]byte))
+	value, _ := protowire.ConsumeVarint(first[sampleValue][0].([]byte))
+	require.Equal(t, uint64(5), value)
+	require.Len(t, first[sampleLabel], 2)
+
+	// Locations are leaf first
+	locations := first[sampleLocationID][0].([]byte)
+	var names []string
+	for len(locations) > 0 {
+		id, n := protowire.ConsumeVarint(locations)
+		locations = locations[n:]
+		for _, fn := range profile[profileFunction] {
+			f := fields(t, fn.([]byte))
+			if f[functionID][0].(uint64) == id {
+				names = append(names, strs[f[functionName][0].(uint64)])
+			}
+		}
+	}
+	require.Equal(t, []string{"schedule", "read", "main"}, names)
+
+	require.Len(t, profile[profileLocation], 5)
+}
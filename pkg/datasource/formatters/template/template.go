@@ -0,0 +1,256 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package template formats the data of a DataSource using Go templates. Fields are accessed by
+// their full name, e.g. "{{.proc.comm}}", and have the Go type matching their kind.
+package template
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/docker/go-units"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
+)
+
+type fieldValue struct {
+	path []string
+	fn   func(datasource.Data) any
+}
+
+type Formatter struct {
+	ds     datasource.DataSource
+	tmpl   *template.Template
+	values []fieldValue
+
+	// mu protects buf, which is reused by all the calls
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+// Funcs are the functions available to templates in addition to the builtin ones
+var Funcs = template.FuncMap{
+	"upper":     strings.ToUpper,
+	"lower":     strings.ToLower,
+	"trim":      strings.TrimSpace,
+	"replace":   strings.ReplaceAll,
+	"contains":  strings.Contains,
+	"join":      join,
+	"truncate":  truncate,
+	"pad":       pad,
+	"hex":       toHex,
+	"json":      toJSON,
+	"default":   defaultValue,
+	"duration":  duration,
+	"timestamp": timestamp,
+	"bytes":     bytesSize,
+}
+
+// New parses text as a template for the data of ds. A line break is appended to the output of
+// every event unless text ends with one.
+func New(ds datasource.DataSource, text string) (*Formatter, error) {
+	if !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+	tmpl, err := template.New(ds.Name()).Funcs(Funcs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("parsing template: %w", err)
+	}
+
+	f := &Formatter{
+		ds:   ds,
+		tmpl: tmpl,
+	}
+	for _, field := range ds.Accessors(false) {
+		if datasource.FieldFlagContainer.In(field.Flags()) || datasource.FieldFlagEmpty.In(field.Flags()) {
+			continue
+		}
+		fn, err := datasource.AsAny(field)
+		if err != nil {
+			continue
+		}
+		f.values = append(f.values, fieldValue{
+			path: strings.Split(field.FullName(), "."),
+			fn:   fn,
+		})
+	}
+	return f, nil
+}
+
+// valuesOf returns the values of the fields as nested maps, following the dots in their names
+func (f *Formatter) valuesOf(data datasource.Data) map[string]any {
+	res := make(map[string]any)
+	for _, v := range f.values {
+		m := res
+		for _, p := range v.path[:len(v.path)-1] {
+			sub, ok := m[p].(map[string]any)
+			if !ok {
+				sub = make(map[string]any)
+				m[p] = sub
+			}
+			m = sub
+		}
+		m[v.path[len(v.path)-1]] = v.fn(data)
+	}
+	return res
+}
+
+// Marshal executes the template for data
+func (f *Formatter) Marshal(data datasource.Data) ([]byte, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.buf.Reset()
+	if err := f.tmpl.Execute(&f.buf, f.valuesOf(data)); err != nil {
+		return nil, fmt.Errorf("executing template: %w", err)
+	}
+	return bytes.Clone(f.buf.Bytes()), nil
+}
+
+// MarshalArray executes the template for every element of the array
+func (f *Formatter) MarshalArray(dataArray datasource.DataArray) ([]byte, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.buf.Reset()
+	for i := range dataArray.Len() {
+		if err := f.tmpl.Execute(&f.buf, f.valuesOf(dataArray.Get(i))); err != nil {
+			return nil, fmt.Errorf("executing template: %w", err)
+		}
+	}
+	return bytes.Clone(f.buf.Bytes()), nil
+}
+
+func toInt64(v any) (int64, error) {
+	switch v := v.(type) {
+	case int8:
+		return int64(v), nil
+	case int16:
+		return int64(v), nil
+	case int32:
+		return int64(v), nil
+	case int64:
+		return v, nil
+	case int:
+		return int64(v), nil
+	case uint8:
+		return int64(v), nil
+	case uint16:
+		return int64(v), nil
+	case uint32:
+		return int64(v), nil
+	case uint64:
+		return int64(v), nil
+	case float32:
+		return int64(v), nil
+	case float64:
+		return int64(v), nil
+	}
+	return 0, fmt.Errorf("expected a number, got %T", v)
+}
+
+func join(sep string, v any) (string, error) {
+	switch v := v.(type) {
+	case []string:
+		return strings.Join(v, sep), nil
+	case []any:
+		strs := make([]string, 0, len(v))
+		for _, e := range v {
+			strs = append(strs, fmt.Sprint(e))
+		}
+		return strings.Join(strs, sep), nil
+	}
+	return "", fmt.Errorf("expected a list, got %T", v)
+}
+
+func truncate(n int, v any) string {
+	s := fmt.Sprint(v)
+	if len([]rune(s)) <= n {
+		return s
+	}
+	return string([]rune(s)[:n])
+}
+
+// pad pads the value with spaces to n characters: on the right if n is positive, on the left
+// otherwise, like "%-*v" and "%*v" do
+func pad(n int, v any) string {
+	if n < 0 {
+		return fmt.Sprintf("%*v", -n, v)
+	}
+	return fmt.Sprintf("%-*v", n, v)
+}
+
+func toHex(v any) (string, error) {
+	switch v := v.(type) {
+	case []byte:
+		return hex.EncodeToString(v), nil
+	case string:
+		return hex.EncodeToString([]byte(v)), nil
+	}
+	i, err := toInt64(v)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("0x%x", uint64(i)), nil
+}
+
+func toJSON(v any) (string, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// defaultValue returns def if v is the zero value of its type
+func defaultValue(def, v any) any {
+	if v == nil || reflect.ValueOf(v).IsZero() {
+		return def
+	}
+	return v
+}
+
+func duration(ns any) (string, error) {
+	v, err := toInt64(ns)
+	if err != nil {
+		return "", err
+	}
+	return time.Duration(v).String(), nil
+}
+
+// timestamp formats a timestamp in nanoseconds since the epoch as RFC 3339
+func timestamp(ns any) (string, error) {
+	v, err := toInt64(ns)
+	if err != nil {
+		return "", err
+	}
+	return time.Unix(0, v).Format(time.RFC3339Nano), nil
+}
+
+// bytesSize formats a size in bytes in a human readable way, e.g. "1.5MiB"
+func bytesSize(size any) (string, error) {
+	v, err := toInt64(size)
+	if err != nil {
+		return "", err
+	}
+	return units.BytesSize(float64(v)), nil
+}
@@ -0,0 +1,169 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package template
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+)
+
+func TestFormatter(t *testing.T) {
+	ds, err := datasource.New(datasource.TypeSingle, "template")
+	require.NoError(t, err)
+	proc, err := ds.AddField("proc", api.Kind_Invalid, datasource.WithFlags(datasource.FieldFlagEmpty))
+	require.NoError(t, err)
+	comm, err := proc.AddSubField("comm", api.Kind_String)
+	require.NoError(t, err)
+	pid, err := proc.AddSubField("pid", api.Kind_Uint32)
+	require.NoError(t, err)
+	size, err := ds.AddField("size", api.Kind_Uint64)
+	require.NoError(t, err)
+	args, err := ds.AddField("args", api.Kind_List, datasource.WithElementKind(api.Kind_String))
+	require.NoError(t, err)
+	_, err = ds.AddField("parent", api.Kind_String)
+	require.NoError(t, err)
+
+	data, err := ds.NewPacketSingle()
+	require.NoError(t, err)
+	require.NoError(t, comm.PutString(data, "cat"))
+	require.NoError(t, pid.PutUint32(data, 255))
+	require.NoError(t, size.PutUint64(data, 1536))
+	require.NoError(t, args.PutStringList(data, []string{"-n", "/etc/hosts"}))
+
+	tests := []struct {
+		name        string
+		text        string
+		expected    string
+		expectedErr bool
+	}{
+		{
+			name:     "nested fields",
+			text:     "{{.proc.comm}}:{{.proc.pid}}",
+			expected: "cat:255\n",
+		},
+		{
+			name:     "trailing line break kept",
+			text:     "{{.proc.comm}}\n",
+			expected: "cat\n",
+		},
+		{
+			name:     "string funcs",
+			text:     `{{upper .proc.comm}} {{pad 5 .proc.pid}}|{{truncate 2 .proc.comm}}`,
+			expected: "CAT 255  |ca\n",
+		},
+		{
+			name:     "list funcs",
+			text:     `{{join " " .args}} {{json .args}}`,
+			expected: "-n /etc/hosts [\"-n\",\"/etc/hosts\"]\n",
+		},
+		{
+			name:     "number funcs",
+			text:     `{{hex .proc.pid}} {{bytes .size}} {{duration .size}}`,
+			expected: "0xff 1.5KiB 1.536µs\n",
+		},
+		{
+			name:     "default",
+			text:     `{{default "-" .parent}} {{default "-" .proc.comm}}`,
+			expected: "- cat\n",
+		},
+		{
+			name:        "invalid template",
+			text:        "{{.proc.comm",
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			f, err := New(ds, test.text)
+			if test.expectedErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			out, err := f.Marshal(data)
+			require.NoError(t, err)
+			require.Equal(t, test.expected, string(out))
+		})
+	}
+}
+
+func TestFormatterMissingField(t *testing.T) {
+	ds, err := datasource.New(datasource.TypeSingle, "template")
+	require.NoError(t, err)
+	_, err = ds.AddField("comm", api.Kind_String)
+	require.NoError(t, err)
+
+	data, err := ds.NewPacketSingle()
+	require.NoError(t, err)
+
+	f, err := New(ds, "{{.foo}}")
+	require.NoError(t, err)
+	_, err = f.Marshal(data)
+	require.Error(t, err)
+}
+
+func TestFormatterArray(t *testing.T) {
+	ds, err := datasource.New(datasource.TypeArray, "template")
+	require.NoError(t, err)
+	comm, err := ds.AddField("comm", api.Kind_String)
+	require.NoError(t, err)
+
+	dataArray, err := ds.NewPacketArray()
+	require.NoError(t, err)
+	for _, c := range []string{"cat", "ls"} {
+		data := dataArray.New()
+		require.NoError(t, comm.PutString(data, c))
+		dataArray.Append(data)
+	}
+
+	f, err := New(ds, "- {{.comm}}")
+	require.NoError(t, err)
+	out, err := f.MarshalArray(dataArray)
+	require.NoError(t, err)
+	require.Equal(t, "- cat\n- ls\n", string(out))
+}
+
+func TestFormatterConcurrent(t *testing.T) {
+	ds, err := datasource.New(datasource.TypeSingle, "template")
+	require.NoError(t, err)
+	pid, err := ds.AddField("pid", api.Kind_Uint32)
+	require.NoError(t, err)
+
+	f, err := New(ds, "{{.pid}}")
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for i := range 8 {
+		data, err := ds.NewPacketSingle()
+		require.NoError(t, err)
+		require.NoError(t, pid.PutUint32(data, uint32(i)))
+		wg.Go(func() {
+			for range 100 {
+				out, err := f.Marshal(data)
+				assert.NoError(t, err)
+				assert.Equal(t, strconv.Itoa(i)+"\n", string(out))
+			}
+		})
+	}
+	wg.Wait()
+}
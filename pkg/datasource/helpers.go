@@ -15,8 +15,10 @@
 package datasource
 
 import (
+	"encoding/hex"
 	"fmt"
 	"math"
+	"strconv"
 
 	"golang.org/x/exp/constraints"
 
@@ -151,3 +153,101 @@ func GetKeyValueFunc[S ~string, T any](
 		}, nil
 	}
 }
+
+// AsAny returns a function that extracts the value of the field as the Go type matching its
+// kind, e.g. uint32 for Kind_Uint32, string for Kind_CString, []any for Kind_List and
+// map[string]any for Kind_Map. On error, nil is returned.
+func AsAny(f FieldAccessor) (func(Data) any, error) {
+	wrap := func(fn func(Data) (any, error)) func(Data) any {
+		return func(data Data) any {
+			v, err := fn(data)
+			if err != nil {
+				return nil
+			}
+			return v
+		}
+	}
+	switch f.Type() {
+	default:
+		return nil, fmt.Errorf("invalid field type for AsAny: %s", f.Type())
+	case api.Kind_Bool:
+		return wrap(func(d Data) (any, error) { return f.Bool(d) }), nil
+	case api.Kind_Int8:
+		return wrap(func(d Data) (any, error) { return f.Int8(d) }), nil
+	case api.Kind_Int16:
+		return wrap(func(d Data) (any, error) { return f.Int16(d) }), nil
+	case api.Kind_Int32:
+		return wrap(func(d Data) (any, error) { return f.Int32(d) }), nil
+	case api.Kind_Int64:
+		return wrap(func(d Data) (any, error) { return f.Int64(d) }), nil
+	case api.Kind_Uint8:
+		return wrap(func(d Data) (any, error) { return f.Uint8(d) }), nil
+	case api.Kind_Uint16:
+		return wrap(func(d Data) (any, error) { return f.Uint16(d) }), nil
+	case api.Kind_Uint32:
+		return wrap(func(d Data) (any, error) { return f.Uint32(d) }), nil
+	case api.Kind_Uint64:
+		return wrap(func(d Data) (any, error) { return f.Uint64(d) }), nil
+	case api.Kind_Float32:
+		return wrap(func(d Data) (any, error) { return f.Float32(d) }), nil
+	case api.Kind_Float64:
+		return wrap(func(d Data) (any, error) { return f.Float64(d) }), nil
+	case api.Kind_String, api.Kind_CString:
+		return wrap(func(d Data) (any, error) { return f.String(d) }), nil
+	case api.Kind_Bytes:
+		return wrap(func(d Data) (any, error) { return f.Bytes(d) }), nil
+	case api.Kind_List:
+		return wrap(func(d Data) (any, error) { return f.List(d) }), nil
+	case api.Kind_Map:
+		return wrap(func(d Data) (any, error) { return f.Map(d) }), nil
+	}
+}
+
+// AsString returns a function that formats the value of the field as text: numbers in base 10,
+// bytes hex encoded, the elements of lists separated by commas and the entries of maps as
+// key=value pairs separated by commas. On error, an empty string is returned.
+func AsString(f FieldAccessor) (func(Data) string, error) {
+	switch f.Type() {
+	case api.Kind_String, api.Kind_CString:
+		return func(data Data) string {
+			s, _ := f.String(data)
+			return s
+		}, nil
+	case api.Kind_Bytes:
+		return func(data Data) string {
+			b, _ := f.Bytes(data)
+			return hex.EncodeToString(b)
+		}, nil
+	case api.Kind_List:
+		return func(data Data) string {
+			vals, _ := f.List(data)
+			return formatList(vals)
+		}, nil
+	case api.Kind_Map:
+		return func(data Data) string {
+			vals, _ := f.Map(data)
+			return formatMap(vals)
+		}, nil
+	case api.Kind_Float32, api.Kind_Float64:
+		asFloatFn, _ := AsFloat64(f) // error can't happen
+		bitSize := 64
+		if f.Type() == api.Kind_Float32 {
+			bitSize = 32
+		}
+		return func(data Data) string {
+			return strconv.FormatFloat(asFloatFn(data), 'g', -1, bitSize)
+		}, nil
+	}
+
+	anyFn, err := AsAny(f)
+	if err != nil {
+		return nil, fmt.Errorf("invalid field type for AsString: %s", f.Type())
+	}
+	return func(data Data) string {
+		v := anyFn(data)
+		if v == nil {
+			return ""
+		}
+		return fmt.Sprint(v)
+	}, nil
+}
@@ -0,0 +1,502 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package gadgetdiff compares the information of two gadgets (as returned by
+// GetGadgetInfo) and reports added, removed and changed data sources, fields,
+// params and eBPF objects.
+package gadgetdiff
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+)
+
+type ChangeType string
+
+const (
+	ChangeAdded   ChangeType = "added"
+	ChangeRemoved ChangeType = "removed"
+	ChangeChanged ChangeType = "changed"
+)
+
+type Object string
+
+const (
+	ObjectDataSource Object = "datasource"
+	ObjectField      Object = "field"
+	ObjectParam      Object = "param"
+	ObjectProgram    Object = "program"
+	ObjectMap        Object = "map"
+)
+
+// Change describes a single difference between two gadgets
+type Change struct {
+	Type      ChangeType `json:"type" yaml:"type" column:"type,width:7,fixed"`
+	Object    Object     `json:"object" yaml:"object" column:"object,width:10,fixed"`
+	Name      string     `json:"name" yaml:"name" column:"name"`
+	Attribute string     `json:"attribute,omitempty" yaml:"attribute,omitempty" column:"attribute"`
+	Old       string     `json:"old,omitempty" yaml:"old,omitempty" column:"old"`
+	New       string     `json:"new,omitempty" yaml:"new,omitempty" column:"new"`
+	Breaking  bool       `json:"breaking" yaml:"breaking" column:"breaking,width:8,fixed"`
+}
+
+func (c *Change) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s %s %q %s", c.Type, c.Object, c.Name, c.Attribute)
+	if c.Type == ChangeChanged {
+		fmt.Fprintf(&sb, ": %q -> %q", c.Old, c.New)
+	}
+	if c.Breaking {
+		sb.WriteString(" (breaking)")
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+// HasBreaking returns true if any of the given changes is breaking
+func HasBreaking(changes []*Change) bool {
+	return slices.ContainsFunc(changes, func(c *Change) bool { return c.Breaking })
+}
+
+// cosmeticAnnotationPrefixes match annotations that only affect presentation; changing
+// them is not considered breaking
+var cosmeticAnnotationPrefixes = []string{
+	"description",
+	"columns.",
+}
+
+func isCosmeticAnnotation(key string) bool {
+	for _, prefix := range cosmeticAnnotationPrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Compare returns the list of changes needed to go from gadget a to gadget b.
+// eBPF programs and maps are only compared if both gadget infos contain the
+// corresponding extra info (see requestExtraInfo).
+func Compare(a, b *api.GadgetInfo) ([]*Change, error) {
+	changes := make([]*Change, 0)
+
+	changes = append(changes, compareDataSources(a.DataSources, b.DataSources)...)
+	changes = append(changes, compareParams(a.Params, b.Params)...)
+
+	ebpfChanges, err := compareEBPF(a.ExtraInfo, b.ExtraInfo)
+	if err != nil {
+		return nil, err
+	}
+	changes = append(changes, ebpfChanges...)
+
+	return changes, nil
+}
+
+func compareDataSources(a, b []*api.DataSource) []*Change {
+	changes := make([]*Change, 0)
+
+	oldDs := make(map[string]*api.DataSource)
+	for _, ds := range a {
+		oldDs[ds.Name] = ds
+	}
+	newDs := make(map[string]*api.DataSource)
+	for _, ds := range b {
+		newDs[ds.Name] = ds
+	}
+
+	for _, name := range sortedKeys(oldDs) {
+		if _, ok := newDs[name]; !ok {
+			changes = append(changes, &Change{
+				Type:     ChangeRemoved,
+				Object:   ObjectDataSource,
+				Name:     name,
+				Breaking: true,
+			})
+		}
+	}
+	for _, name := range sortedKeys(newDs) {
+		ds := newDs[name]
+		old, ok := oldDs[name]
+		if !ok {
+			changes = append(changes, &Change{
+				Type:   ChangeAdded,
+				Object: ObjectDataSource,
+				Name:   name,
+			})
+			continue
+		}
+		if old.Type != ds.Type {
+			changes = append(changes, &Change{
+				Type:      ChangeChanged,
+				Object:    ObjectDataSource,
+				Name:      name,
+				Attribute: "type",
+				Old:       dataSourceTypeString(old.Type),
+				New:       dataSourceTypeString(ds.Type),
+				Breaking:  true,
+			})
+		}
+		changes = append(changes, compareTags(ObjectDataSource, name, old.Tags, ds.Tags)...)
+		changes = append(changes, compareAnnotations(ObjectDataSource, name, old.Annotations, ds.Annotations)...)
+		changes = append(changes, compareFields(name, old.Fields, ds.Fields)...)
+	}
+	return changes
+}
+
+func compareFields(dsName string, a, b []*api.Field) []*Change {
+	changes := make([]*Change, 0)
+
+	oldFields := make(map[string]*api.Field)
+	for _, f := range a {
+		oldFields[f.FullName] = f
+	}
+	newFields := make(map[string]*api.Field)
+	for _, f := range b {
+		newFields[f.FullName] = f
+	}
+
+	for _, name := range sortedKeys(oldFields) {
+		if _, ok := newFields[name]; !ok {
+			changes = append(changes, &Change{
+				Type:     ChangeRemoved,
+				Object:   ObjectField,
+				Name:     dsName + "." + name,
+				Breaking: true,
+			})
+		}
+	}
+	for _, name := range sortedKeys(newFields) {
+		f := newFields[name]
+		fullName := dsName + "." + name
+		old, ok := oldFields[name]
+		if !ok {
+			changes = append(changes, &Change{
+				Type:   ChangeAdded,
+				Object: ObjectField,
+				Name:   fullName,
+			})
+			continue
+		}
+		if old.Kind != f.Kind {
+			changes = append(changes, &Change{
+				Type:      ChangeChanged,
+				Object:    ObjectField,
+				Name:      fullName,
+				Attribute: "kind",
+				Old:       old.Kind.String(),
+				New:       f.Kind.String(),
+				Breaking:  true,
+			})
+		}
+		changes = append(changes, compareTags(ObjectField, fullName, old.Tags, f.Tags)...)
+		changes = append(changes, compareAnnotations(ObjectField, fullName, old.Annotations, f.Annotations)...)
+	}
+	return changes
+}
+
+func compareTags(obj Object, name string, a, b []string) []*Change {
+	changes := make([]*Change, 0)
+	for _, tag := range sortedUnique(a) {
+		if !slices.Contains(b, tag) {
+			changes = append(changes, &Change{
+				Type:      ChangeRemoved,
+				Object:    obj,
+				Name:      name,
+				Attribute: "tag " + tag,
+				Breaking:  true,
+			})
+		}
+	}
+	for _, tag := range sortedUnique(b) {
+		if !slices.Contains(a, tag) {
+			changes = append(changes, &Change{
+				Type:      ChangeAdded,
+				Object:    obj,
+				Name:      name,
+				Attribute: "tag " + tag,
+			})
+		}
+	}
+	return changes
+}
+
+func compareAnnotations(obj Object, name string, a, b map[string]string) []*Change {
+	changes := make([]*Change, 0)
+	for _, k := range sortedKeys(a) {
+		if _, ok := b[k]; !ok {
+			changes = append(changes, &Change{
+				Type:      ChangeRemoved,
+				Object:    obj,
+				Name:      name,
+				Attribute: "annotation " + k,
+				Old:       a[k],
+				Breaking:  !isCosmeticAnnotation(k),
+			})
+		}
+	}
+	for _, k := range sortedKeys(b) {
+		old, ok := a[k]
+		if !ok {
+			changes = append(changes, &Change{
+				Type:      ChangeAdded,
+				Object:    obj,
+				Name:      name,
+				Attribute: "annotation " + k,
+				New:       b[k],
+			})
+			continue
+		}
+		if old != b[k] {
+			changes = append(changes, &Change{
+				Type:      ChangeChanged,
+				Object:    obj,
+				Name:      name,
+				Attribute: "annotation " + k,
+				Old:       old,
+				New:       b[k],
+				Breaking:  !isCosmeticAnnotation(k),
+			})
+		}
+	}
+	return changes
+}
+
+func compareParams(a, b []*api.Param) []*Change {
+	changes := make([]*Change, 0)
+
+	oldParams := make(map[string]*api.Param)
+	for _, p := range a {
+		oldParams[p.Prefix+p.Key] = p
+	}
+	newParams := make(map[string]*api.Param)
+	for _, p := range b {
+		newParams[p.Prefix+p.Key] = p
+	}
+
+	for _, name := range sortedKeys(oldParams) {
+		if _, ok := newParams[name]; !ok {
+			changes = append(changes, &Change{
+				Type:     ChangeRemoved,
+				Object:   ObjectParam,
+				Name:     name,
+				Breaking: true,
+			})
+		}
+	}
+	for _, name := range sortedKeys(newParams) {
+		p := newParams[name]
+		old, ok := oldParams[name]
+		if !ok {
+			changes = append(changes, &Change{
+				Type:     ChangeAdded,
+				Object:   ObjectParam,
+				Name:     name,
+				New:      p.DefaultValue,
+				Breaking: p.IsMandatory && p.DefaultValue == "",
+			})
+			continue
+		}
+		if old.DefaultValue != p.DefaultValue {
+			changes = append(changes, &Change{
+				Type:      ChangeChanged,
+				Object:    ObjectParam,
+				Name:      name,
+				Attribute: "default",
+				Old:       old.DefaultValue,
+				New:       p.DefaultValue,
+				Breaking:  true,
+			})
+		}
+		if old.TypeHint != p.TypeHint {
+			changes = append(changes, &Change{
+				Type:      ChangeChanged,
+				Object:    ObjectParam,
+				Name:      name,
+				Attribute: "type",
+				Old:       old.TypeHint,
+				New:       p.TypeHint,
+				Breaking:  true,
+			})
+		}
+		if old.IsMandatory != p.IsMandatory {
+			changes = append(changes, &Change{
+				Type:      ChangeChanged,
+				Object:    ObjectParam,
+				Name:      name,
+				Attribute: "mandatory",
+				Old:       fmt.Sprint(old.IsMandatory),
+				New:       fmt.Sprint(p.IsMandatory),
+				Breaking:  p.IsMandatory,
+			})
+		}
+		// An empty list of possible values means that any value is accepted
+		for _, v := range sortedUnique(old.PossibleValues) {
+			if len(p.PossibleValues) > 0 && !slices.Contains(p.PossibleValues, v) {
+				changes = append(changes, &Change{
+					Type:      ChangeRemoved,
+					Object:    ObjectParam,
+					Name:      name,
+					Attribute: "possible value",
+					Old:       v,
+					Breaking:  true,
+				})
+			}
+		}
+		for _, v := range sortedUnique(p.PossibleValues) {
+			if len(old.PossibleValues) > 0 && !slices.Contains(old.PossibleValues, v) {
+				changes = append(changes, &Change{
+					Type:      ChangeAdded,
+					Object:    ObjectParam,
+					Name:      name,
+					Attribute: "possible value",
+					New:       v,
+				})
+			}
+		}
+	}
+	return changes
+}
+
+// ebpfProgram and ebpfMap mirror the JSON content of the "ebpf.programs" and
+// "ebpf.maps" extra info entries added by the ebpf operator
+type ebpfProgram struct {
+	Section string
+}
+
+type ebpfMap struct {
+	Name string
+	Type string
+}
+
+func getExtraInfoJSON(ei *api.ExtraInfo, key string, dst any) (bool, error) {
+	if ei == nil || ei.Data == nil {
+		return false, nil
+	}
+	entry, ok := ei.Data[key]
+	if !ok {
+		return false, nil
+	}
+	if err := json.Unmarshal(entry.Content, dst); err != nil {
+		return false, fmt.Errorf("unmarshalling extra info %q: %w", key, err)
+	}
+	return true, nil
+}
+
+func compareEBPF(a, b *api.ExtraInfo) ([]*Change, error) {
+	changes := make([]*Change, 0)
+
+	var oldProgs, newProgs []ebpfProgram
+	okOld, err := getExtraInfoJSON(a, "ebpf.programs", &oldProgs)
+	if err != nil {
+		return nil, err
+	}
+	okNew, err := getExtraInfoJSON(b, "ebpf.programs", &newProgs)
+	if err != nil {
+		return nil, err
+	}
+	if okOld && okNew {
+		oldSections := make([]string, 0, len(oldProgs))
+		for _, p := range oldProgs {
+			oldSections = append(oldSections, p.Section)
+		}
+		newSections := make([]string, 0, len(newProgs))
+		for _, p := range newProgs {
+			newSections = append(newSections, p.Section)
+		}
+		for _, s := range sortedUnique(oldSections) {
+			if !slices.Contains(newSections, s) {
+				changes = append(changes, &Change{Type: ChangeRemoved, Object: ObjectProgram, Name: s})
+			}
+		}
+		for _, s := range sortedUnique(newSections) {
+			if !slices.Contains(oldSections, s) {
+				changes = append(changes, &Change{Type: ChangeAdded, Object: ObjectProgram, Name: s})
+			}
+		}
+	}
+
+	var oldMaps, newMaps []ebpfMap
+	okOld, err = getExtraInfoJSON(a, "ebpf.maps", &oldMaps)
+	if err != nil {
+		return nil, err
+	}
+	okNew, err = getExtraInfoJSON(b, "ebpf.maps", &newMaps)
+	if err != nil {
+		return nil, err
+	}
+	if okOld && okNew {
+		oldTypes := make(map[string]string)
+		for _, m := range oldMaps {
+			oldTypes[m.Name] = m.Type
+		}
+		newTypes := make(map[string]string)
+		for _, m := range newMaps {
+			newTypes[m.Name] = m.Type
+		}
+		for _, name := range sortedKeys(oldTypes) {
+			if _, ok := newTypes[name]; !ok {
+				changes = append(changes, &Change{Type: ChangeRemoved, Object: ObjectMap, Name: name, Old: oldTypes[name]})
+			}
+		}
+		for _, name := range sortedKeys(newTypes) {
+			old, ok := oldTypes[name]
+			if !ok {
+				changes = append(changes, &Change{Type: ChangeAdded, Object: ObjectMap, Name: name, New: newTypes[name]})
+				continue
+			}
+			if old != newTypes[name] {
+				changes = append(changes, &Change{
+					Type:      ChangeChanged,
+					Object:    ObjectMap,
+					Name:      name,
+					Attribute: "type",
+					Old:       old,
+					New:       newTypes[name],
+				})
+			}
+		}
+	}
+
+	return changes, nil
+}
+
+func dataSourceTypeString(t uint32) string {
+	switch datasource.Type(t) {
+	case datasource.TypeSingle:
+		return "single"
+	case datasource.TypeArray:
+		return "array"
+	}
+	return fmt.Sprintf("unknown(%d)", t)
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedUnique(s []string) []string {
+	res := slices.Clone(s)
+	slices.Sort(res)
+	return slices.Compact(res)
+}
@@ -0,0 +1,141 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gadgetdiff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+)
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		name     string
+		a        *api.GadgetInfo
+		b        *api.GadgetInfo
+		expected []*Change
+	}{
+		{
+			name:     "identical",
+			a:        &api.GadgetInfo{DataSources: []*api.DataSource{{Name: "events"}}},
+			b:        &api.GadgetInfo{DataSources: []*api.DataSource{{Name: "events"}}},
+			expected: []*Change{},
+		},
+		{
+			name: "data sources added and removed",
+			a:    &api.GadgetInfo{DataSources: []*api.DataSource{{Name: "old"}}},
+			b:    &api.GadgetInfo{DataSources: []*api.DataSource{{Name: "new"}}},
+			expected: []*Change{
+				{Type: ChangeRemoved, Object: ObjectDataSource, Name: "old", Breaking: true},
+				{Type: ChangeAdded, Object: ObjectDataSource, Name: "new"},
+			},
+		},
+		{
+			name: "field renamed and kind changed",
+			a: &api.GadgetInfo{DataSources: []*api.DataSource{{Name: "events", Fields: []*api.Field{
+				{FullName: "comm", Kind: api.Kind_CString},
+				{FullName: "pid", Kind: api.Kind_Uint32},
+			}}}},
+			b: &api.GadgetInfo{DataSources: []*api.DataSource{{Name: "events", Fields: []*api.Field{
+				{FullName: "command", Kind: api.Kind_CString},
+				{FullName: "pid", Kind: api.Kind_Uint64},
+			}}}},
+			expected: []*Change{
+				{Type: ChangeRemoved, Object: ObjectField, Name: "events.comm", Breaking: true},
+				{Type: ChangeAdded, Object: ObjectField, Name: "events.command"},
+				{Type: ChangeChanged, Object: ObjectField, Name: "events.pid", Attribute: "kind", Old: "Uint32", New: "Uint64", Breaking: true},
+			},
+		},
+		{
+			name: "annotations and tags",
+			a: &api.GadgetInfo{DataSources: []*api.DataSource{{Name: "events", Fields: []*api.Field{
+				{FullName: "count", Tags: []string{"type:u64"}, Annotations: map[string]string{
+					"metrics.type":  "counter",
+					"columns.width": "10",
+				}},
+			}}}},
+			b: &api.GadgetInfo{DataSources: []*api.DataSource{{Name: "events", Fields: []*api.Field{
+				{FullName: "count", Annotations: map[string]string{
+					"columns.width": "12",
+					"description":   "number of events",
+				}},
+			}}}},
+			expected: []*Change{
+				{Type: ChangeRemoved, Object: ObjectField, Name: "events.count", Attribute: "tag type:u64", Breaking: true},
+				{Type: ChangeRemoved, Object: ObjectField, Name: "events.count", Attribute: "annotation metrics.type", Old: "counter", Breaking: true},
+				{Type: ChangeChanged, Object: ObjectField, Name: "events.count", Attribute: "annotation columns.width", Old: "10", New: "12"},
+				{Type: ChangeAdded, Object: ObjectField, Name: "events.count", Attribute: "annotation description", New: "number of events"},
+			},
+		},
+		{
+			name: "params",
+			a: &api.GadgetInfo{Params: []*api.Param{
+				{Prefix: "operator.ebpf.", Key: "iface", DefaultValue: ""},
+				{Prefix: "operator.ebpf.", Key: "mode", DefaultValue: "a", PossibleValues: []string{"a", "b"}},
+				{Prefix: "operator.ebpf.", Key: "gone"},
+			}},
+			b: &api.GadgetInfo{Params: []*api.Param{
+				{Prefix: "operator.ebpf.", Key: "iface", DefaultValue: "eth0"},
+				{Prefix: "operator.ebpf.", Key: "mode", DefaultValue: "a", PossibleValues: []string{"a", "c"}},
+			}},
+			expected: []*Change{
+				{Type: ChangeRemoved, Object: ObjectParam, Name: "operator.ebpf.gone", Breaking: true},
+				{Type: ChangeChanged, Object: ObjectParam, Name: "operator.ebpf.iface", Attribute: "default", New: "eth0", Breaking: true},
+				{Type: ChangeRemoved, Object: ObjectParam, Name: "operator.ebpf.mode", Attribute: "possible value", Old: "b", Breaking: true},
+				{Type: ChangeAdded, Object: ObjectParam, Name: "operator.ebpf.mode", Attribute: "possible value", New: "c"},
+			},
+		},
+		{
+			name: "ebpf programs and maps",
+			a: &api.GadgetInfo{ExtraInfo: &api.ExtraInfo{Data: map[string]*api.GadgetInspectAddendum{
+				"ebpf.programs": {ContentType: "application/json", Content: []byte(`[{"Section":"tracepoint/a"}]`)},
+				"ebpf.maps":     {ContentType: "application/json", Content: []byte(`[{"Name":"events","Type":"PerfEventArray"}]`)},
+			}}},
+			b: &api.GadgetInfo{ExtraInfo: &api.ExtraInfo{Data: map[string]*api.GadgetInspectAddendum{
+				"ebpf.programs": {ContentType: "application/json", Content: []byte(`[{"Section":"tracepoint/b"}]`)},
+				"ebpf.maps":     {ContentType: "application/json", Content: []byte(`[{"Name":"events","Type":"RingBuf"}]`)},
+			}}},
+			expected: []*Change{
+				{Type: ChangeRemoved, Object: ObjectProgram, Name: "tracepoint/a"},
+				{Type: ChangeAdded, Object: ObjectProgram, Name: "tracepoint/b"},
+				{Type: ChangeChanged, Object: ObjectMap, Name: "events", Attribute: "type", Old: "PerfEventArray", New: "RingBuf"},
+			},
+		},
+		{
+			name: "ebpf skipped without extra info",
+			a: &api.GadgetInfo{ExtraInfo: &api.ExtraInfo{Data: map[string]*api.GadgetInspectAddendum{
+				"ebpf.programs": {ContentType: "application/json", Content: []byte(`[{"Section":"tracepoint/a"}]`)},
+			}}},
+			b:        &api.GadgetInfo{},
+			expected: []*Change{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			changes, err := Compare(test.a, test.b)
+			require.NoError(t, err)
+			require.Equal(t, test.expected, changes)
+		})
+	}
+}
+
+func TestHasBreaking(t *testing.T) {
+	require.False(t, HasBreaking(nil))
+	require.False(t, HasBreaking([]*Change{{Type: ChangeAdded}}))
+	require.True(t, HasBreaking([]*Change{{Type: ChangeAdded}, {Type: ChangeRemoved, Breaking: true}}))
+}
@@ -65,6 +65,16 @@ func TestGetStringValuesPerDataSource(t *testing.T) {
 			input:    "datasource1:foo",
 			expected: map[string]string{"datasource1": "foo"},
 		},
+		{
+			name:     "valid with colon in argument",
+			input:    "template={{.a}}:{{.b}}",
+			expected: map[string]string{"": "template={{.a}}:{{.b}}"},
+		},
+		{
+			name:     "valid with datasource and colon in argument",
+			input:    "datasource1:template={{.a}}:{{.b}}",
+			expected: map[string]string{"datasource1": "template={{.a}}:{{.b}}"},
+		},
 		{
 			name:        "invalid mixing datasource and no datasource",
 			input:       "10,datasource1:20",
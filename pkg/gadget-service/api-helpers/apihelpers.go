@@ -110,6 +110,7 @@ func Validate(p api.Params, v api.ParamValues) error {
 
 // GetStringValuesPerDataSource will separate a string and extract per-datasource values. It expects a string like
 // `datasource1:value,datasource2:value` or `value` (datasource is optional - this will lead to an empty key)
+// A prefix containing "=" is not taken as datasource, so values like `template={{.a}}:{{.b}}` are kept as-is.
 func GetStringValuesPerDataSource(s string) (map[string]string, error) {
 	if s == "" {
 		return map[string]string{}, nil
@@ -121,8 +122,8 @@ func GetStringValuesPerDataSource(s string) (map[string]string, error) {
 		}
 		info := strings.SplitN(interval, ":", 2)
 		dsName := ""
-		val := info[0]
-		if len(info) > 1 {
+		val := interval
+		if len(info) > 1 && !strings.Contains(info[0], "=") {
 			dsName = info[0]
 			val = info[1]
 		}
@@ -53,6 +53,8 @@ const (
 	Kind_String  Kind = 12
 	Kind_CString Kind = 13
 	Kind_Bytes   Kind = 14
+	Kind_List    Kind = 15
+	Kind_Map     Kind = 16
 )
 
 // Enum value maps for Kind.
@@ -73,6 +75,8 @@ var (
 		12: "String",
 		13: "CString",
 		14: "Bytes",
+		15: "List",
+		16: "Map",
 	}
 	Kind_value = map[string]int32{
 		"Invalid": 0,
@@ -90,6 +94,8 @@ var (
 		"String":  12,
 		"CString": 13,
 		"Bytes":   14,
+		"List":    15,
+		"Map":     16,
 	}
 )
 
@@ -1246,7 +1252,11 @@ type Field struct {
 	Parent uint32 `protobuf:"varint,11,opt,name=parent,proto3" json:"parent,omitempty"`
 	// order determines the default position of this field when
 	// ordering multiple fields
-	Order         int32 `protobuf:"varint,12,opt,name=order,proto3" json:"order,omitempty"`
+	Order int32 `protobuf:"varint,12,opt,name=order,proto3" json:"order,omitempty"`
+	// elementKind describes the kind of the elements of a field of kind
+	// List or the kind of the values of a field of kind Map; keys of maps
+	// are always strings
+	ElementKind   Kind `protobuf:"varint,13,opt,name=elementKind,proto3,enum=api.Kind" json:"elementKind,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -1365,6 +1375,13 @@ func (x *Field) GetOrder() int32 {
 	return 0
 }
 
+func (x *Field) GetElementKind() Kind {
+	if x != nil {
+		return x.ElementKind
+	}
+	return Kind_Invalid
+}
+
 type GetGadgetInfoRequest struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
 	// params are the gadget's parameters
@@ -2015,7 +2032,7 @@ const file_api_api_proto_rawDesc = "" +
 	"\x05flags\x18\a \x01(\rR\x05flags\x1a>\n" +
 	"\x10AnnotationsEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x8f\x03\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xbc\x03\n" +
 	"\x05Field\x12\x12\n" +
 	"\x04name\x18\x01 \x01(\tR\x04name\x12\x1a\n" +
 	"\bfullName\x18\x02 \x01(\tR\bfullName\x12\x14\n" +
@@ -2029,7 +2046,8 @@ const file_api_api_proto_rawDesc = "" +
 	"\vannotations\x18\n" +
 	" \x03(\v2\x1b.api.Field.AnnotationsEntryR\vannotations\x12\x16\n" +
 	"\x06parent\x18\v \x01(\rR\x06parent\x12\x14\n" +
-	"\x05order\x18\f \x01(\x05R\x05order\x1a>\n" +
+	"\x05order\x18\f \x01(\x05R\x05order\x12+\n" +
+	"\velementKind\x18\r \x01(\x0e2\t.api.KindR\velementKind\x1a>\n" +
 	"\x10AnnotationsEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
 	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x9e\x02\n" +
@@ -2070,7 +2088,7 @@ const file_api_api_proto_rawDesc = "" +
 	"\x02id\x18\x01 \x01(\tR\x02id\"B\n" +
 	"\x0eStatusResponse\x12\x16\n" +
 	"\x06result\x18\x01 \x01(\x05R\x06result\x12\x18\n" +
-	"\amessage\x18\x02 \x01(\tR\amessage*\xb5\x01\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage*\xc8\x01\n" +
 	"\x04Kind\x12\v\n" +
 	"\aInvalid\x10\x00\x12\b\n" +
 	"\x04Bool\x10\x01\x12\b\n" +
@@ -2091,7 +2109,9 @@ const file_api_api_proto_rawDesc = "" +
 	"\n" +
 	"\x06String\x10\f\x12\v\n" +
 	"\aCString\x10\r\x12\t\n" +
-	"\x05Bytes\x10\x0e*M\n" +
+	"\x05Bytes\x10\x0e\x12\b\n" +
+	"\x04List\x10\x0f\x12\a\n" +
+	"\x03Map\x10\x10*M\n" +
 	"\x14GadgetInstanceStatus\x12\x11\n" +
 	"\rStatusInvalid\x10\x00\x12\x11\n" +
 	"\rStatusRunning\x10\x01\x12\x0f\n" +
@@ -2173,34 +2193,35 @@ var file_api_api_proto_depIdxs = []int32{
 	31, // 12: api.DataSource.annotations:type_name -> api.DataSource.AnnotationsEntry
 	0,  // 13: api.Field.kind:type_name -> api.Kind
 	32, // 14: api.Field.annotations:type_name -> api.Field.AnnotationsEntry
-	33, // 15: api.GetGadgetInfoRequest.paramValues:type_name -> api.GetGadgetInfoRequest.ParamValuesEntry
-	13, // 16: api.GetGadgetInfoResponse.gadgetInfo:type_name -> api.GadgetInfo
-	23, // 17: api.CreateGadgetInstanceRequest.gadgetInstance:type_name -> api.GadgetInstance
-	23, // 18: api.CreateGadgetInstanceResponse.gadgetInstance:type_name -> api.GadgetInstance
-	2,  // 19: api.GadgetInstance.gadgetConfig:type_name -> api.GadgetRunRequest
-	24, // 20: api.GadgetInstance.state:type_name -> api.GadgetInstanceState
-	1,  // 21: api.GadgetInstanceState.status:type_name -> api.GadgetInstanceStatus
-	23, // 22: api.ListGadgetInstanceResponse.gadgetInstances:type_name -> api.GadgetInstance
-	15, // 23: api.ExtraInfo.DataEntry.value:type_name -> api.GadgetInspectAddendum
-	7,  // 24: api.BuiltInGadgetManager.GetInfo:input_type -> api.InfoRequest
-	18, // 25: api.GadgetManager.GetGadgetInfo:input_type -> api.GetGadgetInfoRequest
-	6,  // 26: api.GadgetManager.RunGadget:input_type -> api.GadgetControlRequest
-	20, // 27: api.GadgetInstanceManager.CreateGadgetInstance:input_type -> api.CreateGadgetInstanceRequest
-	22, // 28: api.GadgetInstanceManager.ListGadgetInstances:input_type -> api.ListGadgetInstancesRequest
-	26, // 29: api.GadgetInstanceManager.GetGadgetInstance:input_type -> api.GadgetInstanceId
-	26, // 30: api.GadgetInstanceManager.RemoveGadgetInstance:input_type -> api.GadgetInstanceId
-	8,  // 31: api.BuiltInGadgetManager.GetInfo:output_type -> api.InfoResponse
-	19, // 32: api.GadgetManager.GetGadgetInfo:output_type -> api.GetGadgetInfoResponse
-	4,  // 33: api.GadgetManager.RunGadget:output_type -> api.GadgetEvent
-	21, // 34: api.GadgetInstanceManager.CreateGadgetInstance:output_type -> api.CreateGadgetInstanceResponse
-	25, // 35: api.GadgetInstanceManager.ListGadgetInstances:output_type -> api.ListGadgetInstanceResponse
-	23, // 36: api.GadgetInstanceManager.GetGadgetInstance:output_type -> api.GadgetInstance
-	27, // 37: api.GadgetInstanceManager.RemoveGadgetInstance:output_type -> api.StatusResponse
-	31, // [31:38] is the sub-list for method output_type
-	24, // [24:31] is the sub-list for method input_type
-	24, // [24:24] is the sub-list for extension type_name
-	24, // [24:24] is the sub-list for extension extendee
-	0,  // [0:24] is the sub-list for field type_name
+	0,  // 15: api.Field.elementKind:type_name -> api.Kind
+	33, // 16: api.GetGadgetInfoRequest.paramValues:type_name -> api.GetGadgetInfoRequest.ParamValuesEntry
+	13, // 17: api.GetGadgetInfoResponse.gadgetInfo:type_name -> api.GadgetInfo
+	23, // 18: api.CreateGadgetInstanceRequest.gadgetInstance:type_name -> api.GadgetInstance
+	23, // 19: api.CreateGadgetInstanceResponse.gadgetInstance:type_name -> api.GadgetInstance
+	2,  // 20: api.GadgetInstance.gadgetConfig:type_name -> api.GadgetRunRequest
+	24, // 21: api.GadgetInstance.state:type_name -> api.GadgetInstanceState
+	1,  // 22: api.GadgetInstanceState.status:type_name -> api.GadgetInstanceStatus
+	23, // 23: api.ListGadgetInstanceResponse.gadgetInstances:type_name -> api.GadgetInstance
+	15, // 24: api.ExtraInfo.DataEntry.value:type_name -> api.GadgetInspectAddendum
+	7,  // 25: api.BuiltInGadgetManager.GetInfo:input_type -> api.InfoRequest
+	18, // 26: api.GadgetManager.GetGadgetInfo:input_type -> api.GetGadgetInfoRequest
+	6,  // 27: api.GadgetManager.RunGadget:input_type -> api.GadgetControlRequest
+	20, // 28: api.GadgetInstanceManager.CreateGadgetInstance:input_type -> api.CreateGadgetInstanceRequest
+	22, // 29: api.GadgetInstanceManager.ListGadgetInstances:input_type -> api.ListGadgetInstancesRequest
+	26, // 30: api.GadgetInstanceManager.GetGadgetInstance:input_type -> api.GadgetInstanceId
+	26, // 31: api.GadgetInstanceManager.RemoveGadgetInstance:input_type -> api.GadgetInstanceId
+	8,  // 32: api.BuiltInGadgetManager.GetInfo:output_type -> api.InfoResponse
+	19, // 33: api.GadgetManager.GetGadgetInfo:output_type -> api.GetGadgetInfoResponse
+	4,  // 34: api.GadgetManager.RunGadget:output_type -> api.GadgetEvent
+	21, // 35: api.GadgetInstanceManager.CreateGadgetInstance:output_type -> api.CreateGadgetInstanceResponse
+	25, // 36: api.GadgetInstanceManager.ListGadgetInstances:output_type -> api.ListGadgetInstanceResponse
+	23, // 37: api.GadgetInstanceManager.GetGadgetInstance:output_type -> api.GadgetInstance
+	27, // 38: api.GadgetInstanceManager.RemoveGadgetInstance:output_type -> api.StatusResponse
+	32, // [32:39] is the sub-list for method output_type
+	25, // [25:32] is the sub-list for method input_type
+	25, // [25:25] is the sub-list for extension type_name
+	25, // [25:25] is the sub-list for extension extendee
+	0,  // [0:25] is the sub-list for field type_name
 }
 
 func init() { file_api_api_proto_init() }
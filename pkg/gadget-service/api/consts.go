@@ -74,6 +74,9 @@ const (
 	TypeDuration    = "duration"
 	TypeIP          = "ip"
 	TypeStringSlice = "[]string"
+
+	TypeCIDRList      = "[]cidr"
+	TypePortRangeList = "[]portrange"
 )
 
 const (
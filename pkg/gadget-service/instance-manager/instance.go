@@ -71,6 +71,10 @@ type GadgetInstance struct {
 	state                gadgetState
 	error                error
 	ready                chan struct{}
+
+	// removed is set when the instance is removed, so the objects it pinned are released once
+	// it has stopped
+	removed bool
 }
 
 func (p *GadgetInstance) GadgetInfo() (*api.GadgetInfo, error) {
@@ -21,6 +21,7 @@ import (
 
 	log "github.com/sirupsen/logrus"
 
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/bpfpin"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/logger"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators"
@@ -85,6 +86,10 @@ func (m *Manager) RemoveGadget(id string) error {
 	if !ok {
 		return ErrNotFound
 	}
+	// The objects pinned by the instance are released once it has stopped, see RunGadget
+	gadgetInstance.mu.Lock()
+	gadgetInstance.removed = true
+	gadgetInstance.mu.Unlock()
 	gadgetInstance.cancel()
 	delete(m.gadgetInstances, id)
 	return nil
@@ -136,6 +141,17 @@ func (m *Manager) RunGadget(instance *api.GadgetInstance) {
 			gi.mu.Unlock()
 		}
 		gi.RemoveClients()
+
+		// Release the eBPF objects pinned by the instance, if any. This is only done if the
+		// instance was removed: otherwise they are reused when it's started again.
+		gi.mu.Lock()
+		removed := gi.removed
+		gi.mu.Unlock()
+		if removed {
+			if err := bpfpin.Remove(gi.id); err != nil {
+				log.Warnf("removing pinned objects of gadget instance %q: %v", gi.id, err)
+			}
+		}
 	}()
 }
 
@@ -20,6 +20,8 @@ package histogram
 
 import (
 	"fmt"
+	"math"
+	"strconv"
 	"strings"
 )
 
@@ -30,6 +32,17 @@ const (
 	UnitMicroseconds Unit = "µs"
 )
 
+// Scale defines how the number of events in an interval maps to the length of its bar
+type Scale string
+
+const (
+	// ScaleLinear makes bars proportional to the number of events
+	ScaleLinear Scale = "linear"
+	// ScaleLog2 makes bars proportional to the log2 of the number of events, so intervals with
+	// few events, like the tail of a latency distribution, remain visible
+	ScaleLog2 Scale = "log2"
+)
+
 type Interval struct {
 	Count uint64 `json:"count"`
 	Start uint64 `json:"start"`
@@ -45,7 +58,7 @@ type Histogram struct {
 
 // NewIntervalsFromExp2Slots creates a new Interval array from an exp-2
 // histogram represented in slots.
-func NewIntervalsFromExp2Slots(slots []uint32) []Interval {
+func NewIntervalsFromExp2Slots[T uint32 | uint64](slots []T) []Interval {
 	if len(slots) == 0 {
 		return nil
 	}
@@ -127,3 +140,186 @@ func starsToString(val, valMax, width uint64) string {
 
 	return sb.String()
 }
+
+// Count returns the total number of events of the histogram
+func (h *Histogram) Count() uint64 {
+	total := uint64(0)
+	for _, b := range h.Intervals {
+		total += b.Count
+	}
+	return total
+}
+
+// Max returns the end of the highest interval holding events, or 0 if the histogram is empty
+func (h *Histogram) Max() uint64 {
+	for i := len(h.Intervals) - 1; i >= 0; i-- {
+		if h.Intervals[i].Count > 0 {
+			return h.Intervals[i].End
+		}
+	}
+	return 0
+}
+
+// Percentile returns an estimate of the value below which p percent of the events fall. As only
+// the interval of each event is known, events are assumed to be evenly distributed within their
+// interval, like histogram_quantile() of Prometheus does.
+func (h *Histogram) Percentile(p float64) uint64 {
+	total := h.Count()
+	if total == 0 {
+		return 0
+	}
+	p = min(max(p, 0), 100)
+
+	rank := p / 100 * float64(total)
+	cum := uint64(0)
+	for _, b := range h.Intervals {
+		if b.Count == 0 {
+			continue
+		}
+		if float64(cum+b.Count) >= rank {
+			frac := max(rank-float64(cum), 0) / float64(b.Count)
+			return b.Start + uint64(frac*float64(b.End-b.Start))
+		}
+		cum += b.Count
+	}
+	return h.Max()
+}
+
+// Titled is a histogram together with the title shown above it by RenderSideBySide
+type Titled struct {
+	Title     string
+	Histogram *Histogram
+}
+
+// RenderOptions configure RenderSideBySide
+type RenderOptions struct {
+	// Scale of the bars, ScaleLinear if empty
+	Scale Scale
+	// Stars is the length of the longest bar, 40 if not set
+	Stars int
+	// Width is the maximum width of a line; histograms that don't fit are rendered below the
+	// others. All histograms are rendered on the same lines if not set.
+	Width int
+	// Percentiles (e.g. 50, 90, 99) are estimated and printed below the histograms, followed by
+	// the end of the highest interval holding events
+	Percentiles []float64
+}
+
+const (
+	// intervalWidth is the width of the start and end of an interval, labelWidth the one of the
+	// interval column: "<start> -> <end>"
+	intervalWidth = 10
+	labelWidth    = 2*intervalWidth + len(" -> ")
+
+	countHeader = " : count    distribution"
+	blockGap    = 4
+)
+
+// RenderSideBySide renders histograms next to each other, sharing the interval column, like
+// String() does for a single one. Histograms are expected to have the same intervals, as it's
+// the case of exp-2 histograms.
+func RenderSideBySide(hists []Titled, opts RenderOptions) string {
+	if len(hists) == 0 {
+		return ""
+	}
+	if opts.Stars <= 0 {
+		opts.Stars = 40
+	}
+
+	// " : <count> |<bar>|", followed by a gap to the next histogram
+	blockWidth := max(len(" : ")+8+len(" |")+opts.Stars+len("|"), len(countHeader)) + blockGap
+	perLine := len(hists)
+	if opts.Width > 0 {
+		perLine = max(1, (opts.Width-labelWidth)/blockWidth)
+	}
+
+	var sb strings.Builder
+	for i := 0; i < len(hists); i += perLine {
+		if i > 0 {
+			sb.WriteByte('\n')
+		}
+		renderLine(&sb, hists[i:min(i+perLine, len(hists))], blockWidth, opts)
+	}
+	return sb.String()
+}
+
+func renderLine(sb *strings.Builder, hists []Titled, blockWidth int, opts RenderOptions) {
+	var line strings.Builder
+	writeLine := func() {
+		sb.WriteString(strings.TrimRight(line.String(), " "))
+		sb.WriteByte('\n')
+		line.Reset()
+	}
+
+	hasTitles := false
+	intervals := []Interval{}
+	for _, h := range hists {
+		hasTitles = hasTitles || h.Title != ""
+		if len(h.Histogram.Intervals) > len(intervals) {
+			intervals = h.Histogram.Intervals
+		}
+	}
+
+	if hasTitles {
+		fmt.Fprintf(&line, "%*s", labelWidth, "")
+		for _, h := range hists {
+			title := h.Title
+			if len(title) > blockWidth-3 {
+				title = title[:blockWidth-3]
+			}
+			fmt.Fprintf(&line, "   %-*s", blockWidth-3, title)
+		}
+		writeLine()
+	}
+
+	fmt.Fprintf(&line, "%*s%-*s", 8, "", labelWidth-8, hists[0].Histogram.Unit)
+	for range hists {
+		fmt.Fprintf(&line, "%-*s", blockWidth, countHeader)
+	}
+	writeLine()
+
+	valMax := make([]uint64, len(hists))
+	for i, h := range hists {
+		for _, b := range h.Histogram.Intervals {
+			valMax[i] = max(valMax[i], b.Count)
+		}
+	}
+	for row, interval := range intervals {
+		fmt.Fprintf(&line, "%*d -> %-*d", intervalWidth, interval.Start, intervalWidth, interval.End)
+		for i, h := range hists {
+			count := uint64(0)
+			if row < len(h.Histogram.Intervals) {
+				count = h.Histogram.Intervals[row].Count
+			}
+			bar := scaledStarsToString(count, valMax[i], uint64(opts.Stars), opts.Scale)
+			fmt.Fprintf(&line, "%-*s", blockWidth, fmt.Sprintf(" : %-8d |%s|", count, bar))
+		}
+		writeLine()
+	}
+
+	if len(opts.Percentiles) == 0 {
+		return
+	}
+	for _, p := range opts.Percentiles {
+		fmt.Fprintf(&line, "%*s", labelWidth, "p"+strconv.FormatFloat(p, 'f', -1, 64))
+		for _, h := range hists {
+			fmt.Fprintf(&line, "%-*s", blockWidth, fmt.Sprintf(" : %d", h.Histogram.Percentile(p)))
+		}
+		writeLine()
+	}
+	fmt.Fprintf(&line, "%*s", labelWidth, "max")
+	for _, h := range hists {
+		fmt.Fprintf(&line, "%-*s", blockWidth, fmt.Sprintf(" : %d", h.Histogram.Max()))
+	}
+	writeLine()
+}
+
+// scaledStarsToString is starsToString with the given scale
+func scaledStarsToString(val, valMax, width uint64, scale Scale) string {
+	if scale != ScaleLog2 || valMax == 0 {
+		return starsToString(val, valMax, width)
+	}
+
+	stars := uint64(float64(width) * math.Log2(float64(val)+1) / math.Log2(float64(valMax)+1))
+	return strings.Repeat("*", int(stars)) + strings.Repeat(" ", int(width-stars))
+}
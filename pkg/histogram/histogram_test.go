@@ -230,3 +230,170 @@ func TestHistogram_String(t *testing.T) {
 		})
 	}
 }
+
+func TestHistogram_NewIntervalsFromExp2SlotsU64(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, []Interval{
+		{Count: 1 << 40, Start: 0, End: 1},
+		{Count: 0, Start: 2, End: 3},
+		{Count: 3, Start: 4, End: 7},
+	}, NewIntervalsFromExp2Slots([]uint64{1 << 40, 0, 3, 0}))
+}
+
+func TestHistogram_Percentile(t *testing.T) {
+	t.Parallel()
+
+	testTable := []struct {
+		description string
+		slots       []uint32
+		percentile  float64
+		expected    uint64
+	}{
+		{
+			description: "Empty histogram",
+			slots:       nil,
+			percentile:  50,
+			expected:    0,
+		},
+		{
+			description: "Single interval",
+			slots:       []uint32{0, 0, 0, 10},
+			percentile:  50,
+			expected:    11,
+		},
+		{
+			description: "Median in first half",
+			slots:       []uint32{0, 50, 0, 50},
+			percentile:  50,
+			expected:    3,
+		},
+		{
+			description: "Interpolated",
+			slots:       []uint32{0, 50, 0, 50},
+			percentile:  90,
+			expected:    13,
+		},
+		{
+			description: "Highest percentile",
+			slots:       []uint32{0, 50, 0, 50},
+			percentile:  100,
+			expected:    15,
+		},
+		{
+			description: "Lowest percentile",
+			slots:       []uint32{0, 50, 0, 50},
+			percentile:  0,
+			expected:    2,
+		},
+		{
+			description: "Out of range",
+			slots:       []uint32{0, 50, 0, 50},
+			percentile:  1000,
+			expected:    15,
+		},
+	}
+
+	for _, test := range testTable {
+		test := test
+		t.Run(test.description, func(t *testing.T) {
+			t.Parallel()
+
+			h := &Histogram{Intervals: NewIntervalsFromExp2Slots(test.slots)}
+			require.Equal(t, test.expected, h.Percentile(test.percentile))
+		})
+	}
+}
+
+func TestHistogram_CountMax(t *testing.T) {
+	t.Parallel()
+
+	h := &Histogram{Intervals: NewIntervalsFromExp2Slots([]uint32{1, 0, 3, 0})}
+	require.Equal(t, uint64(4), h.Count())
+	require.Equal(t, uint64(7), h.Max())
+
+	h = &Histogram{}
+	require.Equal(t, uint64(0), h.Count())
+	require.Equal(t, uint64(0), h.Max())
+}
+
+func TestHistogram_RenderSideBySide(t *testing.T) {
+	t.Parallel()
+
+	testTable := []struct {
+		description string
+		hists       []Titled
+		opts        RenderOptions
+		expected    string
+	}{
+		{
+			description: "No histograms",
+			expected:    "",
+		},
+		{
+			description: "Like String",
+			hists: []Titled{
+				{Histogram: &Histogram{Unit: UnitMicroseconds, Intervals: NewIntervalsFromExp2Slots([]uint32{1, 2, 3})}},
+			},
+			expected: "" +
+				"        µs               : count    distribution\n" +
+				"         0 -> 1          : 1        |*************                           |\n" +
+				"         2 -> 3          : 2        |**************************              |\n" +
+				"         4 -> 7          : 3        |****************************************|\n",
+		},
+		{
+			description: "Side by side with percentiles",
+			hists: []Titled{
+				{Title: "dev=sda", Histogram: &Histogram{Unit: UnitMicroseconds, Intervals: NewIntervalsFromExp2Slots([]uint32{1, 2, 3})}},
+				{Title: "dev=sdb", Histogram: &Histogram{Unit: UnitMicroseconds, Intervals: NewIntervalsFromExp2Slots([]uint32{4})}},
+			},
+			opts: RenderOptions{Stars: 10, Percentiles: []float64{50, 99.9}},
+			expected: "" +
+				"                           dev=sda                     dev=sdb\n" +
+				"        µs               : count    distribution     : count    distribution\n" +
+				"         0 -> 1          : 1        |***       |     : 4        |**********|\n" +
+				"         2 -> 3          : 2        |******    |     : 0        |          |\n" +
+				"         4 -> 7          : 3        |**********|     : 0        |          |\n" +
+				"                     p50 : 3                         : 0\n" +
+				"                   p99.9 : 6                         : 0\n" +
+				"                     max : 7                         : 1\n",
+		},
+		{
+			description: "Wrapped",
+			hists: []Titled{
+				{Title: "a", Histogram: &Histogram{Intervals: NewIntervalsFromExp2Slots([]uint32{1})}},
+				{Title: "b", Histogram: &Histogram{Intervals: NewIntervalsFromExp2Slots([]uint32{2})}},
+			},
+			opts: RenderOptions{Stars: 10, Width: 60},
+			expected: "" +
+				"                           a\n" +
+				"                         : count    distribution\n" +
+				"         0 -> 1          : 1        |**********|\n" +
+				"\n" +
+				"                           b\n" +
+				"                         : count    distribution\n" +
+				"         0 -> 1          : 2        |**********|\n",
+		},
+		{
+			description: "Log2 scale",
+			hists: []Titled{
+				{Histogram: &Histogram{Intervals: NewIntervalsFromExp2Slots([]uint32{1, 0, 255})}},
+			},
+			opts: RenderOptions{Stars: 8, Scale: ScaleLog2},
+			expected: "" +
+				"                         : count    distribution\n" +
+				"         0 -> 1          : 1        |*       |\n" +
+				"         2 -> 3          : 0        |        |\n" +
+				"         4 -> 7          : 255      |********|\n",
+		},
+	}
+
+	for _, test := range testTable {
+		test := test
+		t.Run(test.description, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, test.expected, RenderSideBySide(test.hists, test.opts))
+		})
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kallsyms
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+)
+
+var availableFilterFunctionsPaths = []string{
+	"/sys/kernel/tracing/available_filter_functions",
+	"/sys/kernel/debug/tracing/available_filter_functions",
+}
+
+// MatchTraceableFunctions returns the kernel functions that can be traced by
+// kprobes and match the given glob pattern, as understood by path.Match. It
+// follows what libbpf does for kprobe.multi programs.
+func MatchTraceableFunctions(pattern string) ([]string, error) {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+	}
+
+	var errs []error
+	for _, p := range availableFilterFunctionsPaths {
+		file, err := os.Open(p)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		defer file.Close()
+		return matchTraceableFunctionsFromReader(file, pattern)
+	}
+	return nil, fmt.Errorf("opening available_filter_functions: %w", errors.Join(errs...))
+}
+
+func matchTraceableFunctionsFromReader(reader io.Reader, pattern string) ([]string, error) {
+	var functions []string
+	seen := make(map[string]struct{})
+
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		// Lines look like "vfs_read" or "nf_nat_setup_info [nf_nat]"
+		name, _, _ := strings.Cut(scanner.Text(), " ")
+		if name == "" {
+			continue
+		}
+		// Skip compiler generated suffixes like .isra.0 or .cold, they
+		// can't be attached to by name
+		if strings.Contains(name, ".") {
+			continue
+		}
+		if _, ok := seen[name]; ok {
+			continue
+		}
+		if ok, _ := path.Match(pattern, name); !ok {
+			continue
+		}
+		seen[name] = struct{}{}
+		functions = append(functions, name)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(functions) == 0 {
+		return nil, fmt.Errorf("no traceable function matches %q", pattern)
+	}
+	return functions, nil
+}
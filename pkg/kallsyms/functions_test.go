@@ -0,0 +1,79 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kallsyms
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testAvailableFilterFunctions = `vfs_read
+vfs_write
+vfs_write
+vfs_fsync_range.isra.0
+do_sys_openat2
+nf_nat_setup_info [nf_nat]
+`
+
+func TestMatchTraceableFunctions(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		pattern     string
+		expected    []string
+		expectedErr bool
+	}{
+		{
+			name:     "glob",
+			pattern:  "vfs_*",
+			expected: []string{"vfs_read", "vfs_write"},
+		},
+		{
+			name:     "exact",
+			pattern:  "do_sys_openat2",
+			expected: []string{"do_sys_openat2"},
+		},
+		{
+			name:     "module",
+			pattern:  "nf_nat_*",
+			expected: []string{"nf_nat_setup_info"},
+		},
+		{
+			name:        "no match",
+			pattern:     "foo_*",
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			functions, err := matchTraceableFunctionsFromReader(strings.NewReader(testAvailableFilterFunctions), test.pattern)
+			if test.expectedErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.expected, functions)
+		})
+	}
+
+	_, err := MatchTraceableFunctions("[")
+	require.Error(t, err)
+}
@@ -0,0 +1,264 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package linkhandler handles how programs attached to the cgroup (cgroup_skb,
+// cgroup/sock_create, cgroup/connect4, etc.) or to the network namespace
+// (sk_lookup) of containers are attached. Several containers can share the
+// same cgroup or network namespace (e.g. containers of the same pod share the
+// network namespace), so programs are attached only once to each of them and
+// detached when the last container using it is detached.
+//
+// Like uprobetracer, containers attached before AttachProg is called are
+// kept in a pending list and attached once the program is available.
+package linkhandler
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+
+	"github.com/cilium/ebpf"
+	"github.com/cilium/ebpf/link"
+
+	containercollection "github.com/inspektor-gadget/inspektor-gadget/pkg/container-collection"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/utils/host"
+)
+
+// targetFunc returns the identifier of the cgroup or network namespace of the
+// container
+type targetFunc func(container *containercollection.Container) (string, error)
+
+// attachFunc attaches prog to the given target of container. Closing the
+// returned value detaches the program.
+type attachFunc func(target string, container *containercollection.Container, prog *ebpf.Program) (io.Closer, error)
+
+// attachment is a program attached to a cgroup or network namespace
+type attachment struct {
+	link io.Closer
+	// users keeps track of the IDs of the containers using this attachment
+	users map[string]struct{}
+}
+
+type Handler struct {
+	targetFn targetFunc
+	attachFn attachFunc
+
+	prog *ebpf.Program
+
+	// key: target (cgroup path or netns inode)
+	attachments map[string]*attachment
+	// key: container ID, value: target
+	containerTargets map[string]string
+	// key: container ID
+	pendingContainers map[string]*containercollection.Container
+
+	closed bool
+	mu     sync.Mutex
+}
+
+func newHandler(targetFn targetFunc, attachFn attachFunc) *Handler {
+	return &Handler{
+		targetFn:          targetFn,
+		attachFn:          attachFn,
+		attachments:       make(map[string]*attachment),
+		containerTargets:  make(map[string]string),
+		pendingContainers: make(map[string]*containercollection.Container),
+	}
+}
+
+// NewCgroupHandler returns a Handler attaching programs to the cgroup v2 of
+// containers using the given attach type
+func NewCgroupHandler(attachType ebpf.AttachType) *Handler {
+	return NewCgroupHandlerWithFunc(func(cgroupPath string, prog *ebpf.Program) (io.Closer, error) {
+		return link.AttachCgroup(link.CgroupOptions{
+			Path:    cgroupPath,
+			Attach:  attachType,
+			Program: prog,
+		})
+	})
+}
+
+// NewCgroupHandlerWithFunc returns a Handler calling attachFn to attach
+// programs to the cgroup v2 of containers. It's used for programs that aren't
+// attached with a cgroup link, like perf event programs scoped to a cgroup.
+func NewCgroupHandlerWithFunc(attachFn func(cgroupPath string, prog *ebpf.Program) (io.Closer, error)) *Handler {
+	targetFn := func(container *containercollection.Container) (string, error) {
+		if container.CgroupPath == "" {
+			return "", fmt.Errorf("cgroup v2 path of container %q not found", container.Runtime.ContainerID)
+		}
+		return container.CgroupPath, nil
+	}
+	return newHandler(targetFn, func(cgroupPath string, _ *containercollection.Container, prog *ebpf.Program) (io.Closer, error) {
+		return attachFn(cgroupPath, prog)
+	})
+}
+
+// NewNetnsHandler returns a Handler attaching programs to the network
+// namespace of containers
+func NewNetnsHandler() *Handler {
+	targetFn := func(container *containercollection.Container) (string, error) {
+		if container.Netns == 0 {
+			return "", fmt.Errorf("network namespace of container %q not found", container.Runtime.ContainerID)
+		}
+		return strconv.FormatUint(container.Netns, 10), nil
+	}
+	attachFn := func(_ string, container *containercollection.Container, prog *ebpf.Program) (io.Closer, error) {
+		netnsPath := filepath.Join(host.HostProcFs, fmt.Sprint(container.ContainerPid()), "ns", "net")
+		netns, err := os.Open(netnsPath)
+		if err != nil {
+			return nil, fmt.Errorf("opening network namespace: %w", err)
+		}
+		defer netns.Close()
+		return link.AttachNetNs(int(netns.Fd()), prog)
+	}
+	return newHandler(targetFn, attachFn)
+}
+
+// AttachProg sets the program to attach and attaches it to the pending
+// containers
+func (h *Handler) AttachProg(prog *ebpf.Program) error {
+	if prog == nil {
+		return errors.New("prog does not exist")
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		return errors.New("handler has been closed")
+	}
+	if h.prog != nil {
+		return errors.New("loading program twice")
+	}
+	h.prog = prog
+
+	var errs []error
+	for _, container := range h.pendingContainers {
+		if err := h.attach(container); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	h.pendingContainers = nil
+
+	return errors.Join(errs...)
+}
+
+func (h *Handler) attach(container *containercollection.Container) error {
+	id := container.Runtime.ContainerID
+
+	target, err := h.targetFn(container)
+	if err != nil {
+		return err
+	}
+
+	a, ok := h.attachments[target]
+	if !ok {
+		l, err := h.attachFn(target, container, h.prog)
+		if err != nil {
+			return fmt.Errorf("attaching to container %q: %w", id, err)
+		}
+		a = &attachment{
+			link:  l,
+			users: make(map[string]struct{}),
+		}
+		h.attachments[target] = a
+	}
+	a.users[id] = struct{}{}
+	h.containerTargets[id] = target
+	return nil
+}
+
+// AttachContainer attaches the program to the container if it's available,
+// otherwise the container is added to the pending list
+func (h *Handler) AttachContainer(container *containercollection.Container) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		return errors.New("handler has been closed")
+	}
+
+	id := container.Runtime.ContainerID
+	if h.prog == nil {
+		if _, ok := h.pendingContainers[id]; ok {
+			return fmt.Errorf("container %q already attached", id)
+		}
+		h.pendingContainers[id] = container
+		return nil
+	}
+
+	if _, ok := h.containerTargets[id]; ok {
+		return fmt.Errorf("container %q already attached", id)
+	}
+	return h.attach(container)
+}
+
+// DetachContainer detaches the program from the container, the link is only
+// closed when no other container is using it
+func (h *Handler) DetachContainer(container *containercollection.Container) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		return nil
+	}
+
+	id := container.Runtime.ContainerID
+	if h.prog == nil {
+		if _, ok := h.pendingContainers[id]; !ok {
+			return fmt.Errorf("container %q has not been attached", id)
+		}
+		delete(h.pendingContainers, id)
+		return nil
+	}
+
+	target, ok := h.containerTargets[id]
+	if !ok {
+		return fmt.Errorf("container %q has not been attached", id)
+	}
+	delete(h.containerTargets, id)
+
+	a, ok := h.attachments[target]
+	if !ok {
+		return fmt.Errorf("internal error: attachment for %q not found", target)
+	}
+	delete(a.users, id)
+	if len(a.users) == 0 {
+		delete(h.attachments, target)
+		return a.link.Close()
+	}
+	return nil
+}
+
+func (h *Handler) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		return
+	}
+
+	for _, a := range h.attachments {
+		a.link.Close()
+	}
+
+	h.attachments = nil
+	h.containerTargets = nil
+	h.pendingContainers = nil
+	h.closed = true
+}
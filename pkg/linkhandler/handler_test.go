@@ -0,0 +1,92 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package linkhandler
+
+import (
+	"io"
+	"testing"
+
+	"github.com/cilium/ebpf"
+	"github.com/stretchr/testify/require"
+
+	containercollection "github.com/inspektor-gadget/inspektor-gadget/pkg/container-collection"
+)
+
+type fakeLink struct {
+	closed *bool
+}
+
+func (l *fakeLink) Close() error {
+	*l.closed = true
+	return nil
+}
+
+func newContainer(id string, netns uint64) *containercollection.Container {
+	c := &containercollection.Container{}
+	c.Runtime.ContainerID = id
+	c.Netns = netns
+	return c
+}
+
+func TestHandler(t *testing.T) {
+	t.Parallel()
+
+	links := make(map[string]*bool)
+	h := NewNetnsHandler()
+	h.attachFn = func(target string, _ *containercollection.Container, _ *ebpf.Program) (io.Closer, error) {
+		closed := false
+		links[target] = &closed
+		return &fakeLink{closed: &closed}, nil
+	}
+
+	// c1 and c2 share the network namespace
+	c1 := newContainer("c1", 1)
+	c2 := newContainer("c2", 1)
+	c3 := newContainer("c3", 2)
+
+	// containers are pending until the program is available
+	require.NoError(t, h.AttachContainer(c1))
+	require.Error(t, h.AttachContainer(c1))
+	require.Empty(t, links)
+
+	require.NoError(t, h.AttachProg(&ebpf.Program{}))
+	require.Len(t, links, 1)
+	require.Error(t, h.AttachProg(&ebpf.Program{}))
+
+	require.NoError(t, h.AttachContainer(c2))
+	require.NoError(t, h.AttachContainer(c3))
+	require.Len(t, links, 2)
+
+	require.Error(t, h.AttachContainer(newContainer("c4", 0)))
+
+	require.NoError(t, h.DetachContainer(c1))
+	require.False(t, *links["1"])
+	require.NoError(t, h.DetachContainer(c2))
+	require.True(t, *links["1"])
+	require.Error(t, h.DetachContainer(c2))
+
+	h.Close()
+	require.True(t, *links["2"])
+	require.Error(t, h.AttachContainer(c1))
+	require.NoError(t, h.DetachContainer(c3))
+}
+
+func TestNewCgroupHandler(t *testing.T) {
+	t.Parallel()
+
+	h := NewCgroupHandler(ebpf.AttachCGroupInetIngress)
+	require.NoError(t, h.AttachProg(&ebpf.Program{}))
+	require.Error(t, h.AttachContainer(newContainer("c1", 1)))
+}
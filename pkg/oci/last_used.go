@@ -0,0 +1,100 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oci
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"time"
+
+	"github.com/gofrs/flock"
+)
+
+// lastUsedFile keeps track of when each image of the local store was last
+// resolved by EnsureImage. It maps full image names to RFC3339 timestamps.
+const lastUsedFile = "last-used.json"
+
+func lastUsedLock(root string) *flock.Flock {
+	return flock.New(path.Join(root, lastUsedFile+".lock"))
+}
+
+func readLastUsedFile(root string) (map[string]time.Time, error) {
+	content, err := os.ReadFile(path.Join(root, lastUsedFile))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return map[string]time.Time{}, nil
+		}
+		return nil, err
+	}
+
+	lastUsed := make(map[string]time.Time)
+	if err := json.Unmarshal(content, &lastUsed); err != nil {
+		return nil, fmt.Errorf("decoding %s: %w", lastUsedFile, err)
+	}
+	return lastUsed, nil
+}
+
+func writeLastUsedFile(root string, lastUsed map[string]time.Time) error {
+	content, err := json.Marshal(lastUsed)
+	if err != nil {
+		return fmt.Errorf("encoding %s: %w", lastUsedFile, err)
+	}
+
+	// Write to a temporary file first to never leave a truncated file behind
+	tmpPath := path.Join(root, lastUsedFile+".tmp")
+	if err := os.WriteFile(tmpPath, content, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path.Join(root, lastUsedFile))
+}
+
+// readLastUsed returns the last time each image was used
+func readLastUsed(root string) (map[string]time.Time, error) {
+	lock := lastUsedLock(root)
+	if err := lock.RLock(); err != nil {
+		return nil, err
+	}
+	defer lock.Unlock()
+
+	return readLastUsedFile(root)
+}
+
+// updateLastUsed sets the last used timestamp of the given images to t. A zero
+// t removes the images from the file instead.
+func updateLastUsed(root string, t time.Time, images ...string) error {
+	lock := lastUsedLock(root)
+	if err := lock.Lock(); err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	lastUsed, err := readLastUsedFile(root)
+	if err != nil {
+		return err
+	}
+
+	for _, image := range images {
+		if t.IsZero() {
+			delete(lastUsed, image)
+			continue
+		}
+		lastUsed[image] = t.UTC()
+	}
+
+	return writeLastUsedFile(root, lastUsed)
+}
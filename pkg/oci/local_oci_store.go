@@ -30,6 +30,7 @@ import (
 type localOciStore struct {
 	*oci.Store
 
+	root       string
 	indexPath  string
 	oldIndex   *ocispec.Index
 	indexFlock *flock.Flock
@@ -38,19 +39,23 @@ type localOciStore struct {
 // newLocalOciStore returns a localOciStore that is safe when executed
 // concurrently, even from different processes.
 func newLocalOciStore() (*localOciStore, error) {
-	if err := os.MkdirAll(filepath.Dir(defaultOciStore), 0o700); err != nil {
+	return newLocalOciStoreAt(defaultOciStore)
+}
+
+func newLocalOciStoreAt(root string) (*localOciStore, error) {
+	if err := os.MkdirAll(filepath.Dir(root), 0o700); err != nil {
 		return nil, err
 	}
 
-	indexPath := path.Join(defaultOciStore, "index.json")
-	indexLock := flock.New(path.Join(defaultOciStore, "index.json.lock"))
+	indexPath := path.Join(root, "index.json")
+	indexLock := flock.New(path.Join(root, "index.json.lock"))
 
 	// lock the file before reading the index below
 	// RLock can't be used since we might create and init an empty index file in oci.New()
 	indexLock.Lock()
 	defer indexLock.Unlock()
 
-	ociStore, err := oci.New(defaultOciStore)
+	ociStore, err := oci.New(root)
 	if err != nil {
 		return nil, err
 	}
@@ -63,6 +68,7 @@ func newLocalOciStore() (*localOciStore, error) {
 
 	return &localOciStore{
 		Store:      ociStore,
+		root:       root,
 		indexPath:  indexPath,
 		oldIndex:   oldIndex,
 		indexFlock: indexLock,
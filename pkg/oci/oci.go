@@ -55,7 +55,10 @@ type AuthOptions struct {
 	// InsecureRegistries is a list of registries that should be accessed over
 	// plain HTTP.
 	InsecureRegistries []string
-	DisallowPulling    bool
+	// RegistriesConfig is the path of a containers-registries.conf(5) file
+	// used to rewrite image locations and to pull from mirrors.
+	RegistriesConfig string
+	DisallowPulling  bool
 }
 
 type AllowedGadgetsOptions struct {
@@ -156,9 +159,9 @@ func VerifyGadgetImage(ctx context.Context, image string, imgOpts *ImageOptions)
 
 			log.Warn("signature not found, will pull signing information and try verification again")
 
-			repo, err := newRepository(imageRef, &imgOpts.AuthOptions)
+			sources, err := newPullSources(imageRef, &imgOpts.AuthOptions)
 			if err != nil {
-				return fmt.Errorf("creating remote repository: %w", err)
+				return err
 			}
 
 			desc, err := imageStore.Resolve(ctx, imageRef.String())
@@ -168,9 +171,16 @@ func VerifyGadgetImage(ctx context.Context, image string, imgOpts *ImageOptions)
 
 			// The signature may not be present locally, let's pull it and verify
 			// again.
-			err = puller.DefaultSignaturePuller.PullSigningInformation(ctx, repo, imageStore, desc.Digest.String())
+			var errs []error
+			for _, source := range sources {
+				err = puller.DefaultSignaturePuller.PullSigningInformation(ctx, source.repo, imageStore, desc.Digest.String())
+				if err == nil {
+					break
+				}
+				errs = append(errs, fmt.Errorf("pulling from %q: %w", source.ref, err))
+			}
 			if err != nil {
-				return fmt.Errorf("pulling gadget signature %q: %w", image, err)
+				return fmt.Errorf("pulling gadget signature %q: %w", image, errors.Join(errs...))
 			}
 
 			if err := imageStore.saveIndexWithLock(); err != nil {
@@ -256,25 +266,32 @@ func pullImage(ctx context.Context, targetImage reference.Named, imageStore oras
 		return nil, errors.New("pulling is disallowed")
 	}
 
-	repo, err := newRepository(targetImage, authOpts)
+	sources, err := newPullSources(targetImage, authOpts)
 	if err != nil {
-		return nil, fmt.Errorf("creating remote repository: %w", err)
+		return nil, err
 	}
 
-	desc, err := oras.Copy(ctx, repo, targetImage.String(), imageStore,
-		targetImage.String(), oras.DefaultCopyOptions)
-	if err != nil {
-		return nil, fmt.Errorf("copying to local repository: %w", err)
-	}
+	var errs []error
+	for _, source := range sources {
+		desc, err := oras.Copy(ctx, source.repo, source.ref, imageStore,
+			targetImage.String(), oras.DefaultCopyOptions)
+		if err != nil {
+			log.Debugf("pulling %q from %q: %v", targetImage, source.ref, err)
+			errs = append(errs, fmt.Errorf("copying %q to local repository: %w", source.ref, err))
+			continue
+		}
+
+		imageDigest := desc.Digest.String()
+		if err := puller.DefaultSignaturePuller.PullSigningInformation(ctx, source.repo, imageStore, imageDigest); err != nil {
+			log.Warnf("error pulling signature: %v", err)
+			// it's not a requirement to have a signature for pulling the image
+			return &desc, nil
+		}
 
-	imageDigest := desc.Digest.String()
-	if err := puller.DefaultSignaturePuller.PullSigningInformation(ctx, repo, imageStore, imageDigest); err != nil {
-		log.Warnf("error pulling signature: %v", err)
-		// it's not a requirement to have a signature for pulling the image
 		return &desc, nil
 	}
 
-	return &desc, nil
+	return nil, errors.Join(errs...)
 }
 
 // PushGadgetImage pushes the gadget image and returns its descriptor.
@@ -727,18 +744,7 @@ func normalizeImageName(image string) (reference.Named, error) {
 	return reference.TagNameOnly(name), nil
 }
 
-func getHostString(repository string) (string, error) {
-	repo, err := reference.Parse(repository)
-	if err != nil {
-		return "", fmt.Errorf("parsing repository %q: %w", repository, err)
-	}
-	if named, ok := repo.(reference.Named); ok {
-		return reference.Domain(named), nil
-	}
-	return "", fmt.Errorf("image has to be a named reference")
-}
-
-func newAuthClient(repository string, authOptions *AuthOptions) (*oras_auth.Client, error) {
+func newAuthClient(authOptions *AuthOptions) (*oras_auth.Client, error) {
 	log.Debugf("Using auth file %q", authOptions.AuthFile)
 
 	var cfg *configfile.ConfigFile
@@ -773,51 +779,99 @@ func newAuthClient(repository string, authOptions *AuthOptions) (*oras_auth.Clie
 		}
 	}
 
-	hostString, err := getHostString(repository)
+	return &oras_auth.Client{
+		// Credentials are looked up for each host the client talks to (the
+		// registry itself or one of its mirrors) and every time a token is
+		// needed. This way the credential helpers configured in credHelpers and
+		// credsStore are asked again once short-lived tokens expire.
+		Credential: func(ctx context.Context, hostport string) (oras_auth.Credential, error) {
+			authKey := hostport
+			// Special case for docker.io
+			if authKey == "docker.io" || authKey == "registry-1.docker.io" {
+				authKey = "index.docker.io"
+			}
+			authConfig, err := cfg.GetAuthConfig(authKey)
+			if err != nil {
+				return oras_auth.EmptyCredential, fmt.Errorf("getting auth config for %q: %w", hostport, err)
+			}
+
+			return oras_auth.Credential{
+				Username:     authConfig.Username,
+				Password:     authConfig.Password,
+				AccessToken:  cmp.Or(authConfig.RegistryToken, authConfig.Auth),
+				RefreshToken: authConfig.IdentityToken,
+			}, nil
+		},
+		Cache: oras_auth.NewCache(),
+	}, nil
+}
+
+// newRemoteRepository creates a client to the remote repository at location.
+func newRemoteRepository(location registryLocation, authOpts *AuthOptions) (*remote.Repository, error) {
+	repo, err := remote.NewRepository(location.ref.Name())
 	if err != nil {
-		return nil, fmt.Errorf("getting host string: %w", err)
-	}
-	authKey := hostString
-	// Special case for docker.io
-	if authKey == "docker.io" {
-		authKey = "index.docker.io"
+		return nil, fmt.Errorf("creating remote repository: %w", err)
 	}
-	authConfig, err := cfg.GetAuthConfig(authKey)
-	if err != nil {
-		return nil, fmt.Errorf("getting auth config: %w", err)
+
+	repo.PlainHTTP = location.insecure
+	if !location.insecure {
+		client, err := newAuthClient(authOpts)
+		if err != nil {
+			return nil, fmt.Errorf("creating auth client: %w", err)
+		}
+		repo.Client = client
 	}
 
-	return &oras_auth.Client{
-		Credential: oras_auth.StaticCredential(hostString, oras_auth.Credential{
-			Username:     authConfig.Username,
-			Password:     authConfig.Password,
-			AccessToken:  authConfig.Auth,
-			RefreshToken: authConfig.IdentityToken,
-		}),
-	}, nil
+	return repo, nil
 }
 
 // newRepository creates a client to the remote repository identified by
-// image using the given auth options.
+// image using the given auth options. The location of the repository can be
+// rewritten by the registries configuration.
 func newRepository(image reference.Named, authOpts *AuthOptions) (*remote.Repository, error) {
-	repo, err := remote.NewRepository(image.Name())
+	registriesConfig, err := loadRegistriesConfig(authOpts.RegistriesConfig)
 	if err != nil {
-		return nil, fmt.Errorf("creating remote repository: %w", err)
+		return nil, err
+	}
+
+	location, err := registriesConfig.pushLocation(image, authOpts.InsecureRegistries)
+	if err != nil {
+		return nil, fmt.Errorf("getting location of %q: %w", image, err)
+	}
+
+	return newRemoteRepository(location, authOpts)
+}
+
+// pullSource is a remote repository an image can be pulled from.
+type pullSource struct {
+	repo *remote.Repository
+	// ref is the reference of the image in repo
+	ref string
+}
+
+// newPullSources returns the repositories to try, in order, to pull image:
+// its mirrors first and then the registry itself.
+func newPullSources(image reference.Named, authOpts *AuthOptions) ([]pullSource, error) {
+	registriesConfig, err := loadRegistriesConfig(authOpts.RegistriesConfig)
+	if err != nil {
+		return nil, err
 	}
 
-	registryDomain := reference.Domain(image)
-	insecure := slices.Contains(authOpts.InsecureRegistries, registryDomain)
+	locations, err := registriesConfig.pullLocations(image, authOpts.InsecureRegistries)
+	if err != nil {
+		return nil, fmt.Errorf("getting locations of %q: %w", image, err)
+	}
 
-	repo.PlainHTTP = insecure
-	if !insecure {
-		client, err := newAuthClient(image.Name(), authOpts)
+	sources := make([]pullSource, 0, len(locations))
+	for _, location := range locations {
+		repo, err := newRemoteRepository(location, authOpts)
 		if err != nil {
-			return nil, fmt.Errorf("creating auth client: %w", err)
+			return nil, fmt.Errorf("creating remote repository for %q: %w", location.ref, err)
 		}
-		repo.Client = client
+		sources = append(sources, pullSource{repo: repo, ref: location.ref.String()})
 	}
 
-	return repo, nil
+	return sources, nil
 }
 
 func getImageListDescriptor(ctx context.Context, target oras.ReadOnlyTarget, reference string) (ocispec.Index, error) {
@@ -919,7 +973,7 @@ func ensureImage(ctx context.Context, imageStore oras.GraphTarget, image string,
 
 // EnsureImage ensures the image is present in the local store
 func EnsureImage(ctx context.Context, image string, imgOpts *ImageOptions, pullPolicy string) error {
-	return retry("EnsureImage", func() error {
+	err := retry("EnsureImage", func() error {
 		imageStore, err := newLocalOciStore()
 		if err != nil {
 			return fmt.Errorf("getting local oci store: %w", err)
@@ -931,6 +985,21 @@ func EnsureImage(ctx context.Context, image string, imgOpts *ImageOptions, pullP
 
 		return imageStore.saveIndexWithLock()
 	})
+	if err != nil {
+		return err
+	}
+
+	// Record the last use of the image, it's used to evict least recently used
+	// images when pruning the store. Failing to do so isn't fatal.
+	targetImage, err := normalizeImageName(image)
+	if err != nil {
+		return fmt.Errorf("normalizing image: %w", err)
+	}
+	if err := updateLastUsed(defaultOciStore, time.Now(), targetImage.String()); err != nil {
+		log.Warnf("recording last use of image %q: %v", targetImage.String(), err)
+	}
+
+	return nil
 }
 
 func getManifestForHost(ctx context.Context, target oras.ReadOnlyTarget, image string) (*ocispec.Manifest, error) {
@@ -0,0 +1,564 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oci
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/distribution/reference"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	log "github.com/sirupsen/logrus"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/errdef"
+)
+
+// PruneOptions selects the images removed by PruneGadgetImages. An image is
+// removed as soon as one of the options selects it.
+type PruneOptions struct {
+	// AllUntagged removes images that aren't referenced by any tag anymore,
+	// e.g. the previous version of a tag that was pulled again.
+	AllUntagged bool
+
+	// OlderThan removes images created more than OlderThan ago. Zero disables
+	// it.
+	OlderThan time.Duration
+
+	// KeepLast keeps only the KeepLast most recently created tags of each
+	// repository. Zero disables it.
+	KeepLast int
+
+	// MaxStoreSize evicts the least recently used images until the store
+	// takes less than MaxStoreSize bytes. Zero disables it.
+	MaxStoreSize int64
+
+	// Keep lists images that are never removed. Images used by the gadget
+	// instances running in this process, see UseImage, are never removed
+	// either.
+	Keep []string
+
+	// DryRun only reports what would be removed.
+	DryRun bool
+}
+
+// PruneReport describes what was (or would be, in dry-run mode) removed by
+// PruneGadgetImages.
+type PruneReport struct {
+	Images         []*GadgetImageDesc `json:"images"`
+	Untagged       []string           `json:"untagged"`
+	ReclaimedBytes int64              `json:"reclaimedBytes"`
+}
+
+// pruneGracePeriod protects recent blobs not referenced by the index from
+// being removed: they could belong to an image that is being pulled.
+const pruneGracePeriod = 10 * time.Minute
+
+// signatureTagRegexp matches tags used to store signatures of an image, see
+// pkg/signature/helpers.
+var signatureTagRegexp = regexp.MustCompile(`^(sha256)-([a-f0-9]{64})(\.sig)?$`)
+
+// imagesInUse counts the gadget instances of this process using each image.
+// They are never removed by PruneGadgetImages.
+var imagesInUse = struct {
+	sync.Mutex
+	refs map[string]int
+}{refs: make(map[string]int)}
+
+// UseImage marks image as used by a gadget instance until the returned
+// function is called, so PruneGadgetImages doesn't remove it in the meantime,
+// e.g. when evicting images to enforce the maximum store size.
+func UseImage(image string) (func(), error) {
+	targetImage, err := normalizeImageName(image)
+	if err != nil {
+		return nil, fmt.Errorf("normalizing image %q: %w", image, err)
+	}
+	ref := targetImage.String()
+
+	imagesInUse.Lock()
+	imagesInUse.refs[ref]++
+	imagesInUse.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			imagesInUse.Lock()
+			defer imagesInUse.Unlock()
+			if imagesInUse.refs[ref]--; imagesInUse.refs[ref] <= 0 {
+				delete(imagesInUse.refs, ref)
+			}
+		})
+	}, nil
+}
+
+// PruneGadgetImages removes images from the local store according to opts
+// and deletes all the blobs that aren't referenced anymore.
+func PruneGadgetImages(ctx context.Context, opts *PruneOptions) (*PruneReport, error) {
+	var report *PruneReport
+	err := retry("PruneGadgetImages", func() error {
+		ociStore, err := newLocalOciStore()
+		if err != nil {
+			return fmt.Errorf("getting oci store: %w", err)
+		}
+
+		report, err = pruneGadgetImages(ctx, ociStore, opts)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, image := range report.Images {
+		image.Repository = strings.TrimPrefix(image.Repository, DefaultDomain+"/"+officialRepoPrefix)
+	}
+
+	return report, nil
+}
+
+type pruneImage struct {
+	*GadgetImageDesc
+	ref      string
+	desc     ocispec.Descriptor
+	created  time.Time
+	lastUsed time.Time
+}
+
+func pruneGadgetImages(ctx context.Context, o *localOciStore, opts *PruneOptions) (*PruneReport, error) {
+	// Hold the index lock for the whole operation: blobs are removed after the
+	// index is saved and nobody should start using them in between.
+	o.indexFlock.Lock()
+	defer o.indexFlock.Unlock()
+
+	currentIndex, err := readIndexFile(o.indexPath)
+	if err != nil {
+		return nil, err
+	}
+	if !reflect.DeepEqual(currentIndex, o.oldIndex) {
+		return nil, errRetry
+	}
+
+	lastUsed, err := readLastUsed(o.root)
+	if err != nil {
+		return nil, fmt.Errorf("reading last used timestamps: %w", err)
+	}
+
+	keep := make(map[string]struct{}, len(opts.Keep))
+	for _, image := range opts.Keep {
+		targetImage, err := normalizeImageName(image)
+		if err != nil {
+			return nil, fmt.Errorf("normalizing image %q: %w", image, err)
+		}
+		keep[targetImage.String()] = struct{}{}
+	}
+	imagesInUse.Lock()
+	for ref := range imagesInUse.refs {
+		keep[ref] = struct{}{}
+	}
+	imagesInUse.Unlock()
+
+	var images []*pruneImage
+	var others []ocispec.Descriptor
+	var untagged []ocispec.Descriptor
+	for _, desc := range o.oldIndex.Manifests {
+		ref, ok := desc.Annotations[ocispec.AnnotationRefName]
+		if !ok {
+			untagged = append(untagged, desc)
+			continue
+		}
+		imageDesc, err := getGadgetImageDescriptor(ctx, o.Store, ref)
+		if err != nil {
+			// signature tags and other artifacts
+			others = append(others, desc)
+			continue
+		}
+		img := &pruneImage{
+			GadgetImageDesc: imageDesc,
+			ref:             ref,
+			desc:            desc,
+			lastUsed:        lastUsed[ref],
+		}
+		img.created, _ = time.Parse(time.RFC3339, imageDesc.Created)
+		images = append(images, img)
+	}
+
+	// subjects holds the image referenced by untagged referrers, like
+	// signatures. They are kept as long as their subject is.
+	subjects := make(map[digest.Digest]digest.Digest)
+	for _, desc := range untagged {
+		if subject, ok := o.getSubject(ctx, desc); ok {
+			subjects[desc.Digest] = subject
+		}
+	}
+
+	removed := make(map[string]*pruneImage)
+	canRemove := func(img *pruneImage) bool {
+		_, ok := keep[img.ref]
+		return !ok
+	}
+
+	if opts.OlderThan > 0 {
+		limit := time.Now().Add(-opts.OlderThan)
+		for _, img := range images {
+			if !img.created.IsZero() && img.created.Before(limit) && canRemove(img) {
+				removed[img.ref] = img
+			}
+		}
+	}
+
+	if opts.KeepLast > 0 {
+		byRepository := make(map[string][]*pruneImage)
+		for _, img := range images {
+			byRepository[img.Repository] = append(byRepository[img.Repository], img)
+		}
+		for _, repoImages := range byRepository {
+			slices.SortStableFunc(repoImages, func(a, b *pruneImage) int {
+				return b.created.Compare(a.created)
+			})
+			for _, img := range repoImages[min(opts.KeepLast, len(repoImages)):] {
+				if canRemove(img) {
+					removed[img.ref] = img
+				}
+			}
+		}
+	}
+
+	blobs, err := o.listBlobs()
+	if err != nil {
+		return nil, fmt.Errorf("listing blobs: %w", err)
+	}
+
+	// children holds everything referenced by another entry of the index: the
+	// manifests of an image index are also added to the index when pulling it
+	children := make(map[digest.Digest]struct{})
+	for _, desc := range o.oldIndex.Manifests {
+		successors, err := content.Successors(ctx, o.Store, desc)
+		if err != nil {
+			if errors.Is(err, errdef.ErrNotFound) {
+				continue
+			}
+			return nil, fmt.Errorf("getting successors of %s: %w", desc.Digest, err)
+		}
+		reachable, err := o.reachableBlobs(ctx, successors)
+		if err != nil {
+			return nil, err
+		}
+		maps.Copy(children, reachable)
+	}
+
+	// roots returns the descriptors that remain in the index once the selected
+	// images are removed
+	roots := func() ([]ocispec.Descriptor, error) {
+		var res []ocispec.Descriptor
+		keptDigests := make(map[digest.Digest]struct{})
+		for _, img := range images {
+			if _, ok := removed[img.ref]; !ok {
+				res = append(res, img.desc)
+				keptDigests[img.desc.Digest] = struct{}{}
+			}
+		}
+		for _, desc := range others {
+			ref := desc.Annotations[ocispec.AnnotationRefName]
+			if dgst, ok := signatureTagDigest(ref); ok {
+				if _, kept := keptDigests[dgst]; !kept {
+					continue
+				}
+			}
+			res = append(res, desc)
+		}
+		for _, desc := range untagged {
+			_, isChild := children[desc.Digest]
+			_, isReferrer := subjects[desc.Digest]
+			if !isChild && !isReferrer && !opts.AllUntagged {
+				res = append(res, desc)
+			}
+		}
+
+		reachable, err := o.reachableBlobs(ctx, res)
+		if err != nil {
+			return nil, err
+		}
+
+		// Keep the untagged entries that are part of the remaining content and
+		// the referrers (e.g. signatures) of the remaining content
+		for _, desc := range untagged {
+			if slices.ContainsFunc(res, func(d ocispec.Descriptor) bool { return d.Digest == desc.Digest }) {
+				continue
+			}
+			if _, ok := reachable[desc.Digest]; ok {
+				res = append(res, desc)
+				continue
+			}
+			if subject, ok := subjects[desc.Digest]; ok {
+				if _, kept := reachable[subject]; kept {
+					res = append(res, desc)
+				}
+			}
+		}
+		return res, nil
+	}
+
+	if opts.MaxStoreSize > 0 {
+		lru := slices.Clone(images)
+		slices.SortStableFunc(lru, func(a, b *pruneImage) int {
+			return lastUseOf(a).Compare(lastUseOf(b))
+		})
+		for _, img := range lru {
+			keptRoots, err := roots()
+			if err != nil {
+				return nil, err
+			}
+			reachable, err := o.reachableBlobs(ctx, keptRoots)
+			if err != nil {
+				return nil, err
+			}
+			if sizeOf(blobs, reachable) <= opts.MaxStoreSize {
+				break
+			}
+			if canRemove(img) {
+				removed[img.ref] = img
+			}
+		}
+	}
+
+	keptRoots, err := roots()
+	if err != nil {
+		return nil, err
+	}
+	reachable, err := o.reachableBlobs(ctx, keptRoots)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &PruneReport{
+		Images:   []*GadgetImageDesc{},
+		Untagged: []string{},
+	}
+	for _, img := range images {
+		if _, ok := removed[img.ref]; ok {
+			report.Images = append(report.Images, img.GadgetImageDesc)
+		}
+	}
+	for _, desc := range untagged {
+		_, isChild := children[desc.Digest]
+		_, isReferrer := subjects[desc.Digest]
+		if isChild || isReferrer {
+			continue
+		}
+		if !slices.ContainsFunc(keptRoots, func(d ocispec.Descriptor) bool { return d.Digest == desc.Digest }) {
+			report.Untagged = append(report.Untagged, desc.Digest.String())
+		}
+	}
+	// Blobs that weren't referenced by the index in the first place are only
+	// removed after a grace period
+	reachableBefore, err := o.reachableBlobs(ctx, o.oldIndex.Manifests)
+	if err != nil {
+		return nil, err
+	}
+	garbage := make(map[digest.Digest]struct{})
+	graceLimit := time.Now().Add(-pruneGracePeriod)
+	for dgst, blob := range blobs {
+		if _, ok := reachable[dgst]; ok {
+			continue
+		}
+		if _, ok := reachableBefore[dgst]; ok || blob.modTime.Before(graceLimit) {
+			garbage[dgst] = struct{}{}
+		}
+	}
+	report.ReclaimedBytes = sizeOf(blobs, garbage)
+
+	if opts.DryRun {
+		return report, nil
+	}
+
+	// Untag images and remove the descriptors that aren't kept from the index.
+	// The store removes their content as well when it's not referenced by any
+	// other descriptor.
+	for ref := range removed {
+		if err := o.Untag(ctx, ref); err != nil {
+			return nil, fmt.Errorf("untagging %q: %w", ref, err)
+		}
+	}
+	for _, desc := range o.oldIndex.Manifests {
+		if slices.ContainsFunc(keptRoots, func(d ocispec.Descriptor) bool { return d.Digest == desc.Digest }) {
+			continue
+		}
+		if ref, ok := desc.Annotations[ocispec.AnnotationRefName]; ok {
+			if _, isImage := removed[ref]; !isImage {
+				if err := o.Untag(ctx, ref); err != nil && !errors.Is(err, errdef.ErrNotFound) {
+					return nil, fmt.Errorf("untagging %q: %w", ref, err)
+				}
+			}
+		}
+		if err := o.Delete(ctx, desc); err != nil && !errors.Is(err, errdef.ErrNotFound) {
+			return nil, fmt.Errorf("deleting %s: %w", desc.Digest, err)
+		}
+	}
+
+	if err := o.SaveIndex(); err != nil {
+		return nil, fmt.Errorf("saving index: %w", err)
+	}
+	if o.oldIndex, err = readIndexFile(o.indexPath); err != nil {
+		return nil, err
+	}
+
+	// Remove leftovers the store doesn't know about, e.g. blobs of images
+	// whose descriptors were previously removed from the index
+	for dgst := range garbage {
+		err := os.Remove(o.blobPath(dgst))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("removing blob %s: %w", dgst, err)
+		}
+	}
+
+	if len(removed) > 0 {
+		refs := make([]string, 0, len(removed))
+		for ref := range removed {
+			refs = append(refs, ref)
+		}
+		if err := updateLastUsed(o.root, time.Time{}, refs...); err != nil {
+			log.Warnf("updating last used timestamps: %v", err)
+		}
+	}
+
+	return report, nil
+}
+
+func lastUseOf(img *pruneImage) time.Time {
+	if !img.lastUsed.IsZero() {
+		return img.lastUsed
+	}
+	return img.created
+}
+
+func signatureTagDigest(ref string) (digest.Digest, bool) {
+	parsed, err := reference.Parse(ref)
+	if err != nil {
+		return "", false
+	}
+	tagged, ok := parsed.(reference.Tagged)
+	if !ok {
+		return "", false
+	}
+	matches := signatureTagRegexp.FindStringSubmatch(tagged.Tag())
+	if matches == nil {
+		return "", false
+	}
+	return digest.NewDigestFromEncoded(digest.Algorithm(matches[1]), matches[2]), true
+}
+
+// getSubject returns the digest of the manifest desc refers to, if any
+func (o *localOciStore) getSubject(ctx context.Context, desc ocispec.Descriptor) (digest.Digest, bool) {
+	if desc.MediaType != ocispec.MediaTypeImageManifest {
+		return "", false
+	}
+	manifestBytes, err := content.FetchAll(ctx, o.Store, desc)
+	if err != nil {
+		return "", false
+	}
+	var manifest ocispec.Manifest
+	if err := json.Unmarshal(manifestBytes, &manifest); err != nil || manifest.Subject == nil {
+		return "", false
+	}
+	return manifest.Subject.Digest, true
+}
+
+func (o *localOciStore) blobPath(dgst digest.Digest) string {
+	return filepath.Join(o.root, ocispec.ImageBlobsDir, dgst.Algorithm().String(), dgst.Encoded())
+}
+
+type blobInfo struct {
+	size    int64
+	modTime time.Time
+}
+
+// listBlobs returns information about all blobs in the store
+func (o *localOciStore) listBlobs() (map[digest.Digest]blobInfo, error) {
+	blobs := make(map[digest.Digest]blobInfo)
+
+	blobsDir := filepath.Join(o.root, ocispec.ImageBlobsDir)
+	algDirs, err := os.ReadDir(blobsDir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return blobs, nil
+		}
+		return nil, err
+	}
+	for _, algDir := range algDirs {
+		alg := digest.Algorithm(algDir.Name())
+		if !algDir.IsDir() || !alg.Available() {
+			continue
+		}
+		entries, err := os.ReadDir(filepath.Join(blobsDir, algDir.Name()))
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			dgst := digest.NewDigestFromEncoded(alg, entry.Name())
+			if dgst.Validate() != nil {
+				continue
+			}
+			info, err := entry.Info()
+			if err != nil {
+				return nil, err
+			}
+			blobs[dgst] = blobInfo{size: info.Size(), modTime: info.ModTime()}
+		}
+	}
+	return blobs, nil
+}
+
+// reachableBlobs returns the digests of all blobs referenced directly or
+// indirectly by roots
+func (o *localOciStore) reachableBlobs(ctx context.Context, roots []ocispec.Descriptor) (map[digest.Digest]struct{}, error) {
+	reachable := make(map[digest.Digest]struct{})
+	queue := slices.Clone(roots)
+	for len(queue) > 0 {
+		desc := queue[0]
+		queue = queue[1:]
+		if _, ok := reachable[desc.Digest]; ok {
+			continue
+		}
+		reachable[desc.Digest] = struct{}{}
+
+		successors, err := content.Successors(ctx, o.Store, desc)
+		if err != nil {
+			if errors.Is(err, errdef.ErrNotFound) {
+				continue
+			}
+			return nil, fmt.Errorf("getting successors of %s: %w", desc.Digest, err)
+		}
+		queue = append(queue, successors...)
+	}
+	return reachable, nil
+}
+
+func sizeOf(blobs map[digest.Digest]blobInfo, digests map[digest.Digest]struct{}) int64 {
+	var size int64
+	for dgst := range digests {
+		size += blobs[dgst].size
+	}
+	return size
+}
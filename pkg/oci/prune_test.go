@@ -0,0 +1,296 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oci
+
+import (
+	"context"
+	"encoding/json"
+	"runtime"
+	"testing"
+	"time"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/require"
+	"oras.land/oras-go/v2/content/oci"
+)
+
+const (
+	pruneTestRepo = "ghcr.io/inspektor-gadget/gadget/test"
+)
+
+// pushGadgetImage pushes a minimal gadget image (index -> manifest -> config
+// and layer) and tags it as ref
+func pushGadgetImage(t *testing.T, ctx context.Context, store *oci.Store, ref string, created time.Time, layer string) ocispec.Descriptor {
+	t.Helper()
+
+	configDesc := pushBlob(t, ctx, store, ocispec.MediaTypeImageConfig, []byte(`{"name":"test"}`))
+	layerDesc := pushBlob(t, ctx, store, ocispec.MediaTypeImageLayer, []byte(layer))
+
+	manifest := ocispec.Manifest{
+		MediaType: ocispec.MediaTypeImageManifest,
+		Config:    configDesc,
+		Layers:    []ocispec.Descriptor{layerDesc},
+		Annotations: map[string]string{
+			ocispec.AnnotationCreated: created.UTC().Format(time.RFC3339),
+		},
+	}
+	manifest.SchemaVersion = 2
+	manifestBytes, err := json.Marshal(manifest)
+	require.NoError(t, err)
+	manifestDesc := pushBlob(t, ctx, store, ocispec.MediaTypeImageManifest, manifestBytes)
+	manifestDesc.Platform = &ocispec.Platform{Architecture: runtime.GOARCH, OS: "linux"}
+
+	index := ocispec.Index{
+		MediaType: ocispec.MediaTypeImageIndex,
+		Manifests: []ocispec.Descriptor{manifestDesc},
+	}
+	index.SchemaVersion = 2
+	indexBytes, err := json.Marshal(index)
+	require.NoError(t, err)
+	indexDesc := pushBlob(t, ctx, store, ocispec.MediaTypeImageIndex, indexBytes)
+
+	require.NoError(t, store.Tag(ctx, indexDesc, ref))
+	return indexDesc
+}
+
+func imageRefs(images []*GadgetImageDesc) []string {
+	refs := make([]string, 0, len(images))
+	for _, img := range images {
+		refs = append(refs, img.Repository+":"+img.Tag)
+	}
+	return refs
+}
+
+func TestPruneGadgetImages(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	hour := time.Hour
+
+	type testImage struct {
+		tag     string
+		created time.Time
+		layer   string
+	}
+
+	tests := []struct {
+		name            string
+		images          []testImage
+		lastUsed        map[string]time.Time
+		inUse           []string
+		opts            PruneOptions
+		expectedRemoved []string
+		expectedKept    []string
+		expectUntagged  int
+		expectReclaimed bool
+	}{
+		{
+			name: "nothing selected",
+			images: []testImage{
+				{tag: "v1", created: now.Add(-2 * hour), layer: "v1"},
+			},
+			opts:         PruneOptions{},
+			expectedKept: []string{pruneTestRepo + ":v1"},
+		},
+		{
+			name: "keep last",
+			images: []testImage{
+				{tag: "v1", created: now.Add(-3 * hour), layer: "v1"},
+				{tag: "v2", created: now.Add(-2 * hour), layer: "v2"},
+				{tag: "v3", created: now.Add(-1 * hour), layer: "v3"},
+			},
+			opts:            PruneOptions{KeepLast: 2},
+			expectedRemoved: []string{pruneTestRepo + ":v1"},
+			expectedKept:    []string{pruneTestRepo + ":v2", pruneTestRepo + ":v3"},
+			expectReclaimed: true,
+		},
+		{
+			name: "older than",
+			images: []testImage{
+				{tag: "v1", created: now.Add(-48 * hour), layer: "v1"},
+				{tag: "v2", created: now.Add(-1 * hour), layer: "v2"},
+			},
+			opts:            PruneOptions{OlderThan: 24 * hour},
+			expectedRemoved: []string{pruneTestRepo + ":v1"},
+			expectedKept:    []string{pruneTestRepo + ":v2"},
+			expectReclaimed: true,
+		},
+		{
+			name: "keep list",
+			images: []testImage{
+				{tag: "v1", created: now.Add(-48 * hour), layer: "v1"},
+			},
+			opts:         PruneOptions{OlderThan: 24 * hour, Keep: []string{"test:v1"}},
+			expectedKept: []string{pruneTestRepo + ":v1"},
+		},
+		{
+			name: "dry run",
+			images: []testImage{
+				{tag: "v1", created: now.Add(-48 * hour), layer: "v1"},
+			},
+			opts:            PruneOptions{OlderThan: 24 * hour, DryRun: true},
+			expectedRemoved: []string{pruneTestRepo + ":v1"},
+			expectedKept:    []string{pruneTestRepo + ":v1"},
+			expectReclaimed: true,
+		},
+		{
+			name: "all untagged",
+			images: []testImage{
+				{tag: "latest", created: now.Add(-2 * hour), layer: "old"},
+				{tag: "latest", created: now.Add(-1 * hour), layer: "new"},
+			},
+			opts:            PruneOptions{AllUntagged: true},
+			expectedKept:    []string{pruneTestRepo + ":latest"},
+			expectUntagged:  1,
+			expectReclaimed: true,
+		},
+		{
+			name: "max store size evicts least recently used",
+			images: []testImage{
+				{tag: "v1", created: now.Add(-3 * hour), layer: "v1"},
+				{tag: "v2", created: now.Add(-2 * hour), layer: "v2"},
+			},
+			lastUsed: map[string]time.Time{
+				pruneTestRepo + ":v1": now,
+				pruneTestRepo + ":v2": now.Add(-hour),
+			},
+			opts:            PruneOptions{MaxStoreSize: 1},
+			expectedRemoved: []string{pruneTestRepo + ":v1", pruneTestRepo + ":v2"},
+			expectReclaimed: true,
+		},
+		{
+			name: "max store size keeps images in use",
+			images: []testImage{
+				{tag: "in-use", created: now.Add(-3 * hour), layer: "in-use"},
+				{tag: "v2", created: now.Add(-2 * hour), layer: "v2"},
+			},
+			lastUsed: map[string]time.Time{
+				pruneTestRepo + ":in-use": now.Add(-48 * hour),
+				pruneTestRepo + ":v2":     now,
+			},
+			inUse:           []string{"test:in-use"},
+			opts:            PruneOptions{MaxStoreSize: 1},
+			expectedRemoved: []string{pruneTestRepo + ":v2"},
+			expectedKept:    []string{pruneTestRepo + ":in-use"},
+			expectReclaimed: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			ctx := context.Background()
+
+			root := t.TempDir()
+			store, err := oci.New(root)
+			require.NoError(t, err)
+			for _, img := range test.images {
+				pushGadgetImage(t, ctx, store, pruneTestRepo+":"+img.tag, img.created, img.layer)
+			}
+			if len(test.lastUsed) > 0 {
+				require.NoError(t, writeLastUsedFile(root, test.lastUsed))
+			}
+			for _, image := range test.inUse {
+				release, err := UseImage(image)
+				require.NoError(t, err)
+				t.Cleanup(release)
+			}
+
+			localStore, err := newLocalOciStoreAt(root)
+			require.NoError(t, err)
+			blobsBefore, err := localStore.listBlobs()
+			require.NoError(t, err)
+
+			report, err := pruneGadgetImages(ctx, localStore, &test.opts)
+			require.NoError(t, err)
+
+			require.ElementsMatch(t, test.expectedRemoved, imageRefs(report.Images))
+			require.Len(t, report.Untagged, test.expectUntagged)
+			if test.expectReclaimed {
+				require.Positive(t, report.ReclaimedBytes)
+			} else {
+				require.Zero(t, report.ReclaimedBytes)
+			}
+
+			// Reopen the store to check what's left
+			localStore, err = newLocalOciStoreAt(root)
+			require.NoError(t, err)
+			images, err := getGadgetImages(ctx, localStore.Store)
+			require.NoError(t, err)
+			require.ElementsMatch(t, test.expectedKept, imageRefs(images))
+
+			// All the remaining content must be reachable and vice versa
+			blobs, err := localStore.listBlobs()
+			require.NoError(t, err)
+			reachable, err := localStore.reachableBlobs(ctx, localStore.oldIndex.Manifests)
+			require.NoError(t, err)
+			if test.opts.DryRun {
+				require.Len(t, blobs, len(blobsBefore))
+				return
+			}
+			require.Len(t, blobs, len(reachable))
+		})
+	}
+}
+
+func TestLastUsed(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	now := time.Now().Truncate(time.Second)
+
+	lastUsed, err := readLastUsed(root)
+	require.NoError(t, err)
+	require.Empty(t, lastUsed)
+
+	require.NoError(t, updateLastUsed(root, now, "a", "b"))
+	lastUsed, err = readLastUsed(root)
+	require.NoError(t, err)
+	require.Len(t, lastUsed, 2)
+	require.True(t, now.Equal(lastUsed["a"]))
+
+	require.NoError(t, updateLastUsed(root, time.Time{}, "a"))
+	lastUsed, err = readLastUsed(root)
+	require.NoError(t, err)
+	require.Len(t, lastUsed, 1)
+	require.Contains(t, lastUsed, "b")
+}
+
+func TestUseImage(t *testing.T) {
+	t.Parallel()
+
+	ref := pruneTestRepo + ":use-image"
+	inUse := func() int {
+		imagesInUse.Lock()
+		defer imagesInUse.Unlock()
+		return imagesInUse.refs[ref]
+	}
+
+	release1, err := UseImage("test:use-image")
+	require.NoError(t, err)
+	release2, err := UseImage(ref)
+	require.NoError(t, err)
+	require.Equal(t, 2, inUse())
+
+	release1()
+	release1()
+	require.Equal(t, 1, inUse())
+
+	release2()
+	require.Equal(t, 0, inUse())
+
+	_, err = UseImage("INVALID")
+	require.Error(t, err)
+}
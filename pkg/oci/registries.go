@@ -0,0 +1,253 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oci
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/distribution/reference"
+	"github.com/pelletier/go-toml/v2"
+)
+
+const (
+	DefaultRegistriesConfig = "/var/lib/ig/registries.conf"
+
+	PullFromMirrorAll        = "all"
+	PullFromMirrorDigestOnly = "digest-only"
+	PullFromMirrorTagOnly    = "tag-only"
+)
+
+// RegistriesConfig is the subset of containers-registries.conf(5) (version 2)
+// used to redirect image pulls to other locations. Unknown keys like
+// unqualified-search-registries or aliases are ignored.
+type RegistriesConfig struct {
+	Registries []RegistryConfig `toml:"registry"`
+}
+
+// RegistryConfig describes a [[registry]] table.
+type RegistryConfig struct {
+	// Prefix selects the images this entry applies to. It defaults to
+	// Location and can be a wildcard like "*.example.com".
+	Prefix string `toml:"prefix"`
+	// Location replaces Prefix in the image name when accessing the registry.
+	Location           string         `toml:"location"`
+	Insecure           bool           `toml:"insecure"`
+	Blocked            bool           `toml:"blocked"`
+	MirrorByDigestOnly bool           `toml:"mirror-by-digest-only"`
+	Mirrors            []MirrorConfig `toml:"mirror"`
+}
+
+// MirrorConfig describes a [[registry.mirror]] table.
+type MirrorConfig struct {
+	Location string `toml:"location"`
+	Insecure bool   `toml:"insecure"`
+	// PullFromMirror is one of "all" (default), "digest-only" or "tag-only".
+	PullFromMirror string `toml:"pull-from-mirror"`
+}
+
+// registryLocation is a place an image can be fetched from or pushed to.
+type registryLocation struct {
+	ref      reference.Named
+	insecure bool
+}
+
+// ParseRegistriesConfig parses and validates a registries configuration.
+func ParseRegistriesConfig(data []byte) (*RegistriesConfig, error) {
+	config := &RegistriesConfig{}
+	if err := toml.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("decoding registries config: %w", err)
+	}
+
+	for i := range config.Registries {
+		reg := &config.Registries[i]
+		if reg.Prefix == "" {
+			reg.Prefix = reg.Location
+		}
+		if reg.Prefix == "" {
+			return nil, fmt.Errorf("registry %d: either prefix or location must be set", i)
+		}
+		if strings.HasPrefix(reg.Prefix, "*.") {
+			if reg.Location != "" {
+				return nil, fmt.Errorf("registry %q: location can't be set with a wildcard prefix", reg.Prefix)
+			}
+		} else if reg.Location == "" {
+			reg.Location = reg.Prefix
+		}
+		for j := range reg.Mirrors {
+			mirror := &reg.Mirrors[j]
+			if mirror.Location == "" {
+				return nil, fmt.Errorf("registry %q: mirror %d has no location", reg.Prefix, j)
+			}
+			switch mirror.PullFromMirror {
+			case "":
+				mirror.PullFromMirror = PullFromMirrorAll
+			case PullFromMirrorAll, PullFromMirrorDigestOnly, PullFromMirrorTagOnly:
+			default:
+				return nil, fmt.Errorf("registry %q: invalid pull-from-mirror %q for mirror %q",
+					reg.Prefix, mirror.PullFromMirror, mirror.Location)
+			}
+			if reg.MirrorByDigestOnly && mirror.PullFromMirror != PullFromMirrorAll {
+				return nil, fmt.Errorf("registry %q: pull-from-mirror can't be used with mirror-by-digest-only", reg.Prefix)
+			}
+		}
+	}
+
+	return config, nil
+}
+
+// loadRegistriesConfig reads the registries configuration from path. A missing
+// default file isn't an error and results in an empty configuration.
+func loadRegistriesConfig(path string) (*RegistriesConfig, error) {
+	if path == "" {
+		return &RegistriesConfig{}, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) && path == DefaultRegistriesConfig {
+			return &RegistriesConfig{}, nil
+		}
+		return nil, fmt.Errorf("reading registries config %q: %w", path, err)
+	}
+
+	config, err := ParseRegistriesConfig(data)
+	if err != nil {
+		return nil, fmt.Errorf("parsing registries config %q: %w", path, err)
+	}
+	return config, nil
+}
+
+// matchPrefix returns the part of name matched by prefix, or an empty string
+// if it doesn't match. Prefixes only match whole path components.
+func matchPrefix(prefix, name string) string {
+	if domain, ok := strings.CutPrefix(prefix, "*."); ok {
+		host, _, _ := strings.Cut(name, "/")
+		if strings.HasSuffix(host, "."+domain) {
+			return host
+		}
+		return ""
+	}
+
+	if name == prefix || strings.HasPrefix(name, prefix+"/") {
+		return prefix
+	}
+	return ""
+}
+
+// findRegistry returns the registry entry with the longest prefix matching
+// name together with the matched part of name.
+func (c *RegistriesConfig) findRegistry(name string) (*RegistryConfig, string) {
+	var found *RegistryConfig
+	var matched string
+	for i := range c.Registries {
+		reg := &c.Registries[i]
+		m := matchPrefix(reg.Prefix, name)
+		if len(m) > len(matched) {
+			found = reg
+			matched = m
+		}
+	}
+	return found, matched
+}
+
+// rewriteReference replaces the matched prefix of image by location keeping
+// its tag and digest.
+func rewriteReference(image reference.Named, matched, location string) (reference.Named, error) {
+	name := location + strings.TrimPrefix(image.Name(), matched)
+	ref, err := reference.ParseNormalizedNamed(name)
+	if err != nil {
+		return nil, fmt.Errorf("parsing rewritten image name %q: %w", name, err)
+	}
+	if tagged, ok := image.(reference.Tagged); ok {
+		ref, err = reference.WithTag(ref, tagged.Tag())
+		if err != nil {
+			return nil, err
+		}
+	}
+	if digested, ok := image.(reference.Digested); ok {
+		ref, err = reference.WithDigest(ref, digested.Digest())
+		if err != nil {
+			return nil, err
+		}
+	}
+	return ref, nil
+}
+
+// pushLocation returns where image lives according to the configuration.
+// Mirrors are only used to pull.
+func (c *RegistriesConfig) pushLocation(image reference.Named, insecureRegistries []string) (registryLocation, error) {
+	reg, matched := c.findRegistry(image.Name())
+	if reg == nil {
+		return registryLocation{
+			ref:      image,
+			insecure: slices.Contains(insecureRegistries, reference.Domain(image)),
+		}, nil
+	}
+
+	location := reg.Location
+	if location == "" {
+		location = matched
+	}
+	ref, err := rewriteReference(image, matched, location)
+	if err != nil {
+		return registryLocation{}, err
+	}
+	return registryLocation{
+		ref:      ref,
+		insecure: reg.Insecure || slices.Contains(insecureRegistries, reference.Domain(ref)),
+	}, nil
+}
+
+// pullLocations returns the locations to try, in order, when pulling image:
+// the mirrors that apply to it followed by the registry itself.
+func (c *RegistriesConfig) pullLocations(image reference.Named, insecureRegistries []string) ([]registryLocation, error) {
+	primary, err := c.pushLocation(image, insecureRegistries)
+	if err != nil {
+		return nil, err
+	}
+
+	reg, matched := c.findRegistry(image.Name())
+	if reg == nil {
+		return []registryLocation{primary}, nil
+	}
+	if reg.Blocked {
+		return nil, fmt.Errorf("registry %q is blocked by the registries configuration", reg.Prefix)
+	}
+
+	_, byDigest := image.(reference.Digested)
+	locations := make([]registryLocation, 0, len(reg.Mirrors)+1)
+	for _, mirror := range reg.Mirrors {
+		switch {
+		case reg.MirrorByDigestOnly && !byDigest,
+			mirror.PullFromMirror == PullFromMirrorDigestOnly && !byDigest,
+			mirror.PullFromMirror == PullFromMirrorTagOnly && byDigest:
+			continue
+		}
+		ref, err := rewriteReference(image, matched, mirror.Location)
+		if err != nil {
+			return nil, fmt.Errorf("mirror %q: %w", mirror.Location, err)
+		}
+		locations = append(locations, registryLocation{
+			ref:      ref,
+			insecure: mirror.Insecure || slices.Contains(insecureRegistries, reference.Domain(ref)),
+		})
+	}
+
+	return append(locations, primary), nil
+}
@@ -0,0 +1,242 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oci
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testRegistriesConfig = `
+unqualified-search-registries = ["docker.io"]
+
+[[registry]]
+prefix = "ghcr.io/inspektor-gadget/gadget"
+location = "ghcr.io/inspektor-gadget/gadget"
+
+[[registry.mirror]]
+location = "mirror.local:5000/gadget"
+insecure = true
+
+[[registry.mirror]]
+location = "digests.local/gadget"
+pull-from-mirror = "digest-only"
+
+[[registry]]
+prefix = "ghcr.io"
+location = "ghcr.local"
+
+[[registry]]
+location = "blocked.io"
+blocked = true
+
+[[registry]]
+prefix = "*.example.com"
+
+[[registry.mirror]]
+location = "wildcard.local"
+`
+
+func TestParseRegistriesConfig(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		config      string
+		expectedErr bool
+	}{
+		{
+			name:   "valid",
+			config: testRegistriesConfig,
+		},
+		{
+			name:   "empty",
+			config: "",
+		},
+		{
+			name:        "no prefix nor location",
+			config:      "[[registry]]\ninsecure = true\n",
+			expectedErr: true,
+		},
+		{
+			name:        "wildcard with location",
+			config:      "[[registry]]\nprefix = \"*.example.com\"\nlocation = \"foo.io\"\n",
+			expectedErr: true,
+		},
+		{
+			name:        "mirror without location",
+			config:      "[[registry]]\nlocation = \"foo.io\"\n[[registry.mirror]]\ninsecure = true\n",
+			expectedErr: true,
+		},
+		{
+			name:        "invalid pull-from-mirror",
+			config:      "[[registry]]\nlocation = \"foo.io\"\n[[registry.mirror]]\nlocation = \"bar.io\"\npull-from-mirror = \"foo\"\n",
+			expectedErr: true,
+		},
+		{
+			name:        "invalid toml",
+			config:      "[[registry]\n",
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := ParseRegistriesConfig([]byte(test.config))
+			if test.expectedErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestRegistriesConfigLocations(t *testing.T) {
+	t.Parallel()
+
+	config, err := ParseRegistriesConfig([]byte(testRegistriesConfig))
+	require.NoError(t, err)
+
+	type location struct {
+		ref      string
+		insecure bool
+	}
+
+	tests := []struct {
+		name               string
+		image              string
+		insecureRegistries []string
+		expectedPull       []location
+		expectedPush       location
+		expectedErr        bool
+	}{
+		{
+			name:  "no match",
+			image: "quay.io/foo/bar:v1",
+			expectedPull: []location{
+				{ref: "quay.io/foo/bar:v1"},
+			},
+			expectedPush: location{ref: "quay.io/foo/bar:v1"},
+		},
+		{
+			name:               "no match insecure",
+			image:              "quay.io/foo/bar:v1",
+			insecureRegistries: []string{"quay.io"},
+			expectedPull: []location{
+				{ref: "quay.io/foo/bar:v1", insecure: true},
+			},
+			expectedPush: location{ref: "quay.io/foo/bar:v1", insecure: true},
+		},
+		{
+			name:  "official gadget by tag",
+			image: "trace_exec:v1",
+			expectedPull: []location{
+				{ref: "mirror.local:5000/gadget/trace_exec:v1", insecure: true},
+				{ref: "ghcr.io/inspektor-gadget/gadget/trace_exec:v1"},
+			},
+			expectedPush: location{ref: "ghcr.io/inspektor-gadget/gadget/trace_exec:v1"},
+		},
+		{
+			name:  "official gadget by digest",
+			image: "trace_exec@sha256:842e69c79177908b6998737b86fc691e8fc0b3e45e2030cafcb362cbfcb1c039",
+			expectedPull: []location{
+				{ref: "mirror.local:5000/gadget/trace_exec@sha256:842e69c79177908b6998737b86fc691e8fc0b3e45e2030cafcb362cbfcb1c039", insecure: true},
+				{ref: "digests.local/gadget/trace_exec@sha256:842e69c79177908b6998737b86fc691e8fc0b3e45e2030cafcb362cbfcb1c039"},
+				{ref: "ghcr.io/inspektor-gadget/gadget/trace_exec@sha256:842e69c79177908b6998737b86fc691e8fc0b3e45e2030cafcb362cbfcb1c039"},
+			},
+			expectedPush: location{ref: "ghcr.io/inspektor-gadget/gadget/trace_exec@sha256:842e69c79177908b6998737b86fc691e8fc0b3e45e2030cafcb362cbfcb1c039"},
+		},
+		{
+			name:  "shorter prefix rewrites location",
+			image: "ghcr.io/foo/bar:v1",
+			expectedPull: []location{
+				{ref: "ghcr.local/foo/bar:v1"},
+			},
+			expectedPush: location{ref: "ghcr.local/foo/bar:v1"},
+		},
+		{
+			name:  "prefix only matches whole components",
+			image: "ghcr.io/inspektor-gadget/gadgets/foo:v1",
+			expectedPull: []location{
+				{ref: "ghcr.local/inspektor-gadget/gadgets/foo:v1"},
+			},
+			expectedPush: location{ref: "ghcr.local/inspektor-gadget/gadgets/foo:v1"},
+		},
+		{
+			name:  "wildcard",
+			image: "registry.example.com/foo/bar:v1",
+			expectedPull: []location{
+				{ref: "wildcard.local/foo/bar:v1"},
+				{ref: "registry.example.com/foo/bar:v1"},
+			},
+			expectedPush: location{ref: "registry.example.com/foo/bar:v1"},
+		},
+		{
+			name:         "blocked",
+			image:        "blocked.io/foo/bar:v1",
+			expectedPush: location{ref: "blocked.io/foo/bar:v1"},
+			expectedErr:  true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			image, err := normalizeImageName(test.image)
+			require.NoError(t, err)
+
+			push, err := config.pushLocation(image, test.insecureRegistries)
+			require.NoError(t, err)
+			require.Equal(t, test.expectedPush, location{ref: push.ref.String(), insecure: push.insecure})
+
+			pull, err := config.pullLocations(image, test.insecureRegistries)
+			if test.expectedErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			locations := make([]location, 0, len(pull))
+			for _, l := range pull {
+				locations = append(locations, location{ref: l.ref.String(), insecure: l.insecure})
+			}
+			require.Equal(t, test.expectedPull, locations)
+		})
+	}
+}
+
+func TestLoadRegistriesConfig(t *testing.T) {
+	t.Parallel()
+
+	config, err := loadRegistriesConfig("")
+	require.NoError(t, err)
+	require.Empty(t, config.Registries)
+
+	_, err = loadRegistriesConfig(filepath.Join(t.TempDir(), "missing.conf"))
+	require.Error(t, err)
+
+	path := filepath.Join(t.TempDir(), "registries.conf")
+	require.NoError(t, os.WriteFile(path, []byte(testRegistriesConfig), 0o600))
+	config, err = loadRegistriesConfig(path)
+	require.NoError(t, err)
+	require.Len(t, config.Registries, 4)
+}
@@ -15,6 +15,7 @@
 package clioperator
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"net/url"
@@ -33,11 +34,17 @@ import (
 	"sigs.k8s.io/yaml"
 
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource/formatters/csv"
+	histogramformatter "github.com/inspektor-gadget/inspektor-gadget/pkg/datasource/formatters/histogram"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource/formatters/json"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource/formatters/profiles"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource/formatters/template"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
 	apihelpers "github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api-helpers"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/histogram"
 	metadatav1 "github.com/inspektor-gadget/inspektor-gadget/pkg/metadata/v1"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators/cli/tui"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/params"
 )
 
@@ -56,6 +63,25 @@ const (
 	ModeNone       = "none"
 	ModeRaw        = "raw"
 	ModePCAPNG     = "pcap-ng"
+	ModeCSV        = "csv"
+	ModeTSV        = "tsv"
+	ModeTUI        = "tui"
+
+	// ModeTemplate and ModeTemplateFile take the Go template to use as argument, e.g.
+	// "template={{.proc.comm}}" or "template-file=summary.tmpl"
+	ModeTemplate     = "template"
+	ModeTemplateFile = "template-file"
+
+	// ModePprof, ModeFolded and ModeFlamegraph write the profiles of data sources with the profiles
+	// annotations to the file given as argument once the gadget is done, e.g. "pprof=cpu.pb.gz"
+	ModePprof      = "pprof"
+	ModeFolded     = "folded"
+	ModeFlamegraph = "flamegraph"
+
+	// ModeHistogram renders the histograms of data sources with histogram fields, per key made of
+	// the other selected fields. It optionally takes the scale of the bars as argument, e.g.
+	// "histogram=log2"
+	ModeHistogram = "histogram"
 
 	DefaultOutputMode = ModeColumns
 
@@ -89,8 +115,17 @@ const (
 )
 
 var (
-	DefaultSupportedOutputModes = []string{ModeColumns, ModeJSON, ModeJSONPretty, ModeNone, ModeYAML}
-	cliWriteMutex               = sync.Mutex{}
+	DefaultSupportedOutputModes = []string{
+		ModeColumns, ModeCSV, ModeJSON, ModeJSONPretty, ModeNone,
+		ModeTemplate, ModeTemplateFile, ModeTSV, ModeTUI, ModeYAML,
+	}
+	// ProfileOutputModes are supported in addition to the default ones by data sources carrying
+	// profiles
+	ProfileOutputModes = []string{ModeFlamegraph, ModeFolded, ModePprof}
+	// HistogramOutputModes are supported in addition to the default ones by data sources with
+	// histogram fields
+	HistogramOutputModes = []string{ModeHistogram}
+	cliWriteMutex        = sync.Mutex{}
 )
 
 type cliOperator struct{}
@@ -131,6 +166,16 @@ type cliOperatorInstance struct {
 	supportedOutputModes map[string][]string
 	// key: datasource name, value: default output mode
 	defaultOutputMode map[string]string
+	// tui is shared by all the data sources using the tui output mode
+	tui *tui.TUI
+	// profileWriters write the profiles to files when the gadget is done
+	profileWriters []profileWriter
+}
+
+type profileWriter struct {
+	mode    string
+	file    string
+	profile *profiles.Profile
 }
 
 func (o *cliOperatorInstance) Name() string {
@@ -149,6 +194,38 @@ func getNamesFromFields(fields []*api.Field) []string {
 	return res
 }
 
+// getFields returns the fields of ds that can be shown, sorted by name, and the ones shown by
+// default, sorted by their order
+func getFields(ds datasource.DataSource) ([]*api.Field, []*api.Field) {
+	fields := ds.Fields()
+	availableFields := make([]*api.Field, 0, len(fields))
+	defaultFields := make([]*api.Field, 0)
+	for _, f := range fields {
+		if datasource.FieldFlagUnreferenced.In(f.Flags) ||
+			datasource.FieldFlagContainer.In(f.Flags) ||
+			datasource.FieldFlagEmpty.In(f.Flags) {
+			continue
+		}
+		availableFields = append(availableFields, f)
+		if datasource.FieldFlagHidden.In(f.Flags) {
+			continue
+		}
+		defaultFields = append(defaultFields, f)
+	}
+
+	// Sort available fields by name
+	sort.Slice(availableFields, func(i, j int) bool {
+		return availableFields[i].FullName < availableFields[j].FullName
+	})
+
+	// Sort default fields by order value
+	sort.SliceStable(defaultFields, func(i, j int) bool {
+		return defaultFields[i].Order < defaultFields[j].Order
+	})
+
+	return availableFields, defaultFields
+}
+
 func (o *cliOperatorInstance) ExtraParams(gadgetCtx operators.GadgetContext) api.Params {
 	dataSources := gadgetCtx.GetDataSources()
 
@@ -166,31 +243,7 @@ func (o *cliOperatorInstance) ExtraParams(gadgetCtx operators.GadgetContext) api
 	outputDefaultValues := make([]string, 0, len(dataSources))
 	for _, ds := range dataSources {
 		// Fields
-		fields := ds.Fields()
-		availableFields := make([]*api.Field, 0, len(fields))
-		defaultFields := make([]*api.Field, 0)
-		for _, f := range fields {
-			if datasource.FieldFlagUnreferenced.In(f.Flags) ||
-				datasource.FieldFlagContainer.In(f.Flags) ||
-				datasource.FieldFlagEmpty.In(f.Flags) {
-				continue
-			}
-			availableFields = append(availableFields, f)
-			if datasource.FieldFlagHidden.In(f.Flags) {
-				continue
-			}
-			defaultFields = append(defaultFields, f)
-		}
-
-		// Sort available fields by name
-		sort.Slice(availableFields, func(i, j int) bool {
-			return availableFields[i].FullName < availableFields[j].FullName
-		})
-
-		// Sort default fields by order value
-		sort.SliceStable(defaultFields, func(i, j int) bool {
-			return defaultFields[i].Order < defaultFields[j].Order
-		})
+		availableFields, defaultFields := getFields(ds)
 
 		fieldsDefaultValue := strings.Join(getNamesFromFields(defaultFields), ",")
 		if nameDS {
@@ -213,7 +266,13 @@ func (o *cliOperatorInstance) ExtraParams(gadgetCtx operators.GadgetContext) api
 		fieldsDescriptions = append(fieldsDescriptions, sb.String())
 
 		// Supported output modes
-		supportedOutputs := DefaultSupportedOutputModes
+		supportedOutputs := slices.Clone(DefaultSupportedOutputModes)
+		if profiles.IsProfile(ds) {
+			supportedOutputs = append(supportedOutputs, ProfileOutputModes...)
+		}
+		if histogramformatter.IsHistogram(ds) {
+			supportedOutputs = append(supportedOutputs, HistogramOutputModes...)
+		}
 		if supportedOutputsAnnotated, ok := ds.Annotations()[AnnotationSupportedOutputModes]; ok {
 			supportedOutputs = strings.Split(supportedOutputsAnnotated, ",")
 		}
@@ -346,6 +405,9 @@ func (o *cliOperatorInstance) PreStart(gadgetCtx operators.GadgetContext) error
 			}
 		}
 
+		// Some modes take an argument, e.g. "template={{.comm}}"
+		mode, modeArg, _ := strings.Cut(mode, "=")
+
 		if !slices.Contains(o.supportedOutputModes[ds.Name()], mode) {
 			gadgetCtx.Logger().Warnf("output mode %q for data source %q is not supported; skipping data source",
 				mode, ds.Name())
@@ -484,6 +546,179 @@ func (o *cliOperatorInstance) PreStart(gadgetCtx operators.GadgetContext) error
 					return nil
 				}, Priority)
 			}
+		case ModeCSV, ModeTSV:
+			_, defaultFields := getFields(ds)
+			selectedFields := getNamesFromFields(defaultFields)
+			if hasFields {
+				selectedFields = parseFields(fields, selectedFields)
+			}
+
+			var opts []csv.Option
+			if mode == ModeTSV {
+				opts = append(opts, csv.WithSeparator('\t'))
+			}
+			csvFormatter, err := csv.New(ds, selectedFields, opts...)
+			if err != nil {
+				gadgetCtx.Logger().Warnf("failed to create %s formatter: %v; skipping data source %q", mode, err, ds.Name())
+				continue
+			}
+
+			cliWriteMutex.Lock()
+			os.Stdout.Write(csvFormatter.Header())
+			cliWriteMutex.Unlock()
+
+			switch ds.Type() {
+			case datasource.TypeSingle:
+				ds.Subscribe(func(ds datasource.DataSource, data datasource.Data) error {
+					writeOutput(csvFormatter.Marshal(data), os.Stdout)
+					return nil
+				}, Priority)
+			case datasource.TypeArray:
+				ds.SubscribeArray(func(ds datasource.DataSource, dataArray datasource.DataArray) error {
+					writeOutput(csvFormatter.MarshalArray(dataArray), os.Stdout)
+					return nil
+				}, Priority)
+			}
+		case ModeHistogram:
+			_, defaultFields := getFields(ds)
+			selectedFields := getNamesFromFields(defaultFields)
+			if hasFields {
+				selectedFields = parseFields(fields, selectedFields)
+			}
+
+			opts := []histogramformatter.Option{}
+			if modeArg != "" {
+				opts = append(opts, histogramformatter.WithScale(histogram.Scale(modeArg)))
+			}
+			if isTerminal {
+				if width, _, err := term.GetSize(int(os.Stdout.Fd())); err == nil {
+					opts = append(opts, histogramformatter.WithWidth(width))
+				}
+			}
+			histFormatter, err := histogramformatter.New(ds, selectedFields, opts...)
+			if err != nil {
+				gadgetCtx.Logger().Warnf("failed to create histogram formatter: %v; skipping data source %q", err, ds.Name())
+				continue
+			}
+
+			before := func() {}
+			if clearScreenBefore && isTerminal {
+				before = clearScreen
+			}
+			switch ds.Type() {
+			case datasource.TypeSingle:
+				ds.Subscribe(func(ds datasource.DataSource, data datasource.Data) error {
+					before()
+					writeOutput(histFormatter.Marshal(data), os.Stdout)
+					return nil
+				}, Priority)
+			case datasource.TypeArray:
+				ds.SubscribeArray(func(ds datasource.DataSource, dataArray datasource.DataArray) error {
+					before()
+					writeOutput(histFormatter.MarshalArray(dataArray), os.Stdout)
+					return nil
+				}, Priority)
+			}
+		case ModeTemplate, ModeTemplateFile:
+			text := modeArg
+			if mode == ModeTemplateFile {
+				content, err := os.ReadFile(modeArg)
+				if err != nil {
+					gadgetCtx.Logger().Warnf("failed to read template: %v; skipping data source %q", err, ds.Name())
+					continue
+				}
+				text = string(content)
+			}
+			if text == "" {
+				gadgetCtx.Logger().Warnf("no template given, use %s=<template>; skipping data source %q", mode, ds.Name())
+				continue
+			}
+
+			tmplFormatter, err := template.New(ds, text)
+			if err != nil {
+				gadgetCtx.Logger().Warnf("failed to parse template: %v; skipping data source %q", err, ds.Name())
+				continue
+			}
+
+			logger := gadgetCtx.Logger()
+			switch ds.Type() {
+			case datasource.TypeSingle:
+				ds.Subscribe(func(ds datasource.DataSource, data datasource.Data) error {
+					out, err := tmplFormatter.Marshal(data)
+					if err != nil {
+						logger.Warnf("data source %q: %v", ds.Name(), err)
+						return nil
+					}
+					writeOutput(out, os.Stdout)
+					return nil
+				}, Priority)
+			case datasource.TypeArray:
+				ds.SubscribeArray(func(ds datasource.DataSource, dataArray datasource.DataArray) error {
+					out, err := tmplFormatter.MarshalArray(dataArray)
+					if err != nil {
+						logger.Warnf("data source %q: %v", ds.Name(), err)
+						return nil
+					}
+					writeOutput(out, os.Stdout)
+					return nil
+				}, Priority)
+			}
+		case ModeTUI:
+			if !isTerminal {
+				gadgetCtx.Logger().Warnf("output mode %q needs a terminal; skipping data source %q", mode, ds.Name())
+				continue
+			}
+
+			availableFields, defaultFields := getFields(ds)
+			selectedFields := getNamesFromFields(defaultFields)
+			if hasFields {
+				selectedFields = parseFields(fields, selectedFields)
+			}
+
+			if o.tui == nil {
+				o.tui = tui.New(os.Stdin, os.Stdout, tui.WithQuitFunc(gadgetCtx.Cancel))
+			}
+			err := o.tui.AddDataSource(ds, getNamesFromFields(availableFields), selectedFields)
+			if err != nil {
+				gadgetCtx.Logger().Warnf("failed to add data source to the TUI: %v; skipping data source %q", err, ds.Name())
+				continue
+			}
+
+			ds.SubscribePacket(func(ds datasource.DataSource, p datasource.Packet) error {
+				if err := o.tui.Update(ds, p); err != nil {
+					gadgetCtx.Logger().Debugf("updating TUI: %v", err)
+				}
+				return nil
+			}, Priority)
+		case ModePprof, ModeFolded, ModeFlamegraph:
+			if modeArg == "" {
+				gadgetCtx.Logger().Warnf("no file given, use %s=<file>; skipping data source %q", mode, ds.Name())
+				continue
+			}
+
+			profile, err := profiles.New(ds)
+			if err != nil {
+				gadgetCtx.Logger().Warnf("failed to read profile annotations: %v; skipping data source %q", err, ds.Name())
+				continue
+			}
+
+			switch ds.Type() {
+			case datasource.TypeSingle:
+				ds.Subscribe(func(ds datasource.DataSource, data datasource.Data) error {
+					profile.Add(data)
+					return nil
+				}, Priority)
+			case datasource.TypeArray:
+				ds.SubscribeArray(func(ds datasource.DataSource, dataArray datasource.DataArray) error {
+					profile.AddArray(dataArray)
+					return nil
+				}, Priority)
+			}
+			o.profileWriters = append(o.profileWriters, profileWriter{
+				mode:    mode,
+				file:    modeArg,
+				profile: profile,
+			})
 		case ModePCAPNG:
 			// Check ds for compatiblity
 			payloadField := ds.GetField(ds.Annotations()[AnnotationPCAPPayload])
@@ -644,16 +879,64 @@ func jsonArrayDataFn(dataArray datasource.DataArray, jsonFormatter *json.Formatt
 	fmt.Fprintln(w, string(jsonFormatter.MarshalArray(dataArray)))
 }
 
+func writeOutput(out []byte, w io.Writer) {
+	cliWriteMutex.Lock()
+	defer cliWriteMutex.Unlock()
+	w.Write(out)
+}
+
 func (o *cliOperatorInstance) Start(gadgetCtx operators.GadgetContext) error {
+	if o.tui != nil {
+		return o.tui.Start()
+	}
 	return nil
 }
 
 func (o *cliOperatorInstance) Stop(gadgetCtx operators.GadgetContext) error {
+	if o.tui != nil {
+		o.tui.Stop()
+	}
 	return nil
 }
 
+// writeProfile writes a profile to its file in the format given by the output mode
+func writeProfile(pw profileWriter) error {
+	f, err := os.Create(pw.file)
+	if err != nil {
+		return fmt.Errorf("creating profile file: %w", err)
+	}
+	defer f.Close()
+
+	switch pw.mode {
+	case ModePprof:
+		err = pw.profile.WritePprof(f)
+	case ModeFolded:
+		err = pw.profile.WriteFolded(f)
+	case ModeFlamegraph:
+		err = pw.profile.WriteFlamegraph(f)
+	}
+	if err != nil {
+		return err
+	}
+	return f.Close()
+}
+
 func (o *cliOperatorInstance) Close(gadgetCtx operators.GadgetContext) error {
-	return nil
+	// Make sure the terminal is restored even if the gadget wasn't stopped
+	if o.tui != nil {
+		o.tui.Stop()
+	}
+
+	// Profiles are written once the gadget is done, so they include the data flushed when it stops
+	var errs []error
+	for _, pw := range o.profileWriters {
+		if err := writeProfile(pw); err != nil {
+			errs = append(errs, fmt.Errorf("writing %s profile to %q: %w", pw.mode, pw.file, err))
+			continue
+		}
+		gadgetCtx.Logger().Infof("%s profile written to %q", pw.mode, pw.file)
+	}
+	return errors.Join(errs...)
 }
 
 var CLIOperator = &cliOperator{}
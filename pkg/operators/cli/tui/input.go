@@ -0,0 +1,106 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tui
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+type key int
+
+const (
+	keyRune key = iota
+	keyEnter
+	keyEsc
+	keyBackspace
+	keyTab
+	keyBacktab
+	keyUp
+	keyDown
+	keyLeft
+	keyRight
+	keyPgUp
+	keyPgDown
+	keyHome
+	keyEnd
+	keyCtrlC
+)
+
+type keyEvent struct {
+	key key
+	r   rune
+}
+
+// escapeSequences maps the CSI and SS3 sequences sent by terminals (without the leading "ESC [" or
+// "ESC O") to keys
+var escapeSequences = map[string]key{
+	"A":  keyUp,
+	"B":  keyDown,
+	"C":  keyRight,
+	"D":  keyLeft,
+	"H":  keyHome,
+	"F":  keyEnd,
+	"Z":  keyBacktab,
+	"1~": keyHome,
+	"4~": keyEnd,
+	"5~": keyPgUp,
+	"6~": keyPgDown,
+	"7~": keyHome,
+	"8~": keyEnd,
+}
+
+// parseKeys returns the keys read from a terminal in raw mode. Unknown escape sequences and
+// control characters are dropped.
+func parseKeys(b []byte) []keyEvent {
+	var res []keyEvent
+	for len(b) > 0 {
+		switch b[0] {
+		case 0x1b:
+			if len(b) > 2 && (b[1] == '[' || b[1] == 'O') {
+				// Parameters are followed by a final byte in the 0x40-0x7e range
+				i := 2
+				for i < len(b) && (b[i] < 0x40 || b[i] > 0x7e) {
+					i++
+				}
+				if i < len(b) {
+					if k, ok := escapeSequences[string(b[2:i+1])]; ok {
+						res = append(res, keyEvent{key: k})
+					}
+					b = b[i+1:]
+					continue
+				}
+			}
+			res = append(res, keyEvent{key: keyEsc})
+		case '\r', '\n':
+			res = append(res, keyEvent{key: keyEnter})
+		case 0x7f, 0x08:
+			res = append(res, keyEvent{key: keyBackspace})
+		case '\t':
+			res = append(res, keyEvent{key: keyTab})
+		case 0x03:
+			res = append(res, keyEvent{key: keyCtrlC})
+		default:
+			r, size := utf8.DecodeRune(b)
+			if r != utf8.RuneError && !unicode.IsControl(r) {
+				res = append(res, keyEvent{key: keyRune, r: r})
+			}
+			b = b[size:]
+			continue
+		}
+		b = b[1:]
+	}
+	return res
+}
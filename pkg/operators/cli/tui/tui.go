@@ -0,0 +1,616 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package tui implements an interactive terminal UI showing the data of data sources as tables,
+// similar to top or htop.
+package tui
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/term"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
+)
+
+const (
+	// DefaultMaxEvents is the number of events kept for data sources that aren't arrays
+	DefaultMaxEvents = 1000
+
+	refreshInterval = 100 * time.Millisecond
+	maxColumnWidth  = 40
+
+	helpText = "q quit  tab source  </> sort  r reverse  c columns  / filter  p pause  enter details"
+
+	styleReverse = "\033[7m"
+	styleBold    = "\033[1m"
+	styleReset   = "\033[0m"
+)
+
+type state int
+
+const (
+	stateTable state = iota
+	stateDetails
+	stateColumns
+	stateFilter
+)
+
+type TUI struct {
+	mu sync.Mutex
+
+	in        io.Reader
+	out       io.Writer
+	getSize   func() (int, int, error)
+	onQuit    func()
+	maxEvents int
+
+	views   []*view
+	current int
+	paused  bool
+
+	state   state
+	message string
+
+	// filter being edited
+	input []rune
+
+	columnCursor int
+
+	details       []string
+	detailsOffset int
+
+	dirty    chan struct{}
+	done     chan struct{}
+	started  bool
+	stopOnce sync.Once
+	restore  func()
+}
+
+type Option func(*TUI)
+
+// WithSize sets the function returning the size of the terminal; by default, the size of out is
+// used if it's a terminal
+func WithSize(getSize func() (width, height int, err error)) Option {
+	return func(t *TUI) {
+		t.getSize = getSize
+	}
+}
+
+// WithQuitFunc sets the function called when the user quits the TUI
+func WithQuitFunc(onQuit func()) Option {
+	return func(t *TUI) {
+		t.onQuit = onQuit
+	}
+}
+
+// WithMaxEvents sets the number of events kept for data sources that aren't arrays
+func WithMaxEvents(maxEvents int) Option {
+	return func(t *TUI) {
+		t.maxEvents = maxEvents
+	}
+}
+
+// New creates a TUI reading keys from in and drawing to out
+func New(in io.Reader, out io.Writer, options ...Option) *TUI {
+	t := &TUI{
+		in:        in,
+		out:       out,
+		onQuit:    func() {},
+		maxEvents: DefaultMaxEvents,
+		dirty:     make(chan struct{}, 1),
+		done:      make(chan struct{}),
+	}
+	t.getSize = func() (int, int, error) {
+		if f, ok := out.(*os.File); ok {
+			return term.GetSize(int(f.Fd()))
+		}
+		return 0, 0, fmt.Errorf("output is not a terminal")
+	}
+	for _, o := range options {
+		o(t)
+	}
+	return t
+}
+
+// AddDataSource adds a data source to the TUI. fields are all the fields that can be shown and
+// visible the ones shown initially, in that order. The TUI needs to get the packets of the data
+// source via Update.
+func (t *TUI) AddDataSource(ds datasource.DataSource, fields []string, visible []string) error {
+	v, err := newView(ds, fields, visible, t.maxEvents)
+	if err != nil {
+		return fmt.Errorf("adding data source %q: %w", ds.Name(), err)
+	}
+	t.mu.Lock()
+	t.views = append(t.views, v)
+	t.mu.Unlock()
+	return nil
+}
+
+// Update passes a packet of ds to the TUI. It's ignored while the TUI is paused.
+func (t *TUI) Update(ds datasource.DataSource, p datasource.Packet) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.paused {
+		return nil
+	}
+	for _, v := range t.views {
+		if v.ds == ds {
+			if err := v.update(p); err != nil {
+				return err
+			}
+			t.redraw()
+			return nil
+		}
+	}
+	return nil
+}
+
+// Start switches the terminal to raw mode and starts handling keys and drawing
+func (t *TUI) Start() error {
+	if f, ok := t.in.(*os.File); ok && term.IsTerminal(int(f.Fd())) {
+		oldState, err := term.MakeRaw(int(f.Fd()))
+		if err != nil {
+			return fmt.Errorf("setting terminal to raw mode: %w", err)
+		}
+		t.restore = func() {
+			term.Restore(int(f.Fd()), oldState)
+		}
+	}
+
+	// Use the alternate screen buffer and hide the cursor
+	t.mu.Lock()
+	fmt.Fprint(t.out, "\033[?1049h\033[?25l")
+	t.started = true
+	t.mu.Unlock()
+
+	go t.readKeys()
+	go t.drawLoop()
+	t.redraw()
+	return nil
+}
+
+// Stop stops drawing and restores the terminal
+func (t *TUI) Stop() {
+	t.stopOnce.Do(func() {
+		close(t.done)
+
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		if !t.started {
+			return
+		}
+		fmt.Fprint(t.out, "\033[?25h\033[?1049l")
+		if t.restore != nil {
+			t.restore()
+		}
+	})
+}
+
+// redraw asks the draw loop to draw the screen again
+func (t *TUI) redraw() {
+	select {
+	case t.dirty <- struct{}{}:
+	default:
+	}
+}
+
+func (t *TUI) readKeys() {
+	buf := make([]byte, 256)
+	for {
+		n, err := t.in.Read(buf)
+		select {
+		case <-t.done:
+			return
+		default:
+		}
+		if err != nil {
+			return
+		}
+		for _, k := range parseKeys(buf[:n]) {
+			t.handleKey(k)
+		}
+	}
+}
+
+func (t *TUI) drawLoop() {
+	for {
+		select {
+		case <-t.done:
+			return
+		case <-t.dirty:
+		}
+		t.draw()
+
+		// Don't draw more often than needed when events arrive fast
+		select {
+		case <-t.done:
+			return
+		case <-time.After(refreshInterval):
+		}
+	}
+}
+
+func (t *TUI) draw() {
+	width, height, err := t.getSize()
+	if err != nil {
+		width, height = 80, 24
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	select {
+	case <-t.done:
+		return
+	default:
+	}
+
+	var sb strings.Builder
+	sb.WriteString("\033[H")
+	for i, line := range t.render(width, height) {
+		if i > 0 {
+			sb.WriteString("\r\n")
+		}
+		sb.WriteString(line)
+		sb.WriteString("\033[K")
+	}
+	sb.WriteString("\033[J")
+	io.WriteString(t.out, sb.String())
+}
+
+func (t *TUI) currentView() *view {
+	if len(t.views) == 0 {
+		return nil
+	}
+	return t.views[t.current]
+}
+
+// handleKey updates the state of the TUI according to a key pressed by the user
+func (t *TUI) handleKey(k keyEvent) {
+	t.mu.Lock()
+	defer t.redraw()
+	defer t.mu.Unlock()
+
+	v := t.currentView()
+	if v == nil {
+		if k.key == keyCtrlC || (k.key == keyRune && k.r == 'q') {
+			go t.onQuit()
+		}
+		return
+	}
+
+	if k.key == keyCtrlC {
+		go t.onQuit()
+		return
+	}
+
+	switch t.state {
+	case stateFilter:
+		t.handleFilterKey(v, k)
+	case stateColumns:
+		t.handleColumnsKey(v, k)
+	case stateDetails:
+		t.handleDetailsKey(k)
+	default:
+		t.message = ""
+		t.handleTableKey(v, k)
+	}
+}
+
+func (t *TUI) handleTableKey(v *view, k keyEvent) {
+	pageSize := 10
+	if _, height, err := t.getSize(); err == nil {
+		pageSize = max(height-3, 1)
+	}
+
+	switch k.key {
+	case keyUp:
+		v.selected = max(v.selected-1, 0)
+	case keyDown:
+		v.selected = max(min(v.selected+1, len(v.shown)-1), 0)
+	case keyPgUp:
+		v.selected = max(v.selected-pageSize, 0)
+	case keyPgDown:
+		v.selected = max(min(v.selected+pageSize, len(v.shown)-1), 0)
+	case keyHome:
+		v.selected = 0
+	case keyEnd:
+		v.selected = max(len(v.shown)-1, 0)
+	case keyTab:
+		t.current = (t.current + 1) % len(t.views)
+	case keyBacktab:
+		t.current = (t.current - 1 + len(t.views)) % len(t.views)
+	case keyEnter:
+		if row := v.selectedRow(); row != nil {
+			t.details = v.details(row)
+			t.detailsOffset = 0
+			t.state = stateDetails
+		}
+	case keyRune:
+		switch k.r {
+		case 'q':
+			go t.onQuit()
+		case 'k':
+			v.selected = max(v.selected-1, 0)
+		case 'j':
+			v.selected = max(min(v.selected+1, len(v.shown)-1), 0)
+		case '>', 's':
+			if err := v.moveSort(1); err != nil {
+				t.message = err.Error()
+			}
+		case '<':
+			if err := v.moveSort(-1); err != nil {
+				t.message = err.Error()
+			}
+		case 'r':
+			if v.sortColumn == nil {
+				t.message = "not sorted, use < or > to sort"
+				break
+			}
+			if err := v.setSort(v.sortColumn, !v.sortDesc); err != nil {
+				t.message = err.Error()
+			}
+		case 'c':
+			t.columnCursor = 0
+			t.state = stateColumns
+		case '/':
+			t.input = []rune(v.filter)
+			t.state = stateFilter
+		case 'p', ' ':
+			t.paused = !t.paused
+		default:
+			// Switch data source by number
+			if k.r >= '1' && k.r <= '9' && int(k.r-'1') < len(t.views) {
+				t.current = int(k.r - '1')
+			}
+		}
+	}
+}
+
+func (t *TUI) handleFilterKey(v *view, k keyEvent) {
+	switch k.key {
+	case keyEsc:
+		t.message = ""
+		t.state = stateTable
+	case keyEnter:
+		if err := v.setFilter(strings.TrimSpace(string(t.input))); err != nil {
+			t.message = fmt.Sprintf("invalid filter: %v", err)
+			return
+		}
+		t.message = ""
+		t.state = stateTable
+	case keyBackspace:
+		if len(t.input) > 0 {
+			t.input = t.input[:len(t.input)-1]
+		}
+	case keyRune:
+		t.input = append(t.input, k.r)
+	}
+}
+
+func (t *TUI) handleColumnsKey(v *view, k keyEvent) {
+	switch k.key {
+	case keyEsc, keyEnter:
+		t.state = stateTable
+	case keyUp:
+		t.columnCursor = max(t.columnCursor-1, 0)
+	case keyDown:
+		t.columnCursor = min(t.columnCursor+1, len(v.columns)-1)
+	case keyRune:
+		switch k.r {
+		case 'c', 'q':
+			t.state = stateTable
+		case 'k':
+			t.columnCursor = max(t.columnCursor-1, 0)
+		case 'j':
+			t.columnCursor = min(t.columnCursor+1, len(v.columns)-1)
+		case ' ', 'x':
+			c := v.columns[t.columnCursor]
+			c.visible = !c.visible
+			if !c.visible && v.sortColumn == c {
+				v.setSort(nil, false)
+			}
+		}
+	}
+}
+
+func (t *TUI) handleDetailsKey(k keyEvent) {
+	switch k.key {
+	case keyEsc, keyEnter:
+		t.state = stateTable
+	case keyUp:
+		t.detailsOffset = max(t.detailsOffset-1, 0)
+	case keyDown:
+		t.detailsOffset = max(min(t.detailsOffset+1, len(t.details)-1), 0)
+	case keyRune:
+		switch k.r {
+		case 'q':
+			t.state = stateTable
+		case 'k':
+			t.detailsOffset = max(t.detailsOffset-1, 0)
+		case 'j':
+			t.detailsOffset = max(min(t.detailsOffset+1, len(t.details)-1), 0)
+		}
+	}
+}
+
+// fit truncates or pads s with spaces to width characters
+func fit(s string, width int, alignRight bool) string {
+	n := utf8.RuneCountInString(s)
+	if n > width {
+		return string([]rune(s)[:width])
+	}
+	if alignRight {
+		return strings.Repeat(" ", width-n) + s
+	}
+	return s + strings.Repeat(" ", width-n)
+}
+
+// render returns the lines of the screen
+func (t *TUI) render(width, height int) []string {
+	lines := make([]string, 0, height)
+
+	v := t.currentView()
+	if v == nil {
+		return append(lines, fit("no data sources", width, false))
+	}
+
+	// Title bar: data sources and the state of the current one
+	var title strings.Builder
+	for i, view := range t.views {
+		if i == t.current {
+			fmt.Fprintf(&title, " [%s]", view.ds.Name())
+		} else {
+			fmt.Fprintf(&title, " %s", view.ds.Name())
+		}
+	}
+	fmt.Fprintf(&title, " | %d/%d rows", len(v.shown), len(v.rows))
+	if v.sortColumn != nil {
+		order := "asc"
+		if v.sortDesc {
+			order = "desc"
+		}
+		fmt.Fprintf(&title, " | sort: %s %s", v.sortColumn.name, order)
+	}
+	if v.filter != "" {
+		fmt.Fprintf(&title, " | filter: %s", v.filter)
+	}
+	if t.paused {
+		title.WriteString(" | PAUSED")
+	}
+	lines = append(lines, styleReverse+fit(title.String(), width, false)+styleReset)
+
+	bodyHeight := max(height-2, 0)
+	switch t.state {
+	case stateDetails:
+		end := min(t.detailsOffset+bodyHeight, len(t.details))
+		for _, line := range t.details[t.detailsOffset:end] {
+			lines = append(lines, fit(line, width, false))
+		}
+	case stateColumns:
+		offset := max(t.columnCursor-bodyHeight+1, 0)
+		end := min(offset+bodyHeight, len(v.columns))
+		for i, c := range v.columns[offset:end] {
+			mark := " "
+			if c.visible {
+				mark = "x"
+			}
+			line := fit(fmt.Sprintf("[%s] %s", mark, c.name), width, false)
+			if offset+i == t.columnCursor {
+				line = styleReverse + line + styleReset
+			}
+			lines = append(lines, line)
+		}
+	default:
+		lines = append(lines, t.renderTable(v, width, bodyHeight)...)
+	}
+
+	for len(lines) < height-1 {
+		lines = append(lines, "")
+	}
+
+	// Status line
+	var status string
+	switch {
+	case t.state == stateFilter:
+		status = "filter: " + string(t.input)
+		if t.message != "" {
+			status += "  (" + t.message + ")"
+		}
+	case t.message != "":
+		status = t.message
+	case t.state == stateColumns:
+		status = "space toggle column  esc back"
+	case t.state == stateDetails:
+		status = "esc back"
+	default:
+		status = helpText
+	}
+	lines = append(lines, styleBold+fit(status, width, false)+styleReset)
+	return lines
+}
+
+func (t *TUI) renderTable(v *view, width, height int) []string {
+	if height <= 0 {
+		return nil
+	}
+	columns := v.visibleColumns()
+
+	// Keep the selected row visible
+	rowsHeight := height - 1
+	if v.selected < v.offset {
+		v.offset = v.selected
+	}
+	if rowsHeight > 0 && v.selected >= v.offset+rowsHeight {
+		v.offset = v.selected - rowsHeight + 1
+	}
+	v.offset = max(min(v.offset, len(v.shown)-rowsHeight), 0)
+	end := min(v.offset+rowsHeight, len(v.shown))
+
+	// Size columns after the names and the values being shown
+	cells := make([][]string, 0, end-v.offset)
+	widths := make([]int, len(columns))
+	for i, c := range columns {
+		widths[i] = utf8.RuneCountInString(c.name) + 1
+	}
+	for _, row := range v.shown[v.offset:end] {
+		values := make([]string, len(columns))
+		for i, c := range columns {
+			values[i] = c.str(row)
+			widths[i] = max(widths[i], min(utf8.RuneCountInString(values[i]), maxColumnWidth))
+		}
+		cells = append(cells, values)
+	}
+
+	var header strings.Builder
+	for i, c := range columns {
+		if i > 0 {
+			header.WriteByte(' ')
+		}
+		name := c.name
+		if c == v.sortColumn {
+			if v.sortDesc {
+				name += "▼"
+			} else {
+				name += "▲"
+			}
+		}
+		header.WriteString(fit(name, widths[i], c.alignRight))
+	}
+	lines := []string{styleBold + fit(header.String(), width, false) + styleReset}
+
+	for i, values := range cells {
+		var line strings.Builder
+		for j, c := range columns {
+			if j > 0 {
+				line.WriteByte(' ')
+			}
+			line.WriteString(fit(values[j], widths[j], c.alignRight))
+		}
+		s := fit(line.String(), width, false)
+		if v.offset+i == v.selected {
+			s = styleReverse + s + styleReset
+		}
+		lines = append(lines, s)
+	}
+	return lines
+}
@@ -0,0 +1,234 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tui
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+)
+
+func TestParseKeys(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []keyEvent
+	}{
+		{
+			name:     "runes",
+			input:    "q/é",
+			expected: []keyEvent{{key: keyRune, r: 'q'}, {key: keyRune, r: '/'}, {key: keyRune, r: 'é'}},
+		},
+		{
+			name:     "control keys",
+			input:    "\r\t\x7f\x03",
+			expected: []keyEvent{{key: keyEnter}, {key: keyTab}, {key: keyBackspace}, {key: keyCtrlC}},
+		},
+		{
+			name:     "escape sequences",
+			input:    "\x1b[A\x1bOB\x1b[5~\x1b[Z",
+			expected: []keyEvent{{key: keyUp}, {key: keyDown}, {key: keyPgUp}, {key: keyBacktab}},
+		},
+		{
+			name:     "lone escape",
+			input:    "\x1b",
+			expected: []keyEvent{{key: keyEsc}},
+		},
+		{
+			name:     "unknown escape sequence",
+			input:    "\x1b[99~x",
+			expected: []keyEvent{{key: keyRune, r: 'x'}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.expected, parseKeys([]byte(test.input)))
+		})
+	}
+}
+
+type testProcess struct {
+	comm string
+	pid  uint32
+}
+
+func newTestTUI(t *testing.T) (*TUI, datasource.DataSource) {
+	t.Helper()
+
+	ds, err := datasource.New(datasource.TypeArray, "processes")
+	require.NoError(t, err)
+	comm, err := ds.AddField("comm", api.Kind_String)
+	require.NoError(t, err)
+	pid, err := ds.AddField("pid", api.Kind_Uint32)
+	require.NoError(t, err)
+	_, err = ds.AddField("args", api.Kind_String)
+	require.NoError(t, err)
+
+	tui := New(nil, io.Discard, WithSize(func() (int, int, error) { return 40, 10, nil }))
+	require.NoError(t, tui.AddDataSource(ds, []string{"args", "comm", "pid"}, []string{"pid", "comm"}))
+
+	pa, err := ds.NewPacketArray()
+	require.NoError(t, err)
+	for _, p := range []testProcess{{"bash", 20}, {"cat", 5}, {"sshd", 10}} {
+		data := pa.New()
+		require.NoError(t, comm.PutString(data, p.comm))
+		require.NoError(t, pid.PutUint32(data, p.pid))
+		pa.Append(data)
+	}
+	require.NoError(t, tui.Update(ds, pa))
+	return tui, ds
+}
+
+func pressKeys(tui *TUI, keys string) {
+	for _, k := range parseKeys([]byte(keys)) {
+		tui.handleKey(k)
+	}
+}
+
+// cellsOf returns the values of the given column of the table, without styles
+func cellsOf(tui *TUI, idx int) []string {
+	var res []string
+	lines := tui.render(40, 10)
+	for _, line := range lines[2 : len(lines)-1] {
+		line = strings.NewReplacer(styleReverse, "", styleBold, "", styleReset, "").Replace(line)
+		fields := strings.Fields(line)
+		if len(fields) > idx {
+			res = append(res, fields[idx])
+		}
+	}
+	return res
+}
+
+func TestTUISort(t *testing.T) {
+	tui, _ := newTestTUI(t)
+
+	require.Equal(t, []string{"20", "5", "10"}, cellsOf(tui, 0))
+
+	pressKeys(tui, ">")
+	require.Equal(t, []string{"5", "10", "20"}, cellsOf(tui, 0))
+	require.Contains(t, tui.render(40, 10)[1], "pid▲")
+
+	pressKeys(tui, "r")
+	require.Equal(t, []string{"20", "10", "5"}, cellsOf(tui, 0))
+
+	pressKeys(tui, ">")
+	require.Equal(t, []string{"sshd", "cat", "bash"}, cellsOf(tui, 1))
+}
+
+func TestTUIFilter(t *testing.T) {
+	tui, _ := newTestTUI(t)
+
+	pressKeys(tui, "/pid > 6\r")
+	require.Equal(t, stateTable, tui.state)
+	require.Equal(t, []string{"20", "10"}, cellsOf(tui, 0))
+
+	// Invalid filters are reported and can be fixed
+	pressKeys(tui, "/\x7f\x7f\x7f\x7f\x7f\x7f\x7ffoo ==\r")
+	require.Equal(t, stateFilter, tui.state)
+	require.Contains(t, tui.message, "invalid filter")
+
+	pressKeys(tui, "\x1b")
+	require.Equal(t, stateTable, tui.state)
+	require.Equal(t, "pid > 6", tui.views[0].filter)
+}
+
+func TestTUIColumnsAndDetails(t *testing.T) {
+	tui, _ := newTestTUI(t)
+
+	// Hide pid and show args
+	pressKeys(tui, "cx\x1b[B\x1b[B \x1b")
+	require.Equal(t, stateTable, tui.state)
+	header := tui.render(40, 10)[1]
+	require.NotContains(t, header, "pid")
+	require.Contains(t, header, "comm")
+	require.Contains(t, header, "args")
+
+	pressKeys(tui, "\x1b[B\r")
+	require.Equal(t, stateDetails, tui.state)
+	require.Equal(t, []string{"pid   5", "comm  cat", "args  "}, tui.details)
+}
+
+func TestTUIPause(t *testing.T) {
+	tui, ds := newTestTUI(t)
+
+	pressKeys(tui, "p")
+	pa, err := ds.NewPacketArray()
+	require.NoError(t, err)
+	require.NoError(t, tui.Update(ds, pa))
+	require.Len(t, tui.views[0].rows, 3)
+
+	pressKeys(tui, "p")
+	require.NoError(t, tui.Update(ds, pa))
+	require.Empty(t, tui.views[0].rows)
+}
+
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestTUIStartStop(t *testing.T) {
+	r, w := io.Pipe()
+	defer w.Close()
+	out := &syncBuffer{}
+	quit := make(chan struct{})
+
+	tui := New(r, out,
+		WithSize(func() (int, int, error) { return 40, 10, nil }),
+		WithQuitFunc(func() { close(quit) }),
+	)
+	ds, err := datasource.New(datasource.TypeSingle, "events")
+	require.NoError(t, err)
+	_, err = ds.AddField("comm", api.Kind_String)
+	require.NoError(t, err)
+	require.NoError(t, tui.AddDataSource(ds, []string{"comm"}, []string{"comm"}))
+
+	require.NoError(t, tui.Start())
+	require.Eventually(t, func() bool {
+		return strings.Contains(out.String(), "[events]")
+	}, time.Second, 10*time.Millisecond)
+
+	_, err = w.Write([]byte("q"))
+	require.NoError(t, err)
+	select {
+	case <-quit:
+	case <-time.After(time.Second):
+		t.Fatal("quit function not called")
+	}
+
+	tui.Stop()
+	require.True(t, strings.HasSuffix(out.String(), "\033[?25h\033[?1049l"))
+}
@@ -0,0 +1,274 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tui
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/expr-lang/expr/vm"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource/expr"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+	sortoperator "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/sort"
+)
+
+type column struct {
+	name       string
+	acc        datasource.FieldAccessor
+	str        func(datasource.Data) string
+	alignRight bool
+	sortable   bool
+	visible    bool
+}
+
+// view holds the latest data of a data source, along with how the user wants to see it
+type view struct {
+	ds        datasource.DataSource
+	columns   []*column
+	maxEvents int
+
+	// packets are copies of the packets emitted by the data source: the latest array for array
+	// data sources or the latest events for the other ones
+	packets []datasource.Packet
+	rows    []datasource.Data
+	shown   []datasource.Data
+
+	sortColumn *column
+	sortDesc   bool
+	less       func(i, j datasource.Data) bool
+
+	filter     string
+	filterProg *vm.Program
+
+	selected int
+	offset   int
+}
+
+func isNumeric(kind api.Kind) bool {
+	switch kind {
+	case api.Kind_Int8, api.Kind_Int16, api.Kind_Int32, api.Kind_Int64,
+		api.Kind_Uint8, api.Kind_Uint16, api.Kind_Uint32, api.Kind_Uint64,
+		api.Kind_Float32, api.Kind_Float64:
+		return true
+	}
+	return false
+}
+
+// newView creates a view showing the visible fields of ds, in that order; the other fields can
+// be toggled on later.
+func newView(ds datasource.DataSource, fields []string, visible []string, maxEvents int) (*view, error) {
+	v := &view{
+		ds:        ds,
+		maxEvents: maxEvents,
+	}
+
+	names := slices.Clone(visible)
+	for _, name := range fields {
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+
+	for _, name := range names {
+		acc := ds.GetField(name)
+		if acc == nil {
+			return nil, fmt.Errorf("field %q not found", name)
+		}
+		str, err := datasource.AsString(acc)
+		if err != nil {
+			return nil, fmt.Errorf("field %q: %w", name, err)
+		}
+		v.columns = append(v.columns, &column{
+			name:       name,
+			acc:        acc,
+			str:        str,
+			alignRight: isNumeric(acc.Type()),
+			sortable:   sortoperator.IsSortable(acc),
+			visible:    slices.Contains(visible, name),
+		})
+	}
+	return v, nil
+}
+
+func (v *view) visibleColumns() []*column {
+	res := make([]*column, 0, len(v.columns))
+	for _, c := range v.columns {
+		if c.visible {
+			res = append(res, c)
+		}
+	}
+	return res
+}
+
+// update stores a copy of p, as packets can't be accessed once the subscription returns
+func (v *view) update(p datasource.Packet) error {
+	b, err := proto.Marshal(p.Raw())
+	if err != nil {
+		return fmt.Errorf("copying packet: %w", err)
+	}
+
+	switch v.ds.Type() {
+	case datasource.TypeArray:
+		pa, err := v.ds.NewPacketArrayFromRaw(b)
+		if err != nil {
+			return fmt.Errorf("copying packet: %w", err)
+		}
+		for _, old := range v.packets {
+			v.ds.Release(old)
+		}
+		v.packets = []datasource.Packet{pa}
+		v.rows = make([]datasource.Data, 0, pa.Len())
+		for i := range pa.Len() {
+			v.rows = append(v.rows, pa.Get(i))
+		}
+	case datasource.TypeSingle:
+		ps, err := v.ds.NewPacketSingleFromRaw(b)
+		if err != nil {
+			return fmt.Errorf("copying packet: %w", err)
+		}
+		v.packets = append(v.packets, ps)
+		v.rows = append(v.rows, ps)
+		if len(v.packets) > v.maxEvents {
+			v.ds.Release(v.packets[0])
+			v.packets = v.packets[1:]
+			v.rows = v.rows[1:]
+		}
+	}
+
+	v.refresh()
+	return nil
+}
+
+// refresh applies the filter and the sorting to the rows
+func (v *view) refresh() {
+	following := len(v.shown) > 0 && v.selected == len(v.shown)-1
+
+	v.shown = v.shown[:0]
+	for _, row := range v.rows {
+		if v.filterProg != nil {
+			ret, err := expr.Run(v.filterProg, row)
+			if err != nil {
+				continue
+			}
+			if match, ok := ret.(bool); !ok || !match {
+				continue
+			}
+		}
+		v.shown = append(v.shown, row)
+	}
+	if v.less != nil {
+		slices.SortStableFunc(v.shown, func(a, b datasource.Data) int {
+			if v.less(a, b) {
+				return -1
+			}
+			if v.less(b, a) {
+				return 1
+			}
+			return 0
+		})
+	}
+
+	// Keep showing the latest events if they were followed
+	if following && v.ds.Type() == datasource.TypeSingle {
+		v.selected = len(v.shown) - 1
+	}
+	v.selected = max(min(v.selected, len(v.shown)-1), 0)
+}
+
+func (v *view) setSort(c *column, desc bool) error {
+	if c == nil {
+		v.sortColumn = nil
+		v.less = nil
+		v.refresh()
+		return nil
+	}
+	field := c.name
+	if desc {
+		field = "-" + field
+	}
+	less, err := sortoperator.NewLessFunc(v.ds, []string{field})
+	if err != nil {
+		return err
+	}
+	v.sortColumn = c
+	v.sortDesc = desc
+	v.less = less
+	v.refresh()
+	return nil
+}
+
+// moveSort sorts by the next (or previous) visible column that can be sorted
+func (v *view) moveSort(delta int) error {
+	var sortable []*column
+	for _, c := range v.visibleColumns() {
+		if c.sortable {
+			sortable = append(sortable, c)
+		}
+	}
+	if len(sortable) == 0 {
+		return fmt.Errorf("no column can be sorted")
+	}
+
+	idx := slices.Index(sortable, v.sortColumn)
+	switch {
+	case idx == -1 && delta > 0:
+		idx = 0
+	case idx == -1:
+		idx = len(sortable) - 1
+	default:
+		idx = (idx + delta + len(sortable)) % len(sortable)
+	}
+	return v.setSort(sortable[idx], v.sortDesc)
+}
+
+func (v *view) setFilter(filter string) error {
+	if filter == "" {
+		v.filter = ""
+		v.filterProg = nil
+		v.refresh()
+		return nil
+	}
+	prog, err := expr.CompileFilterProgram(v.ds, filter)
+	if err != nil {
+		return err
+	}
+	v.filter = filter
+	v.filterProg = prog
+	v.refresh()
+	return nil
+}
+
+func (v *view) selectedRow() datasource.Data {
+	if v.selected < 0 || v.selected >= len(v.shown) {
+		return nil
+	}
+	return v.shown[v.selected]
+}
+
+// details returns all the fields of data, one per line
+func (v *view) details(data datasource.Data) []string {
+	width := 0
+	for _, c := range v.columns {
+		width = max(width, len(c.name))
+	}
+	res := make([]string, 0, len(v.columns))
+	for _, c := range v.columns {
+		res = append(res, fmt.Sprintf("%-*s  %s", width, c.name, c.str(data)))
+	}
+	return res
+}
@@ -17,29 +17,33 @@ package ebpfoperator
 import (
 	"fmt"
 	"net"
-	"runtime"
-	"strconv"
 	"strings"
 
 	"github.com/cilium/ebpf"
 	"github.com/cilium/ebpf/link"
-	"golang.org/x/sys/unix"
 
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/kallsyms"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/uprobetracer"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/xdphandler"
 )
 
 const (
-	kprobePrefix    = "kprobe/"
-	kretprobePrefix = "kretprobe/"
-	iterPrefix      = "iter/"
-	fentryPrefix    = "fentry/"
-	fexitPrefix     = "fexit/"
-	perfEventPrefix = "perf_event/"
-	tpBtfPrefix     = "tp_btf/"
-	uprobePrefix    = "uprobe/"
-	uretprobePrefix = "uretprobe/"
-	usdtPrefix      = "usdt/"
+	kprobePrefix         = "kprobe/"
+	kretprobePrefix      = "kretprobe/"
+	kprobeMultiPrefix    = "kprobe.multi/"
+	kretprobeMultiPrefix = "kretprobe.multi/"
+	iterPrefix           = "iter/"
+	fentryPrefix         = "fentry/"
+	fexitPrefix          = "fexit/"
+	fmodRetPrefix        = "fmod_ret/"
+	perfEventPrefix      = "perf_event/"
+	tpBtfPrefix          = "tp_btf/"
+	uprobePrefix         = "uprobe/"
+	uretprobePrefix      = "uretprobe/"
+	uprobeMultiPrefix    = "uprobe.multi/"
+	uretprobeMultiPrefix = "uretprobe.multi/"
+	usdtPrefix           = "usdt/"
 )
 
 const (
@@ -68,8 +72,23 @@ func (i *ebpfInstance) attachProgram(gadgetCtx operators.GadgetContext, p *ebpf.
 		case strings.HasPrefix(p.SectionName, kretprobePrefix):
 			i.logger.Debugf("Attaching kretprobe %q to %q", p.Name, attachTo)
 			return link.Kretprobe(attachTo, prog, nil)
+		case strings.HasPrefix(p.SectionName, kprobeMultiPrefix) ||
+			strings.HasPrefix(p.SectionName, kretprobeMultiPrefix):
+			symbols, err := kallsyms.MatchTraceableFunctions(attachTo)
+			if err != nil {
+				return nil, fmt.Errorf("resolving symbols for program %q: %w", p.Name, err)
+			}
+			opts := link.KprobeMultiOptions{Symbols: symbols}
+			if strings.HasPrefix(p.SectionName, kretprobeMultiPrefix) {
+				i.logger.Debugf("Attaching kretprobe.multi %q to %d functions matching %q", p.Name, len(symbols), attachTo)
+				return link.KretprobeMulti(prog, opts)
+			}
+			i.logger.Debugf("Attaching kprobe.multi %q to %d functions matching %q", p.Name, len(symbols), attachTo)
+			return link.KprobeMulti(prog, opts)
 		case strings.HasPrefix(p.SectionName, uprobePrefix) ||
 			strings.HasPrefix(p.SectionName, uretprobePrefix) ||
+			strings.HasPrefix(p.SectionName, uprobeMultiPrefix) ||
+			strings.HasPrefix(p.SectionName, uretprobeMultiPrefix) ||
 			strings.HasPrefix(p.SectionName, usdtPrefix):
 			uprobeTracer := i.uprobeTracers[p.Name]
 			switch strings.Split(p.SectionName, "/")[0] {
@@ -77,6 +96,10 @@ func (i *ebpfInstance) attachProgram(gadgetCtx operators.GadgetContext, p *ebpf.
 				return nil, uprobeTracer.AttachProg(p.Name, uprobetracer.ProgUprobe, attachTo, prog)
 			case "uretprobe":
 				return nil, uprobeTracer.AttachProg(p.Name, uprobetracer.ProgUretprobe, attachTo, prog)
+			case "uprobe.multi":
+				return nil, uprobeTracer.AttachProg(p.Name, uprobetracer.ProgUprobeMulti, attachTo, prog)
+			case "uretprobe.multi":
+				return nil, uprobeTracer.AttachProg(p.Name, uprobetracer.ProgUretprobeMulti, attachTo, prog)
 			case "usdt":
 				return nil, uprobeTracer.AttachProg(p.Name, uprobetracer.ProgUSDT, attachTo, prog)
 			}
@@ -95,10 +118,15 @@ func (i *ebpfInstance) attachProgram(gadgetCtx operators.GadgetContext, p *ebpf.
 		case strings.HasPrefix(p.SectionName, iterPrefix):
 			i.logger.Debugf("Attaching iter %q to %q", p.Name, attachTo)
 			switch attachTo {
-			case "task", "task_file", "tcp", "udp", "ksym":
+			case "task", "task_file", "tcp", "udp", "ksym",
+				"bpf_map", "bpf_prog", "bpf_link", "unix", "netlink", "ipv6_route":
 				return link.AttachIter(link.IterOptions{
 					Program: prog,
 				})
+			case "cgroup":
+				// cgroup iterators are attached to the cgroup of each container when
+				// they are run
+				return nil, nil
 			}
 			return nil, fmt.Errorf("unsupported iter type %q", attachTo)
 		case strings.HasPrefix(p.SectionName, fentryPrefix):
@@ -113,6 +141,12 @@ func (i *ebpfInstance) attachProgram(gadgetCtx operators.GadgetContext, p *ebpf.
 				Program:    prog,
 				AttachType: ebpf.AttachTraceFExit,
 			})
+		case strings.HasPrefix(p.SectionName, fmodRetPrefix):
+			i.logger.Debugf("Attaching fmod_ret %q to %q", p.Name, attachTo)
+			return link.AttachTracing(link.TracingOptions{
+				Program:    prog,
+				AttachType: ebpf.AttachModifyReturn,
+			})
 		case strings.HasPrefix(p.SectionName, tpBtfPrefix):
 			i.logger.Debugf("Attaching tp_btf %q to %q", p.Name, p.AttachTo)
 			return link.AttachTracing(link.TracingOptions{
@@ -144,86 +178,52 @@ func (i *ebpfInstance) attachProgram(gadgetCtx operators.GadgetContext, p *ebpf.
 
 		i.logger.Debugf("Attaching sched_cls %q", p.Name)
 		return nil, handler.AttachProg(prog)
+	case ebpf.CGroupSKB, ebpf.CGroupSock, ebpf.CGroupSockAddr:
+		i.logger.Debugf("Attaching cgroup program %q (%s)", p.Name, p.AttachType)
+		return nil, i.linkHandlers[p.Name].AttachProg(prog)
+	case ebpf.SkLookup:
+		i.logger.Debugf("Attaching sk_lookup %q", p.Name)
+		return nil, i.linkHandlers[p.Name].AttachProg(prog)
+	case ebpf.XDP:
+		handler := i.xdpHandlers[p.Name]
+
+		flags, err := xdphandler.ParseMode(i.paramValues[ParamXDPMode])
+		if err != nil {
+			return nil, err
+		}
+
+		ifaceName := i.paramValues[ParamIface]
+		if ifaceName != "" {
+			iface, err := net.InterfaceByName(ifaceName)
+			if err != nil {
+				return nil, fmt.Errorf("getting interface %q: %w", ifaceName, err)
+			}
+
+			if err := handler.AttachIface(iface); err != nil {
+				return nil, fmt.Errorf("attaching iface %q: %w", ifaceName, err)
+			}
+		}
+
+		i.logger.Debugf("Attaching xdp %q", p.Name)
+		return nil, handler.AttachProg(prog, flags)
 	case ebpf.LSM:
 		i.logger.Debugf("Attaching LSM %q to %q", p.Name, attachTo)
 		return link.AttachLSM(link.LSMOptions{
 			Program: prog,
 		})
 	case ebpf.PerfEvent:
-		perfType := uint32(unix.PERF_TYPE_SOFTWARE)
-		perfConfig := uint64(unix.PERF_COUNT_SW_CPU_CLOCK)
-		perfSampleType := uint64(unix.PERF_SAMPLE_RAW)
-		frequency := uint64(0)
-		name, ok := strings.CutPrefix(p.SectionName, perfEventPrefix)
-		if !ok {
-			return nil, fmt.Errorf("perf_event programs require a name")
-		}
-
-		switch tmp := i.config.GetString("programs." + name + ".perf.type"); tmp {
-		case "":
-			return nil, fmt.Errorf("perf.type not specified for program %q", name)
-		case "software":
-			perfType = unix.PERF_TYPE_SOFTWARE
-		default:
-			return nil, fmt.Errorf("unsupported perf.type %q", tmp)
-		}
-		switch tmp := i.config.GetString("programs." + name + ".perf.config"); tmp {
-		case "":
-			return nil, fmt.Errorf("perf.config not specified for program %q", name)
-		case "count_sw_cpu_clock":
-			perfConfig = unix.PERF_COUNT_SW_CPU_CLOCK
-		default:
-			return nil, fmt.Errorf("unsupported perf.config %q", tmp)
-		}
-		switch tmp := i.config.GetString("programs." + name + ".perf.sampleType"); tmp {
-		case "":
-			return nil, fmt.Errorf("perf.sampleType not specified for program %q", name)
-		case "sample_raw":
-			perfSampleType = unix.PERF_SAMPLE_RAW
-		default:
-			return nil, fmt.Errorf("unsupported perf.sampleType %q", tmp)
-		}
-		if tmpFrequency := i.config.GetString("programs." + name + ".sampler.frequency"); tmpFrequency != "" {
-			var err error
-			frequency, err = strconv.ParseUint(tmpFrequency, 10, 64)
-			if err != nil {
-				return nil, fmt.Errorf("parsing frequency %q for program %q: %w", tmpFrequency, name, err)
-			}
-		} else {
-			return nil, fmt.Errorf("sampler.frequency not specified for program %q", name)
+		cfg := i.perfEventConfigs[p.Name]
+		if cfg.scope == perfScopeCgroup {
+			i.logger.Debugf("Attaching perf event %q to containers' cgroups", p.Name)
+			return nil, i.linkHandlers[p.Name].AttachProg(prog)
 		}
-		if frequency == 0 {
-			return nil, fmt.Errorf("sampler.frequency is zero for program %q", name)
-		}
-		for cpu := 0; cpu < runtime.NumCPU(); cpu++ {
-			fd, err := unix.PerfEventOpen(
-				&unix.PerfEventAttr{
-					Type:        perfType,
-					Config:      perfConfig,
-					Sample_type: perfSampleType,
-					Sample:      frequency,
-					Bits:        unix.PerfBitFreq,
-				},
-				-1,
-				cpu,
-				-1,
-				unix.PERF_FLAG_FD_CLOEXEC,
-			)
-			if err != nil {
-				return nil, fmt.Errorf("opening perf event: %w", err)
-			}
-			i.perfFds = append(i.perfFds, fd)
-
-			// Attach program to perf event.
-			if err := unix.IoctlSetInt(fd, unix.PERF_EVENT_IOC_SET_BPF, prog.FD()); err != nil {
-				return nil, fmt.Errorf("attaching eBPF program to perf fd: %w", err)
-			}
 
-			// Start perf event.
-			if err := unix.IoctlSetInt(fd, unix.PERF_EVENT_IOC_ENABLE, 0); err != nil {
-				return nil, fmt.Errorf("enabling perf fd: %w", err)
-			}
+		i.logger.Debugf("Attaching perf event %q", p.Name)
+		fds, err := openPerfEvents(&cfg.attr, -1, 0, prog)
+		if err != nil {
+			return nil, err
 		}
+		i.perfFds = append(i.perfFds, fds...)
 		return nil, nil
 	default:
 		return nil, fmt.Errorf("unsupported program %q of type %q", p.Name, p.Type)
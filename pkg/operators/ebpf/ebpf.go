@@ -41,13 +41,16 @@ import (
 	"oras.land/oras-go/v2"
 
 	"github.com/inspektor-gadget/inspektor-gadget/internal/version"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/bpfpin"
 	containercollection "github.com/inspektor-gadget/inspektor-gadget/pkg/container-collection"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
 	apihelpers "github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api-helpers"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadgets"
 	libpcap_compiler "github.com/inspektor-gadget/inspektor-gadget/pkg/libpcap-compiler"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/linkhandler"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/logger"
+	metadatav1 "github.com/inspektor-gadget/inspektor-gadget/pkg/metadata/v1"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/networktracer"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/oci"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators"
@@ -58,6 +61,7 @@ import (
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/tchandler"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/uprobetracer"
 	ebpfutils "github.com/inspektor-gadget/inspektor-gadget/pkg/utils/ebpf"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/xdphandler"
 )
 
 const (
@@ -66,11 +70,15 @@ const (
 	typeSplitter = "___"
 
 	ParamIface       = "iface"
+	ParamXDPMode     = "xdp-mode"
 	ParamTraceKernel = "trace-pipe"
+	ParamPin         = "pin"
 
 	kernelTypesVar = "kernelTypes"
 
 	AnnotationMapFlushOnStop  = "ebpf.map.flush-on-stop"
+	AnnotationMapMode         = "ebpf.map.mode"
+	AnnotationMapPerCPU       = "ebpf.map.percpu"
 	AnnotationIterFetchOnStop = "ebpf.iter.fetch-on-stop"
 	AnnotationRestName        = "ebpf.rest.name"
 	AnnotationRestLen         = "ebpf.rest.len"
@@ -123,6 +131,7 @@ func (o *ebpfOperator) InstantiateImageOperator(
 
 		logger:  gadgetCtx.Logger(),
 		program: program,
+		digest:  desc.Digest.String(),
 
 		// Preallocate maps
 		tracers:   make(map[string]*Tracer),
@@ -141,6 +150,10 @@ func (o *ebpfOperator) InstantiateImageOperator(
 		networkTracers: make(map[string]*networktracer.Tracer[api.GadgetData]),
 		tcHandlers:     make(map[string]*tchandler.Handler),
 		uprobeTracers:  make(map[string]*uprobetracer.Tracer[api.GadgetData]),
+		linkHandlers:   make(map[string]*linkhandler.Handler),
+		xdpHandlers:    make(map[string]*xdphandler.Handler),
+
+		perfEventConfigs: make(map[string]*perfEventConfig),
 
 		paramValues: paramValues,
 	}
@@ -173,6 +186,7 @@ type ebpfInstance struct {
 	config *viper.Viper
 
 	program        []byte
+	digest         string
 	logger         logger.Logger
 	collectionSpec *ebpf.CollectionSpec
 	collection     *ebpf.Collection
@@ -187,12 +201,20 @@ type ebpfInstance struct {
 	networkTracers map[string]*networktracer.Tracer[api.GadgetData]
 	tcHandlers     map[string]*tchandler.Handler
 	uprobeTracers  map[string]*uprobetracer.Tracer[api.GadgetData]
+	linkHandlers   map[string]*linkhandler.Handler
+	xdpHandlers    map[string]*xdphandler.Handler
 
 	// map from ebpf variable name to ebpfVar struct
 	vars map[string]*ebpfVar
 
-	links   []link.Link
-	perfFds []int
+	links            []link.Link
+	perfFds          []int
+	perfEventConfigs map[string]*perfEventConfig
+
+	// pinDir is set if the objects of the instance are pinned, pinReuse if the objects pinned by
+	// a previous run can be taken over
+	pinDir   *bpfpin.Dir
+	pinReuse bool
 
 	containers map[string]*containercollection.Container
 
@@ -249,6 +271,11 @@ func (i *ebpfInstance) analyze(gadgetCtx operators.GadgetContext, paramValues ap
 			validator:    i.validateGlobalConstVoidPtrVar,
 			populateFunc: i.populateParam,
 		},
+		{
+			prefixFunc:   hasPrefix(paramListPrefix),
+			validator:    i.validateGlobalConstVoidPtrVar,
+			populateFunc: i.populateParamList,
+		},
 		{
 			prefixFunc:   hasPrefix(tracerMapPrefix),
 			validator:    i.validateGlobalConstVoidPtrVar,
@@ -377,6 +404,8 @@ func (i *ebpfInstance) init(gadgetCtx operators.GadgetContext) error {
 		case ebpf.Kprobe:
 			if strings.HasPrefix(p.SectionName, "uprobe/") ||
 				strings.HasPrefix(p.SectionName, "uretprobe/") ||
+				strings.HasPrefix(p.SectionName, "uprobe.multi/") ||
+				strings.HasPrefix(p.SectionName, "uretprobe.multi/") ||
 				strings.HasPrefix(p.SectionName, "usdt/") {
 				uprobeTracer, err := uprobetracer.NewTracer[api.GadgetData](gadgetCtx.Logger())
 				if err != nil {
@@ -392,6 +421,30 @@ func (i *ebpfInstance) init(gadgetCtx operators.GadgetContext) error {
 				}
 				i.networkTracers[p.Name] = networkTracer
 			}
+		case ebpf.CGroupSKB, ebpf.CGroupSock, ebpf.CGroupSockAddr:
+			// cgroup programs are attached to the cgroup of each container
+			i.linkHandlers[p.Name] = linkhandler.NewCgroupHandler(p.AttachType)
+		case ebpf.SkLookup:
+			// sk_lookup programs are attached to the netns of each container
+			i.linkHandlers[p.Name] = linkhandler.NewNetnsHandler()
+		case ebpf.XDP:
+			i.xdpHandlers[p.Name] = xdphandler.NewHandler()
+		case ebpf.PerfEvent:
+			name, ok := strings.CutPrefix(p.SectionName, perfEventPrefix)
+			if !ok {
+				return fmt.Errorf("perf_event programs require a name")
+			}
+			cfg, err := parsePerfEventConfig(i.config, name)
+			if err != nil {
+				return fmt.Errorf("parsing perf event configuration: %w", err)
+			}
+			i.perfEventConfigs[p.Name] = cfg
+			if cfg.scope == perfScopeCgroup {
+				i.linkHandlers[p.Name] = linkhandler.NewCgroupHandlerWithFunc(
+					func(cgroupPath string, prog *ebpf.Program) (io.Closer, error) {
+						return openCgroupPerfEvents(&cfg.attr, cgroupPath, prog)
+					})
+			}
 		case ebpf.SchedCLS:
 			parts := strings.Split(p.SectionName, "/")
 			if len(parts) != 3 {
@@ -443,7 +496,13 @@ func (i *ebpfInstance) init(gadgetCtx operators.GadgetContext) error {
 					return fmt.Errorf("unsupported hook type %q", parts[1])
 				}
 
-				handler, err := tchandler.NewHandler(direction)
+				anchor, err := tchandler.ParseOrder(i.config.GetString("programs." + p.Name + ".order"))
+				if err != nil {
+					return fmt.Errorf("parsing order of program %q: %w", p.Name, err)
+				}
+
+				handler, err := tchandler.NewHandler(direction, tchandler.WithAnchor(anchor),
+					tchandler.WithInstanceID(gadgetCtx.ID()))
 				if err != nil {
 					return fmt.Errorf("creating tc network tracer: %w", err)
 				}
@@ -453,7 +512,11 @@ func (i *ebpfInstance) init(gadgetCtx operators.GadgetContext) error {
 		}
 	}
 
-	if len(i.tcHandlers) > 0 {
+	if len(i.linkHandlers) > 0 {
+		gadgetCtx.SetVar("NeedContainerEvents", true)
+	}
+
+	if len(i.tcHandlers) > 0 || len(i.xdpHandlers) > 0 {
 		// For now, override enrichment
 		gadgetCtx.SetVar("NeedContainerEvents", true)
 		i.params["iface"] = &param{
@@ -464,6 +527,17 @@ func (i *ebpfInstance) init(gadgetCtx operators.GadgetContext) error {
 		}
 	}
 
+	if len(i.xdpHandlers) > 0 {
+		i.params[ParamXDPMode] = &param{
+			Param: &api.Param{
+				Key:            ParamXDPMode,
+				Description:    "Mode used to attach XDP programs",
+				DefaultValue:   xdphandler.ModeAuto,
+				PossibleValues: []string{xdphandler.ModeAuto, xdphandler.ModeGeneric, xdphandler.ModeNative},
+			},
+		}
+	}
+
 	i.params[ParamTraceKernel] = &param{
 		Param: &api.Param{
 			Key:          ParamTraceKernel,
@@ -475,6 +549,26 @@ func (i *ebpfInstance) init(gadgetCtx operators.GadgetContext) error {
 		},
 	}
 
+	for _, t := range i.tracers {
+		if t.sampling {
+			for _, p := range samplingParams() {
+				i.params[p.Key] = p
+			}
+			break
+		}
+	}
+
+	i.params[ParamPin] = &param{
+		Param: &api.Param{
+			Key:          ParamPin,
+			Title:        "Pin eBPF Objects",
+			Description:  "Pin links and maps of a gadget instance so they survive restarts of the daemon",
+			DefaultValue: "false",
+			TypeHint:     api.TypeBool,
+			Tags:         []string{api.TagAdvanced, "group:eBPF"},
+		},
+	}
+
 	for name, m := range i.collectionSpec.Maps {
 		gadgetCtx.SetVar(operators.MapSpecPrefix+name, m)
 	}
@@ -527,6 +621,16 @@ func (i *ebpfInstance) register(gadgetCtx operators.GadgetContext) error {
 				}
 			}
 		}
+		if m.sampling {
+			m.sampleRateAccessor, err = ds.AddField(SampleRateField, api.Kind_Uint32,
+				datasource.WithFlags(datasource.FieldFlagHidden),
+				datasource.WithAnnotations(map[string]string{
+					metadatav1.DescriptionAnnotation: "Only 1 out of sample_rate events was sent to userspace",
+				}))
+			if err != nil {
+				return fmt.Errorf("adding sample rate field: %w", err)
+			}
+		}
 		m.ds = ds
 	}
 	for name, m := range i.iterators {
@@ -583,22 +687,43 @@ func (i *ebpfInstance) register(gadgetCtx operators.GadgetContext) error {
 		}
 		m.keyAccessor = accessor
 
-		staticFields = make([]datasource.StaticField, 0, len(fields))
-		for _, field := range i.structs[m.valStructName].Fields {
-			staticFields = append(staticFields, field)
-		}
-		accessor, err = ds.AddStaticFields(i.structs[m.valStructName].Size, staticFields)
-		if err != nil {
-			return fmt.Errorf("adding fields for datasource: %w", err)
-		}
-		m.valAccessor = accessor
-
 		annotations := ds.Annotations()
 		if flushOnStop := annotations[AnnotationMapFlushOnStop]; flushOnStop == "true" {
 			i.logger.Debugf("flushing on stop enabled")
 			m.flushOnStop = true
 		}
 
+		valFields := i.structs[m.valStructName].Fields
+		if err := m.configure(annotations, i.collectionSpec.Maps[m.mapName].Type, valFields); err != nil {
+			return fmt.Errorf("configuring map iterator %q: %w", name, err)
+		}
+
+		if m.perCPU == mapIterPerCPUArray {
+			// Each value field holds the values of all CPUs
+			for _, field := range valFields {
+				accessor, err := ds.AddField(field.name, api.Kind_List,
+					datasource.WithElementKind(field.kind),
+					datasource.WithTags(field.Tags...),
+					datasource.WithAnnotations(field.Annotations),
+				)
+				if err != nil {
+					return fmt.Errorf("adding field %q for datasource: %w", field.name, err)
+				}
+				m.valListAccessors = append(m.valListAccessors, accessor)
+			}
+			m.valFields = valFields
+		} else {
+			staticFields = make([]datasource.StaticField, 0, len(fields))
+			for _, field := range valFields {
+				staticFields = append(staticFields, field)
+			}
+			accessor, err = ds.AddStaticFields(i.structs[m.valStructName].Size, staticFields)
+			if err != nil {
+				return fmt.Errorf("adding fields for datasource: %w", err)
+			}
+			m.valAccessor = accessor
+		}
+
 		m.ds = ds
 	}
 	return nil
@@ -710,6 +835,17 @@ func (i *ebpfInstance) Start(gadgetCtx operators.GadgetContext) error {
 		}
 	}
 
+	if paramMap[ParamPin].AsBool() {
+		// Only gadget instances are recreated after a restart
+		if gadgetCtx.ID() == "" {
+			return fmt.Errorf("%s is only supported for gadget instances", ParamPin)
+		}
+		i.pinDir, i.pinReuse, err = bpfpin.Open(gadgetCtx.ID(), i.digest)
+		if err != nil {
+			return fmt.Errorf("opening pin directory: %w", err)
+		}
+	}
+
 	mapReplacements := make(map[string]*ebpf.Map)
 
 	// Set gadget params
@@ -755,6 +891,14 @@ func (i *ebpfInstance) Start(gadgetCtx operators.GadgetContext) error {
 		}
 	}
 
+	if err := i.setParamListVars(paramMap); err != nil {
+		return err
+	}
+
+	if err := i.setSampleRates(paramMap); err != nil {
+		return err
+	}
+
 	opts := ebpf.CollectionOptions{
 		MapReplacements: mapReplacements,
 	}
@@ -851,7 +995,18 @@ func (i *ebpfInstance) Start(gadgetCtx operators.GadgetContext) error {
 		}
 		opts.Programs.KernelTypes = btfSpec
 	}
+	if i.pinDir != nil {
+		i.setMapPinning(&opts)
+	}
 	collection, err := ebpf.NewCollectionWithOptions(i.collectionSpec, opts)
+	if i.pinDir != nil && errors.Is(err, ebpf.ErrMapIncompatible) {
+		// The maps were pinned with a different configuration, e.g. another max_entries
+		i.logger.Warnf("pinned maps are incompatible, recreating them: %v", err)
+		if err := i.pinDir.ResetMaps(); err != nil {
+			return fmt.Errorf("removing pinned maps: %w", err)
+		}
+		collection, err = ebpf.NewCollectionWithOptions(i.collectionSpec, opts)
+	}
 	if err != nil {
 		var verifierErr *ebpf.VerifierError
 		if errors.As(err, &verifierErr) {
@@ -862,6 +1017,10 @@ func (i *ebpfInstance) Start(gadgetCtx operators.GadgetContext) error {
 	}
 	i.collection = collection
 
+	if err := i.fillParamListMaps(paramMap); err != nil {
+		return err
+	}
+
 	if otelEbpfProgramI, ok := gadgetCtx.GetVar(symbolizer.OtelEbpfProgramKprobe); ok {
 		otelEbpfProgram, ok := otelEbpfProgramI.(*ebpf.Program)
 		if !ok {
@@ -944,31 +1103,46 @@ func (i *ebpfInstance) Start(gadgetCtx operators.GadgetContext) error {
 
 	// Attach programs
 	for progName, p := range i.collectionSpec.Programs {
-		l, err := i.attachProgram(gadgetCtx, p, i.collection.Programs[progName])
+		isIter := p.Type == ebpf.Tracing && strings.HasPrefix(p.SectionName, iterPrefix)
+
+		var l link.Link
+		var err error
+		// Iterators run on demand, there is nothing to keep attached
+		if i.pinDir != nil && !isIter {
+			l, err = i.attachProgramPinned(gadgetCtx, progName, p, i.collection.Programs[progName])
+		} else {
+			l, err = i.attachProgram(gadgetCtx, p, i.collection.Programs[progName])
+		}
 		if err != nil {
 			return fmt.Errorf("attaching eBPF program %q: %w", progName, err)
 		}
 
-		if l == nil {
-			continue
+		if l != nil {
+			i.links = append(i.links, l)
 		}
 
-		i.links = append(i.links, l)
-
 		// We need to store iterators' links because we need them to run the programs
-		if p.Type == ebpf.Tracing && strings.HasPrefix(p.SectionName, iterPrefix) {
-			lIter, ok := l.(*link.Iter)
-			if !ok {
-				return fmt.Errorf("link is not an iterator")
+		if isIter {
+			lIter := &linkIterator{
+				typ: p.AttachTo,
+			}
+			if isIteratorKindPerCgroup(p.AttachTo) {
+				lIter.prog = i.collection.Programs[progName]
+			} else {
+				if l == nil {
+					continue
+				}
+				var ok bool
+				lIter.link, ok = l.(*link.Iter)
+				if !ok {
+					return fmt.Errorf("link is not an iterator")
+				}
 			}
 
 			found := false
 			for _, iter := range i.iterators {
 				if _, ok := iter.iterators[progName]; ok {
-					iter.links[progName] = &linkIterator{
-						link: lIter,
-						typ:  p.AttachTo,
-					}
+					iter.links[progName] = lIter
 					found = true
 					break
 				}
@@ -1045,6 +1219,12 @@ func (i *ebpfInstance) Close(gadgetCtx operators.GadgetContext) error {
 	for _, uprobeTracer := range i.uprobeTracers {
 		uprobeTracer.Close()
 	}
+	for _, handler := range i.linkHandlers {
+		handler.Close()
+	}
+	for _, handler := range i.xdpHandlers {
+		handler.Close()
+	}
 
 	i.bpfOperator.mu.Lock()
 	delete(i.bpfOperator.gadgetObjs, gadgetCtx)
@@ -1072,6 +1252,11 @@ func (i *ebpfInstance) AttachContainer(container *containercollection.Container)
 				return err
 			}
 		}
+		for _, handler := range i.xdpHandlers {
+			if err := handler.AttachContainer(container); err != nil {
+				return err
+			}
+		}
 	}
 
 	for _, handler := range i.uprobeTracers {
@@ -1080,6 +1265,12 @@ func (i *ebpfInstance) AttachContainer(container *containercollection.Container)
 		}
 	}
 
+	for _, handler := range i.linkHandlers {
+		if err := handler.AttachContainer(container); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -1100,6 +1291,11 @@ func (i *ebpfInstance) DetachContainer(container *containercollection.Container)
 				return err
 			}
 		}
+		for _, handler := range i.xdpHandlers {
+			if err := handler.DetachContainer(container); err != nil {
+				return err
+			}
+		}
 	}
 
 	for _, uTracer := range i.uprobeTracers {
@@ -1108,6 +1304,12 @@ func (i *ebpfInstance) DetachContainer(container *containercollection.Container)
 		}
 	}
 
+	for _, handler := range i.linkHandlers {
+		if err := handler.DetachContainer(container); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -18,14 +18,20 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"os"
+	"runtime"
+	"slices"
 	"strings"
 	"time"
+	"unsafe"
 
 	"github.com/cilium/ebpf"
 	"github.com/cilium/ebpf/btf"
 	"github.com/cilium/ebpf/link"
+	"golang.org/x/sys/unix"
 
+	containercollection "github.com/inspektor-gadget/inspektor-gadget/pkg/container-collection"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
 	bpfiterns "github.com/inspektor-gadget/inspektor-gadget/pkg/utils/bpf-iter-ns"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/utils/nsenter"
@@ -34,6 +40,9 @@ import (
 type linkIterator struct {
 	link *link.Iter
 	typ  string
+	// prog is used to create a link for each container in iterators that are attached to
+	// a specific object, like cgroup iterators. link is nil in that case.
+	prog *ebpf.Program
 }
 
 type Iterator struct {
@@ -146,69 +155,23 @@ func (i *ebpfInstance) runIterators() error {
 				if !isIteratorKindSupported(l.typ) {
 					return fmt.Errorf("iterator kind %q is not supported", l.typ)
 				}
-				if !isIteratorKindPerNetNs(l.typ) {
-					buf, err := bpfiterns.Read(l.link)
+
+				var err error
+				switch {
+				case isIteratorKindPerNetNs(l.typ):
+					err = i.readIteratorPerNetNs(iter, pArray, pName, l)
+				case isIteratorKindPerCgroup(l.typ):
+					err = i.readIteratorPerCgroup(iter, pArray, pName, l)
+				default:
+					var buf []byte
+					buf, err = bpfiterns.Read(l.link)
 					if err != nil {
 						return fmt.Errorf("reading iterator %q: %w", pName, err)
 					}
-
-					size := iter.accessor.Size()
-					if uint32(len(buf))%size != 0 {
-						return fmt.Errorf("iter %q returned an invalid buffer's size %d, expected multiple of %d",
-							pName, len(buf), size)
-					}
-
-					for i := uint32(0); i < uint32(len(buf)); i += size {
-						data := pArray.New()
-						if err := iter.accessor.Set(data, buf[i:i+size]); err != nil {
-							pArray.Release(data)
-							return fmt.Errorf("setting data element %d: %w", i, err)
-						}
-						pArray.Append(data)
-					}
-				} else {
-					visitedNetNs := make(map[uint64]struct{})
-					for _, container := range i.containers {
-						_, visited := visitedNetNs[container.Netns]
-						if visited {
-							continue
-						}
-						visitedNetNs[container.Netns] = struct{}{}
-
-						err := nsenter.NetnsEnter(int(container.ContainerPid()), func() error {
-							reader, err := l.link.Open()
-							if err != nil {
-								return err
-							}
-							defer reader.Close()
-
-							buf, err := io.ReadAll(reader)
-							if err != nil {
-								return fmt.Errorf("reading iterator %q: %w", pName, err)
-							}
-
-							size := iter.accessor.Size()
-							if uint32(len(buf))%size != 0 {
-								return fmt.Errorf("iter %q returned an invalid buffer's size %d, expected multiple of %d",
-									pName, len(buf), size)
-							}
-
-							for i := uint32(0); i < uint32(len(buf)); i += size {
-								data := pArray.New()
-								if err := iter.accessor.Set(data, buf[i:i+size]); err != nil {
-									pArray.Release(data)
-									return fmt.Errorf("setting data element %d: %w", i, err)
-								}
-								pArray.Append(data)
-							}
-
-							return nil
-						})
-						if err != nil && !errors.Is(err, os.ErrNotExist) {
-							return fmt.Errorf("entering container %q's netns to run iterator %q: %w",
-								container.Runtime.ContainerName, pName, err)
-						}
-					}
+					err = appendIteratorData(iter, pArray, pName, buf)
+				}
+				if err != nil {
+					return err
 				}
 			}
 
@@ -261,15 +224,153 @@ func (i *ebpfInstance) runIterators() error {
 	return nil
 }
 
+// appendIteratorData appends the entries of the buffer read from an iterator to pArray
+func appendIteratorData(iter *Iterator, pArray datasource.PacketArray, pName string, buf []byte) error {
+	size := iter.accessor.Size()
+	if uint32(len(buf))%size != 0 {
+		return fmt.Errorf("iter %q returned an invalid buffer's size %d, expected multiple of %d",
+			pName, len(buf), size)
+	}
+
+	for i := uint32(0); i < uint32(len(buf)); i += size {
+		data := pArray.New()
+		if err := iter.accessor.Set(data, buf[i:i+size]); err != nil {
+			pArray.Release(data)
+			return fmt.Errorf("setting data element %d: %w", i, err)
+		}
+		pArray.Append(data)
+	}
+	return nil
+}
+
+func (i *ebpfInstance) getContainers() []*containercollection.Container {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	return slices.Collect(maps.Values(i.containers))
+}
+
+// readIteratorPerNetNs runs the iterator once in the network namespace of each container
+func (i *ebpfInstance) readIteratorPerNetNs(iter *Iterator, pArray datasource.PacketArray, pName string, l *linkIterator) error {
+	visitedNetNs := make(map[uint64]struct{})
+	for _, container := range i.getContainers() {
+		_, visited := visitedNetNs[container.Netns]
+		if visited {
+			continue
+		}
+		visitedNetNs[container.Netns] = struct{}{}
+
+		err := nsenter.NetnsEnter(int(container.ContainerPid()), func() error {
+			reader, err := l.link.Open()
+			if err != nil {
+				return err
+			}
+			defer reader.Close()
+
+			buf, err := io.ReadAll(reader)
+			if err != nil {
+				return fmt.Errorf("reading iterator %q: %w", pName, err)
+			}
+
+			return appendIteratorData(iter, pArray, pName, buf)
+		})
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("entering container %q's netns to run iterator %q: %w",
+				container.Runtime.ContainerName, pName, err)
+		}
+	}
+	return nil
+}
+
+// readIteratorPerCgroup runs the iterator on the cgroup of each container and its descendants
+func (i *ebpfInstance) readIteratorPerCgroup(iter *Iterator, pArray datasource.PacketArray, pName string, l *linkIterator) error {
+	visitedCgroups := make(map[string]struct{})
+	for _, container := range i.getContainers() {
+		if container.CgroupPath == "" {
+			continue
+		}
+		_, visited := visitedCgroups[container.CgroupPath]
+		if visited {
+			continue
+		}
+		visitedCgroups[container.CgroupPath] = struct{}{}
+
+		lIter, err := attachCgroupIter(l.prog, container.CgroupPath)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				// The container is gone
+				continue
+			}
+			return fmt.Errorf("attaching iterator %q to cgroup of container %q: %w",
+				pName, container.Runtime.ContainerName, err)
+		}
+
+		buf, err := bpfiterns.Read(lIter)
+		lIter.Close()
+		if err != nil {
+			return fmt.Errorf("reading iterator %q: %w", pName, err)
+		}
+		if err := appendIteratorData(iter, pArray, pName, buf); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// attachCgroupIter attaches a cgroup iterator walking the given cgroup and its descendants
+func attachCgroupIter(prog *ebpf.Program, cgroupPath string) (*link.Iter, error) {
+	cgroup, err := os.Open(cgroupPath)
+	if err != nil {
+		return nil, err
+	}
+	defer cgroup.Close()
+
+	info := IterLinkInfoCgroup{
+		Order:    unix.BPF_CGROUP_ITER_DESCENDANTS_PRE,
+		CgroupFd: uint32(cgroup.Fd()),
+	}
+	attr := LinkCreateIterAttr{
+		ProgFd:      uint32(prog.FD()),
+		AttachType:  uint32(ebpf.AttachTraceIter),
+		IterInfo:    Pointer{ptr: unsafe.Pointer(&info)},
+		IterInfoLen: uint32(unsafe.Sizeof(info)),
+	}
+	fd, err := BPF(BPF_LINK_CREATE, unsafe.Pointer(&attr), unsafe.Sizeof(attr))
+	runtime.KeepAlive(&info)
+	runtime.KeepAlive(cgroup)
+	if err != nil {
+		return nil, fmt.Errorf("creating cgroup iterator link: %w", err)
+	}
+
+	l, err := link.NewFromFD(int(fd))
+	if err != nil {
+		unix.Close(int(fd))
+		return nil, err
+	}
+	lIter, ok := l.(*link.Iter)
+	if !ok {
+		l.Close()
+		return nil, fmt.Errorf("link is not an iterator")
+	}
+	return lIter, nil
+}
+
 // isIteratorKindPerNetNs returns true if the iterator kind needs to be run per
 // network namespace.
 func isIteratorKindPerNetNs(kind string) bool {
-	if kind == "tcp" || kind == "udp" {
+	switch kind {
+	case "tcp", "udp", "unix", "netlink", "ipv6_route":
 		return true
 	}
 	return false
 }
 
+// isIteratorKindPerCgroup returns true if the iterator kind needs to be run
+// on the cgroup of each container.
+func isIteratorKindPerCgroup(kind string) bool {
+	return kind == "cgroup"
+}
+
 // isIteratorKindSupported returns true if the iterator kind is supported by
 // Inspektor Gadget.
 func isIteratorKindSupported(kind string) bool {
@@ -281,7 +382,8 @@ func isIteratorKindSupported(kind string) bool {
 	//
 	// But at the moment, only a subset is supported by Inspektor Gadget.
 	switch kind {
-	case "task", "task_file", "ksym", "tcp", "udp":
+	case "task", "task_file", "ksym", "tcp", "udp",
+		"bpf_map", "bpf_prog", "bpf_link", "cgroup", "unix", "netlink", "ipv6_route":
 		return true
 	}
 	return false
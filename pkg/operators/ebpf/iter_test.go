@@ -0,0 +1,57 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebpfoperator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIteratorKinds(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		kind      string
+		supported bool
+		perNetNs  bool
+		perCgroup bool
+	}{
+		{kind: "task", supported: true},
+		{kind: "task_file", supported: true},
+		{kind: "ksym", supported: true},
+		{kind: "bpf_map", supported: true},
+		{kind: "bpf_prog", supported: true},
+		{kind: "bpf_link", supported: true},
+		{kind: "tcp", supported: true, perNetNs: true},
+		{kind: "udp", supported: true, perNetNs: true},
+		{kind: "unix", supported: true, perNetNs: true},
+		{kind: "netlink", supported: true, perNetNs: true},
+		{kind: "ipv6_route", supported: true, perNetNs: true},
+		{kind: "cgroup", supported: true, perCgroup: true},
+		{kind: "task_vma"},
+		{kind: "bpf_map_elem"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.kind, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, test.supported, isIteratorKindSupported(test.kind))
+			require.Equal(t, test.perNetNs, isIteratorKindPerNetNs(test.kind))
+			require.Equal(t, test.perCgroup, isIteratorKindPerCgroup(test.kind))
+		})
+	}
+}
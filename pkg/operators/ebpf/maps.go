@@ -18,6 +18,7 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"slices"
 	"strings"
 	"time"
 	"unsafe"
@@ -36,6 +37,17 @@ const (
 	ParamMapIterCount    = "map-fetch-count"
 
 	mapIterIntervalDefault = "1000ms"
+
+	// mapIterModeDrain reads and deletes the entries of the map
+	mapIterModeDrain = "drain"
+	// mapIterModeSnapshot reads the entries without deleting them and reports the difference
+	// of integer fields against the previous read
+	mapIterModeSnapshot = "snapshot"
+
+	// mapIterPerCPUSum sums up the values of all CPUs
+	mapIterPerCPUSum = "sum"
+	// mapIterPerCPUArray reports the values of each CPU as a list
+	mapIterPerCPUArray = "array"
 )
 
 type mapIter struct {
@@ -48,10 +60,24 @@ type mapIter struct {
 	keyAccessor datasource.FieldAccessor
 	valAccessor datasource.FieldAccessor
 
+	// valListAccessors are used instead of valAccessor when per-CPU values are reported as
+	// lists. They match the fields in valFields.
+	valListAccessors []datasource.FieldAccessor
+	valFields        []*Field
+
 	interval time.Duration
 	count    int
 
 	flushOnStop bool
+
+	mode   string
+	perCPU string
+	// intFields are the integer fields summed up across CPUs
+	intFields []intField
+	// counterFields are the integer fields reported as deltas in snapshot mode
+	counterFields []intField
+	// prev holds the values of the previous read in snapshot mode, indexed by key
+	prev map[string][]byte
 }
 
 func (i *ebpfInstance) populateMap(t btf.Type, varName string) error {
@@ -166,60 +192,7 @@ func (i *ebpfInstance) runMapIterators() error {
 			return fmt.Errorf("map %q not found", iter.mapName)
 		}
 		fetch := func() {
-			p, err := iter.ds.NewPacketArray()
-			if err != nil {
-				i.logger.Errorf("error creating packet for map iterator: %v", err)
-				return
-			}
-
-			var prevKey []byte
-
-			batchSize := 100 // discuss
-
-			keySize := int(iterMap.KeySize())
-			valSize := int(iterMap.ValueSize())
-			for {
-				keys := make([]byte, keySize*batchSize)
-				vals := make([]byte, valSize*batchSize)
-				keysPtr := Pointer{ptr: unsafe.Pointer(&keys[0])}
-				valuesPtr := Pointer{ptr: unsafe.Pointer(&vals[0])}
-
-				// TODO: use cilium lib once raw byte access has been added
-				// TODO: open PR to actually make that happen
-				nk := make([]byte, keySize)
-				attr := MapLookupBatchAttr{
-					MapFd:    uint32(iterMap.FD()),
-					Keys:     keysPtr,
-					Values:   valuesPtr,
-					Count:    uint32(batchSize),
-					OutBatch: Pointer{ptr: unsafe.Pointer(&nk[0])},
-				}
-				if prevKey != nil {
-					attr.InBatch = Pointer{ptr: unsafe.Pointer(&prevKey[0])}
-				}
-
-				_, err := BPF(BPF_MAP_LOOKUP_AND_DELETE_BATCH, unsafe.Pointer(&attr), unsafe.Sizeof(attr))
-				if err != nil && !errors.Is(err, unix.ENOENT) {
-					i.logger.Warnf("error from map iterator: %v", err)
-					break
-				}
-				n := int(attr.Count)
-
-				prevKey = nk
-				for c := range n {
-					d := p.New()
-					iter.keyAccessor.Set(d, keys[keySize*c:keySize*(c+1)])
-					iter.valAccessor.Set(d, vals[valSize*c:valSize*(c+1)])
-					p.Append(d)
-				}
-				if errors.Is(err, unix.ENOENT) { // ebpf.ErrKeyNotExist when doing this with cilium/ebpf later on
-					break
-				}
-			}
-			if err := iter.ds.EmitAndRelease(p); err != nil {
-				i.logger.Errorf("emitting and releasing %q data: %v", iter.name, err)
-				return
-			}
+			i.fetchMapIter(iter, iterMap)
 		}
 		i.wg.Add(1)
 		go func() {
@@ -257,6 +230,177 @@ func (i *ebpfInstance) runMapIterators() error {
 	return nil
 }
 
+func (i *ebpfInstance) fetchMapIter(iter *mapIter, iterMap *ebpf.Map) {
+	p, err := iter.ds.NewPacketArray()
+	if err != nil {
+		i.logger.Errorf("error creating packet for map iterator: %v", err)
+		return
+	}
+
+	cmd := BPF_MAP_LOOKUP_AND_DELETE_BATCH
+	if iter.mode == mapIterModeSnapshot {
+		cmd = BPF_MAP_LOOKUP_BATCH
+	}
+
+	var prevKey []byte
+
+	batchSize := 100 // discuss
+
+	keySize := int(iterMap.KeySize())
+	valSize := int(iterMap.ValueSize())
+
+	// Values of per-CPU maps are stored for each possible CPU, each of them rounded up to 8
+	// bytes
+	nCPU := 1
+	stride := valSize
+	if isPerCPUMap(iterMap.Type()) {
+		var err error
+		nCPU, err = ebpf.PossibleCPU()
+		if err != nil {
+			i.logger.Errorf("getting number of possible CPUs: %v", err)
+			return
+		}
+		stride = (valSize + 7) &^ 7
+	}
+	entrySize := stride * nCPU
+
+	var current map[string][]byte
+	if iter.mode == mapIterModeSnapshot {
+		current = make(map[string][]byte, len(iter.prev))
+	}
+
+	for {
+		keys := make([]byte, keySize*batchSize)
+		vals := make([]byte, entrySize*batchSize)
+		keysPtr := Pointer{ptr: unsafe.Pointer(&keys[0])}
+		valuesPtr := Pointer{ptr: unsafe.Pointer(&vals[0])}
+
+		// TODO: use cilium lib once raw byte access has been added
+		// TODO: open PR to actually make that happen
+		nk := make([]byte, keySize)
+		attr := MapLookupBatchAttr{
+			MapFd:    uint32(iterMap.FD()),
+			Keys:     keysPtr,
+			Values:   valuesPtr,
+			Count:    uint32(batchSize),
+			OutBatch: Pointer{ptr: unsafe.Pointer(&nk[0])},
+		}
+		if prevKey != nil {
+			attr.InBatch = Pointer{ptr: unsafe.Pointer(&prevKey[0])}
+		}
+
+		_, err := BPF(cmd, unsafe.Pointer(&attr), unsafe.Sizeof(attr))
+		if err != nil && !errors.Is(err, unix.ENOENT) {
+			i.logger.Warnf("error from map iterator: %v", err)
+			break
+		}
+		n := int(attr.Count)
+
+		prevKey = nk
+		for c := range n {
+			key := keys[keySize*c : keySize*(c+1)]
+			val := vals[entrySize*c : entrySize*(c+1)]
+			nVals := nCPU
+
+			if nCPU > 1 && iter.perCPU != mapIterPerCPUArray {
+				val = sumPerCPU(val, uint32(valSize), uint32(stride), nCPU, iter.intFields)
+				nVals = 1
+			}
+
+			if iter.mode == mapIterModeSnapshot {
+				current[string(key)] = slices.Clone(val)
+				if !subtractCounters(val, iter.prev[string(key)], uint32(stride), nVals, iter.counterFields) {
+					// Nothing happened since the last read
+					continue
+				}
+			}
+
+			d := p.New()
+			iter.keyAccessor.Set(d, key)
+			if iter.valListAccessors != nil {
+				if err := iter.setPerCPUValues(d, val, stride, nCPU); err != nil {
+					i.logger.Warnf("setting per-CPU values of %q: %v", iter.name, err)
+				}
+			} else {
+				iter.valAccessor.Set(d, val[:valSize])
+			}
+			p.Append(d)
+		}
+		if errors.Is(err, unix.ENOENT) { // ebpf.ErrKeyNotExist when doing this with cilium/ebpf later on
+			break
+		}
+	}
+
+	if iter.mode == mapIterModeSnapshot {
+		// Entries that are gone from the map are forgotten as well
+		iter.prev = current
+	}
+
+	if err := iter.ds.EmitAndRelease(p); err != nil {
+		i.logger.Errorf("emitting and releasing %q data: %v", iter.name, err)
+		return
+	}
+}
+
+// setPerCPUValues stores the value of each CPU in val into the list fields of d
+func (iter *mapIter) setPerCPUValues(d datasource.Data, val []byte, stride, nCPU int) error {
+	for idx, f := range iter.valFields {
+		elems := make([]any, 0, nCPU)
+		for cpu := range nCPU {
+			elems = append(elems, readNumber(val[cpu*stride+int(f.Offset):], f.kind))
+		}
+		if err := iter.valListAccessors[idx].PutList(d, elems); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func isPerCPUMap(typ ebpf.MapType) bool {
+	return typ == ebpf.PerCPUHash || typ == ebpf.PerCPUArray || typ == ebpf.LRUCPUHash
+}
+
+// configure applies the mode annotations of the data source to the iterator
+func (iter *mapIter) configure(annotations map[string]string, mapType ebpf.MapType, valFields []*Field) error {
+	iter.mode = annotations[AnnotationMapMode]
+	switch iter.mode {
+	case "":
+		iter.mode = mapIterModeDrain
+		if mapType == ebpf.Array || mapType == ebpf.PerCPUArray {
+			// entries of arrays can't be deleted
+			iter.mode = mapIterModeSnapshot
+		}
+	case mapIterModeDrain:
+		if mapType == ebpf.Array || mapType == ebpf.PerCPUArray {
+			return fmt.Errorf("mode %q is not supported for array maps", iter.mode)
+		}
+	case mapIterModeSnapshot:
+	default:
+		return fmt.Errorf("invalid mode %q", iter.mode)
+	}
+
+	iter.perCPU = annotations[AnnotationMapPerCPU]
+	switch iter.perCPU {
+	case "", mapIterPerCPUSum:
+		iter.perCPU = mapIterPerCPUSum
+	case mapIterPerCPUArray:
+		if !isPerCPUMap(mapType) {
+			return fmt.Errorf("per-CPU mode %q is only supported for per-CPU maps", iter.perCPU)
+		}
+		for _, f := range valFields {
+			if f.parent != -1 || !isNumericKind(f.kind) {
+				return fmt.Errorf("per-CPU mode %q only supports numeric fields, %q is not", iter.perCPU, f.name)
+			}
+		}
+	default:
+		return fmt.Errorf("invalid per-CPU mode %q", iter.perCPU)
+	}
+
+	iter.intFields = getIntFields(valFields, false)
+	iter.counterFields = getIntFields(valFields, true)
+	return nil
+}
+
 func (i *ebpfInstance) populateMapIter(t btf.Type, varName string) error {
 	i.logger.Debugf("populating mapiter %q", varName)
 
@@ -278,12 +422,21 @@ func (i *ebpfInstance) populateMapIter(t btf.Type, varName string) error {
 		return fmt.Errorf("map %q not found in eBPF object", mapName)
 	}
 
-	if iterMap.Type != ebpf.Hash {
-		return fmt.Errorf("map %q is not a hash map", mapName)
+	switch iterMap.Type {
+	case ebpf.Hash, ebpf.LRUHash, ebpf.PerCPUHash, ebpf.LRUCPUHash, ebpf.Array, ebpf.PerCPUArray:
+	default:
+		return fmt.Errorf("map %q is not a hash or array map", mapName)
 	}
 
 	keyStruct, ok := iterMap.Key.(*btf.Struct)
-	if !ok {
+	if !ok && (iterMap.Type == ebpf.Array || iterMap.Type == ebpf.PerCPUArray) {
+		// Keys of arrays are plain indexes, wrap them in a struct to use them as fields
+		keyStruct = &btf.Struct{
+			Name:    mapName + "_key",
+			Size:    iterMap.KeySize,
+			Members: []btf.Member{{Name: "index", Type: iterMap.Key}},
+		}
+	} else if !ok {
 		return fmt.Errorf("map %q key is not a struct", mapName)
 	}
 
@@ -0,0 +1,182 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebpfoperator
+
+import (
+	"encoding/binary"
+	"math"
+	"slices"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+	ebpftypes "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/ebpf/types"
+)
+
+// intField describes an integer (or an array of integers) inside a map value. These are the
+// values summed up across CPUs and reported as deltas in snapshot mode.
+type intField struct {
+	offset   uint32
+	elemSize uint32
+	count    uint32
+}
+
+func intKindSize(kind api.Kind) uint32 {
+	switch kind {
+	case api.Kind_Int8, api.Kind_Uint8:
+		return 1
+	case api.Kind_Int16, api.Kind_Uint16:
+		return 2
+	case api.Kind_Int32, api.Kind_Uint32:
+		return 4
+	case api.Kind_Int64, api.Kind_Uint64:
+		return 8
+	}
+	return 0
+}
+
+func isNumericKind(kind api.Kind) bool {
+	switch kind {
+	case api.Kind_Bool, api.Kind_Float32, api.Kind_Float64:
+		return true
+	}
+	return intKindSize(kind) != 0
+}
+
+func isGaugeField(f *Field) bool {
+	return f.Annotations["metrics.type"] == "gauge" ||
+		slices.Contains(f.Tags, "type:"+ebpftypes.GaugeU32TypeName) ||
+		slices.Contains(f.Tags, "type:"+ebpftypes.GaugeU64TypeName)
+}
+
+// getIntFields returns the top level integer fields of a map value. Gauges are skipped if
+// skipGauges is set.
+func getIntFields(fields []*Field, skipGauges bool) []intField {
+	res := make([]intField, 0, len(fields))
+	for _, f := range fields {
+		if f.parent != -1 || (skipGauges && isGaugeField(f)) {
+			continue
+		}
+		elemSize := intKindSize(f.kind &^ api.KindFlagArray)
+		if elemSize == 0 {
+			continue
+		}
+		res = append(res, intField{
+			offset:   f.Offset,
+			elemSize: elemSize,
+			count:    f.Size / elemSize,
+		})
+	}
+	return res
+}
+
+func readUint(b []byte, size uint32) uint64 {
+	switch size {
+	case 1:
+		return uint64(b[0])
+	case 2:
+		return uint64(binary.NativeEndian.Uint16(b))
+	case 4:
+		return uint64(binary.NativeEndian.Uint32(b))
+	}
+	return binary.NativeEndian.Uint64(b)
+}
+
+func writeUint(b []byte, size uint32, v uint64) {
+	switch size {
+	case 1:
+		b[0] = uint8(v)
+	case 2:
+		binary.NativeEndian.PutUint16(b, uint16(v))
+	case 4:
+		binary.NativeEndian.PutUint32(b, uint32(v))
+	default:
+		binary.NativeEndian.PutUint64(b, v)
+	}
+}
+
+// sumPerCPU aggregates the values of all CPUs in raw, where the value of each CPU takes stride
+// bytes. Integer fields are summed up, all other fields are taken from the first CPU.
+func sumPerCPU(raw []byte, valSize, stride uint32, nCPU int, fields []intField) []byte {
+	res := slices.Clone(raw[:valSize])
+	for cpu := 1; cpu < nCPU; cpu++ {
+		val := raw[uint32(cpu)*stride:]
+		for _, f := range fields {
+			for e := range f.count {
+				off := f.offset + e*f.elemSize
+				writeUint(res[off:], f.elemSize, readUint(res[off:], f.elemSize)+readUint(val[off:], f.elemSize))
+			}
+		}
+	}
+	return res
+}
+
+// subtractCounters replaces the integer fields of each of the n values of stride bytes in cur
+// with the difference to the same fields in prev. prev can be nil if there is no previous value.
+// Fields lower than in prev are considered reset and keep their value. It returns whether any of
+// the resulting differences is not zero, or true if there are no fields to compare.
+func subtractCounters(cur, prev []byte, stride uint32, n int, fields []intField) bool {
+	if len(fields) == 0 {
+		return true
+	}
+	changed := false
+	for i := range uint32(n) {
+		for _, f := range fields {
+			for e := range f.count {
+				off := i*stride + f.offset + e*f.elemSize
+				v := readUint(cur[off:], f.elemSize)
+				// A counter going backwards has been reset (e.g. the entry was recreated or the
+				// program reloaded), so its current value is what it increased by since then
+				if prev != nil {
+					if p := readUint(prev[off:], f.elemSize); v >= p {
+						v -= p
+					}
+				}
+				writeUint(cur[off:], f.elemSize, v)
+				if v&(uint64(math.MaxUint64)>>(64-8*f.elemSize)) != 0 {
+					changed = true
+				}
+			}
+		}
+	}
+	return changed
+}
+
+// readNumber decodes a value of the given (numeric) kind from b
+func readNumber(b []byte, kind api.Kind) any {
+	switch kind {
+	case api.Kind_Bool:
+		return b[0] != 0
+	case api.Kind_Int8:
+		return int8(b[0])
+	case api.Kind_Uint8:
+		return b[0]
+	case api.Kind_Int16:
+		return int16(binary.NativeEndian.Uint16(b))
+	case api.Kind_Uint16:
+		return binary.NativeEndian.Uint16(b)
+	case api.Kind_Int32:
+		return int32(binary.NativeEndian.Uint32(b))
+	case api.Kind_Uint32:
+		return binary.NativeEndian.Uint32(b)
+	case api.Kind_Int64:
+		return int64(binary.NativeEndian.Uint64(b))
+	case api.Kind_Uint64:
+		return binary.NativeEndian.Uint64(b)
+	case api.Kind_Float32:
+		return math.Float32frombits(binary.NativeEndian.Uint32(b))
+	case api.Kind_Float64:
+		return math.Float64frombits(binary.NativeEndian.Uint64(b))
+	}
+	return nil
+}
@@ -0,0 +1,204 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebpfoperator
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/cilium/ebpf"
+	"github.com/stretchr/testify/require"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+	ebpftypes "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/ebpf/types"
+)
+
+// testValueFields describes:
+//
+//	struct value {
+//		gadget_counter__u64 count;
+//		gadget_gauge__u32 current;
+//		char name[4];
+//	};
+func testValueFields() []*Field {
+	return []*Field{
+		{name: "count", kind: api.Kind_Uint64, Offset: 0, Size: 8, parent: -1,
+			Tags: []string{"type:" + ebpftypes.CounterU64TypeName}},
+		{name: "current", kind: api.Kind_Uint32, Offset: 8, Size: 4, parent: -1,
+			Tags: []string{"type:" + ebpftypes.GaugeU32TypeName}},
+		{name: "name", kind: api.Kind_CString, Offset: 12, Size: 4, parent: -1},
+	}
+}
+
+func testValue(count uint64, current uint32, name string) []byte {
+	b := make([]byte, 16)
+	binary.NativeEndian.PutUint64(b, count)
+	binary.NativeEndian.PutUint32(b[8:], current)
+	copy(b[12:], name)
+	return b
+}
+
+func TestGetIntFields(t *testing.T) {
+	t.Parallel()
+
+	fields := testValueFields()
+	require.Equal(t, []intField{
+		{offset: 0, elemSize: 8, count: 1},
+		{offset: 8, elemSize: 4, count: 1},
+	}, getIntFields(fields, false))
+	require.Equal(t, []intField{
+		{offset: 0, elemSize: 8, count: 1},
+	}, getIntFields(fields, true))
+
+	hist := []*Field{{name: "slots", kind: api.ArrayOf(api.Kind_Uint32), Size: 16, parent: -1}}
+	require.Equal(t, []intField{{offset: 0, elemSize: 4, count: 4}}, getIntFields(hist, true))
+}
+
+func TestSumPerCPU(t *testing.T) {
+	t.Parallel()
+
+	fields := getIntFields(testValueFields(), false)
+
+	raw := append(testValue(1, 2, "abc"), testValue(10, 20, "xyz")...)
+	raw = append(raw, testValue(100, 200, "")...)
+
+	require.Equal(t, testValue(111, 222, "abc"), sumPerCPU(raw, 16, 16, 3, fields))
+}
+
+func TestSubtractCounters(t *testing.T) {
+	t.Parallel()
+
+	fields := getIntFields(testValueFields(), true)
+
+	// First read: the value is reported as is
+	cur := testValue(5, 3, "abc")
+	require.True(t, subtractCounters(cur, nil, 16, 1, fields))
+	require.Equal(t, testValue(5, 3, "abc"), cur)
+
+	// Gauges are not subtracted
+	prev := testValue(5, 3, "abc")
+	cur = testValue(8, 4, "abc")
+	require.True(t, subtractCounters(cur, prev, 16, 1, fields))
+	require.Equal(t, testValue(3, 4, "abc"), cur)
+
+	// Unchanged counters
+	cur = testValue(8, 1, "abc")
+	require.False(t, subtractCounters(cur, testValue(8, 4, "abc"), 16, 1, fields))
+
+	// Reset counters keep their current value instead of wrapping around
+	cur = testValue(2, 4, "abc")
+	require.True(t, subtractCounters(cur, testValue(8, 4, "abc"), 16, 1, fields))
+	require.Equal(t, testValue(2, 4, "abc"), cur)
+
+	// Per-CPU values
+	cur = append(testValue(8, 0, ""), testValue(2, 0, "")...)
+	prev = append(testValue(8, 0, ""), testValue(1, 0, "")...)
+	require.True(t, subtractCounters(cur, prev, 16, 2, fields))
+	require.Equal(t, append(testValue(0, 0, ""), testValue(1, 0, "")...), cur)
+
+	// No counters at all
+	require.True(t, subtractCounters(testValue(0, 0, ""), nil, 16, 1, nil))
+}
+
+func TestMapIterConfigure(t *testing.T) {
+	t.Parallel()
+
+	numericFields := testValueFields()[:2]
+
+	tests := []struct {
+		name           string
+		annotations    map[string]string
+		mapType        ebpf.MapType
+		fields         []*Field
+		expectedMode   string
+		expectedPerCPU string
+		expectedErr    bool
+	}{
+		{
+			name:           "defaults",
+			mapType:        ebpf.Hash,
+			fields:         testValueFields(),
+			expectedMode:   mapIterModeDrain,
+			expectedPerCPU: mapIterPerCPUSum,
+		},
+		{
+			name:           "array defaults to snapshot",
+			mapType:        ebpf.PerCPUArray,
+			fields:         testValueFields(),
+			expectedMode:   mapIterModeSnapshot,
+			expectedPerCPU: mapIterPerCPUSum,
+		},
+		{
+			name:        "drain array",
+			annotations: map[string]string{AnnotationMapMode: mapIterModeDrain},
+			mapType:     ebpf.Array,
+			fields:      testValueFields(),
+			expectedErr: true,
+		},
+		{
+			name:        "invalid mode",
+			annotations: map[string]string{AnnotationMapMode: "foo"},
+			mapType:     ebpf.Hash,
+			expectedErr: true,
+		},
+		{
+			name: "per-CPU array",
+			annotations: map[string]string{
+				AnnotationMapMode:   mapIterModeSnapshot,
+				AnnotationMapPerCPU: mapIterPerCPUArray,
+			},
+			mapType:        ebpf.PerCPUHash,
+			fields:         numericFields,
+			expectedMode:   mapIterModeSnapshot,
+			expectedPerCPU: mapIterPerCPUArray,
+		},
+		{
+			name:        "per-CPU array with non numeric fields",
+			annotations: map[string]string{AnnotationMapPerCPU: mapIterPerCPUArray},
+			mapType:     ebpf.PerCPUHash,
+			fields:      testValueFields(),
+			expectedErr: true,
+		},
+		{
+			name:        "per-CPU array on non per-CPU map",
+			annotations: map[string]string{AnnotationMapPerCPU: mapIterPerCPUArray},
+			mapType:     ebpf.Hash,
+			fields:      numericFields,
+			expectedErr: true,
+		},
+		{
+			name:        "invalid per-CPU mode",
+			annotations: map[string]string{AnnotationMapPerCPU: "avg"},
+			mapType:     ebpf.PerCPUHash,
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			iter := &mapIter{}
+			err := iter.configure(test.annotations, test.mapType, test.fields)
+			if test.expectedErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.expectedMode, iter.mode)
+			require.Equal(t, test.expectedPerCPU, iter.perCPU)
+		})
+	}
+}
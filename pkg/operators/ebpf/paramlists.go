@@ -0,0 +1,200 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebpfoperator
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/netip"
+
+	"github.com/cilium/ebpf"
+	"github.com/cilium/ebpf/btf"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/params"
+	ebpfutils "github.com/inspektor-gadget/inspektor-gadget/pkg/utils/ebpf"
+)
+
+// Keep this aligned with include/gadget/net_filter.h
+const (
+	// cidrKeySize is the size of struct gadget_cidr_key
+	cidrKeySize = 4 + 16
+	// portListSize is the number of entries of the array backing a port
+	// range list, one per port
+	portListSize = 1 << 16
+
+	// paramListSetSuffix is the suffix of the const volatile bool telling the
+	// eBPF program whether the list was given
+	paramListSetSuffix = "_set"
+)
+
+// getListTypeHint returns the type of the list param backed by the given map
+func getListTypeHint(m *ebpf.MapSpec) (params.TypeHint, error) {
+	switch {
+	case m.Type == ebpf.LPMTrie && m.KeySize == cidrKeySize && m.ValueSize == 1:
+		return params.TypeCIDRList, nil
+	case m.Type == ebpf.Array && m.KeySize == 4 && m.ValueSize == 1 && m.MaxEntries == portListSize:
+		return params.TypePortRangeList, nil
+	}
+	return params.TypeUnknown, fmt.Errorf("map %q (%s, key size %d, value size %d, max entries %d) can't back a list param",
+		m.Name, m.Type, m.KeySize, m.ValueSize, m.MaxEntries)
+}
+
+// populateParamList adds a param whose values are written to a map instead of
+// a variable. See GADGET_PARAM_CIDR_LIST and GADGET_PARAM_PORT_RANGE_LIST.
+func (i *ebpfInstance) populateParamList(_ btf.Type, mapName string) error {
+	if _, found := i.params[mapName]; found {
+		i.logger.Debugf("param %q already defined, skipping", mapName)
+		return nil
+	}
+
+	mapSpec, ok := i.collectionSpec.Maps[mapName]
+	if !ok {
+		return fmt.Errorf("map %q not found", mapName)
+	}
+	th, err := getListTypeHint(mapSpec)
+	if err != nil {
+		return err
+	}
+	if _, ok := i.collectionSpec.Variables[mapName+paramListSetSuffix]; !ok {
+		return fmt.Errorf("variable %q not found", mapName+paramListSetSuffix)
+	}
+
+	newParam := &param{
+		Param: &api.Param{
+			Key:      mapName,
+			TypeHint: string(th),
+		},
+		listMap: mapName,
+	}
+
+	i.logger.Debugf("adding list param %q (%v)", mapName, th)
+
+	i.fillParamFromMetadata(mapName, newParam)
+
+	i.params[mapName] = newParam
+	return nil
+}
+
+// setParamListVars tells the eBPF programs which lists were given
+func (i *ebpfInstance) setParamListVars(paramMap map[string]*params.Param) error {
+	for name, p := range i.params {
+		if p.listMap == "" {
+			continue
+		}
+
+		isSet := len(paramMap[name].AsStringSlice()) > 0
+		i.logger.Debugf("setting list param %q: %t", name, isSet)
+		if err := ebpfutils.SpecSetVar(i.collectionSpec, p.listMap+paramListSetSuffix, isSet); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fillParamListMaps writes the values of the list params into their maps. It
+// must be called once the collection is loaded.
+func (i *ebpfInstance) fillParamListMaps(paramMap map[string]*params.Param) error {
+	for name, p := range i.params {
+		if p.listMap == "" {
+			continue
+		}
+
+		m, ok := i.collection.Maps[p.listMap]
+		if !ok {
+			return fmt.Errorf("map %q of param %q not found", p.listMap, name)
+		}
+
+		var err error
+		switch p.TypeHint {
+		case api.TypeCIDRList:
+			err = fillCIDRList(m, paramMap[name].AsCIDRList())
+		case api.TypePortRangeList:
+			err = fillPortList(m, portRangeListPorts(paramMap[name].AsPortRangeList()))
+		}
+		if err != nil {
+			return fmt.Errorf("filling map %q of param %q: %w", p.listMap, name, err)
+		}
+	}
+	return nil
+}
+
+// cidrKey returns the key of the LPM trie for prefix. IPv4 prefixes are stored
+// as IPv4-mapped IPv6 prefixes.
+func cidrKey(prefix netip.Prefix) [cidrKeySize]byte {
+	var key [cidrKeySize]byte
+	bits := prefix.Bits()
+	if prefix.Addr().Is4() {
+		bits += 96
+	}
+	binary.NativeEndian.PutUint32(key[:4], uint32(bits))
+	addr := prefix.Addr().As16()
+	copy(key[4:], addr[:])
+	return key
+}
+
+func fillCIDRList(m *ebpf.Map, prefixes []netip.Prefix) error {
+	if len(prefixes) > int(m.MaxEntries()) {
+		return fmt.Errorf("too many CIDRs: %d > %d", len(prefixes), m.MaxEntries())
+	}
+	// Batch operations aren't supported by LPM tries
+	for _, prefix := range prefixes {
+		if err := m.Update(cidrKey(prefix), uint8(1), ebpf.UpdateAny); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// portRangeListPorts returns the ports covered by ranges without duplicates
+func portRangeListPorts(ranges []params.PortRange) []uint32 {
+	var ports []uint32
+	seen := make(map[uint32]struct{})
+	for _, r := range ranges {
+		for port := uint32(r.Start); port <= uint32(r.End); port++ {
+			if _, ok := seen[port]; ok {
+				continue
+			}
+			seen[port] = struct{}{}
+			ports = append(ports, port)
+		}
+	}
+	return ports
+}
+
+// fillPortList marks the given ports in the array map, using a batch
+// operation if the kernel supports it
+func fillPortList(m *ebpf.Map, ports []uint32) error {
+	if len(ports) == 0 {
+		return nil
+	}
+
+	values := make([]uint8, len(ports))
+	for idx := range values {
+		values[idx] = 1
+	}
+
+	_, err := m.BatchUpdate(ports, values, nil)
+	if !errors.Is(err, ebpf.ErrNotSupported) {
+		return err
+	}
+	for _, port := range ports {
+		if err := m.Update(port, uint8(1), ebpf.UpdateAny); err != nil {
+			return err
+		}
+	}
+	return nil
+}
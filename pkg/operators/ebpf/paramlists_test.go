@@ -0,0 +1,103 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebpfoperator
+
+import (
+	"encoding/binary"
+	"net/netip"
+	"testing"
+
+	"github.com/cilium/ebpf"
+	"github.com/stretchr/testify/require"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/params"
+)
+
+func TestGetListTypeHint(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		spec        *ebpf.MapSpec
+		expected    params.TypeHint
+		expectedErr bool
+	}{
+		{
+			name:     "cidr list",
+			spec:     &ebpf.MapSpec{Type: ebpf.LPMTrie, KeySize: cidrKeySize, ValueSize: 1, MaxEntries: 1024},
+			expected: params.TypeCIDRList,
+		},
+		{
+			name:     "port range list",
+			spec:     &ebpf.MapSpec{Type: ebpf.Array, KeySize: 4, ValueSize: 1, MaxEntries: portListSize},
+			expected: params.TypePortRangeList,
+		},
+		{
+			name:        "lpm trie with wrong key",
+			spec:        &ebpf.MapSpec{Type: ebpf.LPMTrie, KeySize: 8, ValueSize: 1, MaxEntries: 1024},
+			expectedErr: true,
+		},
+		{
+			name:        "small array",
+			spec:        &ebpf.MapSpec{Type: ebpf.Array, KeySize: 4, ValueSize: 1, MaxEntries: 1024},
+			expectedErr: true,
+		},
+		{
+			name:        "hash",
+			spec:        &ebpf.MapSpec{Type: ebpf.Hash, KeySize: 4, ValueSize: 1, MaxEntries: portListSize},
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			th, err := getListTypeHint(test.spec)
+			if test.expectedErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.expected, th)
+		})
+	}
+}
+
+func TestCidrKey(t *testing.T) {
+	t.Parallel()
+
+	key := cidrKey(netip.MustParsePrefix("10.0.0.0/8"))
+	require.Equal(t, uint32(96+8), binary.NativeEndian.Uint32(key[:4]))
+	require.Equal(t, []byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0xff, 0xff, 10, 0, 0, 0}, key[4:])
+
+	key = cidrKey(netip.MustParsePrefix("fd00::/8"))
+	require.Equal(t, uint32(8), binary.NativeEndian.Uint32(key[:4]))
+	require.Equal(t, byte(0xfd), key[4])
+}
+
+func TestPortRangeListPorts(t *testing.T) {
+	t.Parallel()
+
+	ports := portRangeListPorts([]params.PortRange{
+		{Start: 53, End: 53},
+		{Start: 8000, End: 8002},
+		{Start: 8001, End: 8003},
+	})
+	require.Equal(t, []uint32{53, 8000, 8001, 8002, 8003}, ports)
+
+	ports = portRangeListPorts([]params.PortRange{{Start: 65535, End: 65535}})
+	require.Equal(t, []uint32{65535}, ports)
+}
@@ -34,6 +34,8 @@ type param struct {
 	fromEbpf bool
 	// Only valid for string parameters
 	strLen int
+	// Only valid for list parameters: name of the map holding the list
+	listMap string
 }
 
 func getTypeHint(typ btf.Type) params.TypeHint {
@@ -199,25 +201,32 @@ func (i *ebpfInstance) populateParam(t btf.Type, varName string) error {
 
 	handleWellKnownParam(btfVar, newParam)
 
-	// Fill additional information from metadata
+	i.fillParamFromMetadata(varName, newParam)
+
+	i.params[varName] = newParam
+	return nil
+}
+
+// fillParamFromMetadata fills additional information of the param from the
+// metadata file
+func (i *ebpfInstance) fillParamFromMetadata(varName string, p *param) {
 	paramInfo := i.config.Sub("params.ebpf." + varName)
 	if paramInfo == nil {
 		// Backward compatibility
 		paramInfo = i.config.Sub("ebpfParams." + varName)
 	}
-	if paramInfo != nil {
-		i.logger.Debugf(" filling additional information from metadata")
-		if s := paramInfo.GetString("key"); s != "" {
-			newParam.Key = s
-		}
-		if s := paramInfo.GetString("defaultValue"); s != "" {
-			newParam.DefaultValue = s
-		}
-		if s := paramInfo.GetString("description"); s != "" {
-			newParam.Description = s
-		}
+	if paramInfo == nil {
+		return
 	}
 
-	i.params[varName] = newParam
-	return nil
+	i.logger.Debugf(" filling additional information from metadata")
+	if s := paramInfo.GetString("key"); s != "" {
+		p.Key = s
+	}
+	if s := paramInfo.GetString("defaultValue"); s != "" {
+		p.DefaultValue = s
+	}
+	if s := paramInfo.GetString("description"); s != "" {
+		p.Description = s
+	}
 }
@@ -0,0 +1,306 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebpfoperator
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"unsafe"
+
+	"github.com/cilium/ebpf"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	// perfScopeGlobal opens perf events on all CPUs for all processes
+	perfScopeGlobal = "global"
+	// perfScopeCgroup opens perf events on all CPUs for the cgroup of each container
+	perfScopeCgroup = "cgroup"
+)
+
+var perfSoftwareConfigs = map[string]uint64{
+	"count_sw_cpu_clock": unix.PERF_COUNT_SW_CPU_CLOCK, // kept for compatibility
+	"cpu_clock":          unix.PERF_COUNT_SW_CPU_CLOCK,
+	"task_clock":         unix.PERF_COUNT_SW_TASK_CLOCK,
+	"page_faults":        unix.PERF_COUNT_SW_PAGE_FAULTS,
+	"page_faults_min":    unix.PERF_COUNT_SW_PAGE_FAULTS_MIN,
+	"page_faults_maj":    unix.PERF_COUNT_SW_PAGE_FAULTS_MAJ,
+	"context_switches":   unix.PERF_COUNT_SW_CONTEXT_SWITCHES,
+	"cpu_migrations":     unix.PERF_COUNT_SW_CPU_MIGRATIONS,
+}
+
+var perfHardwareConfigs = map[string]uint64{
+	"cpu_cycles":          unix.PERF_COUNT_HW_CPU_CYCLES,
+	"instructions":        unix.PERF_COUNT_HW_INSTRUCTIONS,
+	"cache_references":    unix.PERF_COUNT_HW_CACHE_REFERENCES,
+	"cache_misses":        unix.PERF_COUNT_HW_CACHE_MISSES,
+	"branch_instructions": unix.PERF_COUNT_HW_BRANCH_INSTRUCTIONS,
+	"branch_misses":       unix.PERF_COUNT_HW_BRANCH_MISSES,
+	"bus_cycles":          unix.PERF_COUNT_HW_BUS_CYCLES,
+	"ref_cpu_cycles":      unix.PERF_COUNT_HW_REF_CPU_CYCLES,
+}
+
+// Keep in sync with include/uapi/linux/hw_breakpoint.h, they aren't part of x/sys/unix
+const (
+	hwBreakpointR  = 1
+	hwBreakpointW  = 2
+	hwBreakpointRW = hwBreakpointR | hwBreakpointW
+	hwBreakpointX  = 4
+)
+
+var perfBreakpointTypes = map[string]uint32{
+	"r":  hwBreakpointR,
+	"w":  hwBreakpointW,
+	"rw": hwBreakpointRW,
+	"x":  hwBreakpointX,
+}
+
+var tracefsPaths = []string{
+	"/sys/kernel/tracing",
+	"/sys/kernel/debug/tracing",
+}
+
+// configGetter is implemented by *viper.Viper
+type configGetter interface {
+	GetString(key string) string
+}
+
+type perfEventConfig struct {
+	attr  unix.PerfEventAttr
+	scope string
+}
+
+// getTracepointID returns the ID of the given tracepoint (<category>/<name>) from tracefs
+func getTracepointID(tracepoint string) (uint64, error) {
+	category, name, ok := strings.Cut(tracepoint, "/")
+	if !ok || category == "" || name == "" || strings.Contains(name, "/") {
+		return 0, fmt.Errorf("invalid tracepoint %q, expected <category>/<name>", tracepoint)
+	}
+
+	var errs []error
+	for _, p := range tracefsPaths {
+		buf, err := os.ReadFile(filepath.Join(p, "events", category, name, "id"))
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		return strconv.ParseUint(strings.TrimSpace(string(buf)), 10, 64)
+	}
+	return 0, fmt.Errorf("reading id of tracepoint %q: %w", tracepoint, errors.Join(errs...))
+}
+
+// parsePerfEventConfig parses the perf event configuration of the program with the given name
+// from the gadget configuration
+func parsePerfEventConfig(cfg configGetter, name string) (*perfEventConfig, error) {
+	prefix := "programs." + name + "."
+	get := func(key string) string {
+		return cfg.GetString(prefix + key)
+	}
+
+	pc := &perfEventConfig{}
+	attr := &pc.attr
+	// Use the size of the whole struct, breakpoint fields aren't part of the first version
+	attr.Size = uint32(unsafe.Sizeof(*attr))
+
+	// Events that happen rarely are sampled every time by default
+	defaultPeriod := uint64(0)
+
+	switch typ := get("perf.type"); typ {
+	case "":
+		return nil, fmt.Errorf("perf.type not specified for program %q", name)
+	case "software", "hardware":
+		configs := perfSoftwareConfigs
+		attr.Type = unix.PERF_TYPE_SOFTWARE
+		if typ == "hardware" {
+			configs = perfHardwareConfigs
+			attr.Type = unix.PERF_TYPE_HARDWARE
+		}
+		config := get("perf.config")
+		if config == "" {
+			return nil, fmt.Errorf("perf.config not specified for program %q", name)
+		}
+		var ok bool
+		attr.Config, ok = configs[config]
+		if !ok {
+			return nil, fmt.Errorf("unsupported perf.config %q for perf.type %q", config, typ)
+		}
+	case "tracepoint":
+		tracepoint := get("perf.config")
+		if tracepoint == "" {
+			return nil, fmt.Errorf("perf.config not specified for program %q", name)
+		}
+		id, err := getTracepointID(tracepoint)
+		if err != nil {
+			return nil, err
+		}
+		attr.Type = unix.PERF_TYPE_TRACEPOINT
+		attr.Config = id
+		defaultPeriod = 1
+	case "breakpoint":
+		addrStr := get("perf.breakpoint.address")
+		if addrStr == "" {
+			return nil, fmt.Errorf("perf.breakpoint.address not specified for program %q", name)
+		}
+		addr, err := strconv.ParseUint(addrStr, 0, 64)
+		if err != nil {
+			return nil, fmt.Errorf("parsing perf.breakpoint.address %q: %w", addrStr, err)
+		}
+
+		bpTypeStr := get("perf.breakpoint.type")
+		if bpTypeStr == "" {
+			bpTypeStr = "w"
+		}
+		bpType, ok := perfBreakpointTypes[bpTypeStr]
+		if !ok {
+			return nil, fmt.Errorf("unsupported perf.breakpoint.type %q", bpTypeStr)
+		}
+
+		bpLen := uint64(8)
+		if bpLenStr := get("perf.breakpoint.len"); bpLenStr != "" {
+			bpLen, err = strconv.ParseUint(bpLenStr, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("parsing perf.breakpoint.len %q: %w", bpLenStr, err)
+			}
+			switch bpLen {
+			case 1, 2, 4, 8:
+			default:
+				return nil, fmt.Errorf("unsupported perf.breakpoint.len %d", bpLen)
+			}
+		}
+		if bpType == hwBreakpointX && bpLen != uint64(unsafe.Sizeof(uintptr(0))) {
+			return nil, fmt.Errorf("perf.breakpoint.len must be %d for execution breakpoints", unsafe.Sizeof(uintptr(0)))
+		}
+
+		attr.Type = unix.PERF_TYPE_BREAKPOINT
+		attr.Bp_type = bpType
+		attr.Ext1 = addr
+		attr.Ext2 = bpLen
+		defaultPeriod = 1
+	default:
+		return nil, fmt.Errorf("unsupported perf.type %q", typ)
+	}
+
+	switch tmp := get("perf.sampleType"); tmp {
+	case "", "sample_raw":
+		attr.Sample_type = unix.PERF_SAMPLE_RAW
+	default:
+		return nil, fmt.Errorf("unsupported perf.sampleType %q", tmp)
+	}
+
+	frequencyStr := get("sampler.frequency")
+	periodStr := get("sampler.period")
+	switch {
+	case frequencyStr != "" && periodStr != "":
+		return nil, fmt.Errorf("sampler.frequency and sampler.period are mutually exclusive for program %q", name)
+	case frequencyStr != "":
+		frequency, err := strconv.ParseUint(frequencyStr, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("parsing frequency %q for program %q: %w", frequencyStr, name, err)
+		}
+		if frequency == 0 {
+			return nil, fmt.Errorf("sampler.frequency is zero for program %q", name)
+		}
+		attr.Sample = frequency
+		attr.Bits |= unix.PerfBitFreq
+	case periodStr != "":
+		period, err := strconv.ParseUint(periodStr, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("parsing period %q for program %q: %w", periodStr, name, err)
+		}
+		if period == 0 {
+			return nil, fmt.Errorf("sampler.period is zero for program %q", name)
+		}
+		attr.Sample = period
+	case defaultPeriod != 0:
+		attr.Sample = defaultPeriod
+	default:
+		return nil, fmt.Errorf("sampler.frequency or sampler.period not specified for program %q", name)
+	}
+
+	switch pc.scope = get("perf.scope"); pc.scope {
+	case "":
+		pc.scope = perfScopeGlobal
+	case perfScopeGlobal, perfScopeCgroup:
+	default:
+		return nil, fmt.Errorf("unsupported perf.scope %q", pc.scope)
+	}
+
+	return pc, nil
+}
+
+// perfEventFds are perf events the program is attached to. Closing them detaches the program.
+type perfEventFds []int
+
+func (fds perfEventFds) Close() error {
+	for _, fd := range fds {
+		unix.Close(fd)
+	}
+	return nil
+}
+
+// openPerfEvents opens the perf event described by attr on each CPU, for the given pid (or
+// cgroup, see PERF_FLAG_PID_CGROUP) and attaches prog to them.
+func openPerfEvents(attr *unix.PerfEventAttr, pid int, flags int, prog *ebpf.Program) (_ perfEventFds, err error) {
+	fds := make(perfEventFds, 0, runtime.NumCPU())
+	defer func() {
+		if err != nil {
+			fds.Close()
+		}
+	}()
+
+	for cpu := 0; cpu < runtime.NumCPU(); cpu++ {
+		fd, err := unix.PerfEventOpen(attr, pid, cpu, -1, flags|unix.PERF_FLAG_FD_CLOEXEC)
+		if err != nil {
+			if attr.Type == unix.PERF_TYPE_HARDWARE && (errors.Is(err, unix.ENOENT) || errors.Is(err, unix.EOPNOTSUPP)) {
+				return nil, fmt.Errorf("opening perf event: hardware event not available: %w", err)
+			}
+			return nil, fmt.Errorf("opening perf event: %w", err)
+		}
+		fds = append(fds, fd)
+
+		// Attach program to perf event.
+		if err := unix.IoctlSetInt(fd, unix.PERF_EVENT_IOC_SET_BPF, prog.FD()); err != nil {
+			return nil, fmt.Errorf("attaching eBPF program to perf fd: %w", err)
+		}
+
+		// Start perf event.
+		if err := unix.IoctlSetInt(fd, unix.PERF_EVENT_IOC_ENABLE, 0); err != nil {
+			return nil, fmt.Errorf("enabling perf fd: %w", err)
+		}
+	}
+	return fds, nil
+}
+
+// openCgroupPerfEvents opens the perf event described by attr for the processes of the given
+// cgroup and attaches prog to them
+func openCgroupPerfEvents(attr *unix.PerfEventAttr, cgroupPath string, prog *ebpf.Program) (io.Closer, error) {
+	cgroup, err := os.Open(cgroupPath)
+	if err != nil {
+		return nil, fmt.Errorf("opening cgroup: %w", err)
+	}
+	defer cgroup.Close()
+
+	fds, err := openPerfEvents(attr, int(cgroup.Fd()), unix.PERF_FLAG_PID_CGROUP, prog)
+	if err != nil {
+		return nil, err
+	}
+	return fds, nil
+}
@@ -0,0 +1,172 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebpfoperator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+)
+
+type testConfig map[string]string
+
+func (c testConfig) GetString(key string) string {
+	return c[key]
+}
+
+func TestParsePerfEventConfig(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		config         testConfig
+		expectedType   uint32
+		expectedConfig uint64
+		expectedSample uint64
+		expectedFreq   bool
+		expectedScope  string
+		expectedErr    bool
+	}{
+		{
+			name: "legacy software cpu clock",
+			config: testConfig{
+				"programs.foo.perf.type":         "software",
+				"programs.foo.perf.config":       "count_sw_cpu_clock",
+				"programs.foo.perf.sampleType":   "sample_raw",
+				"programs.foo.sampler.frequency": "49",
+			},
+			expectedType:   unix.PERF_TYPE_SOFTWARE,
+			expectedConfig: unix.PERF_COUNT_SW_CPU_CLOCK,
+			expectedSample: 49,
+			expectedFreq:   true,
+			expectedScope:  perfScopeGlobal,
+		},
+		{
+			name: "software page faults per cgroup",
+			config: testConfig{
+				"programs.foo.perf.type":      "software",
+				"programs.foo.perf.config":    "page_faults",
+				"programs.foo.perf.scope":     "cgroup",
+				"programs.foo.sampler.period": "100",
+			},
+			expectedType:   unix.PERF_TYPE_SOFTWARE,
+			expectedConfig: unix.PERF_COUNT_SW_PAGE_FAULTS,
+			expectedSample: 100,
+			expectedScope:  perfScopeCgroup,
+		},
+		{
+			name: "hardware cache misses",
+			config: testConfig{
+				"programs.foo.perf.type":      "hardware",
+				"programs.foo.perf.config":    "cache_misses",
+				"programs.foo.sampler.period": "10000",
+			},
+			expectedType:   unix.PERF_TYPE_HARDWARE,
+			expectedConfig: unix.PERF_COUNT_HW_CACHE_MISSES,
+			expectedSample: 10000,
+			expectedScope:  perfScopeGlobal,
+		},
+		{
+			name: "breakpoint defaults to every hit",
+			config: testConfig{
+				"programs.foo.perf.type":               "breakpoint",
+				"programs.foo.perf.breakpoint.address": "0xffff0000",
+				"programs.foo.perf.breakpoint.type":    "rw",
+				"programs.foo.perf.breakpoint.len":     "4",
+			},
+			expectedType:   unix.PERF_TYPE_BREAKPOINT,
+			expectedSample: 1,
+			expectedScope:  perfScopeGlobal,
+		},
+		{
+			name:        "missing type",
+			config:      testConfig{},
+			expectedErr: true,
+		},
+		{
+			name: "software event with hardware config",
+			config: testConfig{
+				"programs.foo.perf.type":      "software",
+				"programs.foo.perf.config":    "cpu_cycles",
+				"programs.foo.sampler.period": "1",
+			},
+			expectedErr: true,
+		},
+		{
+			name: "missing sampler",
+			config: testConfig{
+				"programs.foo.perf.type":   "software",
+				"programs.foo.perf.config": "cpu_clock",
+			},
+			expectedErr: true,
+		},
+		{
+			name: "frequency and period",
+			config: testConfig{
+				"programs.foo.perf.type":         "software",
+				"programs.foo.perf.config":       "cpu_clock",
+				"programs.foo.sampler.frequency": "49",
+				"programs.foo.sampler.period":    "1",
+			},
+			expectedErr: true,
+		},
+		{
+			name: "invalid breakpoint length",
+			config: testConfig{
+				"programs.foo.perf.type":               "breakpoint",
+				"programs.foo.perf.breakpoint.address": "0xffff0000",
+				"programs.foo.perf.breakpoint.len":     "3",
+			},
+			expectedErr: true,
+		},
+		{
+			name: "invalid tracepoint",
+			config: testConfig{
+				"programs.foo.perf.type":   "tracepoint",
+				"programs.foo.perf.config": "sched_switch",
+			},
+			expectedErr: true,
+		},
+		{
+			name: "invalid scope",
+			config: testConfig{
+				"programs.foo.perf.type":      "software",
+				"programs.foo.perf.config":    "cpu_clock",
+				"programs.foo.perf.scope":     "pod",
+				"programs.foo.sampler.period": "1",
+			},
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg, err := parsePerfEventConfig(test.config, "foo")
+			if test.expectedErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.expectedType, cfg.attr.Type)
+			require.Equal(t, test.expectedConfig, cfg.attr.Config)
+			require.Equal(t, test.expectedSample, cfg.attr.Sample)
+			require.Equal(t, test.expectedFreq, cfg.attr.Bits&unix.PerfBitFreq != 0)
+			require.Equal(t, test.expectedScope, cfg.scope)
+		})
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebpfoperator
+
+import (
+	"errors"
+	"os"
+	"strings"
+
+	"github.com/cilium/ebpf"
+	"github.com/cilium/ebpf/link"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators"
+)
+
+// isPinnableMap returns whether the given map keeps state worth preserving across restarts.
+// Data sections are rewritten with the current parameters on each start, perf and ring buffers
+// are consumed by the running process and program arrays are filled by it.
+func isPinnableMap(m *ebpf.MapSpec, replaced bool) bool {
+	if replaced || strings.HasPrefix(m.Name, ".") {
+		return false
+	}
+	switch m.Type {
+	case ebpf.PerfEventArray, ebpf.RingBuf, ebpf.ProgramArray:
+		return false
+	}
+	return true
+}
+
+// setMapPinning makes the collection pin its maps in the pin directory of the instance, reusing
+// the ones pinned by a previous run.
+func (i *ebpfInstance) setMapPinning(opts *ebpf.CollectionOptions) {
+	for name, m := range i.collectionSpec.Maps {
+		_, replaced := opts.MapReplacements[name]
+		if isPinnableMap(m, replaced) {
+			m.Pinning = ebpf.PinByName
+		}
+	}
+	opts.Maps.PinPath = i.pinDir.MapsPath()
+}
+
+// attachProgramPinned is attachProgram for instances that pin their objects. A link pinned by a
+// previous run of the instance is updated to use prog, so the hook stays attached while the
+// instance is restarted. If the link can't be updated, prog is attached again and the previous
+// link is detached afterwards.
+func (i *ebpfInstance) attachProgramPinned(
+	gadgetCtx operators.GadgetContext,
+	progName string,
+	p *ebpf.ProgramSpec,
+	prog *ebpf.Program,
+) (link.Link, error) {
+	var prev link.Link
+	if i.pinReuse {
+		l, err := i.pinDir.LoadLink(progName)
+		switch {
+		case err == nil:
+			if err := l.Update(prog); err == nil {
+				i.logger.Debugf("reusing pinned link of program %q", progName)
+				return l, nil
+			}
+			prev = l
+		case !errors.Is(err, os.ErrNotExist):
+			i.logger.Warnf("loading pinned link of program %q: %v", progName, err)
+		}
+	}
+
+	l, err := i.attachProgram(gadgetCtx, p, prog)
+	if prev != nil {
+		prev.Unpin()
+		prev.Close()
+	}
+	if err != nil || l == nil {
+		return l, err
+	}
+
+	// Not all links can be pinned (e.g. kprobes on kernels without perf links), they're detached
+	// when the instance stops.
+	if err := i.pinDir.PinLink(progName, l); err != nil {
+		i.logger.Debugf("pinning link of program %q: %v", progName, err)
+	}
+	return l, nil
+}
@@ -26,7 +26,9 @@ import (
 // thus get the raw data from the map in a performant way.
 
 const (
+	BPF_MAP_LOOKUP_BATCH            uintptr = 24
 	BPF_MAP_LOOKUP_AND_DELETE_BATCH uintptr = 25
+	BPF_LINK_CREATE                 uintptr = 28
 )
 
 type Pointer struct {
@@ -44,6 +46,25 @@ type MapLookupBatchAttr struct {
 	Flags     uint64
 }
 
+// LinkCreateIterAttr is used instead of link.AttachIter to pass the iterator information of
+// cgroup iterators, which isn't supported by cilium/ebpf.
+type LinkCreateIterAttr struct {
+	ProgFd      uint32
+	TargetFd    uint32
+	AttachType  uint32
+	Flags       uint32
+	IterInfo    Pointer
+	IterInfoLen uint32
+	_           [36]byte
+}
+
+// IterLinkInfoCgroup is the cgroup member of union bpf_iter_link_info
+type IterLinkInfoCgroup struct {
+	Order    uint32
+	CgroupFd uint32
+	CgroupID uint64
+}
+
 func BPF(cmd uintptr, attr unsafe.Pointer, size uintptr) (uintptr, error) {
 	r1, _, errNo := unix.Syscall(unix.SYS_BPF, uintptr(cmd), uintptr(attr), size)
 	runtime.KeepAlive(attr)
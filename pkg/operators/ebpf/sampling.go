@@ -0,0 +1,186 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebpfoperator
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cilium/ebpf"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/params"
+	ebpfutils "github.com/inspektor-gadget/inspektor-gadget/pkg/utils/ebpf"
+)
+
+const (
+	ParamSampleRate       = "sample-rate"
+	ParamAdaptiveSampling = "adaptive-sampling"
+
+	// SampleRateField is added to the data sources of tracers supporting sampling, it contains
+	// the rate (1 out of N) the event was sampled with
+	SampleRateField = "sample_rate"
+
+	// sampleRateSuffix is the suffix of the variable declared by GADGET_SAMPLED_TRACER_MAP()
+	// that holds the sample rate of the buffer
+	sampleRateSuffix = "_sample_rate"
+
+	// sampleHeaderSize is the size of struct gadget_sample_header, which precedes the events of
+	// tracers supporting sampling
+	sampleHeaderSize = 8
+
+	maxSampleRate = 1 << 16
+
+	samplingInterval = time.Second
+
+	// Thresholds used by the adaptive sampling. The rate is increased if the ring buffer is
+	// filling up or the operators consuming the events are the bottleneck, and decreased again
+	// once there is enough headroom.
+	samplingFillHigh = 0.5
+	samplingFillLow  = 0.1
+	samplingBusyHigh = 0.8
+	samplingBusyLow  = 0.4
+)
+
+// samplingStats is what the adaptive sampling bases its decisions on. All values refer to the
+// last samplingInterval.
+type samplingStats struct {
+	// lost is the number of events lost because the buffer was full
+	lost uint64
+	// fill is the fraction of the ring buffer in use, or -1 if unknown (perf buffers)
+	fill float64
+	// busy is the fraction of time spent emitting events to the operators downstream
+	busy float64
+}
+
+// nextSampleRate returns the sample rate to use after cur, given the stats of the last
+// interval. The rate is doubled or halved, so with minRate being a power of two, events kept by
+// gadget_sample_key() at a given rate are also kept at all lower rates.
+func nextSampleRate(cur, minRate uint32, s samplingStats) uint32 {
+	switch {
+	case s.lost > 0 || s.fill > samplingFillHigh || s.busy > samplingBusyHigh:
+		if cur <= maxSampleRate/2 {
+			return cur * 2
+		}
+		return maxSampleRate
+	case s.fill < samplingFillLow && s.busy < samplingBusyLow:
+		if cur/2 >= minRate {
+			return cur / 2
+		}
+		return minRate
+	}
+	return cur
+}
+
+func samplingParams() []*param {
+	return []*param{
+		{
+			Param: &api.Param{
+				Key:          ParamSampleRate,
+				Title:        "Sample Rate",
+				Description:  "Only send 1 out of N events of tracers to userspace. With adaptive sampling, this is the minimum rate",
+				DefaultValue: "1",
+				TypeHint:     api.TypeUint32,
+				Tags:         []string{api.TagAdvanced, "group:eBPF"},
+			},
+		},
+		{
+			Param: &api.Param{
+				Key:          ParamAdaptiveSampling,
+				Title:        "Adaptive Sampling",
+				Description:  "Increase the sample rate of tracers when events are produced faster than they can be processed",
+				DefaultValue: "false",
+				TypeHint:     api.TypeBool,
+				Tags:         []string{api.TagAdvanced, "group:eBPF"},
+			},
+		},
+	}
+}
+
+// splitSampleHeader returns the sample rate recorded in the header of sample, and the event after
+// the header
+func splitSampleHeader(sample []byte) (uint32, []byte, error) {
+	if len(sample) < sampleHeaderSize {
+		return 0, nil, fmt.Errorf("sample of %d bytes is shorter than its header", len(sample))
+	}
+	return max(binary.NativeEndian.Uint32(sample), 1), sample[sampleHeaderSize:], nil
+}
+
+// setSampleRates sets the initial sample rate of the tracers supporting sampling
+func (i *ebpfInstance) setSampleRates(paramMap map[string]*params.Param) error {
+	p, ok := paramMap[ParamSampleRate]
+	if !ok {
+		return nil
+	}
+	rate := max(p.AsUint32(), 1)
+	adaptive := paramMap[ParamAdaptiveSampling].AsBool()
+	for _, t := range i.tracers {
+		if !t.sampling {
+			continue
+		}
+		if err := ebpfutils.SpecSetVar(i.collectionSpec, t.mapName+sampleRateSuffix, rate); err != nil {
+			return err
+		}
+		t.sampleRate.Store(rate)
+		t.minSampleRate = rate
+		t.adaptive = adaptive
+	}
+	return nil
+}
+
+// adaptSampleRate periodically adjusts the sample rate of the tracer until done is closed
+func (t *Tracer) adaptSampleRate(done <-chan struct{}) {
+	ticker := time.NewTicker(samplingInterval)
+	defer ticker.Stop()
+
+	var lastLost uint64
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+
+		lost := t.lostTotal.Load()
+		s := samplingStats{
+			lost: lost - lastLost,
+			fill: -1,
+			busy: float64(t.busyNs.Swap(0)) / float64(samplingInterval),
+		}
+		lastLost = lost
+		if t.ringbufReader != nil {
+			s.fill = float64(t.ringbufReader.AvailableBytes()) / float64(t.ringbufReader.BufferSize())
+		}
+
+		cur := t.sampleRate.Load()
+		next := nextSampleRate(cur, t.minSampleRate, s)
+		if next == cur {
+			continue
+		}
+		if err := t.sampleRateVar.Set(next); err != nil {
+			if errors.Is(err, ebpf.ErrNotSupported) {
+				t.logger.Warnf("adaptive sampling not supported for tracer map %q: %v", t.mapName, err)
+				return
+			}
+			t.logger.Warnf("setting sample rate of tracer map %q: %v", t.mapName, err)
+			continue
+		}
+		t.logger.Debugf("sample rate of tracer map %q changed from %d to %d (lost: %d, fill: %.2f, busy: %.2f)",
+			t.mapName, cur, next, s.lost, s.fill, s.busy)
+		t.sampleRate.Store(next)
+	}
+}
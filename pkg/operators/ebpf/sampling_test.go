@@ -0,0 +1,148 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebpfoperator
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNextSampleRate(t *testing.T) {
+	tests := []struct {
+		name     string
+		cur      uint32
+		minRate  uint32
+		stats    samplingStats
+		expected uint32
+	}{
+		{
+			name:     "lost events",
+			cur:      1,
+			minRate:  1,
+			stats:    samplingStats{lost: 10, fill: 0},
+			expected: 2,
+		},
+		{
+			name:     "buffer filling up",
+			cur:      4,
+			minRate:  1,
+			stats:    samplingStats{fill: 0.7},
+			expected: 8,
+		},
+		{
+			name:     "slow consumers",
+			cur:      4,
+			minRate:  1,
+			stats:    samplingStats{fill: -1, busy: 0.9},
+			expected: 8,
+		},
+		{
+			name:     "capped",
+			cur:      maxSampleRate,
+			minRate:  1,
+			stats:    samplingStats{lost: 1},
+			expected: maxSampleRate,
+		},
+		{
+			name:     "headroom",
+			cur:      8,
+			minRate:  1,
+			stats:    samplingStats{fill: 0.05, busy: 0.1},
+			expected: 4,
+		},
+		{
+			name:     "headroom with unknown fill",
+			cur:      8,
+			minRate:  1,
+			stats:    samplingStats{fill: -1, busy: 0.1},
+			expected: 4,
+		},
+		{
+			name:     "not below minimum",
+			cur:      4,
+			minRate:  4,
+			stats:    samplingStats{fill: 0},
+			expected: 4,
+		},
+		{
+			name:     "steady",
+			cur:      8,
+			minRate:  1,
+			stats:    samplingStats{fill: 0.3, busy: 0.5},
+			expected: 8,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, nextSampleRate(tt.cur, tt.minRate, tt.stats))
+		})
+	}
+}
+
+func TestSplitSampleHeader(t *testing.T) {
+	header := func(rate uint32) []byte {
+		b := make([]byte, sampleHeaderSize)
+		binary.NativeEndian.PutUint32(b, rate)
+		return b
+	}
+
+	tests := []struct {
+		name          string
+		sample        []byte
+		expectedRate  uint32
+		expectedEvent []byte
+		expectedErr   bool
+	}{
+		{
+			name:          "rate",
+			sample:        append(header(16), 1, 2, 3),
+			expectedRate:  16,
+			expectedEvent: []byte{1, 2, 3},
+		},
+		{
+			name:          "zero rate",
+			sample:        append(header(0), 1),
+			expectedRate:  1,
+			expectedEvent: []byte{1},
+		},
+		{
+			name:          "header only",
+			sample:        header(2),
+			expectedRate:  2,
+			expectedEvent: []byte{},
+		},
+		{
+			name:        "truncated header",
+			sample:      []byte{1, 0, 0},
+			expectedErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rate, event, err := splitSampleHeader(tt.sample)
+			if tt.expectedErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expectedRate, rate)
+			require.Equal(t, tt.expectedEvent, event)
+		})
+	}
+}
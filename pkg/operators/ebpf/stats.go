@@ -220,6 +220,7 @@ func (o *ebpfOperator) InstantiateDataOperator(
 			metadatav1.ColumnsAlignmentAnnotation: string(metadatav1.AlignmentRight),
 			metadatav1.DescriptionAnnotation:      "Time that the eBPF program or Gadget has run in nanoseconds",
 			"metrics.type":                        "counter",
+			"timeseries.type":                     "cumulative",
 		}),
 		datasource.WithTags("type:gadget_duration"),
 	)
@@ -232,6 +233,7 @@ func (o *ebpfOperator) InstantiateDataOperator(
 			metadatav1.ColumnsAlignmentAnnotation: string(metadatav1.AlignmentRight),
 			metadatav1.DescriptionAnnotation:      "Number of times the eBPF program or Gadget has run",
 			"metrics.type":                        "counter",
+			"timeseries.type":                     "cumulative",
 		}),
 	)
 	if err != nil {
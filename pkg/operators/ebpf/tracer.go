@@ -50,6 +50,18 @@ type Tracer struct {
 	perfReader    *perf.Reader
 	slowBuf       []byte
 	logger        logger.Logger
+
+	// sampling is set if the buffer was declared with GADGET_SAMPLED_TRACER_MAP(); its events
+	// are then preceded by a header holding the rate they were sampled with, see
+	// include/gadget/buffer.h
+	sampling           bool
+	sampleRateVar      *ebpf.Variable
+	sampleRateAccessor datasource.FieldAccessor
+	sampleRate         atomic.Uint32
+	minSampleRate      uint32
+	adaptive           bool
+	lostTotal          atomic.Uint64
+	busyNs             atomic.Int64
 }
 
 func validateTracerMap(traceMap *ebpf.MapSpec) error {
@@ -107,11 +119,15 @@ func (i *ebpfInstance) populateTracer(t btf.Type, varName string) error {
 	}
 
 	i.logger.Debugf("adding tracer %q", name)
-	i.tracers[name] = &Tracer{
+	tracer := &Tracer{
 		mapName:    mapName,
 		structName: btfStruct.Name,
 		eventSize:  btfStruct.Size,
 	}
+	// Only the buffers declared with GADGET_SAMPLED_TRACER_MAP() support sampling
+	_, tracer.sampling = i.collectionSpec.Variables[mapName+sampleRateSuffix]
+	tracer.sampleRate.Store(1)
+	i.tracers[name] = tracer
 
 	err := i.populateStructDirect(btfStruct)
 	if err != nil {
@@ -201,6 +217,7 @@ func (t *Tracer) receiveEvents(gadgetCtx operators.GadgetContext, wg *sync.WaitG
 		}
 
 		if lost > 0 {
+			t.lostTotal.Add(lost)
 			gadgetCtx.Logger().Warnf("reading event: lost %d samples", lost)
 			t.ds.ReportLostData(lost)
 			continue
@@ -214,6 +231,15 @@ func (t *Tracer) receiveEvents(gadgetCtx operators.GadgetContext, wg *sync.WaitG
 }
 
 func (t *Tracer) processEvent(gadgetCtx operators.GadgetContext, fullSample []byte) error {
+	sampleRate := uint32(1)
+	if t.sampling {
+		var err error
+		sampleRate, fullSample, err = splitSampleHeader(fullSample)
+		if err != nil {
+			return err
+		}
+	}
+
 	pSingle, err := t.ds.NewPacketSingle()
 	if err != nil {
 		return fmt.Errorf("creating new packet: %w", err)
@@ -259,6 +285,14 @@ func (t *Tracer) processEvent(gadgetCtx operators.GadgetContext, fullSample []by
 		t.restAccessor.Set(pSingle, fullSample[t.eventSize:t.eventSize+xlen])
 	}
 
+	if t.sampleRateAccessor != nil {
+		t.sampleRateAccessor.PutUint32(pSingle, sampleRate)
+	}
+
+	if t.adaptive {
+		start := time.Now()
+		defer func() { t.busyNs.Add(int64(time.Since(start))) }()
+	}
 	if err := t.ds.EmitAndRelease(pSingle); err != nil {
 		return fmt.Errorf("emitting data: %w", err)
 	}
@@ -286,6 +320,7 @@ func (i *ebpfInstance) runTracer(gadgetCtx operators.GadgetContext, tracer *Trac
 	}
 
 	tracer.mapType = m.Type()
+	tracer.logger = i.logger
 
 	var err error
 	switch m.Type() {
@@ -299,7 +334,6 @@ func (i *ebpfInstance) runTracer(gadgetCtx operators.GadgetContext, tracer *Trac
 			i.logger.Warnf("looking up lost sample map %q: not found; ring buffer lost samples will not be reported.", lostSamplesMapName)
 		}
 		tracer.lostSampleMap = lostSamplesMap
-		tracer.logger = i.logger
 	case ebpf.PerfEventArray:
 		i.logger.Debugf("creating perf reader for map %q", tracer.mapName)
 		tracer.perfReader, err = perf.NewReader(m, gadgets.PerfBufferPages*os.Getpagesize())
@@ -317,6 +351,13 @@ func (i *ebpfInstance) runTracer(gadgetCtx operators.GadgetContext, tracer *Trac
 		}
 	}
 
+	if tracer.sampling {
+		tracer.sampleRateVar = i.collection.Variables[tracer.mapName+sampleRateSuffix]
+		if tracer.adaptive && tracer.sampleRateVar != nil {
+			go tracer.adaptSampleRate(i.done)
+		}
+	}
+
 	i.wg.Add(1)
 	go tracer.receiveEvents(gadgetCtx, &i.wg)
 
@@ -22,6 +22,9 @@ const (
 	// Prefix used to mark eBPF params
 	paramPrefix = "gadget_param_"
 
+	// Prefix used to mark maps used as list params
+	paramListPrefix = "gadget_paramlist_"
+
 	// Prefix used to mark snapshotters structs.
 	// Deprecated: use iteratorsPrefix instead
 	snapshottersPrefix = "gadget_snapshotter_"
@@ -0,0 +1,136 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filter
+
+import (
+	"fmt"
+	"regexp"
+
+	"golang.org/x/exp/constraints"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+)
+
+func getElementCompareFunc[T constraints.Ordered](op comparisonType, val T) func(any) bool {
+	cmp := getCompareFunc[T](op)
+	return func(v any) bool {
+		e, ok := v.(T)
+		return ok && cmp(e, val)
+	}
+}
+
+// getElementMatchFunc returns a function that checks whether an element of a
+// List or Map field of the given kind matches the filter rule
+func getElementMatchFunc(kind api.Kind, op comparisonType, stringVal string) (func(any) bool, error) {
+	if (kind == api.Kind_String || kind == api.Kind_CString) && op == comparisonTypeRegex {
+		re, err := regexp.Compile(stringVal)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regular expression: %q", stringVal)
+		}
+		return func(v any) bool {
+			s, ok := v.(string)
+			return ok && re.MatchString(s)
+		}, nil
+	}
+
+	fv, err := parseFilterValue(kind, op, stringVal)
+	if err != nil {
+		return nil, err
+	}
+
+	switch kind {
+	case api.Kind_String, api.Kind_CString:
+		return getElementCompareFunc(op, stringVal), nil
+	case api.Kind_Int8:
+		return getElementCompareFunc(op, int8(fv.intVal)), nil
+	case api.Kind_Int16:
+		return getElementCompareFunc(op, int16(fv.intVal)), nil
+	case api.Kind_Int32:
+		return getElementCompareFunc(op, int32(fv.intVal)), nil
+	case api.Kind_Int64:
+		return getElementCompareFunc(op, fv.intVal), nil
+	case api.Kind_Uint8:
+		return getElementCompareFunc(op, uint8(fv.uintVal)), nil
+	case api.Kind_Uint16:
+		return getElementCompareFunc(op, uint16(fv.uintVal)), nil
+	case api.Kind_Uint32:
+		return getElementCompareFunc(op, uint32(fv.uintVal)), nil
+	case api.Kind_Uint64:
+		return getElementCompareFunc(op, fv.uintVal), nil
+	case api.Kind_Float32:
+		return getElementCompareFunc(op, float32(fv.floatVal)), nil
+	case api.Kind_Float64:
+		return getElementCompareFunc(op, fv.floatVal), nil
+	case api.Kind_Bool:
+		return func(v any) bool {
+			b, ok := v.(bool)
+			return ok && b == fv.boolVal
+		}, nil
+	}
+
+	return nil, fmt.Errorf("unsupported element type: %s", kind)
+}
+
+// getCompositeFilterFunc returns a filter function for a List or Map field.
+// A List field matches if any of its elements matches. A Map field matches if
+// any of its keys matches or, if key is given, if the value of key matches.
+// When negated, they match if no element (or key) matches.
+func getCompositeFilterFunc(f datasource.FieldAccessor, key string, op comparisonType, negate bool, stringVal string) (
+	func(datasource.DataSource, datasource.Data) bool, error,
+) {
+	switch {
+	case f.Type() == api.Kind_List:
+		match, err := getElementMatchFunc(f.ElementType(), op, stringVal)
+		if err != nil {
+			return nil, err
+		}
+		return func(ds datasource.DataSource, data datasource.Data) bool {
+			vals, _ := f.List(data)
+			for _, v := range vals {
+				if match(v) {
+					return !negate
+				}
+			}
+			return negate
+		}, nil
+	case f.Type() == api.Kind_Map && key == "":
+		match, err := getElementMatchFunc(api.Kind_String, op, stringVal)
+		if err != nil {
+			return nil, err
+		}
+		return func(ds datasource.DataSource, data datasource.Data) bool {
+			vals, _ := f.Map(data)
+			for k := range vals {
+				if match(k) {
+					return !negate
+				}
+			}
+			return negate
+		}, nil
+	case f.Type() == api.Kind_Map:
+		match, err := getElementMatchFunc(f.ElementType(), op, stringVal)
+		if err != nil {
+			return nil, err
+		}
+		return func(ds datasource.DataSource, data datasource.Data) bool {
+			vals, _ := f.Map(data)
+			v, ok := vals[key]
+			return (ok && match(v)) != negate
+		}, nil
+	}
+
+	return nil, fmt.Errorf("unsupported type: %s", f.Type())
+}
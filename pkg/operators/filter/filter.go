@@ -18,6 +18,7 @@ import (
 	"fmt"
 	"regexp"
 	"strconv"
+	"strings"
 
 	"golang.org/x/exp/constraints"
 
@@ -336,12 +337,22 @@ func (f *filterOperatorInstance) addFilter(gadgetCtx operators.GadgetContext, ds
 		return fmt.Errorf("extracting filter rule %q: %w", filter, err)
 	}
 
+	var ff func(datasource.DataSource, datasource.Data) bool
 	field := ds.GetField(fieldName)
 	if field == nil {
-		return fmt.Errorf("field %q not found in datasource %s", fieldName, ds.Name())
+		// Values of maps are referenced as mapfield.key
+		idx := strings.LastIndex(fieldName, ".")
+		if idx < 0 {
+			return fmt.Errorf("field %q not found in datasource %s", fieldName, ds.Name())
+		}
+		field = ds.GetField(fieldName[:idx])
+		if field == nil || field.Type() != api.Kind_Map {
+			return fmt.Errorf("field %q not found in datasource %s", fieldName, ds.Name())
+		}
+		ff, err = getCompositeFilterFunc(field, fieldName[idx+1:], op, negate, value)
+	} else {
+		ff, err = getFilterFunc(field, op, negate, value)
 	}
-
-	ff, err := getFilterFunc(field, op, negate, value)
 	if err != nil {
 		return err
 	}
@@ -350,37 +361,29 @@ func (f *filterOperatorInstance) addFilter(gadgetCtx operators.GadgetContext, ds
 	return nil
 }
 
-func getFilterFunc(f datasource.FieldAccessor, op comparisonType, negate bool, stringVal string) (
-	func(datasource.DataSource, datasource.Data) bool, error,
-) {
-	var intVal int64
-	var uintVal uint64
-	var floatVal float64
-	var boolVal bool
-	var err error
+type filterValue struct {
+	intVal   int64
+	uintVal  uint64
+	floatVal float64
+	boolVal  bool
+}
 
-	fieldType := f.Type()
+// parseFilterValue parses the value of a filter rule according to the kind of
+// the field (or element) it's compared to
+func parseFilterValue(kind api.Kind, op comparisonType, stringVal string) (filterValue, error) {
+	var fv filterValue
+	var err error
 
-	if (fieldType == api.Kind_String || fieldType == api.Kind_CString) && op == comparisonTypeRegex {
-		re, err := regexp.Compile(stringVal)
-		if err != nil {
-			return nil, fmt.Errorf("invalid regular expression: %q", stringVal)
-		}
-		return func(ds datasource.DataSource, data datasource.Data) bool {
-			val, _ := f.String(data)
-			return re.MatchString(val) != negate
-		}, nil
-	}
 	if op == comparisonTypeRegex {
-		return nil, fmt.Errorf("regex based filtering can only be used on strings")
+		return fv, fmt.Errorf("regex based filtering can only be used on strings")
 	}
 
-	if fieldType == api.Kind_Bool && op != comparisonTypeMatch {
-		return nil, fmt.Errorf("boolean values can only be filtered by exact match")
+	if kind == api.Kind_Bool && op != comparisonTypeMatch {
+		return fv, fmt.Errorf("boolean values can only be filtered by exact match")
 	}
 
 	bitSize := 64
-	switch f.Type() {
+	switch kind {
 	case api.Kind_Int8, api.Kind_Uint8:
 		bitSize = 8
 	case api.Kind_Int16, api.Kind_Uint16:
@@ -389,35 +392,63 @@ func getFilterFunc(f datasource.FieldAccessor, op comparisonType, negate bool, s
 		bitSize = 32
 	}
 
-	switch f.Type() {
+	switch kind {
 	default:
-		return nil, fmt.Errorf("unsupported field type for comparison: %s", f.Type())
+		return fv, fmt.Errorf("unsupported field type for comparison: %s", kind)
 	case api.Kind_Int8, api.Kind_Int16, api.Kind_Int32, api.Kind_Int64:
-		intVal, err = strconv.ParseInt(stringVal, 10, bitSize)
+		fv.intVal, err = strconv.ParseInt(stringVal, 10, bitSize)
 		if err != nil {
-			return nil, fmt.Errorf("parsing comparison value as int: %w", err)
+			return fv, fmt.Errorf("parsing comparison value as int: %w", err)
 		}
 	case api.Kind_Uint8, api.Kind_Uint16, api.Kind_Uint32, api.Kind_Uint64:
-		uintVal, err = strconv.ParseUint(stringVal, 10, bitSize)
+		fv.uintVal, err = strconv.ParseUint(stringVal, 10, bitSize)
 		if err != nil {
-			return nil, fmt.Errorf("parsing comparison value as uint: %w", err)
+			return fv, fmt.Errorf("parsing comparison value as uint: %w", err)
 		}
 	case api.Kind_Float32, api.Kind_Float64:
-		floatVal, err = strconv.ParseFloat(stringVal, bitSize)
+		fv.floatVal, err = strconv.ParseFloat(stringVal, bitSize)
 		if err != nil {
-			return nil, fmt.Errorf("parsing comparison value as float: %w", err)
+			return fv, fmt.Errorf("parsing comparison value as float: %w", err)
 		}
 	case api.Kind_String, api.Kind_CString, api.Kind_Invalid:
 	// Nothing to be done in this case
 	case api.Kind_Bool:
 		switch stringVal {
 		default:
-			return nil, fmt.Errorf("parsing comparison value %q as bool", stringVal)
+			return fv, fmt.Errorf("parsing comparison value %q as bool", stringVal)
 		case "true", "1":
-			boolVal = true
+			fv.boolVal = true
 		case "false", "0":
 		}
 	}
+	return fv, nil
+}
+
+func getFilterFunc(f datasource.FieldAccessor, op comparisonType, negate bool, stringVal string) (
+	func(datasource.DataSource, datasource.Data) bool, error,
+) {
+	fieldType := f.Type()
+
+	if datasource.IsCompositeKind(fieldType) {
+		return getCompositeFilterFunc(f, "", op, negate, stringVal)
+	}
+
+	if (fieldType == api.Kind_String || fieldType == api.Kind_CString) && op == comparisonTypeRegex {
+		re, err := regexp.Compile(stringVal)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regular expression: %q", stringVal)
+		}
+		return func(ds datasource.DataSource, data datasource.Data) bool {
+			val, _ := f.String(data)
+			return re.MatchString(val) != negate
+		}, nil
+	}
+
+	fv, err := parseFilterValue(fieldType, op, stringVal)
+	if err != nil {
+		return nil, err
+	}
+	intVal, uintVal, floatVal, boolVal := fv.intVal, fv.uintVal, fv.floatVal, fv.boolVal
 
 	switch f.Type() {
 	case api.Kind_String, api.Kind_CString:
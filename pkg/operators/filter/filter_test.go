@@ -478,6 +478,147 @@ func TestFilter(t *testing.T) {
 	}
 }
 
+func TestFilterComposite(t *testing.T) {
+	type testCase struct {
+		name         string
+		filterString string
+		match        bool
+		error        bool
+	}
+	testCases := []testCase{
+		{
+			name:         "list match positive",
+			filterString: "answers==1.1.1.1",
+			match:        true,
+		},
+		{
+			name:         "list match negative",
+			filterString: "answers==8.8.8.8",
+			match:        false,
+		},
+		{
+			name:         "list not match positive",
+			filterString: "answers!=8.8.8.8",
+			match:        true,
+		},
+		{
+			name:         "list not match negative",
+			filterString: "answers!=1.1.1.1",
+			match:        false,
+		},
+		{
+			name:         "list regex positive",
+			filterString: "answers~^10\\.",
+			match:        true,
+		},
+		{
+			name:         "list of numbers gte positive",
+			filterString: "ports>=8000",
+			match:        true,
+		},
+		{
+			name:         "list of numbers gt negative",
+			filterString: "ports>9000",
+			match:        false,
+		},
+		{
+			name:         "list of numbers invalid value",
+			filterString: "ports==abc",
+			error:        true,
+		},
+		{
+			name:         "map key positive",
+			filterString: "labels==app",
+			match:        true,
+		},
+		{
+			name:         "map key negative",
+			filterString: "labels==version",
+			match:        false,
+		},
+		{
+			name:         "map value positive",
+			filterString: "labels.app==nginx",
+			match:        true,
+		},
+		{
+			name:         "map value negative",
+			filterString: "labels.app==apache",
+			match:        false,
+		},
+		{
+			name:         "map missing value negated",
+			filterString: "labels.version!=1",
+			match:        true,
+		},
+		{
+			name:         "unknown subfield",
+			filterString: "answers.foo==bar",
+			error:        true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var ds datasource.DataSource
+			var answersField datasource.FieldAccessor
+			var portsField datasource.FieldAccessor
+			var labelsField datasource.FieldAccessor
+			rows := 0
+
+			err := Tester(
+				t,
+				&filterOperator{},
+				api.ParamValues{
+					"operator.filter.filter": tc.filterString,
+				},
+				func(gadgetCtx operators.GadgetContext) error {
+					var err error
+					ds, err = gadgetCtx.RegisterDataSource(datasource.TypeSingle, "filter")
+					require.NoError(t, err)
+					answersField, err = ds.AddField("answers", api.Kind_List, datasource.WithElementKind(api.Kind_String))
+					require.NoError(t, err)
+					portsField, err = ds.AddField("ports", api.Kind_List, datasource.WithElementKind(api.Kind_Uint16))
+					require.NoError(t, err)
+					labelsField, err = ds.AddField("labels", api.Kind_Map, datasource.WithElementKind(api.Kind_String))
+					require.NoError(t, err)
+					return nil
+				},
+				func(gadgetCtx operators.GadgetContext) error {
+					data, err := ds.NewPacketSingle()
+					require.NoError(t, err)
+					err = answersField.PutStringList(data, []string{"10.0.0.1", "1.1.1.1"})
+					require.NoError(t, err)
+					err = portsField.PutList(data, []any{uint16(53), uint16(8080)})
+					require.NoError(t, err)
+					err = labelsField.PutMap(data, map[string]any{"app": "nginx"})
+					require.NoError(t, err)
+					err = ds.EmitAndRelease(data)
+					require.NoError(t, err)
+					return nil
+				},
+				func(gadgetCtx operators.GadgetContext) error {
+					err := ds.Subscribe(func(ds datasource.DataSource, data datasource.Data) error {
+						rows++
+						return nil
+					}, Priority+1)
+					require.NoError(t, err)
+					return nil
+				},
+			)
+			if tc.error {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				if tc.match {
+					assert.Equal(t, rows, 1)
+				} else {
+					assert.Equal(t, rows, 0)
+				}
+			}
+		})
+	}
+}
+
 func Tester(
 	t *testing.T,
 	operator operators.DataOperator,
@@ -0,0 +1,242 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package histogram implements an operator that summarizes the exp-2 histograms held by the
+// fields of the gadget_histogram_slot__u32 and gadget_histogram_slot__u64 types. For each of
+// these fields, it adds the estimated percentiles (<field>_p50, <field>_p90, ...) and the end of
+// the highest bucket holding events (<field>_max), so they can be filtered, sorted and exported
+// by all output modes.
+package histogram
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
+	histogramformatter "github.com/inspektor-gadget/inspektor-gadget/pkg/datasource/formatters/histogram"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/histogram"
+	metadatav1 "github.com/inspektor-gadget/inspektor-gadget/pkg/metadata/v1"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/params"
+)
+
+const (
+	name = "histogram"
+	// Priority is after the timeseries operator and before filter, sort and the outputs, so the
+	// new fields can be filtered and sorted by
+	Priority = 8100
+
+	ParamPercentiles = "histogram-percentiles"
+
+	MaxSuffix = "_max"
+)
+
+type histogramOperator struct{}
+
+func (o *histogramOperator) Name() string {
+	return name
+}
+
+func (o *histogramOperator) Init(params *params.Params) error {
+	return nil
+}
+
+func (o *histogramOperator) GlobalParams() api.Params {
+	return nil
+}
+
+func (o *histogramOperator) InstanceParams() api.Params {
+	return api.Params{
+		{
+			Key:   ParamPercentiles,
+			Title: "Histogram Percentiles",
+			Description: "Percentiles to estimate for the fields holding histograms, e.g. 50,90,99.9; " +
+				"each one is added as <field>_p<percentile>. Leave empty to only add <field>" + MaxSuffix,
+			DefaultValue: "50,90,99",
+			Tags:         []string{api.TagAdvanced},
+		},
+	}
+}
+
+// parsePercentiles parses a comma-separated list of percentiles
+func parsePercentiles(value string) ([]float64, error) {
+	var res []float64
+	for _, s := range strings.Split(value, ",") {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+		p, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, fmt.Errorf("parsing percentile %q: %w", s, err)
+		}
+		if p <= 0 || p > 100 {
+			return nil, fmt.Errorf("percentile %q out of range (0, 100]", s)
+		}
+		res = append(res, p)
+	}
+	return res, nil
+}
+
+// PercentileSuffix returns the suffix of the field holding the percentile p, e.g. "_p99_9" for
+// 99.9, as dots would separate subfields
+func PercentileSuffix(p float64) string {
+	return "_p" + strings.ReplaceAll(strconv.FormatFloat(p, 'f', -1, 64), ".", "_")
+}
+
+func (o *histogramOperator) InstantiateDataOperator(gadgetCtx operators.GadgetContext, instanceParamValues api.ParamValues) (operators.DataOperatorInstance, error) {
+	percentiles, err := parsePercentiles(instanceParamValues[ParamPercentiles])
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", ParamPercentiles, err)
+	}
+
+	inst := &histogramOperatorInstance{}
+	for _, ds := range gadgetCtx.GetDataSources() {
+		var summaries []*summary
+		for _, f := range histogramformatter.HistogramFields(ds) {
+			s, err := newSummary(ds, f, percentiles)
+			if err != nil {
+				return nil, fmt.Errorf("data source %q: field %q: %w", ds.Name(), f.FullName(), err)
+			}
+			if s == nil {
+				continue
+			}
+			summaries = append(summaries, s)
+		}
+		if len(summaries) == 0 {
+			continue
+		}
+		gadgetCtx.Logger().Debugf("histogram: summarizing %d fields of %q", len(summaries), ds.Name())
+		inst.dataSources = append(inst.dataSources, ds)
+		inst.summaries = append(inst.summaries, summaries)
+	}
+	if len(inst.dataSources) == 0 {
+		return nil, nil
+	}
+	return inst, nil
+}
+
+func (o *histogramOperator) Priority() int {
+	return Priority
+}
+
+// summary computes the percentiles and the max of a histogram field
+type summary struct {
+	intervals   func(datasource.Data) []histogram.Interval
+	percentiles []float64
+	fields      []datasource.FieldAccessor
+	max         datasource.FieldAccessor
+}
+
+// newSummary adds the percentile and max fields of f; it returns nil if they have already been
+// added, e.g. on the remote side
+func newSummary(ds datasource.DataSource, f datasource.FieldAccessor, percentiles []float64) (*summary, error) {
+	if ds.GetField(f.FullName()+MaxSuffix) != nil {
+		return nil, nil
+	}
+
+	intervals, err := histogramformatter.IntervalsFunc(f)
+	if err != nil {
+		return nil, err
+	}
+	s := &summary{
+		intervals:   intervals,
+		percentiles: percentiles,
+	}
+
+	annotations := map[string]string{
+		histogramformatter.AnnotationSource:   f.FullName(),
+		metadatav1.ColumnsAlignmentAnnotation: string(metadatav1.AlignmentRight),
+	}
+	unit := ""
+	if u := f.Annotations()[histogramformatter.AnnotationUnit]; u != "" {
+		unit = " (" + u + ")"
+		annotations[histogramformatter.AnnotationUnit] = u
+	}
+	if datasource.FieldFlagHidden.In(f.Flags()) {
+		annotations[metadatav1.ColumnsHiddenAnnotation] = "true"
+	}
+
+	for _, p := range percentiles {
+		description := fmt.Sprintf("Estimated %sth percentile of %s%s", strconv.FormatFloat(p, 'f', -1, 64), f.FullName(), unit)
+		acc, err := ds.AddField(f.FullName()+PercentileSuffix(p), api.Kind_Uint64,
+			datasource.WithAnnotations(annotations),
+			datasource.WithAddedAnnotations(map[string]string{metadatav1.DescriptionAnnotation: description}))
+		if err != nil {
+			return nil, fmt.Errorf("adding percentile field: %w", err)
+		}
+		s.fields = append(s.fields, acc)
+	}
+
+	description := fmt.Sprintf("End of the highest bucket of %s holding events%s", f.FullName(), unit)
+	s.max, err = ds.AddField(f.FullName()+MaxSuffix, api.Kind_Uint64,
+		datasource.WithAnnotations(annotations),
+		datasource.WithAddedAnnotations(map[string]string{metadatav1.DescriptionAnnotation: description}))
+	if err != nil {
+		return nil, fmt.Errorf("adding max field: %w", err)
+	}
+	return s, nil
+}
+
+func (s *summary) update(data datasource.Data) {
+	h := &histogram.Histogram{Intervals: s.intervals(data)}
+	for i, p := range s.percentiles {
+		s.fields[i].PutUint64(data, h.Percentile(p))
+	}
+	s.max.PutUint64(data, h.Max())
+}
+
+type histogramOperatorInstance struct {
+	dataSources []datasource.DataSource
+	// summaries of the histogram fields, per data source
+	summaries [][]*summary
+}
+
+func (o *histogramOperatorInstance) Name() string {
+	return name
+}
+
+func (o *histogramOperatorInstance) PreStart(gadgetCtx operators.GadgetContext) error {
+	for i, ds := range o.dataSources {
+		summaries := o.summaries[i]
+		// Subscribe calls the callback for each element of arrays
+		ds.Subscribe(func(ds datasource.DataSource, data datasource.Data) error {
+			for _, s := range summaries {
+				s.update(data)
+			}
+			return nil
+		}, Priority)
+	}
+	return nil
+}
+
+func (o *histogramOperatorInstance) Start(gadgetCtx operators.GadgetContext) error {
+	return nil
+}
+
+func (o *histogramOperatorInstance) Stop(gadgetCtx operators.GadgetContext) error {
+	return nil
+}
+
+func (o *histogramOperatorInstance) Close(gadgetCtx operators.GadgetContext) error {
+	return nil
+}
+
+var Operator = &histogramOperator{}
+
+func init() {
+	operators.RegisterDataOperator(Operator)
+}
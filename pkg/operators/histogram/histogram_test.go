@@ -0,0 +1,213 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package histogram
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
+	histogramformatter "github.com/inspektor-gadget/inspektor-gadget/pkg/datasource/formatters/histogram"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+	apihelpers "github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api-helpers"
+	metadatav1 "github.com/inspektor-gadget/inspektor-gadget/pkg/metadata/v1"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators"
+	ebpftypes "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/ebpf/types"
+	gadgetcontext "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/testing/gadget-context"
+)
+
+func instantiate(t *testing.T, values api.ParamValues, dss ...datasource.DataSource) (operators.DataOperatorInstance, error) {
+	gadgetCtx := &gadgetcontext.MockGadgetContext{
+		Ctx:         context.Background(),
+		DataSources: map[string]datasource.DataSource{},
+	}
+	for _, ds := range dss {
+		gadgetCtx.DataSources[ds.Name()] = ds
+	}
+	paramValues := apihelpers.ToParamDescs(Operator.InstanceParams()).ToParams().ParamMap()
+	for k, v := range values {
+		paramValues[k] = v
+	}
+	inst, err := Operator.InstantiateDataOperator(gadgetCtx, paramValues)
+	if err != nil || inst == nil {
+		return inst, err
+	}
+	require.NoError(t, inst.(*histogramOperatorInstance).PreStart(gadgetCtx))
+	return inst, nil
+}
+
+func newDataSource(t *testing.T, dsType datasource.Type, kind api.Kind, tag string) datasource.DataSource {
+	ds, err := datasource.New(dsType, "blockio")
+	require.NoError(t, err)
+	_, err = ds.AddField("dev", api.Kind_Uint32)
+	require.NoError(t, err)
+	_, err = ds.AddField("latency", api.ArrayOf(kind), datasource.WithTags("type:"+tag),
+		datasource.WithAnnotations(map[string]string{histogramformatter.AnnotationUnit: "µs"}))
+	require.NoError(t, err)
+	return ds
+}
+
+func TestHistogramArray(t *testing.T) {
+	ds := newDataSource(t, datasource.TypeArray, api.Kind_Uint32, ebpftypes.HistogramSlotU32TypeName)
+
+	inst, err := instantiate(t, nil, ds)
+	require.NoError(t, err)
+	require.NotNil(t, inst)
+
+	latency := ds.GetField("latency")
+	fields := make(map[string]datasource.FieldAccessor)
+	for _, name := range []string{"latency_p50", "latency_p90", "latency_p99", "latency_max"} {
+		f := ds.GetField(name)
+		require.NotNil(t, f, name)
+		require.Equal(t, api.Kind_Uint64, f.Type())
+		require.Equal(t, "latency", f.Annotations()[histogramformatter.AnnotationSource])
+		require.Equal(t, "µs", f.Annotations()[histogramformatter.AnnotationUnit])
+		require.Contains(t, f.Annotations()[metadatav1.DescriptionAnnotation], "(µs)")
+		fields[name] = f
+	}
+
+	tests := []struct {
+		slots    []uint32
+		expected map[string]uint64
+	}{
+		{
+			slots:    []uint32{0, 50, 0, 50},
+			expected: map[string]uint64{"latency_p50": 3, "latency_p90": 13, "latency_p99": 14, "latency_max": 15},
+		},
+		{
+			slots:    []uint32{0, 0, 0, 0},
+			expected: map[string]uint64{"latency_p50": 0, "latency_p90": 0, "latency_p99": 0, "latency_max": 0},
+		},
+		{
+			slots:    []uint32{100, 0, 0, 0},
+			expected: map[string]uint64{"latency_p50": 0, "latency_p90": 0, "latency_p99": 0, "latency_max": 1},
+		},
+	}
+
+	arr, err := ds.NewPacketArray()
+	require.NoError(t, err)
+	for _, test := range tests {
+		e := arr.New()
+		b := make([]byte, 4*len(test.slots))
+		for i, slot := range test.slots {
+			ds.ByteOrder().PutUint32(b[4*i:], slot)
+		}
+		require.NoError(t, latency.Set(e, b))
+		arr.Append(e)
+	}
+	require.NoError(t, ds.EmitAndRelease(arr))
+
+	for i, test := range tests {
+		for name, expected := range test.expected {
+			v, err := fields[name].Uint64(arr.Get(i))
+			require.NoError(t, err)
+			require.Equal(t, expected, v, "element %d, field %s", i, name)
+		}
+	}
+}
+
+func TestHistogramParams(t *testing.T) {
+	ds := newDataSource(t, datasource.TypeSingle, api.Kind_Uint64, ebpftypes.HistogramSlotU64TypeName)
+
+	inst, err := instantiate(t, api.ParamValues{ParamPercentiles: "99.9"}, ds)
+	require.NoError(t, err)
+	require.NotNil(t, inst)
+	require.Nil(t, ds.GetField("latency_p50"))
+
+	p999 := ds.GetField("latency_p99_9")
+	require.NotNil(t, p999)
+	maxField := ds.GetField("latency_max")
+	require.NotNil(t, maxField)
+
+	data, err := ds.NewPacketSingle()
+	require.NoError(t, err)
+	b := make([]byte, 8*11)
+	ds.ByteOrder().PutUint64(b[8*10:], 1<<40)
+	require.NoError(t, ds.GetField("latency").Set(data, b))
+	require.NoError(t, ds.EmitAndRelease(data))
+
+	v, _ := p999.Uint64(data)
+	require.Equal(t, uint64(2045), v)
+	v, _ = maxField.Uint64(data)
+	require.Equal(t, uint64(2047), v)
+}
+
+func TestHistogramInstantiate(t *testing.T) {
+	tests := []struct {
+		name        string
+		ds          func() datasource.DataSource
+		values      api.ParamValues
+		expectedNil bool
+		expectedErr bool
+	}{
+		{
+			name: "histogram",
+			ds: func() datasource.DataSource {
+				return newDataSource(t, datasource.TypeSingle, api.Kind_Uint32, ebpftypes.HistogramSlotU32TypeName)
+			},
+		},
+		{
+			name: "no histogram",
+			ds: func() datasource.DataSource {
+				return newDataSource(t, datasource.TypeSingle, api.Kind_Uint32, "foo")
+			},
+			expectedNil: true,
+		},
+		{
+			name: "already summarized",
+			ds: func() datasource.DataSource {
+				ds := newDataSource(t, datasource.TypeSingle, api.Kind_Uint32, ebpftypes.HistogramSlotU32TypeName)
+				_, err := ds.AddField("latency_max", api.Kind_Uint64)
+				require.NoError(t, err)
+				return ds
+			},
+			expectedNil: true,
+		},
+		{
+			name: "invalid percentile",
+			ds: func() datasource.DataSource {
+				return newDataSource(t, datasource.TypeSingle, api.Kind_Uint32, ebpftypes.HistogramSlotU32TypeName)
+			},
+			values:      api.ParamValues{ParamPercentiles: "foo"},
+			expectedErr: true,
+		},
+		{
+			name: "percentile out of range",
+			ds: func() datasource.DataSource {
+				return newDataSource(t, datasource.TypeSingle, api.Kind_Uint32, ebpftypes.HistogramSlotU32TypeName)
+			},
+			values:      api.ParamValues{ParamPercentiles: "50,101"},
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			inst, err := instantiate(t, test.values, test.ds())
+			if test.expectedErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			if test.expectedNil {
+				require.Nil(t, inst)
+				return
+			}
+			require.NotNil(t, inst)
+		})
+	}
+}
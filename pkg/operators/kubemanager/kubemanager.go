@@ -395,6 +395,12 @@ func (k *KubeManager) InstantiateDataOperator(gadgetCtx operators.GadgetContext,
 		return nil, fmt.Errorf("invalid configuration format")
 	}
 
+	// Make the container collection available to other operators, e.g. for wasm modules
+	// looking up containers
+	if k.containerCollection != nil {
+		gadgetCtx.SetVar(operators.ContainerCollectionVar, k.containerCollection)
+	}
+
 	enableContainersDs := v.GetBool("annotations.enable-containers-datasource")
 
 	var containersPublisher *common.ContainersPublisher
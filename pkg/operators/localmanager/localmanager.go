@@ -539,6 +539,12 @@ func (l *localManager) InstantiateDataOperator(gadgetCtx operators.GadgetContext
 		return nil, fmt.Errorf("invalid configuration format")
 	}
 
+	// Make the container collection available to other operators, e.g. for wasm modules
+	// looking up containers
+	if l.containerCollection != nil {
+		gadgetCtx.SetVar(operators.ContainerCollectionVar, l.containerCollection)
+	}
+
 	enableContainersDs := v.GetBool("annotations.enable-containers-datasource")
 
 	var containersPublisher *common.ContainersPublisher
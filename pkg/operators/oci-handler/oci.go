@@ -23,6 +23,7 @@ import (
 	"strings"
 
 	"github.com/blang/semver"
+	"github.com/docker/go-units"
 	v1 "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/spf13/viper"
 	corev1 "k8s.io/api/core/v1"
@@ -48,6 +49,7 @@ const (
 	validateMetadataParam   = "validate-metadata"
 	authfileParam           = "authfile"
 	insecureRegistriesParam = "insecure-registries"
+	registriesConfigParam   = "registries-config"
 	disallowPulling         = "disallow-pulling"
 	pullParam               = "pull"
 	pullSecret              = "pull-secret"
@@ -57,6 +59,8 @@ const (
 	certificates            = "notation-certificates"
 	policyDocument          = "notation-policy-document"
 	allowedGadgets          = "allowed-gadgets"
+	maxStoreSize            = "max-store-size"
+	wasmOperatorParam       = "wasm-operator"
 
 	TagGroupOCI = "group:OCI"
 )
@@ -151,6 +155,13 @@ func (o *ociHandler) GlobalParams() api.Params {
 			Description: "List of registries to access over plain HTTP",
 			TypeHint:    api.TypeStringSlice,
 		},
+		{
+			Key:          registriesConfigParam,
+			Title:        "Registries config",
+			Description:  "Path of a containers-registries.conf file used to rewrite registry locations and to pull from mirrors",
+			DefaultValue: oci.DefaultRegistriesConfig,
+			TypeHint:     api.TypeString,
+		},
 		{
 			Key:          disallowPulling,
 			Title:        "Disallow pulling",
@@ -165,6 +176,13 @@ func (o *ociHandler) GlobalParams() api.Params {
 			DefaultValue: oci.DefaultAuthFile,
 			TypeHint:     api.TypeString,
 		},
+		{
+			Key:          maxStoreSize,
+			Title:        "Maximum store size",
+			Description:  "Maximum size of the local gadget image store (e.g. 1GiB). Least recently used images are removed when it's exceeded. 0 disables the limit",
+			DefaultValue: "0",
+			TypeHint:     api.TypeString,
+		},
 	}
 
 	if environment.Environment == environment.Kubernetes {
@@ -212,6 +230,13 @@ func (o *ociHandler) InstanceParams() api.Params {
 			TypeHint: api.TypeStringSlice,
 			Tags:     []string{api.TagAdvanced, TagGroupOCI},
 		},
+		{
+			Key:         wasmOperatorParam,
+			Title:       "Wasm operators",
+			Description: "Operator images with wasm modules to run along the gadget, e.g. to enrich, redact or drop fields of its data sources",
+			TypeHint:    api.TypeStringSlice,
+			Tags:        []string{TagGroupOCI},
+		},
 	}
 }
 
@@ -251,10 +276,12 @@ func (o *ociHandler) InstantiateDataOperator(gadgetCtx operators.GadgetContext,
 
 	err = instance.init(gadgetCtx)
 	if err != nil {
+		instance.releaseImage()
 		return nil, err
 	}
 
 	if len(instance.imageOperatorInstances) == 0 {
+		instance.releaseImage()
 		return nil, nil
 	}
 
@@ -386,6 +413,7 @@ func (o *OciHandlerInstance) init(gadgetCtx operators.GadgetContext) error {
 			AuthFile:           o.globalParams.Get(authfileParam).AsString(),
 			SecretBytes:        secretBytes,
 			InsecureRegistries: o.globalParams.Get(insecureRegistriesParam).AsStringSlice(),
+			RegistriesConfig:   o.globalParams.Get(registriesConfigParam).AsString(),
 			DisallowPulling:    o.globalParams.Get(disallowPulling).AsBool(),
 		},
 		VerifyOptions: o.ociHandler.verifyOpts,
@@ -401,9 +429,17 @@ func (o *OciHandlerInstance) init(gadgetCtx operators.GadgetContext) error {
 	// If the target wasn't explicitly set, use the local store. In this case we
 	// need to be sure the image is available.
 	if target == nil {
+		// Keep the image from being evicted from the local store by other
+		// gadget instances while this one uses it
+		release, err := oci.UseImage(gadgetCtx.ImageName())
+		if err != nil {
+			return fmt.Errorf("using image: %w", err)
+		}
+		o.imageRelease = release
+
 		// Make sure the image is available, either through pulling or by just accessing a local copy
 		// TODO: add security constraints (e.g. don't allow pulling - add GlobalParams for that)
-		err := oci.EnsureImage(gadgetCtx.Context(), gadgetCtx.ImageName(),
+		err = oci.EnsureImage(gadgetCtx.Context(), gadgetCtx.ImageName(),
 			imgOpts, o.instanceParams.Get(pullParam).AsString())
 		if err != nil {
 			return fmt.Errorf("ensuring image: %w", err)
@@ -413,6 +449,8 @@ func (o *OciHandlerInstance) init(gadgetCtx operators.GadgetContext) error {
 		if err != nil {
 			return fmt.Errorf("verifying image: %w", err)
 		}
+
+		o.enforceMaxStoreSize(gadgetCtx)
 	}
 
 	manifest, err := oci.GetManifestForHost(gadgetCtx.Context(), target, gadgetCtx.ImageName())
@@ -493,6 +531,12 @@ func (o *OciHandlerInstance) init(gadgetCtx operators.GadgetContext) error {
 		o.imageOperatorInstances = append(o.imageOperatorInstances, opInst)
 	}
 
+	// Operator images are instantiated after the gadget so they can access its data sources
+	operatorImageParams, err := o.initOperatorImages(gadgetCtx, imgOpts)
+	if err != nil {
+		return err
+	}
+
 	if len(o.imageOperatorInstances) == 0 {
 		return nil
 	}
@@ -507,16 +551,44 @@ func (o *OciHandlerInstance) init(gadgetCtx operators.GadgetContext) error {
 
 	extraParams := make([]*api.Param, 0)
 	for _, opInst := range o.imageOperatorInstances {
+		if slices.Contains(o.operatorImageInstances, opInst) {
+			continue
+		}
 		if extra, ok := opInst.(operators.ExtraParams); ok {
 			params := extra.ExtraParams(gadgetCtx)
 			extraParams = append(extraParams, params.AddPrefix(opInst.Name())...)
 		}
 	}
 
-	o.extraParams = extraParams
+	o.extraParams = append(extraParams, operatorImageParams...)
 	return nil
 }
 
+// enforceMaxStoreSize evicts least recently used images from the local store
+// if it's bigger than the configured maximum size. The images used by running
+// gadget instances are never evicted.
+func (o *OciHandlerInstance) enforceMaxStoreSize(gadgetCtx operators.GadgetContext) {
+	maxSize, err := units.RAMInBytes(o.globalParams.Get(maxStoreSize).AsString())
+	if err != nil {
+		gadgetCtx.Logger().Warnf("invalid %s: %v", maxStoreSize, err)
+		return
+	}
+	if maxSize <= 0 {
+		return
+	}
+
+	report, err := oci.PruneGadgetImages(gadgetCtx.Context(), &oci.PruneOptions{
+		MaxStoreSize: maxSize,
+	})
+	if err != nil {
+		gadgetCtx.Logger().Warnf("enforcing maximum store size: %v", err)
+		return
+	}
+	for _, image := range report.Images {
+		gadgetCtx.Logger().Debugf("evicted image %s from the local store", image)
+	}
+}
+
 func (o *OciHandlerInstance) PreStart(gadgetCtx operators.GadgetContext) error {
 	for _, opInst := range o.imageOperatorInstances {
 		if preStart, ok := opInst.(operators.PreStart); ok {
@@ -600,17 +672,30 @@ func (o *OciHandlerInstance) Close(gadgetCtx operators.GadgetContext) error {
 		errs = append(errs, opInst.Close(o.gadgetCtx))
 	}
 
+	o.releaseImage()
+
 	return errors.Join(errs...)
 }
 
+// releaseImage allows the image to be evicted from the local store again
+func (o *OciHandlerInstance) releaseImage() {
+	if o.imageRelease != nil {
+		o.imageRelease()
+	}
+}
+
 type OciHandlerInstance struct {
 	ociHandler             *ociHandler
 	gadgetCtx              operators.GadgetContext
 	imageOperatorInstances []operators.ImageOperatorInstance
+	// subset of imageOperatorInstances coming from operator images
+	operatorImageInstances []operators.ImageOperatorInstance
 	extraParams            api.Params
 	paramValues            api.ParamValues
 	globalParams           *params.Params
 	instanceParams         *params.Params
+	// imageRelease releases the image marked as used with oci.UseImage
+	imageRelease func()
 }
 
 func (o *OciHandlerInstance) Name() string {
@@ -28,6 +28,8 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
 )
 
 func TestCheckBuilderVersion(t *testing.T) {
@@ -368,3 +370,64 @@ func TestConstructTempConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestOperatorImageName(t *testing.T) {
+	tests := []struct {
+		name     string
+		image    string
+		wantName string
+		wantErr  bool
+	}{
+		{
+			name:     "Full reference",
+			image:    "ghcr.io/acme/redact-secrets:v1",
+			wantName: "redact-secrets",
+		},
+		{
+			name:     "Digest",
+			image:    "ghcr.io/acme/tools/redact-secrets@sha256:0123456789012345678901234567890123456789012345678901234567890123",
+			wantName: "redact-secrets",
+		},
+		{
+			name:     "Default registry",
+			image:    "redact-secrets",
+			wantName: "redact-secrets",
+		},
+		{
+			name:    "Invalid reference",
+			image:   "ghcr.io/acme/Redact:v1",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name, err := operatorImageName(tt.image)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantName, name)
+		})
+	}
+}
+
+func TestOperatorImageParams(t *testing.T) {
+	params := api.Params{
+		{Key: "pattern", Prefix: "", DefaultValue: "secret"},
+		{Key: "replacement", Prefix: ""},
+	}
+
+	got := operatorImageParams("redact-secrets", params)
+	require.Len(t, got, 2)
+	assert.Equal(t, "redact-secrets.pattern", got[0].Key)
+	assert.Equal(t, wasmOperatorParam+".", got[0].Prefix)
+	assert.Equal(t, "secret", got[0].DefaultValue)
+	assert.Equal(t, "redact-secrets.replacement", got[1].Key)
+
+	// the params of the operator instance are left untouched
+	assert.Equal(t, "pattern", params[0].Key)
+	assert.Empty(t, params[0].Prefix)
+	assert.Equal(t, "replacement", params[1].Key)
+}
@@ -0,0 +1,147 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ocihandler
+
+import (
+	"fmt"
+	"io"
+	"path"
+
+	"github.com/distribution/reference"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/oci"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators"
+)
+
+// operatorImageName returns the name identifying an operator image, i.e. the last component of
+// its repository: "redact-secrets" for "ghcr.io/acme/redact-secrets:v1"
+func operatorImageName(image string) (string, error) {
+	domain, remainder := oci.SplitIGDomain(image)
+	named, err := reference.ParseNormalizedNamed(domain + "/" + remainder)
+	if err != nil {
+		return "", fmt.Errorf("parsing operator image %q: %w", image, err)
+	}
+	return path.Base(reference.Path(named)), nil
+}
+
+// initOperatorImages instantiates the operator images passed with --wasm-operator. They're
+// always loaded from the local store, pulled and verified like gadget images. It returns the
+// params of the instantiated operators.
+func (o *OciHandlerInstance) initOperatorImages(gadgetCtx operators.GadgetContext, imgOpts *oci.ImageOptions) (api.Params, error) {
+	var extraParams api.Params
+
+	names := map[string]string{}
+	for _, image := range o.instanceParams.Get(wasmOperatorParam).AsStringSlice() {
+		if image == "" {
+			continue
+		}
+
+		name, err := operatorImageName(image)
+		if err != nil {
+			return nil, err
+		}
+		if other, ok := names[name]; ok {
+			return nil, fmt.Errorf("operator images %q and %q have the same name %q", other, image, name)
+		}
+		names[name] = image
+
+		params, err := o.initOperatorImage(gadgetCtx, imgOpts, image, name)
+		if err != nil {
+			return nil, fmt.Errorf("operator image %q: %w", image, err)
+		}
+		extraParams = append(extraParams, params...)
+	}
+
+	return extraParams, nil
+}
+
+func (o *OciHandlerInstance) initOperatorImage(
+	gadgetCtx operators.GadgetContext,
+	imgOpts *oci.ImageOptions,
+	image string,
+	name string,
+) (api.Params, error) {
+	err := oci.EnsureImage(gadgetCtx.Context(), image, imgOpts, o.instanceParams.Get(pullParam).AsString())
+	if err != nil {
+		return nil, fmt.Errorf("ensuring image: %w", err)
+	}
+
+	err = oci.VerifyGadgetImage(gadgetCtx.Context(), image, imgOpts)
+	if err != nil {
+		return nil, fmt.Errorf("verifying image: %w", err)
+	}
+
+	manifest, err := oci.GetManifestForHost(gadgetCtx.Context(), nil, image)
+	if err != nil {
+		return nil, fmt.Errorf("getting manifest: %w", err)
+	}
+
+	r, err := oci.GetContentFromDescriptor(gadgetCtx.Context(), nil, manifest.Config)
+	if err != nil {
+		return nil, fmt.Errorf("getting metadata: %w", err)
+	}
+	metadata, err := io.ReadAll(r)
+	r.Close()
+	if err != nil {
+		return nil, fmt.Errorf("reading metadata: %w", err)
+	}
+
+	// Params of operator images are named "operator.oci.wasm-operator.<name>.<key>"
+	paramValues := o.paramValues.ExtractPrefixedValues(wasmOperatorParam + "." + name)
+
+	var extraParams api.Params
+	for _, layer := range manifest.Layers {
+		op, ok := operators.GetImageOperatorForMediaType(layer.MediaType)
+		if !ok {
+			return nil, fmt.Errorf("no operator found for media type %q", layer.MediaType)
+		}
+		standaloneOp, ok := op.(operators.StandaloneImageOperator)
+		if !ok {
+			return nil, fmt.Errorf("operator %q can't be used in operator images", op.Name())
+		}
+
+		opInst, err := standaloneOp.InstantiateStandaloneImageOperator(gadgetCtx, name, nil, layer, metadata, paramValues)
+		if err != nil {
+			return nil, fmt.Errorf("instantiating operator %q: %w", op.Name(), err)
+		}
+		if opInst == nil {
+			continue
+		}
+		o.imageOperatorInstances = append(o.imageOperatorInstances, opInst)
+		o.operatorImageInstances = append(o.operatorImageInstances, opInst)
+
+		if extra, ok := opInst.(operators.ExtraParams); ok {
+			extraParams = append(extraParams, operatorImageParams(name, extra.ExtraParams(gadgetCtx))...)
+		}
+	}
+
+	return extraParams, nil
+}
+
+// operatorImageParams returns copies of the params of the operator image name, as they're owned by
+// the operator instance. Flags are named after the key of the params only, so the name of the
+// operator image is included to avoid clashes with the params of the gadget or other operator
+// images.
+func operatorImageParams(name string, params api.Params) api.Params {
+	ret := make(api.Params, 0, len(params))
+	for _, p := range params {
+		p, _ = proto.Clone(p).(*api.Param)
+		p.Key = name + "." + p.Key
+		ret = append(ret, p.AddPrefix(wasmOperatorParam))
+	}
+	return ret
+}
@@ -55,6 +55,10 @@ const (
 	MapPrefix string = "map/"
 
 	MapSpecPrefix string = "mapspec/"
+
+	// ContainerCollectionVar is the variable the operator managing containers (localmanager or
+	// kubemanager) stores its *containercollection.ContainerCollection in, if it has one.
+	ContainerCollectionVar string = "containerCollection"
 )
 
 type ImageOperator interface {
@@ -71,6 +75,25 @@ type ImageOperator interface {
 	) (ImageOperatorInstance, error)
 }
 
+// StandaloneImageOperator is implemented by image operators whose layers can also be shipped in
+// operator images, i.e. images that don't contain a gadget but are attached to the run of any
+// gadget (e.g. "ig run trace_exec --wasm-operator ghcr.io/acme/redact-secrets:v1").
+type StandaloneImageOperator interface {
+	ImageOperator
+
+	// InstantiateStandaloneImageOperator is like InstantiateImageOperator, but for a layer of
+	// an operator image. name identifies the operator image and metadata holds its own
+	// metadata, as opposed to the one of the gadget.
+	InstantiateStandaloneImageOperator(
+		gadgetCtx GadgetContext,
+		name string,
+		target oras.ReadOnlyTarget,
+		descriptor ocispec.Descriptor,
+		metadata []byte,
+		paramValues api.ParamValues,
+	) (ImageOperatorInstance, error)
+}
+
 type ImageOperatorInstance interface {
 	Name() string
 	Start(gadgetCtx GadgetContext) error
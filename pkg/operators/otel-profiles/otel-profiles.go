@@ -32,6 +32,7 @@ import (
 	"github.com/inspektor-gadget/inspektor-gadget/internal/version"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/config"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource/formatters/profiles"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
 	apihelpers "github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api-helpers"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators"
@@ -49,12 +50,12 @@ const (
 	CompressionNone = "none"
 	CompressionGZIP = "gzip"
 
-	stackFieldsAnnotation     = "profiles.stack-fields"
-	valueFieldAnnotation      = "profiles.value-field"
-	sampleAttributeAnnotation = "profiles.sample-attribute"
-	profilesNameAnnotation    = "profiles.name"
-	profilesTypeAnnotation    = "profiles.type"
-	profilesUnitAnnotation    = "profiles.unit"
+	stackFieldsAnnotation     = profiles.StackFieldsAnnotation
+	valueFieldAnnotation      = profiles.ValueFieldAnnotation
+	sampleAttributeAnnotation = profiles.SampleAttributeAnnotation
+	profilesNameAnnotation    = profiles.NameAnnotation
+	profilesTypeAnnotation    = profiles.TypeAnnotation
+	profilesUnitAnnotation    = profiles.UnitAnnotation
 
 	tagGroupOtelProfiles = "group:OpenTelemetry Profiles"
 
@@ -0,0 +1,392 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package parquet implements an operator that writes the data sources of a gadget to Apache
+// Parquet files, for offline analysis with tools like DuckDB or pandas.
+package parquet
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/docker/go-units"
+
+	"github.com/inspektor-gadget/inspektor-gadget/internal/version"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
+	parquetformatter "github.com/inspektor-gadget/inspektor-gadget/pkg/datasource/formatters/parquet"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/params"
+)
+
+const (
+	name     = "parquet"
+	Priority = 9999
+
+	ParamDir          = "parquet-dir"
+	ParamMaxFileSize  = "parquet-max-file-size"
+	ParamMaxFileAge   = "parquet-max-file-age"
+	ParamRowGroupSize = "parquet-row-group-size"
+	ParamCompression  = "parquet-compression"
+
+	// MetadataGadget is the key of the file metadata holding the image of the gadget
+	MetadataGadget = "inspektor-gadget.gadget"
+
+	TagGroupParquet = "group:Parquet Export"
+
+	// Files are written with this suffix and renamed once they are complete
+	partialSuffix = ".partial"
+)
+
+type parquetOperator struct{}
+
+func (o *parquetOperator) Name() string {
+	return name
+}
+
+func (o *parquetOperator) Init(params *params.Params) error {
+	return nil
+}
+
+func (o *parquetOperator) GlobalParams() api.Params {
+	return nil
+}
+
+func (o *parquetOperator) InstanceParams() api.Params {
+	return api.Params{
+		{
+			Key:         ParamDir,
+			Title:       "Parquet Directory",
+			Description: "Directory to write the data sources to as Parquet files; export is disabled if empty",
+			Tags:        []string{TagGroupParquet},
+		},
+		{
+			Key:          ParamMaxFileSize,
+			Title:        "Parquet Max File Size",
+			Description:  "Size (e.g. 128MB) after which a new Parquet file is started; 0 disables rotation by size",
+			DefaultValue: "128MB",
+			TypeHint:     api.TypeString,
+			Tags:         []string{TagGroupParquet},
+		},
+		{
+			Key:          ParamMaxFileAge,
+			Title:        "Parquet Max File Age",
+			Description:  "Time after which a new Parquet file is started; 0 disables rotation by time",
+			DefaultValue: "1h",
+			TypeHint:     api.TypeDuration,
+			Tags:         []string{TagGroupParquet},
+		},
+		{
+			Key:          ParamRowGroupSize,
+			Title:        "Parquet Row Group Size",
+			Description:  "Number of events buffered before they are written as a row group; events of array data sources are written as one row group per array",
+			DefaultValue: "65536",
+			TypeHint:     api.TypeUint,
+			Tags:         []string{TagGroupParquet, api.TagAdvanced},
+		},
+		{
+			Key:            ParamCompression,
+			Title:          "Parquet Compression",
+			Description:    "Compression codec of the Parquet files",
+			DefaultValue:   parquetformatter.CompressionZstd,
+			PossibleValues: parquetformatter.Compressions,
+			Tags:           []string{TagGroupParquet},
+		},
+	}
+}
+
+func (o *parquetOperator) InstantiateDataOperator(gadgetCtx operators.GadgetContext, instanceParamValues api.ParamValues) (operators.DataOperatorInstance, error) {
+	dir := instanceParamValues[ParamDir]
+	// Files are written where the data ends up, not on the remote side
+	if dir == "" || gadgetCtx.IsRemoteCall() {
+		return nil, nil
+	}
+
+	maxFileSize, err := units.RAMInBytes(instanceParamValues[ParamMaxFileSize])
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", ParamMaxFileSize, err)
+	}
+	maxFileAge, err := time.ParseDuration(instanceParamValues[ParamMaxFileAge])
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", ParamMaxFileAge, err)
+	}
+	rowGroupSize, err := strconv.ParseUint(instanceParamValues[ParamRowGroupSize], 10, 31)
+	if err != nil || rowGroupSize == 0 {
+		return nil, fmt.Errorf("parsing %s: expected a positive number, got %q", ParamRowGroupSize,
+			instanceParamValues[ParamRowGroupSize])
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("creating parquet directory: %w", err)
+	}
+
+	return &parquetOperatorInstance{
+		dir:          dir,
+		maxFileSize:  maxFileSize,
+		maxFileAge:   maxFileAge,
+		rowGroupSize: int(rowGroupSize),
+		options: []parquetformatter.Option{
+			parquetformatter.WithCompression(instanceParamValues[ParamCompression]),
+			parquetformatter.WithCreatedBy("inspektor-gadget version " + version.Version().String()),
+			parquetformatter.WithMetadata(MetadataGadget, gadgetCtx.ImageName()),
+		},
+		done: make(chan struct{}),
+	}, nil
+}
+
+func (o *parquetOperator) Priority() int {
+	return Priority
+}
+
+type parquetOperatorInstance struct {
+	dir          string
+	maxFileSize  int64
+	maxFileAge   time.Duration
+	rowGroupSize int
+	options      []parquetformatter.Option
+
+	mu        sync.Mutex
+	series    []*series
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// series is the sequence of files a data source is written to
+type series struct {
+	ds datasource.DataSource
+
+	file   *os.File
+	buf    *bufio.Writer
+	w      *parquetformatter.Writer
+	path   string
+	opened time.Time
+
+	// err is set when writing failed; the data source isn't written anymore then
+	err error
+}
+
+func (s *series) open(dir string, options []parquetformatter.Option) error {
+	now := time.Now()
+	base := filepath.Join(dir, fmt.Sprintf("%s-%s", s.ds.Name(), now.UTC().Format("20060102T150405Z")))
+	for i := 0; ; i++ {
+		s.path = base + ".parquet"
+		if i > 0 {
+			s.path = fmt.Sprintf("%s-%d.parquet", base, i)
+		}
+		if _, err := os.Stat(s.path); err == nil {
+			continue
+		}
+		f, err := os.OpenFile(s.path+partialSuffix, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if errors.Is(err, fs.ErrExist) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("creating parquet file: %w", err)
+		}
+		s.file = f
+		break
+	}
+
+	s.buf = bufio.NewWriter(s.file)
+	w, err := parquetformatter.New(s.buf, s.ds, options...)
+	if err != nil {
+		s.file.Close()
+		os.Remove(s.file.Name())
+		s.file = nil
+		return fmt.Errorf("creating parquet writer: %w", err)
+	}
+	s.w = w
+	s.opened = now
+	return nil
+}
+
+// close finishes the current file, if any, and moves it to its final name
+func (s *series) close() error {
+	if s.file == nil {
+		return nil
+	}
+	f := s.file
+	s.file = nil
+	err := errors.Join(s.w.Close(), s.buf.Flush(), f.Close())
+	if err != nil {
+		return fmt.Errorf("writing parquet file %q: %w", s.path, err)
+	}
+	if err := os.Rename(f.Name(), s.path); err != nil {
+		return fmt.Errorf("renaming parquet file: %w", err)
+	}
+	return nil
+}
+
+// discard removes the file being written after an error, which can't be finished anymore
+func (s *series) discard() error {
+	if s.file != nil {
+		s.file.Close()
+		s.file = nil
+	}
+	if s.path == "" {
+		return nil
+	}
+	err := os.Remove(s.path + partialSuffix)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("removing parquet file: %w", err)
+	}
+	return nil
+}
+
+func (o *parquetOperatorInstance) expired(s *series) bool {
+	if s.file == nil {
+		return false
+	}
+	return (o.maxFileSize > 0 && s.w.Size() >= o.maxFileSize) ||
+		(o.maxFileAge > 0 && time.Since(s.opened) >= o.maxFileAge)
+}
+
+// write calls fn with the writer of the current file of s, rotating files as needed
+func (o *parquetOperatorInstance) write(gadgetCtx operators.GadgetContext, s *series, fn func(w *parquetformatter.Writer) error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if s.err != nil {
+		return
+	}
+	err := func() error {
+		if s.file == nil {
+			if err := s.open(o.dir, o.options); err != nil {
+				return err
+			}
+		}
+		if err := fn(s.w); err != nil {
+			return fmt.Errorf("writing parquet file %q: %w", s.path, err)
+		}
+		if o.expired(s) {
+			return s.close()
+		}
+		return nil
+	}()
+	if err != nil {
+		s.err = err
+		gadgetCtx.Logger().Errorf("%v; not writing data source %q anymore", err, s.ds.Name())
+	}
+}
+
+func (o *parquetOperatorInstance) Name() string {
+	return name
+}
+
+func (o *parquetOperatorInstance) PreStart(gadgetCtx operators.GadgetContext) error {
+	for _, ds := range gadgetCtx.GetDataSources() {
+		s := &series{ds: ds}
+		// Fail early on invalid options
+		if _, err := parquetformatter.New(io.Discard, ds, o.options...); err != nil {
+			if errors.Is(err, parquetformatter.ErrNoFields) {
+				gadgetCtx.Logger().Debugf("parquet: not writing data source %q: %v", ds.Name(), err)
+				continue
+			}
+			return fmt.Errorf("data source %q: %w", ds.Name(), err)
+		}
+		o.series = append(o.series, s)
+
+		switch ds.Type() {
+		case datasource.TypeSingle:
+			ds.Subscribe(func(ds datasource.DataSource, data datasource.Data) error {
+				o.write(gadgetCtx, s, func(w *parquetformatter.Writer) error {
+					if err := w.Write(data); err != nil {
+						return err
+					}
+					if w.Buffered() >= o.rowGroupSize {
+						return w.Flush()
+					}
+					return nil
+				})
+				return nil
+			}, Priority)
+		case datasource.TypeArray:
+			ds.SubscribeArray(func(ds datasource.DataSource, dataArray datasource.DataArray) error {
+				o.write(gadgetCtx, s, func(w *parquetformatter.Writer) error {
+					return w.WriteArray(dataArray)
+				})
+				return nil
+			}, Priority)
+		}
+	}
+	return nil
+}
+
+func (o *parquetOperatorInstance) Start(gadgetCtx operators.GadgetContext) error {
+	if o.maxFileAge <= 0 {
+		return nil
+	}
+	// Rotate files by age even if no more events arrive
+	go func() {
+		ticker := time.NewTicker(min(o.maxFileAge, time.Second))
+		defer ticker.Stop()
+		for {
+			select {
+			case <-o.done:
+				return
+			case <-ticker.C:
+			}
+			o.mu.Lock()
+			for _, s := range o.series {
+				if s.err != nil || !o.expired(s) {
+					continue
+				}
+				if err := s.close(); err != nil {
+					s.err = err
+					gadgetCtx.Logger().Errorf("%v; not writing data source %q anymore", err, s.ds.Name())
+				}
+			}
+			o.mu.Unlock()
+		}
+	}()
+	return nil
+}
+
+func (o *parquetOperatorInstance) Stop(gadgetCtx operators.GadgetContext) error {
+	return nil
+}
+
+func (o *parquetOperatorInstance) Close(gadgetCtx operators.GadgetContext) error {
+	o.closeOnce.Do(func() {
+		close(o.done)
+	})
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	// Data sources can emit until they are stopped, so files are only finished here
+	var errs []error
+	for _, s := range o.series {
+		if s.err != nil {
+			errs = append(errs, s.discard())
+			continue
+		}
+		errs = append(errs, s.close())
+	}
+	return errors.Join(errs...)
+}
+
+var Operator = &parquetOperator{}
+
+func init() {
+	operators.RegisterDataOperator(Operator)
+}
@@ -0,0 +1,188 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parquet
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+	apihelpers "github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api-helpers"
+	gadgetcontext "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/testing/gadget-context"
+)
+
+// paramValues returns the default values of the params, overridden by values
+func paramValues(values api.ParamValues) api.ParamValues {
+	res := apihelpers.ToParamDescs(Operator.InstanceParams()).ToParams().ParamMap()
+	for k, v := range values {
+		res[k] = v
+	}
+	return res
+}
+
+func TestParquetOperator(t *testing.T) {
+	single, err := datasource.New(datasource.TypeSingle, "events")
+	require.NoError(t, err)
+	pid, err := single.AddField("pid", api.Kind_Uint32)
+	require.NoError(t, err)
+
+	array, err := datasource.New(datasource.TypeArray, "stats")
+	require.NoError(t, err)
+	count, err := array.AddField("count", api.Kind_Int64)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		values api.ParamValues
+		// files expected per data source
+		expectedFiles int
+	}{
+		{
+			name:          "default",
+			expectedFiles: 1,
+		},
+		{
+			name:          "rotate by size",
+			values:        api.ParamValues{ParamMaxFileSize: "1", ParamRowGroupSize: "1"},
+			expectedFiles: 3,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := filepath.Join(t.TempDir(), "out")
+			gadgetCtx := &gadgetcontext.MockGadgetContext{
+				Ctx: context.Background(),
+				DataSources: map[string]datasource.DataSource{
+					"events": single,
+					"stats":  array,
+				},
+			}
+			values := paramValues(test.values)
+			values[ParamDir] = dir
+			op, err := Operator.InstantiateDataOperator(gadgetCtx, values)
+			require.NoError(t, err)
+			inst := op.(*parquetOperatorInstance)
+			require.NoError(t, inst.PreStart(gadgetCtx))
+			require.NoError(t, inst.Start(gadgetCtx))
+
+			for i := range 3 {
+				data, err := single.NewPacketSingle()
+				require.NoError(t, err)
+				require.NoError(t, pid.PutUint32(data, uint32(i)))
+				require.NoError(t, single.EmitAndRelease(data))
+
+				arr, err := array.NewPacketArray()
+				require.NoError(t, err)
+				e := arr.New()
+				require.NoError(t, count.PutInt64(e, int64(i)))
+				arr.Append(e)
+				require.NoError(t, array.EmitAndRelease(arr))
+			}
+
+			require.NoError(t, inst.Stop(gadgetCtx))
+			require.NoError(t, inst.Close(gadgetCtx))
+
+			for _, ds := range []string{"events", "stats"} {
+				files, err := filepath.Glob(filepath.Join(dir, ds+"-*.parquet"))
+				require.NoError(t, err)
+				require.Len(t, files, test.expectedFiles)
+				for _, file := range files {
+					b, err := os.ReadFile(file)
+					require.NoError(t, err)
+					require.Equal(t, "PAR1", string(b[:4]))
+					require.Equal(t, "PAR1", string(b[len(b)-4:]))
+				}
+			}
+			partial, err := filepath.Glob(filepath.Join(dir, "*"+partialSuffix))
+			require.NoError(t, err)
+			require.Empty(t, partial)
+		})
+	}
+}
+
+func TestParquetOperatorErrors(t *testing.T) {
+	events, err := datasource.New(datasource.TypeSingle, "events")
+	require.NoError(t, err)
+	pid, err := events.AddField("pid", api.Kind_Uint32)
+	require.NoError(t, err)
+
+	// Data sources without fields that can be written are skipped
+	empty, err := datasource.New(datasource.TypeSingle, "empty")
+	require.NoError(t, err)
+	_, err = empty.AddField("unreferenced", api.Kind_Uint32,
+		datasource.WithFlags(datasource.FieldFlagUnreferenced))
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	gadgetCtx := &gadgetcontext.MockGadgetContext{
+		Ctx: context.Background(),
+		DataSources: map[string]datasource.DataSource{
+			"events": events,
+			"empty":  empty,
+		},
+	}
+	values := paramValues(nil)
+	values[ParamDir] = dir
+	op, err := Operator.InstantiateDataOperator(gadgetCtx, values)
+	require.NoError(t, err)
+	inst := op.(*parquetOperatorInstance)
+	require.NoError(t, inst.PreStart(gadgetCtx))
+	require.Len(t, inst.series, 1)
+	require.NoError(t, inst.Start(gadgetCtx))
+
+	data, err := events.NewPacketSingle()
+	require.NoError(t, err)
+	require.NoError(t, pid.PutUint32(data, 1))
+	require.NoError(t, events.EmitAndRelease(data))
+
+	partial, err := filepath.Glob(filepath.Join(dir, "*"+partialSuffix))
+	require.NoError(t, err)
+	require.Len(t, partial, 1)
+
+	// The file of a data source that failed to be written is removed
+	inst.series[0].err = errors.New("failed")
+	require.NoError(t, inst.Stop(gadgetCtx))
+	require.NoError(t, inst.Close(gadgetCtx))
+	require.NoError(t, inst.Close(gadgetCtx))
+
+	files, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Empty(t, files)
+}
+
+func TestParquetOperatorParams(t *testing.T) {
+	gadgetCtx := &gadgetcontext.MockGadgetContext{Ctx: context.Background()}
+
+	inst, err := Operator.InstantiateDataOperator(gadgetCtx, paramValues(nil))
+	require.NoError(t, err)
+	require.Nil(t, inst)
+
+	for _, values := range []api.ParamValues{
+		{ParamMaxFileSize: "big"},
+		{ParamMaxFileAge: "soon"},
+		{ParamRowGroupSize: "0"},
+	} {
+		values[ParamDir] = t.TempDir()
+		_, err := Operator.InstantiateDataOperator(gadgetCtx, paramValues(values))
+		require.Error(t, err, "%v", values)
+	}
+}
@@ -171,6 +171,7 @@ func (p *processOperator) InstantiateDataOperator(gadgetCtx operators.GadgetCont
 	// PID field is always added (it's required)
 	instance.pidField, err = ds.AddField(fieldPID, api.Kind_Int32, datasource.WithAnnotations(map[string]string{
 		metadatav1.TemplateAnnotation: "pid",
+		"timeseries.type":             "key",
 	}))
 	if err != nil {
 		return nil, fmt.Errorf("adding pid field: %w", err)
@@ -216,6 +217,7 @@ func (p *processOperator) InstantiateDataOperator(gadgetCtx operators.GadgetCont
 			instance.cpuTimeField, err = ds.AddField(fieldCPUTime, api.Kind_Int64, datasource.WithAnnotations(map[string]string{
 				metadatav1.ColumnsHiddenAnnotation: "true",
 				metadatav1.DescriptionAnnotation:   "Total CPU time",
+				"timeseries.type":                  "cumulative",
 			}))
 			if err != nil {
 				return nil, fmt.Errorf("adding cpuTime field: %w", err)
@@ -176,6 +176,71 @@ func getCompareFunc(f datasource.FieldAccessor, negate bool) func(i, j datasourc
 	}
 }
 
+type sortField struct {
+	field  datasource.FieldAccessor
+	negate bool
+}
+
+// lookupSortFields returns the given fields of ds, checking they can be used for sorting. negate
+// is set for the fields prefixed with '-', that are sorted in descending order.
+func lookupSortFields(ds datasource.DataSource, sortFields []string) ([]sortField, error) {
+	res := make([]sortField, 0, len(sortFields))
+	for _, fieldName := range sortFields {
+		fieldName, negate := strings.CutPrefix(fieldName, "-")
+
+		field := ds.GetField(fieldName)
+		if field == nil {
+			return nil, fmt.Errorf("field %s not found", fieldName)
+		}
+		if !IsSortable(field) {
+			return nil, fmt.Errorf("field %s cannot be used for sorting", fieldName)
+		}
+		res = append(res, sortField{field: field, negate: negate})
+	}
+	return res, nil
+}
+
+// NewLessFunc returns a function reporting whether Data i sorts before Data j when sorting by the
+// given fields of ds, using the same rules as the sort operator: fields are given by decreasing
+// precedence, and prefixing one with '-' sorts it in descending order.
+func NewLessFunc(ds datasource.DataSource, sortFields []string) (func(i, j datasource.Data) bool, error) {
+	fields, err := lookupSortFields(ds, sortFields)
+	if err != nil {
+		return nil, err
+	}
+
+	var sortFuncs []func(i, j datasource.Data) bool
+	for _, f := range fields {
+		less := getCompareFunc(f.field, false)
+		if f.negate {
+			// Negating the result would make equal values compare as less, swap the arguments
+			// instead
+			asc := less
+			less = func(i, j datasource.Data) bool {
+				return asc(j, i)
+			}
+		}
+		sortFuncs = append(sortFuncs, less)
+	}
+
+	return func(i, j datasource.Data) bool {
+		for _, less := range sortFuncs {
+			if less(i, j) {
+				return true
+			}
+			if less(j, i) {
+				return false
+			}
+		}
+		return false
+	}, nil
+}
+
+// IsSortable reports whether the sort operator can sort by f
+func IsSortable(f datasource.FieldAccessor) bool {
+	return getCompareFunc(f, false) != nil
+}
+
 func (s *sortOperatorInstance) getFieldsByDs() map[string][]string {
 	dsSorts := make(map[string][]string)
 	for _, srt := range strings.Split(s.sortBy, ";") {
@@ -221,20 +286,14 @@ func (s *sortOperatorInstance) init(gadgetCtx operators.GadgetContext) error {
 			return fmt.Errorf("sort can only be used on array data sources")
 		}
 
-		var sortFuncs []func(i, j datasource.Data) bool
-		for _, fieldName := range sortFields {
-			fieldName, negate := strings.CutPrefix(fieldName, "-")
-
-			field := ds.GetField(fieldName)
-			if field == nil {
-				return fmt.Errorf("field %s not found", fieldName)
-			}
+		fields, err := lookupSortFields(ds, sortFields)
+		if err != nil {
+			return err
+		}
 
-			cmp := getCompareFunc(field, negate)
-			if cmp == nil {
-				return fmt.Errorf("field %s cannot be used for sorting", fieldName)
-			}
-			sortFuncs = append(sortFuncs, cmp)
+		var sortFuncs []func(i, j datasource.Data) bool
+		for _, f := range fields {
+			sortFuncs = append(sortFuncs, getCompareFunc(f.field, f.negate))
 		}
 
 		slices.Reverse(sortFuncs)
@@ -245,3 +245,41 @@ func TestGetFieldsByDsFieldSorting(t *testing.T) {
 	fieldsByDs := s.getFieldsByDs()
 	assert.EqualValues(t, map[string][]string{"foo": {"string", "number"}}, fieldsByDs)
 }
+
+func TestNewLessFunc(t *testing.T) {
+	ds, err := datasource.New(datasource.TypeArray, "foo")
+	require.NoError(t, err)
+	str, err := ds.AddField("string", api.Kind_String)
+	require.NoError(t, err)
+	num, err := ds.AddField("number", api.Kind_Uint32)
+	require.NoError(t, err)
+	_, err = ds.AddField("bytes", api.Kind_Bytes)
+	require.NoError(t, err)
+
+	pa, err := ds.NewPacketArray()
+	require.NoError(t, err)
+	newData := func(s string, n uint32) datasource.Data {
+		data := pa.New()
+		require.NoError(t, str.PutString(data, s))
+		require.NoError(t, num.PutUint32(data, n))
+		return data
+	}
+	a := newData("abc", 2)
+	b := newData("abc", 1)
+	c := newData("def", 1)
+
+	less, err := NewLessFunc(ds, []string{"string", "-number"})
+	require.NoError(t, err)
+	assert.True(t, less(a, b))
+	assert.False(t, less(b, a))
+	assert.True(t, less(b, c))
+	assert.False(t, less(a, a))
+
+	_, err = NewLessFunc(ds, []string{"unknown"})
+	require.Error(t, err)
+	_, err = NewLessFunc(ds, []string{"bytes"})
+	require.Error(t, err)
+
+	assert.True(t, IsSortable(num))
+	assert.False(t, IsSortable(ds.GetField("bytes")))
+}
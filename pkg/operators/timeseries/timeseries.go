@@ -0,0 +1,527 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package timeseries implements an operator that turns cumulative counters into deltas and rates.
+// For each counter field, it adds a <field>_delta field holding the increase since the previous
+// sample with the same key and a <field>_rate field holding that increase per second.
+package timeseries
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+	metadatav1 "github.com/inspektor-gadget/inspektor-gadget/pkg/metadata/v1"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators"
+	ebpftypes "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/ebpf/types"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/params"
+)
+
+const (
+	name = "timeseries"
+	// Priority is after the enrichers and before filter, sort and the outputs, so the new fields
+	// can be filtered and sorted by
+	Priority = 8000
+
+	ParamCumulative = "timeseries-cumulative"
+	ParamKeys       = "timeseries-keys"
+	ParamKeyExpiry  = "timeseries-key-expiry"
+
+	// AnnotationType marks a field as a cumulative counter (TypeCumulative) or as part of the key
+	// identifying the series a sample belongs to (TypeKey)
+	AnnotationType = "timeseries.type"
+	TypeCumulative = "cumulative"
+	TypeKey        = "key"
+
+	// Fields annotated as keys for the otel-metrics operator are used if no field is annotated
+	// with AnnotationType=key
+	annotationMetricsType = "metrics.type"
+	metricsTypeKey        = "key"
+
+	DeltaSuffix = "_delta"
+	RateSuffix  = "_rate"
+)
+
+type timeseriesOperator struct{}
+
+func (o *timeseriesOperator) Name() string {
+	return name
+}
+
+func (o *timeseriesOperator) Init(params *params.Params) error {
+	return nil
+}
+
+func (o *timeseriesOperator) GlobalParams() api.Params {
+	return nil
+}
+
+func (o *timeseriesOperator) InstanceParams() api.Params {
+	return api.Params{
+		{
+			Key:   ParamCumulative,
+			Title: "Cumulative Fields",
+			Description: "Cumulative counter fields to compute deltas and rates for, in addition to the ones annotated with " +
+				AnnotationType + "=" + TypeCumulative + ". Join multiple fields with ','. " +
+				"If using multiple data sources, prefix fields with 'datasourcename:' and separate with ';'. " +
+				"Fields without prefix are used for all the data sources having them",
+		},
+		{
+			Key:   ParamKeys,
+			Title: "Key Fields",
+			Description: "Fields identifying the series a sample belongs to, instead of the ones annotated with " +
+				AnnotationType + "=" + TypeKey + ". Join multiple fields with ','. " +
+				"If using multiple data sources, prefix fields with 'datasourcename:' and separate with ';'",
+		},
+		{
+			Key:          ParamKeyExpiry,
+			Title:        "Key Expiry",
+			Description:  "Time after which the previous values of a key that wasn't seen anymore are forgotten. Keys missing from an array are forgotten right away",
+			DefaultValue: "5m",
+			TypeHint:     api.TypeDuration,
+			Tags:         []string{api.TagAdvanced},
+		},
+	}
+}
+
+// fieldsPerDataSource parses a list of fields in the format of the sort operator,
+// "ds1:field1,field2;ds2:field3" or "field1,field2" for all data sources
+func fieldsPerDataSource(value string) (map[string][]string, error) {
+	res := make(map[string][]string)
+	for _, entry := range strings.Split(value, ";") {
+		if entry == "" {
+			continue
+		}
+		dsName, fields, ok := strings.Cut(entry, ":")
+		if !ok {
+			dsName, fields = "", entry
+		}
+		for _, field := range strings.Split(fields, ",") {
+			if field == "" {
+				continue
+			}
+			res[dsName] = append(res[dsName], field)
+		}
+	}
+	if _, ok := res[""]; ok && len(res) > 1 {
+		return nil, fmt.Errorf("mixing fields with and without specifying data source")
+	}
+	return res, nil
+}
+
+// forDataSource returns the fields to use for ds. Fields given without data source only apply to
+// the data sources having them: the ones ds lacks are left out and the others are added to found.
+func forDataSource(fields map[string][]string, ds datasource.DataSource, found map[string]struct{}) []string {
+	all, ok := fields[""]
+	if !ok {
+		return fields[ds.Name()]
+	}
+	var res []string
+	for _, name := range all {
+		if ds.GetField(name) == nil {
+			continue
+		}
+		found[name] = struct{}{}
+		res = append(res, name)
+	}
+	return res
+}
+
+// checkFound returns an error if a field given without data source wasn't found in any of them
+func checkFound(fields map[string][]string, found map[string]struct{}) error {
+	for _, name := range fields[""] {
+		if _, ok := found[name]; !ok {
+			return fmt.Errorf("field %q not found in any data source", name)
+		}
+	}
+	return nil
+}
+
+func (o *timeseriesOperator) InstantiateDataOperator(gadgetCtx operators.GadgetContext, instanceParamValues api.ParamValues) (operators.DataOperatorInstance, error) {
+	cumulative, err := fieldsPerDataSource(instanceParamValues[ParamCumulative])
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", ParamCumulative, err)
+	}
+	keys, err := fieldsPerDataSource(instanceParamValues[ParamKeys])
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", ParamKeys, err)
+	}
+	expiry, err := time.ParseDuration(instanceParamValues[ParamKeyExpiry])
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", ParamKeyExpiry, err)
+	}
+
+	// Check all the fields given without data source first: they don't need to be found in
+	// every data source, but in one of them at least
+	found := make(map[string]struct{})
+	for _, ds := range gadgetCtx.GetDataSources() {
+		forDataSource(cumulative, ds, found)
+		forDataSource(keys, ds, found)
+	}
+	if err := checkFound(cumulative, found); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", ParamCumulative, err)
+	}
+	if err := checkFound(keys, found); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", ParamKeys, err)
+	}
+
+	inst := &timeseriesOperatorInstance{}
+	for _, ds := range gadgetCtx.GetDataSources() {
+		t, err := newTracker(ds, forDataSource(cumulative, ds, found), forDataSource(keys, ds, found), expiry)
+		if err != nil {
+			return nil, fmt.Errorf("data source %q: %w", ds.Name(), err)
+		}
+		if t == nil {
+			continue
+		}
+		gadgetCtx.Logger().Debugf("timeseries: computing deltas of %d fields of %q", len(t.counters), ds.Name())
+		inst.trackers = append(inst.trackers, t)
+	}
+	if len(inst.trackers) == 0 {
+		return nil, nil
+	}
+	return inst, nil
+}
+
+func (o *timeseriesOperator) Priority() int {
+	return Priority
+}
+
+type counter struct {
+	name string
+	// kind is api.Kind_Int64, api.Kind_Uint64 or api.Kind_Float64, whatever the kind of the field;
+	// read returns the bits of the value as that kind
+	kind api.Kind
+	read func(datasource.Data) uint64
+
+	delta datasource.FieldAccessor
+	rate  datasource.FieldAccessor
+}
+
+// series holds the previous sample of a key
+type series struct {
+	values []uint64
+	time   time.Time
+	// generation is the array the key was last seen in
+	generation uint64
+}
+
+// tracker computes the deltas of the counters of a data source
+type tracker struct {
+	ds        datasource.DataSource
+	keys      []func(datasource.Data) string
+	counters  []*counter
+	timestamp datasource.FieldAccessor
+	expiry    time.Duration
+
+	mu         sync.Mutex
+	series     map[string]*series
+	generation uint64
+	lastSweep  time.Time
+	key        strings.Builder
+}
+
+// newTracker returns the tracker for the cumulative fields of ds, or nil if it has none
+func newTracker(ds datasource.DataSource, cumulative []string, keys []string, expiry time.Duration) (*tracker, error) {
+	var counterFields, keyFields, metricsKeyFields []datasource.FieldAccessor
+	for _, name := range cumulative {
+		f := ds.GetField(name)
+		if f == nil {
+			return nil, fmt.Errorf("field %q not found", name)
+		}
+		counterFields = append(counterFields, f)
+	}
+	for _, name := range keys {
+		f := ds.GetField(name)
+		if f == nil {
+			return nil, fmt.Errorf("field %q not found", name)
+		}
+		keyFields = append(keyFields, f)
+	}
+	for _, f := range ds.Accessors(false) {
+		switch f.Annotations()[AnnotationType] {
+		case TypeCumulative:
+			if !slices.Contains(counterFields, f) {
+				counterFields = append(counterFields, f)
+			}
+		case TypeKey:
+			if keys == nil {
+				keyFields = append(keyFields, f)
+			}
+		}
+		if f.Annotations()[annotationMetricsType] == metricsTypeKey {
+			metricsKeyFields = append(metricsKeyFields, f)
+		}
+	}
+	if len(keyFields) == 0 {
+		keyFields = metricsKeyFields
+	}
+
+	t := &tracker{
+		ds:     ds,
+		expiry: expiry,
+		series: make(map[string]*series),
+	}
+	for _, f := range counterFields {
+		c, err := t.addCounter(f)
+		if err != nil {
+			return nil, fmt.Errorf("field %q: %w", f.FullName(), err)
+		}
+		if c != nil {
+			t.counters = append(t.counters, c)
+		}
+	}
+	if len(t.counters) == 0 {
+		return nil, nil
+	}
+
+	for _, f := range keyFields {
+		fn, err := datasource.AsString(f)
+		if err != nil {
+			return nil, fmt.Errorf("key field %q: %w", f.FullName(), err)
+		}
+		t.keys = append(t.keys, fn)
+	}
+
+	if ts := ds.GetFieldsWithTag("type:" + ebpftypes.TimestampTypeName); len(ts) > 0 {
+		t.timestamp = ts[0]
+	}
+	return t, nil
+}
+
+// addCounter adds the delta and rate fields of f; it returns nil if they have already been added,
+// e.g. on the remote side
+func (t *tracker) addCounter(f datasource.FieldAccessor) (*counter, error) {
+	baseName := strings.TrimSuffix(f.FullName(), "_raw")
+	if t.ds.GetField(baseName+DeltaSuffix) != nil {
+		return nil, nil
+	}
+
+	c := &counter{name: f.FullName()}
+	switch f.Type() {
+	case api.Kind_Int8, api.Kind_Int16, api.Kind_Int32, api.Kind_Int64:
+		c.kind = api.Kind_Int64
+	case api.Kind_Uint8, api.Kind_Uint16, api.Kind_Uint32, api.Kind_Uint64:
+		c.kind = api.Kind_Uint64
+	case api.Kind_Float32, api.Kind_Float64:
+		c.kind = api.Kind_Float64
+	default:
+		return nil, fmt.Errorf("unsupported kind %s for a counter", f.Type())
+	}
+	if c.kind == api.Kind_Float64 {
+		fn, _ := datasource.AsFloat64(f)
+		c.read = func(data datasource.Data) uint64 {
+			return math.Float64bits(fn(data))
+		}
+	} else {
+		// unsigned values keep their bits when read as int64
+		fn, _ := datasource.AsInt64(f)
+		c.read = func(data datasource.Data) uint64 {
+			return uint64(fn(data))
+		}
+	}
+
+	annotations := map[string]string{
+		metadatav1.ColumnsAlignmentAnnotation: string(metadatav1.AlignmentRight),
+	}
+	if width, ok := f.Annotations()[metadatav1.ColumnsWidthAnnotation]; ok {
+		annotations[metadatav1.ColumnsWidthAnnotation] = width
+	}
+
+	deltaAnnotations := map[string]string{
+		metadatav1.DescriptionAnnotation:   fmt.Sprintf("Increase of %s since the previous sample", f.FullName()),
+		metadatav1.ColumnsHiddenAnnotation: "true",
+	}
+	var err error
+	c.delta, err = t.ds.AddField(baseName+DeltaSuffix, c.kind,
+		datasource.WithAnnotations(annotations), datasource.WithAddedAnnotations(deltaAnnotations))
+	if err != nil {
+		return nil, fmt.Errorf("adding delta field: %w", err)
+	}
+
+	rateAnnotations := map[string]string{
+		metadatav1.DescriptionAnnotation:      fmt.Sprintf("Increase of %s per second", f.FullName()),
+		metadatav1.ColumnsPrecisionAnnotation: "2",
+	}
+	if datasource.FieldFlagHidden.In(f.Flags()) {
+		rateAnnotations[metadatav1.ColumnsHiddenAnnotation] = "true"
+	}
+	c.rate, err = t.ds.AddField(baseName+RateSuffix, api.Kind_Float64,
+		datasource.WithAnnotations(annotations), datasource.WithAddedAnnotations(rateAnnotations))
+	if err != nil {
+		return nil, fmt.Errorf("adding rate field: %w", err)
+	}
+	return c, nil
+}
+
+func (t *tracker) keyOf(data datasource.Data) string {
+	t.key.Reset()
+	for i, fn := range t.keys {
+		if i > 0 {
+			t.key.WriteByte(0)
+		}
+		t.key.WriteString(fn(data))
+	}
+	return t.key.String()
+}
+
+// update computes the deltas of data relative to the previous sample with the same key
+func (t *tracker) update(data datasource.Data, now time.Time) {
+	if t.timestamp != nil {
+		if ts, err := t.timestamp.Uint64(data); err == nil && ts != 0 {
+			now = time.Unix(0, int64(ts))
+		}
+	}
+
+	key := t.keyOf(data)
+	s, ok := t.series[key]
+	if !ok {
+		s = &series{values: make([]uint64, len(t.counters))}
+		t.series[key] = s
+	}
+	elapsed := now.Sub(s.time).Seconds()
+
+	for i, c := range t.counters {
+		cur := c.read(data)
+		prev := s.values[i]
+		s.values[i] = cur
+
+		// The first sample of a key has nothing to compare with
+		if !ok {
+			// all kinds of deltas are 8 bytes long, zero for all of them
+			c.delta.PutUint64(data, 0)
+			c.rate.PutFloat64(data, 0)
+			continue
+		}
+
+		// A counter going backwards has been reset (e.g. the process was restarted), so its
+		// current value is what it increased by since then
+		var delta float64
+		switch c.kind {
+		case api.Kind_Uint64:
+			d := cur - prev
+			if cur < prev {
+				d = cur
+			}
+			c.delta.PutUint64(data, d)
+			delta = float64(d)
+		case api.Kind_Int64:
+			d := int64(cur) - int64(prev)
+			if int64(cur) < int64(prev) {
+				d = int64(cur)
+			}
+			c.delta.PutInt64(data, d)
+			delta = float64(d)
+		case api.Kind_Float64:
+			curF, prevF := math.Float64frombits(cur), math.Float64frombits(prev)
+			delta = curF - prevF
+			if curF < prevF {
+				delta = curF
+			}
+			c.delta.PutFloat64(data, delta)
+		}
+
+		rate := 0.0
+		if elapsed > 0 {
+			rate = delta / elapsed
+		}
+		c.rate.PutFloat64(data, rate)
+	}
+	s.time = now
+	s.generation = t.generation
+}
+
+func (t *tracker) updateSingle(data datasource.Data) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	t.update(data, now)
+
+	if t.expiry <= 0 || now.Sub(t.lastSweep) < t.expiry {
+		return
+	}
+	t.lastSweep = now
+	for key, s := range t.series {
+		if now.Sub(s.time) >= t.expiry {
+			delete(t.series, key)
+		}
+	}
+}
+
+func (t *tracker) updateArray(dataArray datasource.DataArray) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	t.generation++
+	for i := range dataArray.Len() {
+		t.update(dataArray.Get(i), now)
+	}
+	// Keys missing from the array are gone, e.g. a process that exited
+	for key, s := range t.series {
+		if s.generation != t.generation {
+			delete(t.series, key)
+		}
+	}
+}
+
+type timeseriesOperatorInstance struct {
+	trackers []*tracker
+}
+
+func (o *timeseriesOperatorInstance) Name() string {
+	return name
+}
+
+func (o *timeseriesOperatorInstance) PreStart(gadgetCtx operators.GadgetContext) error {
+	for _, t := range o.trackers {
+		switch t.ds.Type() {
+		case datasource.TypeSingle:
+			t.ds.Subscribe(func(ds datasource.DataSource, data datasource.Data) error {
+				t.updateSingle(data)
+				return nil
+			}, Priority)
+		case datasource.TypeArray:
+			t.ds.SubscribeArray(func(ds datasource.DataSource, dataArray datasource.DataArray) error {
+				t.updateArray(dataArray)
+				return nil
+			}, Priority)
+		}
+	}
+	return nil
+}
+
+func (o *timeseriesOperatorInstance) Start(gadgetCtx operators.GadgetContext) error {
+	return nil
+}
+
+func (o *timeseriesOperatorInstance) Stop(gadgetCtx operators.GadgetContext) error {
+	return nil
+}
+
+func (o *timeseriesOperatorInstance) Close(gadgetCtx operators.GadgetContext) error {
+	return nil
+}
+
+var Operator = &timeseriesOperator{}
+
+func init() {
+	operators.RegisterDataOperator(Operator)
+}
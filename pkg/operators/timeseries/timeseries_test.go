@@ -0,0 +1,343 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package timeseries
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+	apihelpers "github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api-helpers"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators"
+	gadgetcontext "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/testing/gadget-context"
+)
+
+func instantiate(t *testing.T, values api.ParamValues, dss ...datasource.DataSource) (operators.DataOperatorInstance, error) {
+	gadgetCtx := &gadgetcontext.MockGadgetContext{
+		Ctx:         context.Background(),
+		DataSources: map[string]datasource.DataSource{},
+	}
+	for _, ds := range dss {
+		gadgetCtx.DataSources[ds.Name()] = ds
+	}
+	paramValues := apihelpers.ToParamDescs(Operator.InstanceParams()).ToParams().ParamMap()
+	for k, v := range values {
+		paramValues[k] = v
+	}
+	inst, err := Operator.InstantiateDataOperator(gadgetCtx, paramValues)
+	if err != nil || inst == nil {
+		return inst, err
+	}
+	require.NoError(t, inst.(*timeseriesOperatorInstance).PreStart(gadgetCtx))
+	return inst, nil
+}
+
+func TestTimeseriesArray(t *testing.T) {
+	ds, err := datasource.New(datasource.TypeArray, "processes")
+	require.NoError(t, err)
+	pid, err := ds.AddField("pid", api.Kind_Int32,
+		datasource.WithAnnotations(map[string]string{AnnotationType: TypeKey}))
+	require.NoError(t, err)
+	cpu, err := ds.AddField("cpu_raw", api.Kind_Uint64,
+		datasource.WithAnnotations(map[string]string{AnnotationType: TypeCumulative}))
+	require.NoError(t, err)
+	ts, err := ds.AddField("timestamp_raw", api.Kind_Uint64, datasource.WithTags("type:gadget_timestamp"))
+	require.NoError(t, err)
+
+	inst, err := instantiate(t, nil, ds)
+	require.NoError(t, err)
+	require.NotNil(t, inst)
+
+	delta := ds.GetField("cpu_delta")
+	require.NotNil(t, delta)
+	require.Equal(t, api.Kind_Uint64, delta.Type())
+	require.True(t, datasource.FieldFlagHidden.In(delta.Flags()))
+	rate := ds.GetField("cpu_rate")
+	require.NotNil(t, rate)
+	require.Equal(t, api.Kind_Float64, rate.Type())
+	require.False(t, datasource.FieldFlagHidden.In(rate.Flags()))
+
+	type sample struct {
+		pid int32
+		cpu uint64
+	}
+	type result struct {
+		delta uint64
+		rate  float64
+	}
+	steps := []struct {
+		samples  []sample
+		expected []result
+	}{
+		{
+			// first samples of the keys
+			samples:  []sample{{1, 100}, {2, 50}},
+			expected: []result{{0, 0}, {0, 0}},
+		},
+		{
+			// 2 was reset
+			samples:  []sample{{1, 150}, {2, 40}},
+			expected: []result{{50, 25}, {40, 20}},
+		},
+		{
+			samples:  []sample{{1, 250}},
+			expected: []result{{100, 50}},
+		},
+		{
+			// 2 disappeared in the previous array, so it starts over
+			samples:  []sample{{2, 70}, {1, 250}},
+			expected: []result{{0, 0}, {0, 0}},
+		},
+	}
+
+	for i, step := range steps {
+		arr, err := ds.NewPacketArray()
+		require.NoError(t, err)
+		for _, s := range step.samples {
+			e := arr.New()
+			require.NoError(t, pid.PutInt32(e, s.pid))
+			require.NoError(t, cpu.PutUint64(e, s.cpu))
+			// 2 seconds between arrays
+			require.NoError(t, ts.PutUint64(e, uint64(i+1)*2_000_000_000))
+			arr.Append(e)
+		}
+		require.NoError(t, ds.EmitAndRelease(arr))
+
+		for j, expected := range step.expected {
+			d, err := delta.Uint64(arr.Get(j))
+			require.NoError(t, err)
+			r, err := rate.Float64(arr.Get(j))
+			require.NoError(t, err)
+			require.Equal(t, expected, result{d, r}, "step %d, sample %d", i, j)
+		}
+	}
+}
+
+func TestTimeseriesParams(t *testing.T) {
+	ds, err := datasource.New(datasource.TypeSingle, "events")
+	require.NoError(t, err)
+	comm, err := ds.AddField("comm", api.Kind_String)
+	require.NoError(t, err)
+	count, err := ds.AddField("count", api.Kind_Int64)
+	require.NoError(t, err)
+	load, err := ds.AddField("load", api.Kind_Float64, datasource.WithFlags(datasource.FieldFlagHidden))
+	require.NoError(t, err)
+
+	inst, err := instantiate(t, api.ParamValues{
+		ParamCumulative: "events:count,load",
+		ParamKeys:       "events:comm",
+	}, ds)
+	require.NoError(t, err)
+	require.NotNil(t, inst)
+
+	countDelta := ds.GetField("count_delta")
+	require.NotNil(t, countDelta)
+	require.Equal(t, api.Kind_Int64, countDelta.Type())
+	loadDelta := ds.GetField("load_delta")
+	require.NotNil(t, loadDelta)
+	require.Equal(t, api.Kind_Float64, loadDelta.Type())
+	// hidden counters get hidden rates
+	require.True(t, datasource.FieldFlagHidden.In(ds.GetField("load_rate").Flags()))
+
+	emit := func(c string, n int64, l float64) datasource.Data {
+		data, err := ds.NewPacketSingle()
+		require.NoError(t, err)
+		require.NoError(t, comm.PutString(data, c))
+		require.NoError(t, count.PutInt64(data, n))
+		require.NoError(t, load.PutFloat64(data, l))
+		require.NoError(t, ds.EmitAndRelease(data))
+		return data
+	}
+
+	data := emit("a", 10, 1.5)
+	d, _ := countDelta.Int64(data)
+	require.Zero(t, d)
+
+	data = emit("b", 100, 0)
+	d, _ = countDelta.Int64(data)
+	require.Zero(t, d)
+
+	data = emit("a", 13, 2.25)
+	d, _ = countDelta.Int64(data)
+	require.Equal(t, int64(3), d)
+	l, _ := loadDelta.Float64(data)
+	require.Equal(t, 0.75, l)
+
+	data = emit("a", 5, 1)
+	d, _ = countDelta.Int64(data)
+	require.Equal(t, int64(5), d)
+	l, _ = loadDelta.Float64(data)
+	require.Equal(t, 1.0, l)
+}
+
+func TestTimeseriesInstantiate(t *testing.T) {
+	newDs := func() datasource.DataSource {
+		ds, err := datasource.New(datasource.TypeSingle, "events")
+		require.NoError(t, err)
+		_, err = ds.AddField("comm", api.Kind_String)
+		require.NoError(t, err)
+		_, err = ds.AddField("count", api.Kind_Uint32)
+		require.NoError(t, err)
+		return ds
+	}
+
+	tests := []struct {
+		name        string
+		values      api.ParamValues
+		expectedNil bool
+		expectedErr bool
+	}{
+		{
+			name:        "no counters",
+			expectedNil: true,
+		},
+		{
+			name:   "counter",
+			values: api.ParamValues{ParamCumulative: "count"},
+		},
+		{
+			name:        "other data source",
+			values:      api.ParamValues{ParamCumulative: "other:count"},
+			expectedNil: true,
+		},
+		{
+			name:        "unknown field",
+			values:      api.ParamValues{ParamCumulative: "foo"},
+			expectedErr: true,
+		},
+		{
+			name:        "unsupported kind",
+			values:      api.ParamValues{ParamCumulative: "comm"},
+			expectedErr: true,
+		},
+		{
+			name:        "unknown key",
+			values:      api.ParamValues{ParamCumulative: "count", ParamKeys: "foo"},
+			expectedErr: true,
+		},
+		{
+			name:        "unknown field of data source",
+			values:      api.ParamValues{ParamCumulative: "events:foo"},
+			expectedErr: true,
+		},
+		{
+			name:        "mixed data sources",
+			values:      api.ParamValues{ParamCumulative: "count;events:count"},
+			expectedErr: true,
+		},
+		{
+			name:        "invalid expiry",
+			values:      api.ParamValues{ParamCumulative: "count", ParamKeyExpiry: "soon"},
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			inst, err := instantiate(t, test.values, newDs())
+			if test.expectedErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.expectedNil, inst == nil)
+		})
+	}
+
+	// Fields added on the remote side aren't added again
+	ds := newDs()
+	inst, err := instantiate(t, api.ParamValues{ParamCumulative: "count"}, ds)
+	require.NoError(t, err)
+	require.NotNil(t, inst)
+	inst, err = instantiate(t, api.ParamValues{ParamCumulative: "count"}, ds)
+	require.NoError(t, err)
+	require.Nil(t, inst)
+}
+
+func TestTimeseriesMultipleDataSources(t *testing.T) {
+	newDss := func() []datasource.DataSource {
+		events, err := datasource.New(datasource.TypeSingle, "events")
+		require.NoError(t, err)
+		_, err = events.AddField("comm", api.Kind_String)
+		require.NoError(t, err)
+		_, err = events.AddField("count", api.Kind_Uint32)
+		require.NoError(t, err)
+
+		stats, err := datasource.New(datasource.TypeSingle, "stats")
+		require.NoError(t, err)
+		_, err = stats.AddField("bytes", api.Kind_Uint64)
+		require.NoError(t, err)
+		return []datasource.DataSource{events, stats}
+	}
+
+	tests := []struct {
+		name             string
+		values           api.ParamValues
+		expectedTrackers []string
+		expectedErr      bool
+	}{
+		{
+			name:             "field of one data source",
+			values:           api.ParamValues{ParamCumulative: "count"},
+			expectedTrackers: []string{"events"},
+		},
+		{
+			name:             "fields of different data sources",
+			values:           api.ParamValues{ParamCumulative: "count,bytes"},
+			expectedTrackers: []string{"events", "stats"},
+		},
+		{
+			name:             "key of one data source",
+			values:           api.ParamValues{ParamCumulative: "count,bytes", ParamKeys: "comm"},
+			expectedTrackers: []string{"events", "stats"},
+		},
+		{
+			name:        "field of no data source",
+			values:      api.ParamValues{ParamCumulative: "count,foo"},
+			expectedErr: true,
+		},
+		{
+			name:        "key of no data source",
+			values:      api.ParamValues{ParamCumulative: "count", ParamKeys: "foo"},
+			expectedErr: true,
+		},
+		{
+			name:        "field missing from prefixed data source",
+			values:      api.ParamValues{ParamCumulative: "events:count;stats:count"},
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			inst, err := instantiate(t, test.values, newDss()...)
+			if test.expectedErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.NotNil(t, inst)
+
+			var trackers []string
+			for _, tr := range inst.(*timeseriesOperatorInstance).trackers {
+				trackers = append(trackers, tr.ds.Name())
+			}
+			require.ElementsMatch(t, test.expectedTrackers, trackers)
+		})
+	}
+}
@@ -69,6 +69,14 @@ func TestConsts(t *testing.T) {
 	assert.EqualValues(t, wasmapi.DataSourceTypeSingle, datasource.TypeSingle)
 	assert.EqualValues(t, wasmapi.DataSourceTypeArray, datasource.TypeArray)
 
+	// FieldFlag
+	assert.EqualValues(t, wasmapi.FieldFlagEmpty, datasource.FieldFlagEmpty)
+	assert.EqualValues(t, wasmapi.FieldFlagContainer, datasource.FieldFlagContainer)
+	assert.EqualValues(t, wasmapi.FieldFlagHidden, datasource.FieldFlagHidden)
+	assert.EqualValues(t, wasmapi.FieldFlagHasParent, datasource.FieldFlagHasParent)
+	assert.EqualValues(t, wasmapi.FieldFlagStaticMember, datasource.FieldFlagStaticMember)
+	assert.EqualValues(t, wasmapi.FieldFlagUnreferenced, datasource.FieldFlagUnreferenced)
+
 	// subscriptionType
 	assert.EqualValues(t, wasmapiSubscriptionTypeData, subscriptionTypeData)
 	assert.EqualValues(t, wasmapiSubscriptionTypeArray, subscriptionTypeArray)
@@ -0,0 +1,296 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wasm
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/tetratelabs/wazero"
+	wapi "github.com/tetratelabs/wazero/api"
+
+	containercollection "github.com/inspektor-gadget/inspektor-gadget/pkg/container-collection"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators"
+)
+
+// Container events passed to containerCallback(). Keep in sync with wasmapi/go/containers.go
+const (
+	containerEventAdd    uint32 = 0
+	containerEventRemove uint32 = 1
+)
+
+func (i *wasmOperatorInstance) addContainerFuncs(env wazero.HostModuleBuilder) {
+	exportFunction(env, "containerLookupByMntns", i.containerLookupByMntns,
+		[]wapi.ValueType{
+			wapi.ValueTypeI64, // MntNS ID
+		},
+		[]wapi.ValueType{wapi.ValueTypeI32}, // Container
+	)
+
+	exportFunction(env, "containerLookupByPid", i.containerLookupByPid,
+		[]wapi.ValueType{
+			wapi.ValueTypeI32, // PID
+		},
+		[]wapi.ValueType{wapi.ValueTypeI32}, // Container
+	)
+
+	exportFunction(env, "containersLookupByNetns", i.containersLookupByNetns,
+		[]wapi.ValueType{
+			wapi.ValueTypeI64, // NetNS ID
+			wapi.ValueTypeI64, // Handles
+		},
+		[]wapi.ValueType{wapi.ValueTypeI64}, // Number of containers
+	)
+
+	exportFunction(env, "containerGetMetadata", i.containerGetMetadata,
+		[]wapi.ValueType{
+			wapi.ValueTypeI32, // Container
+			wapi.ValueTypeI64, // Destination buffer
+		},
+		[]wapi.ValueType{wapi.ValueTypeI64}, // Size of the metadata
+	)
+
+	exportFunction(env, "containerSubscribe", i.containerSubscribe,
+		[]wapi.ValueType{
+			wapi.ValueTypeI64, // Callback ID
+		},
+		[]wapi.ValueType{wapi.ValueTypeI32}, // Error
+	)
+}
+
+// getContainerCollection returns the container collection of the operator managing containers.
+// It's only available once the data operators are instantiated, i.e. not in gadgetInit().
+func (i *wasmOperatorInstance) getContainerCollection() *containercollection.ContainerCollection {
+	ccVar, ok := i.gadgetCtx.GetVar(operators.ContainerCollectionVar)
+	if !ok {
+		return nil
+	}
+	cc, _ := ccVar.(*containercollection.ContainerCollection)
+	return cc
+}
+
+// containerLookupByMntns returns the container running in the given mount namespace.
+// Params:
+// - stack[0]: mount ns ID
+// Return value:
+// - Container handle on success, 0 if not found or on error
+func (i *wasmOperatorInstance) containerLookupByMntns(ctx context.Context, m wapi.Module, stack []uint64) {
+	mntnsID := stack[0]
+
+	cc := i.getContainerCollection()
+	if cc == nil {
+		i.logger.Warnf("containerLookupByMntns: container collection not available")
+		stack[0] = 0
+		return
+	}
+
+	c := cc.LookupContainerByMntns(mntnsID)
+	if c == nil {
+		stack[0] = 0
+		return
+	}
+	stack[0] = wapi.EncodeU32(i.addHandle(c))
+}
+
+// containerLookupByPid returns the container the given process runs in.
+// Params:
+// - stack[0]: PID, as seen from the host
+// Return value:
+// - Container handle on success, 0 if not found or on error
+func (i *wasmOperatorInstance) containerLookupByPid(ctx context.Context, m wapi.Module, stack []uint64) {
+	pid := wapi.DecodeU32(stack[0])
+
+	cc := i.getContainerCollection()
+	if cc == nil {
+		i.logger.Warnf("containerLookupByPid: container collection not available")
+		stack[0] = 0
+		return
+	}
+
+	c := cc.LookupContainerByPid(pid)
+	if c == nil {
+		stack[0] = 0
+		return
+	}
+	stack[0] = wapi.EncodeU32(i.addHandle(c))
+}
+
+// containersLookupByNetns returns the containers running in the given network namespace. The
+// handles are only written if they fit into the buffer.
+// Params:
+// - stack[0]: network ns ID
+// - stack[1]: Handles buffer pointer (u32 each)
+// Return value:
+// - Number of containers on success, -1 on error
+func (i *wasmOperatorInstance) containersLookupByNetns(ctx context.Context, m wapi.Module, stack []uint64) {
+	netnsID := stack[0]
+	handlesBuf := stack[1]
+
+	cc := i.getContainerCollection()
+	if cc == nil {
+		i.logger.Warnf("containersLookupByNetns: container collection not available")
+		stack[0] = wapi.EncodeI64(-1)
+		return
+	}
+
+	stack[0] = writeHandlesIfFit(i, cc.LookupContainersByNetns(netnsID), handlesBuf)
+}
+
+// containerMetadata returns the metadata of a container as a map, with keys named after the
+// fields of the data sources enriched with container information
+func containerMetadata(c *containercollection.Container) map[string]string {
+	md := map[string]string{
+		"runtime.runtimeName":          string(c.Runtime.RuntimeName),
+		"runtime.containerId":          c.Runtime.ContainerID,
+		"runtime.containerName":        c.Runtime.ContainerName,
+		"runtime.containerPid":         strconv.FormatUint(uint64(c.Runtime.ContainerPID), 10),
+		"runtime.containerImageName":   c.Runtime.ContainerImageName,
+		"runtime.containerImageDigest": c.Runtime.ContainerImageDigest,
+		"k8s.namespace":                c.K8s.Namespace,
+		"k8s.podName":                  c.K8s.PodName,
+		"k8s.podUID":                   c.K8s.PodUID,
+		"k8s.containerName":            c.K8s.ContainerName,
+		"k8s.hostNetwork":              strconv.FormatBool(c.HostNetwork),
+		"mntns":                        strconv.FormatUint(c.Mntns, 10),
+		"netns":                        strconv.FormatUint(c.Netns, 10),
+		"cgroupId":                     strconv.FormatUint(c.CgroupID, 10),
+		"cgroupPath":                   c.CgroupPath,
+	}
+	if owner := c.K8sOwnerReference(); owner != nil {
+		md["k8s.owner.kind"] = owner.Kind
+		md["k8s.owner.name"] = owner.Name
+	}
+	for k, v := range c.K8s.PodLabels {
+		md["k8s.podLabels."+k] = v
+	}
+	return md
+}
+
+// containerGetMetadata writes the runtime and Kubernetes metadata of a container to the buffer
+// if it fits.
+// Params:
+// - stack[0]: Container handle
+// - stack[1]: Destination buffer pointer
+// Return value:
+// - Size of the metadata on success, -1 on error
+func (i *wasmOperatorInstance) containerGetMetadata(ctx context.Context, m wapi.Module, stack []uint64) {
+	cHandle := wapi.DecodeU32(stack[0])
+	dstBuf := stack[1]
+
+	c, ok := getHandle[*containercollection.Container](i, cHandle)
+	if !ok {
+		stack[0] = wapi.EncodeI64(-1)
+		return
+	}
+
+	stack[0] = i.writeIfFits(stringMapToBuf(containerMetadata(c)), dstBuf)
+}
+
+// containerSubscribe makes the host call containerCallback() of the guest when a container is
+// added or removed. The containers running when the gadget starts are passed as added ones.
+// Notifications are only sent while the gadget is running.
+// Params:
+// - stack[0]: Callback ID
+// Return value:
+// - 0 on success, 1 on error
+func (i *wasmOperatorInstance) containerSubscribe(ctx context.Context, m wapi.Module, stack []uint64) {
+	cbID := stack[0]
+
+	if i.containerCallback == nil {
+		i.logger.Warnf("wasm module doesn't export containerCallback")
+		stack[0] = 1
+		return
+	}
+
+	i.containerSubsLock.Lock()
+	i.containerSubs = append(i.containerSubs, cbID)
+	i.containerSubsLock.Unlock()
+
+	stack[0] = 0
+}
+
+// callContainerCallbacks passes a container event to all the subscriptions of the guest. The
+// handle of the container is only valid during the callback.
+func (i *wasmOperatorInstance) callContainerCallbacks(event uint32, c *containercollection.Container) {
+	if i.guestFailed.Load() {
+		return
+	}
+
+	handle := i.addHandle(c)
+	if handle == 0 {
+		return
+	}
+	defer i.delHandle(handle)
+
+	i.containerSubsLock.Lock()
+	subs := i.containerSubs
+	i.containerSubsLock.Unlock()
+
+	stats := i.getCallbackStats("container", "")
+	for _, cbID := range subs {
+		err := i.callGuestCallbackWithLock(context.Background(), stats, i.containerCallback,
+			cbID, uint64(event), wapi.EncodeU32(handle))
+		if err != nil {
+			i.handleTrap(fmt.Errorf("calling container callback: %w", err))
+			return
+		}
+	}
+}
+
+// startContainerSubscription subscribes to the container collection if the guest asked for
+// container notifications
+func (i *wasmOperatorInstance) startContainerSubscription() error {
+	i.containerSubsLock.Lock()
+	subscribed := len(i.containerSubs) > 0
+	i.containerSubsLock.Unlock()
+	if !subscribed {
+		return nil
+	}
+
+	cc := i.getContainerCollection()
+	if cc == nil {
+		return fmt.Errorf("subscribing to containers: container collection not available")
+	}
+
+	i.containerSubKey = uuid.New().String()
+	containers := cc.Subscribe(
+		i.containerSubKey,
+		containercollection.ContainerSelector{},
+		func(event containercollection.PubSubEvent) {
+			switch event.Type {
+			case containercollection.EventTypeAddContainer:
+				i.callContainerCallbacks(containerEventAdd, event.Container)
+			case containercollection.EventTypeRemoveContainer:
+				i.callContainerCallbacks(containerEventRemove, event.Container)
+			}
+		},
+	)
+	for _, c := range containers {
+		i.callContainerCallbacks(containerEventAdd, c)
+	}
+	return nil
+}
+
+func (i *wasmOperatorInstance) stopContainerSubscription() {
+	if i.containerSubKey == "" {
+		return
+	}
+	if cc := i.getContainerCollection(); cc != nil {
+		cc.Unsubscribe(i.containerSubKey)
+	}
+	i.containerSubKey = ""
+}
@@ -17,6 +17,8 @@ package wasm
 import (
 	"context"
 	"fmt"
+	"slices"
+	"strings"
 
 	"github.com/tetratelabs/wazero"
 	wapi "github.com/tetratelabs/wazero/api"
@@ -39,6 +41,14 @@ func (i *wasmOperatorInstance) addDataSourceFuncs(env wazero.HostModuleBuilder)
 		[]wapi.ValueType{wapi.ValueTypeI32}, // DataSource
 	)
 
+	exportFunction(env, "getDataSourcesByTag", i.getDataSourcesByTag,
+		[]wapi.ValueType{
+			wapi.ValueTypeI64, // Tag
+			wapi.ValueTypeI64, // DataSource handles
+		},
+		[]wapi.ValueType{wapi.ValueTypeI64}, // Count
+	)
+
 	exportFunction(env, "dataSourceSubscribe", i.dataSourceSubscribe,
 		[]wapi.ValueType{
 			wapi.ValueTypeI32, // DataSource
@@ -66,6 +76,61 @@ func (i *wasmOperatorInstance) addDataSourceFuncs(env wazero.HostModuleBuilder)
 		[]wapi.ValueType{wapi.ValueTypeI32}, // Accessor
 	)
 
+	exportFunction(env, "dataSourceAddFieldWithOptions", i.dataSourceAddFieldWithOptions,
+		[]wapi.ValueType{
+			wapi.ValueTypeI32, // DataSource
+			wapi.ValueTypeI64, // Field name
+			wapi.ValueTypeI32, // Field kind
+			wapi.ValueTypeI32, // Flags
+			wapi.ValueTypeI32, // Order
+			wapi.ValueTypeI64, // Tags
+			wapi.ValueTypeI64, // Annotations
+		},
+		[]wapi.ValueType{wapi.ValueTypeI32}, // Field
+	)
+
+	exportFunction(env, "dataSourceGetFieldsWithTag", i.dataSourceGetFieldsWithTag,
+		[]wapi.ValueType{
+			wapi.ValueTypeI32, // DataSource
+			wapi.ValueTypeI64, // Tags
+			wapi.ValueTypeI64, // Field handles
+		},
+		[]wapi.ValueType{wapi.ValueTypeI64}, // Count
+	)
+
+	exportFunction(env, "dataSourceGetTags", i.dataSourceGetTags,
+		[]wapi.ValueType{
+			wapi.ValueTypeI32, // DataSource
+			wapi.ValueTypeI64, // Dest buffer
+		},
+		[]wapi.ValueType{wapi.ValueTypeI64}, // Size or Error
+	)
+
+	exportFunction(env, "dataSourceAddTag", i.dataSourceAddTag,
+		[]wapi.ValueType{
+			wapi.ValueTypeI32, // DataSource
+			wapi.ValueTypeI64, // Tag
+		},
+		[]wapi.ValueType{wapi.ValueTypeI32}, // Error
+	)
+
+	exportFunction(env, "dataSourceGetAnnotations", i.dataSourceGetAnnotations,
+		[]wapi.ValueType{
+			wapi.ValueTypeI32, // DataSource
+			wapi.ValueTypeI64, // Dest buffer
+		},
+		[]wapi.ValueType{wapi.ValueTypeI64}, // Size or Error
+	)
+
+	exportFunction(env, "dataSourceAddAnnotation", i.dataSourceAddAnnotation,
+		[]wapi.ValueType{
+			wapi.ValueTypeI32, // DataSource
+			wapi.ValueTypeI64, // Key
+			wapi.ValueTypeI64, // Value
+		},
+		[]wapi.ValueType{wapi.ValueTypeI32}, // Error
+	)
+
 	exportFunction(env, "dataSourceNewPacketSingle", i.dataSourceNewPacketSingle,
 		[]wapi.ValueType{wapi.ValueTypeI32}, // DataSource
 		[]wapi.ValueType{wapi.ValueTypeI32}, // Packet
@@ -194,6 +259,37 @@ func (i *wasmOperatorInstance) getDataSource(ctx context.Context, m wapi.Module,
 	stack[0] = wapi.EncodeU32(i.addHandle(ds))
 }
 
+// getDataSourcesByTag returns the data sources having the given tag, sorted by name.
+// Params:
+// - stack[0] is the tag (string encoded)
+// - stack[1] is a buffer where the handles of the data sources are written as uint32. Nothing is
+// written if it's too small to hold all of them.
+// Return value:
+// - Number of data sources having the tag on success, -1 on error
+func (i *wasmOperatorInstance) getDataSourcesByTag(ctx context.Context, m wapi.Module, stack []uint64) {
+	tagPtr := stack[0]
+	handlesPtr := stack[1]
+
+	tag, err := stringFromStack(m, tagPtr)
+	if err != nil {
+		i.logger.Warnf("getDataSourcesByTag: reading string from stack: %v", err)
+		stack[0] = wapi.EncodeI64(-1)
+		return
+	}
+
+	var dataSources []datasource.DataSource
+	for _, ds := range i.gadgetCtx.GetDataSources() {
+		if slices.Contains(ds.Tags(), tag) {
+			dataSources = append(dataSources, ds)
+		}
+	}
+	slices.SortFunc(dataSources, func(a, b datasource.DataSource) int {
+		return strings.Compare(a.Name(), b.Name())
+	})
+
+	stack[0] = writeHandlesIfFit(i, dataSources, handlesPtr)
+}
+
 // dataSourceGetField returns a handle to a field.
 // Params:
 // - stack[0]: DataSource handle
@@ -257,6 +353,204 @@ func (i *wasmOperatorInstance) dataSourceAddField(ctx context.Context, m wapi.Mo
 	stack[0] = wapi.EncodeU32(i.addHandle(acc))
 }
 
+// dataSourceAddFieldWithOptions is like dataSourceAddField, but also sets the flags, order, tags
+// and annotations of the field.
+// Params:
+// - stack[0]: DataSource handle
+// - stack[1]: Field name
+// - stack[2]: Field kind
+// - stack[3]: Flags, only FieldFlagEmpty and FieldFlagHidden can be set
+// - stack[4]: Order, 0 to use the default
+// - stack[5]: Tags as NUL terminated strings
+// - stack[6]: Annotations as NUL terminated key and value strings
+// Return value:
+// - Field handle on success, 0 on error
+func (i *wasmOperatorInstance) dataSourceAddFieldWithOptions(ctx context.Context, m wapi.Module, stack []uint64) {
+	dsHandle := wapi.DecodeU32(stack[0])
+	fieldNamePtr := stack[1]
+	fieldKind := wapi.DecodeU32(stack[2])
+	flags := datasource.FieldFlag(wapi.DecodeU32(stack[3]))
+	order := wapi.DecodeI32(stack[4])
+	tagsPtr := stack[5]
+	annotationsPtr := stack[6]
+
+	if fieldKind > uint32(api.Kind_Bytes) {
+		i.logger.Warnf("dataSourceAddFieldWithOptions: invalid field kind %d", fieldKind)
+		stack[0] = 0
+		return
+	}
+	if flags&^(datasource.FieldFlagEmpty|datasource.FieldFlagHidden) != 0 {
+		i.logger.Warnf("dataSourceAddFieldWithOptions: invalid flags %#x", uint32(flags))
+		stack[0] = 0
+		return
+	}
+
+	ds, ok := getHandle[datasource.DataSource](i, dsHandle)
+	if !ok {
+		stack[0] = 0
+		return
+	}
+	fieldName, err := stringFromStack(m, fieldNamePtr)
+	if err != nil {
+		i.logger.Warnf("dataSourceAddFieldWithOptions: reading string from stack: %v", err)
+		stack[0] = 0
+		return
+	}
+	tags, err := stringsFromStack(m, tagsPtr)
+	if err != nil {
+		i.logger.Warnf("dataSourceAddFieldWithOptions: reading tags from stack: %v", err)
+		stack[0] = 0
+		return
+	}
+	annotations, err := stringMapFromStack(m, annotationsPtr)
+	if err != nil {
+		i.logger.Warnf("dataSourceAddFieldWithOptions: reading annotations from stack: %v", err)
+		stack[0] = 0
+		return
+	}
+
+	opts := []datasource.FieldOption{
+		datasource.WithFlags(flags),
+		datasource.WithOrder(order),
+		datasource.WithTags(tags...),
+		datasource.WithAddedAnnotations(annotations),
+	}
+	acc, err := ds.AddField(fieldName, api.Kind(fieldKind), opts...)
+	if err != nil {
+		i.logger.Warnf("adding field %q to datasource %q: %v", fieldName, ds.Name(), err)
+		stack[0] = 0
+		return
+	}
+	stack[0] = wapi.EncodeU32(i.addHandle(acc))
+}
+
+// dataSourceGetFieldsWithTag returns the fields having any of the given tags
+// Params:
+// - stack[0]: DataSource handle
+// - stack[1]: Tags as NUL terminated strings
+// - stack[2]: Buffer where the handles of the fields are written as uint32. Nothing is written if
+// it's too small to hold all of them.
+// Return value:
+// - Number of fields having any of the tags on success, -1 on error
+func (i *wasmOperatorInstance) dataSourceGetFieldsWithTag(ctx context.Context, m wapi.Module, stack []uint64) {
+	dsHandle := wapi.DecodeU32(stack[0])
+	tagsPtr := stack[1]
+	handlesPtr := stack[2]
+
+	ds, ok := getHandle[datasource.DataSource](i, dsHandle)
+	if !ok {
+		stack[0] = wapi.EncodeI64(-1)
+		return
+	}
+	tags, err := stringsFromStack(m, tagsPtr)
+	if err != nil {
+		i.logger.Warnf("dataSourceGetFieldsWithTag: reading tags from stack: %v", err)
+		stack[0] = wapi.EncodeI64(-1)
+		return
+	}
+
+	stack[0] = writeHandlesIfFit(i, ds.GetFieldsWithTag(tags...), handlesPtr)
+}
+
+// dataSourceGetTags returns the tags of the data source as NUL terminated strings
+// Params:
+// - stack[0]: DataSource handle
+// - stack[1]: Destination buffer. Nothing is written if it's too small.
+// Return value:
+// - Size of the tags on success, -1 on error
+func (i *wasmOperatorInstance) dataSourceGetTags(ctx context.Context, m wapi.Module, stack []uint64) {
+	dsHandle := wapi.DecodeU32(stack[0])
+	dst := stack[1]
+
+	ds, ok := getHandle[datasource.DataSource](i, dsHandle)
+	if !ok {
+		stack[0] = wapi.EncodeI64(-1)
+		return
+	}
+
+	stack[0] = i.writeIfFits(stringsToBuf(ds.Tags()), dst)
+}
+
+// dataSourceAddTag adds a tag to the data source
+// Params:
+// - stack[0]: DataSource handle
+// - stack[1]: Tag to add
+// Return value:
+// - 0 on success, 1 on error
+func (i *wasmOperatorInstance) dataSourceAddTag(ctx context.Context, m wapi.Module, stack []uint64) {
+	dsHandle := wapi.DecodeU32(stack[0])
+	tagPtr := stack[1]
+
+	ds, ok := getHandle[datasource.DataSource](i, dsHandle)
+	if !ok {
+		stack[0] = 1
+		return
+	}
+	tag, err := stringFromStack(m, tagPtr)
+	if err != nil {
+		i.logger.Warnf("dataSourceAddTag: reading string from stack: %v", err)
+		stack[0] = 1
+		return
+	}
+
+	ds.AddTags(tag)
+	stack[0] = 0
+}
+
+// dataSourceGetAnnotations returns the annotations of the data source as NUL terminated key and
+// value strings, sorted by key
+// Params:
+// - stack[0]: DataSource handle
+// - stack[1]: Destination buffer. Nothing is written if it's too small.
+// Return value:
+// - Size of the annotations on success, -1 on error
+func (i *wasmOperatorInstance) dataSourceGetAnnotations(ctx context.Context, m wapi.Module, stack []uint64) {
+	dsHandle := wapi.DecodeU32(stack[0])
+	dst := stack[1]
+
+	ds, ok := getHandle[datasource.DataSource](i, dsHandle)
+	if !ok {
+		stack[0] = wapi.EncodeI64(-1)
+		return
+	}
+
+	stack[0] = i.writeIfFits(stringMapToBuf(ds.Annotations()), dst)
+}
+
+// dataSourceAddAnnotation sets an annotation of the data source
+// Params:
+// - stack[0]: DataSource handle
+// - stack[1]: Key
+// - stack[2]: Value
+// Return value:
+// - 0 on success, 1 on error
+func (i *wasmOperatorInstance) dataSourceAddAnnotation(ctx context.Context, m wapi.Module, stack []uint64) {
+	dsHandle := wapi.DecodeU32(stack[0])
+	keyPtr := stack[1]
+	valuePtr := stack[2]
+
+	ds, ok := getHandle[datasource.DataSource](i, dsHandle)
+	if !ok {
+		stack[0] = 1
+		return
+	}
+	key, err := stringFromStack(m, keyPtr)
+	if err != nil {
+		i.logger.Warnf("dataSourceAddAnnotation: reading string from stack: %v", err)
+		stack[0] = 1
+		return
+	}
+	value, err := stringFromStack(m, valuePtr)
+	if err != nil {
+		i.logger.Warnf("dataSourceAddAnnotation: reading string from stack: %v", err)
+		stack[0] = 1
+		return
+	}
+
+	ds.AddAnnotation(key, value)
+	stack[0] = 0
+}
+
 type subscriptionType uint32
 
 const (
@@ -266,11 +560,21 @@ const (
 	subscriptionTypePacket subscriptionType = 3
 )
 
-func (i *wasmOperatorInstance) callDsCallbackWithLock(ctx context.Context, cbID uint64, dsHandle uint64, dataHandle uint64) error {
-	i.dataSourceCallbackLock.Lock()
-	_, err := i.dataSourceCallback.Call(ctx, cbID, dsHandle, dataHandle)
-	i.dataSourceCallbackLock.Unlock()
-	return err
+// callDsCallback calls the data source callback of the guest for the given data, applying the
+// on-trap policy if it fails
+func (i *wasmOperatorInstance) callDsCallback(ctx context.Context, stats *callbackStats, cbID uint64, dsHandle uint32, data any) error {
+	if i.guestFailed.Load() {
+		return i.failedGuestResult()
+	}
+
+	tmpData := i.addHandle(data)
+	defer i.delHandle(tmpData)
+
+	err := i.callGuestCallbackWithLock(ctx, stats, i.dataSourceCallback, cbID, wapi.EncodeU32(dsHandle), wapi.EncodeU32(tmpData))
+	if err != nil {
+		return i.handleTrap(err)
+	}
+	return nil
 }
 
 // dataSourceSubscribe subscribes to the datasource.
@@ -298,29 +602,22 @@ func (i *wasmOperatorInstance) dataSourceSubscribe(ctx context.Context, m wapi.M
 		stack[0] = 1
 		return
 	}
-	var err error
 
+	stats := i.getCallbackStats("datasource", ds.Name())
+
+	var err error
 	switch subscriptionType(typ) {
 	case subscriptionTypeData:
 		err = ds.Subscribe(func(source datasource.DataSource, data datasource.Data) error {
-			tmpData := i.addHandle(data)
-			i.callDsCallbackWithLock(ctx, cbID, stack[0], wapi.EncodeU32(tmpData))
-			i.delHandle(tmpData)
-			return err
+			return i.callDsCallback(ctx, stats, cbID, dsHandle, data)
 		}, int(prio))
 	case subscriptionTypeArray:
 		err = ds.SubscribeArray(func(source datasource.DataSource, data datasource.DataArray) error {
-			tmpData := i.addHandle(data)
-			i.callDsCallbackWithLock(ctx, cbID, stack[0], wapi.EncodeU32(tmpData))
-			i.delHandle(tmpData)
-			return err
+			return i.callDsCallback(ctx, stats, cbID, dsHandle, data)
 		}, int(prio))
 	case subscriptionTypePacket:
 		err = ds.SubscribePacket(func(source datasource.DataSource, data datasource.Packet) error {
-			tmpData := i.addHandle(data)
-			i.callDsCallbackWithLock(ctx, cbID, stack[0], wapi.EncodeU32(tmpData))
-			i.delHandle(tmpData)
-			return err
+			return i.callDsCallback(ctx, stats, cbID, dsHandle, data)
 		}, int(prio))
 	default:
 		err = fmt.Errorf("unknown subscription type %d", typ)
@@ -38,16 +38,16 @@ func (i *wasmOperatorInstance) addExtraInfo(gadgetcontext operators.GadgetContex
 	wasmInfo := &api.ExtraInfo{
 		Data: make(map[string]*api.GadgetInspectAddendum),
 	}
-	wasmInfo.Data["wasm.gadgetAPIVersion"] = &api.GadgetInspectAddendum{
+	wasmInfo.Data[i.name+".gadgetAPIVersion"] = &api.GadgetInspectAddendum{
 		ContentType: "text/plain",
 		Content:     []byte(fmt.Sprintf("%d", version)),
 	}
 	upcallsJSON, _ := json.Marshal(upcalls)
-	wasmInfo.Data["wasm.upcalls"] = &api.GadgetInspectAddendum{
+	wasmInfo.Data[i.name+".upcalls"] = &api.GadgetInspectAddendum{
 		ContentType: "application/json",
 		Content:     []byte(upcallsJSON),
 	}
-	gadgetcontext.SetVar("extraInfo.wasm", wasmInfo)
+	gadgetcontext.SetVar("extraInfo."+i.name, wasmInfo)
 
 	return nil
 }
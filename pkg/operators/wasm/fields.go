@@ -62,6 +62,62 @@ func (i *wasmOperatorInstance) addFieldFuncs(env wazero.HostModuleBuilder) {
 		},
 		[]wapi.ValueType{wapi.ValueTypeI32}, // Error
 	)
+
+	exportFunction(env, "fieldGetName", i.fieldGetName,
+		[]wapi.ValueType{
+			wapi.ValueTypeI32, // Accessor
+			wapi.ValueTypeI64, // Dest buffer
+		},
+		[]wapi.ValueType{wapi.ValueTypeI64}, // Size or Error
+	)
+
+	exportFunction(env, "fieldGetKind", i.fieldGetKind,
+		[]wapi.ValueType{
+			wapi.ValueTypeI32, // Accessor
+		},
+		[]wapi.ValueType{wapi.ValueTypeI64}, // Kind or Error
+	)
+
+	exportFunction(env, "fieldGetFlags", i.fieldGetFlags,
+		[]wapi.ValueType{
+			wapi.ValueTypeI32, // Accessor
+		},
+		[]wapi.ValueType{wapi.ValueTypeI64}, // Flags or Error
+	)
+
+	exportFunction(env, "fieldSetHidden", i.fieldSetHidden,
+		[]wapi.ValueType{
+			wapi.ValueTypeI32, // Accessor
+			wapi.ValueTypeI32, // Hidden
+			wapi.ValueTypeI32, // Recurse
+		},
+		[]wapi.ValueType{wapi.ValueTypeI32}, // Error
+	)
+
+	exportFunction(env, "fieldGetTags", i.fieldGetTags,
+		[]wapi.ValueType{
+			wapi.ValueTypeI32, // Accessor
+			wapi.ValueTypeI64, // Dest buffer
+		},
+		[]wapi.ValueType{wapi.ValueTypeI64}, // Size or Error
+	)
+
+	exportFunction(env, "fieldGetAnnotations", i.fieldGetAnnotations,
+		[]wapi.ValueType{
+			wapi.ValueTypeI32, // Accessor
+			wapi.ValueTypeI64, // Dest buffer
+		},
+		[]wapi.ValueType{wapi.ValueTypeI64}, // Size or Error
+	)
+
+	exportFunction(env, "fieldAddAnnotation", i.fieldAddAnnotation,
+		[]wapi.ValueType{
+			wapi.ValueTypeI32, // Accessor
+			wapi.ValueTypeI64, // Key
+			wapi.ValueTypeI64, // Value
+		},
+		[]wapi.ValueType{wapi.ValueTypeI32}, // Error
+	)
 }
 
 func (i *wasmOperatorInstance) getDataFromDatasourceHandle(dataHandle uint32) (datasource.Data, bool) {
@@ -322,3 +378,151 @@ func (i *wasmOperatorInstance) fieldAddTag(ctx context.Context, m wapi.Module, s
 	field.AddTags(tag)
 	stack[0] = 0
 }
+
+// fieldGetName returns the name of the field
+// Params:
+// - stack[0]: Field handle
+// - stack[1]: Destination buffer. Nothing is written if it's too small.
+// Return value:
+// - Size of the name on success, -1 on error
+func (i *wasmOperatorInstance) fieldGetName(ctx context.Context, m wapi.Module, stack []uint64) {
+	fieldHandle := wapi.DecodeU32(stack[0])
+	dst := stack[1]
+
+	field, ok := getHandle[datasource.FieldAccessor](i, fieldHandle)
+	if !ok {
+		stack[0] = wapi.EncodeI64(-1)
+		return
+	}
+
+	stack[0] = i.writeIfFits([]byte(field.Name()), dst)
+}
+
+// fieldGetKind returns the kind of the field
+// Params:
+// - stack[0]: Field handle
+// Return value:
+// - Kind on success, -1 on error
+func (i *wasmOperatorInstance) fieldGetKind(ctx context.Context, m wapi.Module, stack []uint64) {
+	fieldHandle := wapi.DecodeU32(stack[0])
+
+	field, ok := getHandle[datasource.FieldAccessor](i, fieldHandle)
+	if !ok {
+		stack[0] = wapi.EncodeI64(-1)
+		return
+	}
+
+	stack[0] = wapi.EncodeI64(int64(field.Type()))
+}
+
+// fieldGetFlags returns the flags of the field
+// Params:
+// - stack[0]: Field handle
+// Return value:
+// - Flags on success, -1 on error
+func (i *wasmOperatorInstance) fieldGetFlags(ctx context.Context, m wapi.Module, stack []uint64) {
+	fieldHandle := wapi.DecodeU32(stack[0])
+
+	field, ok := getHandle[datasource.FieldAccessor](i, fieldHandle)
+	if !ok {
+		stack[0] = wapi.EncodeI64(-1)
+		return
+	}
+
+	stack[0] = wapi.EncodeI64(int64(field.Flags()))
+}
+
+// fieldSetHidden marks the field as hidden (by default), it can still be requested
+// Params:
+// - stack[0]: Field handle
+// - stack[1]: Hidden (0: visible, 1: hidden)
+// - stack[2]: Recurse (1: apply to the subfields too)
+// Return value:
+// - 0 on success, 1 on error
+func (i *wasmOperatorInstance) fieldSetHidden(ctx context.Context, m wapi.Module, stack []uint64) {
+	fieldHandle := wapi.DecodeU32(stack[0])
+	hidden := wapi.DecodeU32(stack[1]) != 0
+	recurse := wapi.DecodeU32(stack[2]) != 0
+
+	field, ok := getHandle[datasource.FieldAccessor](i, fieldHandle)
+	if !ok {
+		stack[0] = 1
+		return
+	}
+
+	field.SetHidden(hidden, recurse)
+	stack[0] = 0
+}
+
+// fieldGetTags returns the tags of the field as NUL terminated strings
+// Params:
+// - stack[0]: Field handle
+// - stack[1]: Destination buffer. Nothing is written if it's too small.
+// Return value:
+// - Size of the tags on success, -1 on error
+func (i *wasmOperatorInstance) fieldGetTags(ctx context.Context, m wapi.Module, stack []uint64) {
+	fieldHandle := wapi.DecodeU32(stack[0])
+	dst := stack[1]
+
+	field, ok := getHandle[datasource.FieldAccessor](i, fieldHandle)
+	if !ok {
+		stack[0] = wapi.EncodeI64(-1)
+		return
+	}
+
+	stack[0] = i.writeIfFits(stringsToBuf(field.Tags()), dst)
+}
+
+// fieldGetAnnotations returns the annotations of the field as NUL terminated key and value
+// strings, sorted by key
+// Params:
+// - stack[0]: Field handle
+// - stack[1]: Destination buffer. Nothing is written if it's too small.
+// Return value:
+// - Size of the annotations on success, -1 on error
+func (i *wasmOperatorInstance) fieldGetAnnotations(ctx context.Context, m wapi.Module, stack []uint64) {
+	fieldHandle := wapi.DecodeU32(stack[0])
+	dst := stack[1]
+
+	field, ok := getHandle[datasource.FieldAccessor](i, fieldHandle)
+	if !ok {
+		stack[0] = wapi.EncodeI64(-1)
+		return
+	}
+
+	stack[0] = i.writeIfFits(stringMapToBuf(field.Annotations()), dst)
+}
+
+// fieldAddAnnotation sets an annotation of the field
+// Params:
+// - stack[0]: Field handle
+// - stack[1]: Key
+// - stack[2]: Value
+// Return value:
+// - 0 on success, 1 on error
+func (i *wasmOperatorInstance) fieldAddAnnotation(ctx context.Context, m wapi.Module, stack []uint64) {
+	fieldHandle := wapi.DecodeU32(stack[0])
+	keyPtr := stack[1]
+	valuePtr := stack[2]
+
+	field, ok := getHandle[datasource.FieldAccessor](i, fieldHandle)
+	if !ok {
+		stack[0] = 1
+		return
+	}
+	key, err := stringFromStack(m, keyPtr)
+	if err != nil {
+		i.logger.Warnf("fieldAddAnnotation: reading string from stack: %v", err)
+		stack[0] = 1
+		return
+	}
+	value, err := stringFromStack(m, valuePtr)
+	if err != nil {
+		i.logger.Warnf("fieldAddAnnotation: reading string from stack: %v", err)
+		stack[0] = 1
+		return
+	}
+
+	field.AddAnnotation(key, value)
+	stack[0] = 0
+}
@@ -15,10 +15,13 @@
 package wasm
 
 import (
+	"bytes"
 	"context"
 	"encoding/binary"
 	"errors"
 	"fmt"
+	"maps"
+	"slices"
 
 	"github.com/tetratelabs/wazero"
 	wapi "github.com/tetratelabs/wazero/api"
@@ -112,3 +115,107 @@ func isDataArrayHandle(handle uint32) bool {
 func getIndexFromDataArrayHandle(dataHandle uint32) int {
 	return int(dataHandle &^ dataArrayHandleFlag >> 16)
 }
+
+// stringsToBuf encodes a list of strings as NUL terminated strings, the format used to pass lists
+// of strings (e.g. tags) to and from the guest
+func stringsToBuf(strs []string) []byte {
+	var buf []byte
+	for _, s := range strs {
+		buf = append(buf, s...)
+		buf = append(buf, 0)
+	}
+	return buf
+}
+
+// stringMapToBuf encodes a map as a list of key, value pairs sorted by key, see stringsToBuf
+func stringMapToBuf(m map[string]string) []byte {
+	strs := make([]string, 0, 2*len(m))
+	for _, k := range slices.Sorted(maps.Keys(m)) {
+		strs = append(strs, k, m[k])
+	}
+	return stringsToBuf(strs)
+}
+
+// stringsFromStack decodes a list of strings encoded by stringsToBuf
+func stringsFromStack(m wapi.Module, val uint64) ([]string, error) {
+	// handle empty lists in a special way
+	if val == 0 {
+		return nil, nil
+	}
+
+	buf, err := bufFromStack(m, val)
+	if err != nil {
+		return nil, err
+	}
+	if len(buf) > 0 && buf[len(buf)-1] != 0 {
+		return nil, errors.New("list of strings not NUL terminated")
+	}
+
+	var strs []string
+	for len(buf) > 0 {
+		idx := bytes.IndexByte(buf, 0)
+		strs = append(strs, string(buf[:idx]))
+		buf = buf[idx+1:]
+	}
+	return strs, nil
+}
+
+// stringMapFromStack decodes a map encoded by stringMapToBuf
+func stringMapFromStack(m wapi.Module, val uint64) (map[string]string, error) {
+	strs, err := stringsFromStack(m, val)
+	if err != nil {
+		return nil, err
+	}
+	if len(strs)%2 != 0 {
+		return nil, fmt.Errorf("odd number of strings in map: %d", len(strs))
+	}
+
+	res := make(map[string]string, len(strs)/2)
+	for idx := 0; idx < len(strs); idx += 2 {
+		res[strs[idx]] = strs[idx+1]
+	}
+	return res, nil
+}
+
+// writeIfFits writes src to dstBuf if it's big enough. It returns the size of src, so the guest
+// can retry with a bigger buffer if it wasn't written, or -1 on error.
+func (i *wasmOperatorInstance) writeIfFits(src []byte, dstBuf uint64) uint64 {
+	if getLength(dstBuf) < uint32(len(src)) {
+		return wapi.EncodeI64(int64(len(src)))
+	}
+	if !i.mod.Memory().Write(getAddress(dstBuf), src) {
+		i.logger.Warnf("writing bytes to guest memory: out of memory write")
+		return wapi.EncodeI64(-1)
+	}
+	return wapi.EncodeI64(int64(len(src)))
+}
+
+// writeHandlesIfFit creates handles for objs and writes them as uint32 to dstBuf, if it's big
+// enough to hold all of them. It returns the number of objects, so the guest can retry with a
+// bigger buffer if they weren't written, or -1 on error.
+func writeHandlesIfFit[T any](i *wasmOperatorInstance, objs []T, dstBuf uint64) uint64 {
+	if getLength(dstBuf) < uint32(4*len(objs)) {
+		return wapi.EncodeI64(int64(len(objs)))
+	}
+
+	buf := make([]byte, 4*len(objs))
+	for idx, obj := range objs {
+		handle := i.addHandle(obj)
+		if handle == 0 {
+			for j := range idx {
+				i.delHandle(binary.LittleEndian.Uint32(buf[4*j:]))
+			}
+			return wapi.EncodeI64(-1)
+		}
+		binary.LittleEndian.PutUint32(buf[4*idx:], handle)
+	}
+
+	ret := i.writeIfFits(buf, dstBuf)
+	if int64(ret) < 0 {
+		for idx := range objs {
+			i.delHandle(binary.LittleEndian.Uint32(buf[4*idx:]))
+		}
+		return ret
+	}
+	return wapi.EncodeI64(int64(len(objs)))
+}
@@ -0,0 +1,248 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wasm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/docker/go-units"
+	"github.com/tetratelabs/wazero/sys"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/metrics"
+)
+
+const (
+	ParamMemoryLimit     = "memory-limit"
+	ParamCallbackTimeout = "callback-timeout"
+	ParamOnTrap          = "on-trap"
+
+	// Gadgets can change the defaults of the params above with annotations in their metadata,
+	// e.g. "wasm.memory-limit: 64MB"
+	annotationPrefix = "annotations.wasm."
+
+	defaultMemoryLimit     = "16MB"
+	defaultCallbackTimeout = "1s"
+
+	OnTrapDrop    = "drop"
+	OnTrapDisable = "disable"
+	OnTrapStop    = "stop"
+
+	wasmPageSize = 64 * 1024
+	// wasm32 can't address more than 4GiB
+	maxMemoryPages = 65536
+)
+
+var (
+	metricGuestTime, _ = metrics.Float64Counter("ig_wasm_guest_time",
+		metric.WithDescription("Time spent running callbacks of wasm gadget modules"),
+		metric.WithUnit("s"),
+	)
+	metricGuestCalls, _ = metrics.Int64Counter("ig_wasm_guest_calls",
+		metric.WithDescription("Number of callbacks of wasm gadget modules called"),
+		metric.WithUnit("{call}"),
+	)
+	metricGuestTraps, _ = metrics.Int64Counter("ig_wasm_guest_traps",
+		metric.WithDescription("Number of callbacks of wasm gadget modules that failed"),
+		metric.WithUnit("{trap}"),
+	)
+)
+
+// limits are the resource limits of a wasm module and what to do when it fails
+type limits struct {
+	memoryPages     uint32
+	callbackTimeout time.Duration
+	onTrap          string
+}
+
+func (i *wasmOperatorInstance) limitParams() api.Params {
+	return api.Params{
+		{
+			Key:          ParamMemoryLimit,
+			Title:        "Memory Limit",
+			Description:  "Maximum memory the wasm module can use (e.g. 64MB)",
+			DefaultValue: i.limitDefault(ParamMemoryLimit, defaultMemoryLimit),
+			TypeHint:     api.TypeString,
+			Tags:         []string{api.TagAdvanced},
+		},
+		{
+			Key:          ParamCallbackTimeout,
+			Title:        "Callback Timeout",
+			Description:  "Maximum time the wasm module can spend handling an event or a timer. The module is terminated when it's exceeded. 0 disables the limit",
+			DefaultValue: i.limitDefault(ParamCallbackTimeout, defaultCallbackTimeout),
+			TypeHint:     api.TypeDuration,
+			Tags:         []string{api.TagAdvanced},
+		},
+		{
+			Key:            ParamOnTrap,
+			Title:          "On Trap",
+			Description:    "What to do when the wasm module fails handling an event: drop the event, disable the wasm module and let events through or stop the gadget",
+			DefaultValue:   i.limitDefault(ParamOnTrap, OnTrapDrop),
+			TypeHint:       api.TypeString,
+			PossibleValues: []string{OnTrapDrop, OnTrapDisable, OnTrapStop},
+			Tags:           []string{api.TagAdvanced},
+		},
+	}
+}
+
+// limitDefault returns the default value of a limit, as set by the annotations of the gadget or
+// def otherwise
+func (i *wasmOperatorInstance) limitDefault(key, def string) string {
+	if i.imageConfig != nil {
+		if v := i.imageConfig.GetString(annotationPrefix + key); v != "" {
+			return v
+		}
+	}
+	return def
+}
+
+func (i *wasmOperatorInstance) initLimits() error {
+	values := map[string]string{}
+	for _, p := range i.limitParams() {
+		values[p.Key] = p.DefaultValue
+		if v, ok := i.paramValues[p.Key]; ok && v != "" {
+			values[p.Key] = v
+		}
+	}
+
+	memoryLimit, err := units.RAMInBytes(values[ParamMemoryLimit])
+	if err != nil {
+		return fmt.Errorf("parsing %s: %w", ParamMemoryLimit, err)
+	}
+	pages := memoryLimit / wasmPageSize
+	if pages < 1 || pages > maxMemoryPages {
+		return fmt.Errorf("%s must be between %s and %s", ParamMemoryLimit,
+			units.BytesSize(wasmPageSize), units.BytesSize(maxMemoryPages*wasmPageSize))
+	}
+	i.limits.memoryPages = uint32(pages)
+
+	i.limits.callbackTimeout, err = time.ParseDuration(values[ParamCallbackTimeout])
+	if err != nil {
+		return fmt.Errorf("parsing %s: %w", ParamCallbackTimeout, err)
+	}
+
+	switch onTrap := values[ParamOnTrap]; onTrap {
+	case OnTrapDrop, OnTrapDisable, OnTrapStop:
+		i.limits.onTrap = onTrap
+	default:
+		return fmt.Errorf("invalid %s %q, expected %q, %q or %q", ParamOnTrap, onTrap,
+			OnTrapDrop, OnTrapDisable, OnTrapStop)
+	}
+
+	return nil
+}
+
+// callbackStats accounts the time spent in the callbacks of the guest for a data source or the
+// timers
+type callbackStats struct {
+	name  string
+	attrs metric.MeasurementOption
+
+	calls  atomic.Uint64
+	traps  atomic.Uint64
+	timeNs atomic.Int64
+}
+
+func (s *callbackStats) record(d time.Duration, failed bool) {
+	s.calls.Add(1)
+	s.timeNs.Add(int64(d))
+
+	ctx := context.Background()
+	metricGuestCalls.Add(ctx, 1, s.attrs)
+	metricGuestTime.Add(ctx, d.Seconds(), s.attrs)
+	if failed {
+		s.traps.Add(1)
+		metricGuestTraps.Add(ctx, 1, s.attrs)
+	}
+}
+
+// getCallbackStats returns the stats for the callbacks of the given kind ("datasource", "timer"
+// or "container") and data source
+func (i *wasmOperatorInstance) getCallbackStats(kind, dsName string) *callbackStats {
+	name := kind
+	if dsName != "" {
+		name += ":" + dsName
+	}
+
+	i.callbackStatsLock.Lock()
+	defer i.callbackStatsLock.Unlock()
+
+	if s, ok := i.callbackStats[name]; ok {
+		return s
+	}
+
+	s := &callbackStats{
+		name: name,
+		attrs: metric.WithAttributeSet(attribute.NewSet(
+			attribute.String("gadget_image", i.gadgetCtx.ImageName()),
+			attribute.String("callback", kind),
+			attribute.String("datasource", dsName),
+		)),
+	}
+	i.callbackStats[name] = s
+	return s
+}
+
+func (i *wasmOperatorInstance) logCallbackStats() {
+	i.callbackStatsLock.Lock()
+	defer i.callbackStatsLock.Unlock()
+
+	for _, s := range i.callbackStats {
+		i.logger.Debugf("wasm guest spent %s in %d calls for %s (%d failed)",
+			time.Duration(s.timeNs.Load()), s.calls.Load(), s.name, s.traps.Load())
+	}
+}
+
+// handleTrap applies the on-trap policy after a callback of the guest returned err. It returns
+// the error the data source callback has to return.
+func (i *wasmOperatorInstance) handleTrap(err error) error {
+	// The module can't be called anymore after it exited, e.g. on a Go panic or when a callback
+	// was interrupted
+	var exitErr *sys.ExitError
+	exited := errors.As(err, &exitErr)
+
+	if i.limits.onTrap == OnTrapDrop && !exited {
+		i.logger.Warnf("wasm callback failed: %v", err)
+		return datasource.ErrDiscard
+	}
+
+	if i.guestFailed.CompareAndSwap(false, true) {
+		switch i.limits.onTrap {
+		case OnTrapDrop:
+			i.logger.Errorf("wasm module terminated, dropping all events: %v", err)
+		case OnTrapDisable:
+			i.logger.Errorf("wasm callback failed, disabling wasm module: %v", err)
+		case OnTrapStop:
+			i.logger.Errorf("wasm callback failed, stopping gadget: %v", err)
+			i.gadgetCtx.Cancel()
+		}
+	}
+	return i.failedGuestResult()
+}
+
+// failedGuestResult is what data source callbacks return once the guest can't be called anymore
+func (i *wasmOperatorInstance) failedGuestResult() error {
+	if i.limits.onTrap == OnTrapDisable {
+		return nil
+	}
+	return datasource.ErrDiscard
+}
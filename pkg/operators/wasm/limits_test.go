@@ -0,0 +1,106 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wasm
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInitLimits(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		metadata    string
+		paramValues map[string]string
+		expected    limits
+		errExpected bool
+	}{
+		{
+			name:     "defaults",
+			expected: limits{memoryPages: 256, callbackTimeout: time.Second, onTrap: OnTrapDrop},
+		},
+		{
+			name: "annotations",
+			metadata: `
+annotations:
+  wasm.memory-limit: 64MB
+  wasm.callback-timeout: 0s
+  wasm.on-trap: stop
+`,
+			expected: limits{memoryPages: 1024, callbackTimeout: 0, onTrap: OnTrapStop},
+		},
+		{
+			name: "params override annotations",
+			metadata: `
+annotations:
+  wasm.memory-limit: 64MB
+`,
+			paramValues: map[string]string{
+				ParamMemoryLimit:     "1MB",
+				ParamCallbackTimeout: "100ms",
+				ParamOnTrap:          OnTrapDisable,
+			},
+			expected: limits{memoryPages: 16, callbackTimeout: 100 * time.Millisecond, onTrap: OnTrapDisable},
+		},
+		{
+			name:        "memory limit too small",
+			paramValues: map[string]string{ParamMemoryLimit: "1KB"},
+			errExpected: true,
+		},
+		{
+			name:        "memory limit too big",
+			paramValues: map[string]string{ParamMemoryLimit: "8GB"},
+			errExpected: true,
+		},
+		{
+			name:        "invalid timeout",
+			paramValues: map[string]string{ParamCallbackTimeout: "foo"},
+			errExpected: true,
+		},
+		{
+			name:        "invalid on-trap",
+			paramValues: map[string]string{ParamOnTrap: "foo"},
+			errExpected: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			config := viper.New()
+			config.SetConfigType("yaml")
+			require.NoError(t, config.ReadConfig(bytes.NewBufferString(test.metadata)))
+
+			i := &wasmOperatorInstance{
+				imageConfig: config,
+				paramValues: test.paramValues,
+			}
+			err := i.initLimits()
+			if test.errExpected {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.expected, i.limits)
+		})
+	}
+}
@@ -17,7 +17,10 @@ package wasm
 import (
 	"context"
 	"encoding/binary"
+	"errors"
 	"fmt"
+	"reflect"
+	"unsafe"
 
 	"github.com/cilium/ebpf"
 	"github.com/tetratelabs/wazero"
@@ -70,6 +73,35 @@ func (i *wasmOperatorInstance) addMapFuncs(env wazero.HostModuleBuilder) {
 		[]wapi.ValueType{wapi.ValueTypeI32}, // Error
 	)
 
+	exportFunction(env, "mapGetNextKey", i.mapGetNextKey,
+		[]wapi.ValueType{
+			wapi.ValueTypeI32, // Map
+			wapi.ValueTypeI64, // Key pointer
+			wapi.ValueTypeI64, // Next key pointer
+		},
+		[]wapi.ValueType{wapi.ValueTypeI32}, // Error
+	)
+
+	exportFunction(env, "mapBatchLookup", i.mapBatchLookup,
+		[]wapi.ValueType{
+			wapi.ValueTypeI32, // Map
+			wapi.ValueTypeI64, // Cursor pointer
+			wapi.ValueTypeI64, // Keys pointer
+			wapi.ValueTypeI64, // Values pointer
+		},
+		[]wapi.ValueType{wapi.ValueTypeI64}, // Count
+	)
+
+	exportFunction(env, "mapBatchLookupAndDelete", i.mapBatchLookupAndDelete,
+		[]wapi.ValueType{
+			wapi.ValueTypeI32, // Map
+			wapi.ValueTypeI64, // Cursor pointer
+			wapi.ValueTypeI64, // Keys pointer
+			wapi.ValueTypeI64, // Values pointer
+		},
+		[]wapi.ValueType{wapi.ValueTypeI64}, // Count
+	)
+
 	exportFunction(env, "mapRelease", i.mapRelease,
 		[]wapi.ValueType{
 			wapi.ValueTypeI32, // Map
@@ -91,6 +123,10 @@ func isMapOfMaps(typ ebpf.MapType) bool {
 	return typ == ebpf.ArrayOfMaps || typ == ebpf.HashOfMaps
 }
 
+func isMapPerCPU(typ ebpf.MapType) bool {
+	return typ == ebpf.PerCPUHash || typ == ebpf.PerCPUArray || typ == ebpf.LRUCPUHash || typ == ebpf.PerCPUCGroupStorage
+}
+
 func isMapTypeSupportedForAccess(typ ebpf.MapType) error {
 	// We do not permit operation on map unknown to us.
 	// A newer kernel can introduce a new type of map taking an FD as a value,
@@ -381,6 +417,267 @@ func (i *wasmOperatorInstance) mapDelete(ctx context.Context, m wapi.Module, sta
 	stack[0] = 0
 }
 
+// mapGetNextKey gets the key following the given one, which allows to iterate over the map.
+// Params:
+// - stack[0]: Map handle
+// - stack[1]: Key pointer, 0 to get the first key of the map
+// - stack[2]: Next key pointer
+// Return value:
+// - 0 on success, 1 on error, 2 if the given key is the last one
+func (i *wasmOperatorInstance) mapGetNextKey(ctx context.Context, m wapi.Module, stack []uint64) {
+	mapHandle := wapi.DecodeU32(stack[0])
+	keyPtr := stack[1]
+	nextKeyPtr := stack[2]
+
+	ebpfMap, ok := getHandle[*ebpf.Map](i, mapHandle)
+	if !ok {
+		stack[0] = 1
+		return
+	}
+
+	err := isMapTypeSupportedForAccess(ebpfMap.Type())
+	if err != nil {
+		i.logger.Warnf("mapGetNextKey: %v", err)
+		stack[0] = 1
+		return
+	}
+
+	// A nil key makes the kernel return the first key
+	var key any
+	if keyPtr != 0 {
+		key, err = bufFromStack(m, keyPtr)
+		if err != nil {
+			i.logger.Warnf("mapGetNextKey: getting a buf for key pointer: %v", err)
+			stack[0] = 1
+			return
+		}
+	}
+
+	nextKey, err := ebpfMap.NextKeyBytes(key)
+	if err != nil {
+		i.logger.Warnf("mapGetNextKey: getting next key: %v", err)
+		stack[0] = 1
+		return
+	}
+	if nextKey == nil {
+		stack[0] = 2
+		return
+	}
+
+	err = bufToStack(m, nextKey, nextKeyPtr)
+	if err != nil {
+		i.logger.Warnf("mapGetNextKey: writing back next key to stack: %v", err)
+		stack[0] = 1
+		return
+	}
+
+	stack[0] = 0
+}
+
+// mapBatchCursor keeps the position of batch operations on a map between calls
+type mapBatchCursor struct {
+	m      *ebpf.Map
+	cursor ebpf.MapBatchCursor
+
+	// iterate is set when the kernel doesn't support batch operations for the map. Entries are
+	// then read one by one, starting after lastKey.
+	iterate bool
+	lastKey []byte
+}
+
+// sliceOf returns buf as a slice of count arrays of size bytes, sharing the memory of buf. This
+// lets the kernel write the entries directly to the memory of the guest.
+func sliceOf(buf []byte, count, size int) any {
+	elem := reflect.ArrayOf(size, reflect.TypeFor[byte]())
+	arr := reflect.NewAt(reflect.ArrayOf(count, elem), unsafe.Pointer(unsafe.SliceData(buf)))
+	return arr.Elem().Slice(0, count).Interface()
+}
+
+// next reads the next entries of the map into keys and values. It returns the number of entries
+// read and whether the end of the map was reached.
+func (c *mapBatchCursor) next(keys, values []byte, count int, del bool) (int, bool, error) {
+	keySize := int(c.m.KeySize())
+	valueSize := int(c.m.ValueSize())
+
+	if !c.iterate {
+		var n int
+		var err error
+		keysOut := sliceOf(keys, count, keySize)
+		valuesOut := sliceOf(values, count, valueSize)
+		if del {
+			n, err = c.m.BatchLookupAndDelete(&c.cursor, keysOut, valuesOut, nil)
+		} else {
+			n, err = c.m.BatchLookup(&c.cursor, keysOut, valuesOut, nil)
+		}
+		switch {
+		case err == nil:
+			return n, false, nil
+		case errors.Is(err, ebpf.ErrKeyNotExist):
+			return n, true, nil
+		case !errors.Is(err, ebpf.ErrNotSupported):
+			return 0, false, err
+		}
+		// Batch operations aren't available before Linux 5.6 and not all map types implement
+		// them, fall back to reading the entries one by one.
+		c.iterate = true
+	}
+
+	n := 0
+	for n < count {
+		// Deleted keys aren't returned anymore, so always start from the beginning in that case
+		var prev any
+		if !del && c.lastKey != nil {
+			prev = c.lastKey
+		}
+		key, err := c.m.NextKeyBytes(prev)
+		if err != nil {
+			return 0, false, fmt.Errorf("getting next key: %w", err)
+		}
+		if key == nil {
+			return n, true, nil
+		}
+		c.lastKey = key
+
+		value, err := c.m.LookupBytes(key)
+		if err != nil {
+			return 0, false, fmt.Errorf("looking up key: %w", err)
+		}
+		if value == nil {
+			// Deleted in the meantime
+			continue
+		}
+		if del {
+			err := c.m.Delete(key)
+			if err != nil && !errors.Is(err, ebpf.ErrKeyNotExist) {
+				return 0, false, fmt.Errorf("deleting key: %w", err)
+			}
+		}
+
+		copy(keys[n*keySize:], key)
+		copy(values[n*valueSize:], value)
+		n++
+	}
+	return n, false, nil
+}
+
+// mapBatchLookup looks up multiple entries of the map at once.
+// Params:
+// - stack[0]: Map handle
+// - stack[1]: Cursor pointer, to a u32 holding the handle of the cursor. It has to be 0 to start
+// reading at the beginning of the map. It's updated on each call and set back to 0 (releasing the
+// cursor) once all entries were read or on error.
+// - stack[2]: Keys pointer, to a buffer that can hold N keys
+// - stack[3]: Values pointer, to a buffer that can hold N values
+// Return value:
+// - Number of entries read on success, -1 on error
+func (i *wasmOperatorInstance) mapBatchLookup(ctx context.Context, m wapi.Module, stack []uint64) {
+	stack[0] = i.mapBatch("mapBatchLookup", m, stack, false)
+}
+
+// mapBatchLookupAndDelete looks up and deletes multiple entries of the map at once.
+// Params:
+// - stack[0]: Map handle
+// - stack[1]: Cursor pointer, see mapBatchLookup
+// - stack[2]: Keys pointer, to a buffer that can hold N keys
+// - stack[3]: Values pointer, to a buffer that can hold N values
+// Return value:
+// - Number of entries read and deleted on success, -1 on error
+func (i *wasmOperatorInstance) mapBatchLookupAndDelete(ctx context.Context, m wapi.Module, stack []uint64) {
+	stack[0] = i.mapBatch("mapBatchLookupAndDelete", m, stack, true)
+}
+
+func (i *wasmOperatorInstance) mapBatch(fn string, m wapi.Module, stack []uint64, del bool) uint64 {
+	mapHandle := wapi.DecodeU32(stack[0])
+	cursorPtr := stack[1]
+	keysPtr := stack[2]
+	valuesPtr := stack[3]
+
+	errRet := wapi.EncodeI64(-1)
+
+	ebpfMap, ok := getHandle[*ebpf.Map](i, mapHandle)
+	if !ok {
+		return errRet
+	}
+
+	mapType := ebpfMap.Type()
+	err := isMapTypeSupportedForAccess(mapType)
+	if err != nil {
+		i.logger.Warnf("%s: %v", fn, err)
+		return errRet
+	}
+
+	keySize := int(ebpfMap.KeySize())
+	valueSize := int(ebpfMap.ValueSize())
+	if isMapOfMaps(mapType) || isMapPerCPU(mapType) || keySize == 0 || valueSize == 0 {
+		i.logger.Warnf("%s: batch operations are not supported for %v maps", fn, mapType)
+		return errRet
+	}
+
+	cursorBuf, err := bufFromStack(m, cursorPtr)
+	if err != nil {
+		i.logger.Warnf("%s: getting a buf for cursor pointer: %v", fn, err)
+		return errRet
+	}
+	if len(cursorBuf) != 4 {
+		i.logger.Warnf("%s: cursor has %d bytes, expected 4", fn, len(cursorBuf))
+		return errRet
+	}
+
+	keys, err := bufFromStack(m, keysPtr)
+	if err != nil {
+		i.logger.Warnf("%s: getting a buf for keys pointer: %v", fn, err)
+		return errRet
+	}
+
+	values, err := bufFromStack(m, valuesPtr)
+	if err != nil {
+		i.logger.Warnf("%s: getting a buf for values pointer: %v", fn, err)
+		return errRet
+	}
+
+	count := len(keys) / keySize
+	if count == 0 || len(keys)%keySize != 0 || len(values) != count*valueSize {
+		i.logger.Warnf("%s: buffers of %d and %d bytes don't hold the same number of keys of %d bytes and values of %d bytes",
+			fn, len(keys), len(values), keySize, valueSize)
+		return errRet
+	}
+
+	cursorHandle := binary.NativeEndian.Uint32(cursorBuf)
+	var cursor *mapBatchCursor
+	if cursorHandle == 0 {
+		cursor = &mapBatchCursor{m: ebpfMap}
+		cursorHandle = i.addHandle(cursor)
+		if cursorHandle == 0 {
+			return errRet
+		}
+	} else {
+		cursor, ok = getHandle[*mapBatchCursor](i, cursorHandle)
+		if !ok {
+			return errRet
+		}
+		if cursor.m != ebpfMap {
+			i.logger.Warnf("%s: cursor %d belongs to another map", fn, cursorHandle)
+			return errRet
+		}
+	}
+
+	// keys, values and cursorBuf are views of the memory of the guest, so the results are
+	// written to the guest directly
+	n, done, err := cursor.next(keys, values, count, del)
+	if err != nil || done {
+		i.delHandle(cursorHandle)
+		cursorHandle = 0
+	}
+	binary.NativeEndian.PutUint32(cursorBuf, cursorHandle)
+
+	if err != nil {
+		i.logger.Warnf("%s: %v", fn, err)
+		return errRet
+	}
+
+	return uint64(n)
+}
+
 // mapRelease close the map and release the handle.
 // This should only be called by maps that were created by newMap
 // Params:
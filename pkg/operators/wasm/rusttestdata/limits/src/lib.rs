@@ -0,0 +1,59 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+use api::{
+    datasources::{Data, DataSource, FieldKind},
+    fields::FieldData,
+    warnf,
+};
+
+// Keep in sync with TestWasmLimits in wasm_test.go
+const VALUE_HANG: u32 = 1;
+
+#[no_mangle]
+#[allow(non_snake_case)]
+fn gadgetInit() -> i32 {
+    let Ok(ds) = DataSource::get_datasource("input_ds".to_string()) else {
+        warnf!("failed to get datasource");
+        return 1;
+    };
+
+    let value_field = match ds.get_field("value") {
+        Ok(field) => field,
+        Err(e) => {
+            warnf!("failed to get field: {:?}", e);
+            return 1;
+        }
+    };
+
+    if let Err(e) = ds.subscribe(
+        move |_source: DataSource, data: Data| {
+            let Ok(FieldData::Uint32(val)) = value_field.get_data(data, FieldKind::Uint32) else {
+                panic!("failed to get field");
+            };
+            if val == VALUE_HANG {
+                // Never returns, the host has to interrupt it
+                loop {
+                    std::hint::spin_loop();
+                }
+            }
+        },
+        0,
+    ) {
+        warnf!("failed to subscribe: {:?}", e);
+        return 1;
+    }
+
+    0
+}
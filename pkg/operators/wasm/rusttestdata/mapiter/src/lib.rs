@@ -0,0 +1,147 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+use std::collections::HashSet;
+
+use api::{
+    errorf,
+    map::{Map, MapBatchCursor, MapSpec, MapType},
+};
+
+const ENTRIES: u32 = 100;
+
+fn fill_map(m: &Map) -> Result<(), String> {
+    for k in 0..ENTRIES {
+        m.put(&k, &(u64::from(k) * 2))?;
+    }
+    Ok(())
+}
+
+fn test_next_key(m: &Map) -> Result<(), String> {
+    let mut key: Option<u32> = None;
+    let mut next_key: u32 = 0;
+    let mut seen = HashSet::new();
+    while m.next_key(key.as_ref(), &mut next_key)? {
+        seen.insert(next_key);
+        key = Some(next_key);
+    }
+    if seen.len() != ENTRIES as usize {
+        return Err(format!(
+            "next_key returned {} keys, expected {}",
+            seen.len(),
+            ENTRIES
+        ));
+    }
+    Ok(())
+}
+
+fn test_batch(m: &Map, delete: bool) -> Result<(), String> {
+    // Smaller than the number of entries to need several calls
+    let mut keys = [0u32; 16];
+    let mut values = [0u64; 16];
+    let mut cursor = MapBatchCursor::new();
+    let mut seen = HashSet::new();
+    while !cursor.done() {
+        let n = if delete {
+            m.batch_lookup_and_delete(&mut cursor, &mut keys, &mut values)?
+        } else {
+            m.batch_lookup(&mut cursor, &mut keys, &mut values)?
+        };
+        for i in 0..n {
+            if values[i] != u64::from(keys[i]) * 2 {
+                return Err(format!(
+                    "expected value {} for key {}, got {}",
+                    keys[i] * 2,
+                    keys[i],
+                    values[i]
+                ));
+            }
+            seen.insert(keys[i]);
+        }
+    }
+    if seen.len() != ENTRIES as usize {
+        return Err(format!(
+            "batch returned {} entries, expected {}",
+            seen.len(),
+            ENTRIES
+        ));
+    }
+    Ok(())
+}
+
+#[no_mangle]
+#[allow(non_snake_case)]
+fn gadgetStart() -> i32 {
+    for typ in [MapType::Hash, MapType::Array] {
+        let m = match Map::new(&MapSpec {
+            name: "map_iter".to_string(),
+            typ,
+            key_size: 4,
+            value_size: 8,
+            max_entries: ENTRIES,
+        }) {
+            Ok(m) => m,
+            Err(e) => {
+                errorf!("creating map: {}", e);
+                return 1;
+            }
+        };
+
+        if let Err(e) = fill_map(&m) {
+            errorf!("filling map: {}", e);
+            return 1;
+        }
+        if let Err(e) = test_next_key(&m) {
+            errorf!("iterating over map: {}", e);
+            return 1;
+        }
+        if let Err(e) = test_batch(&m, false) {
+            errorf!("batch lookup: {}", e);
+            return 1;
+        }
+
+        // The cursor is released when dropped before reading all entries
+        {
+            let mut cursor = MapBatchCursor::new();
+            if let Err(e) = m.batch_lookup(&mut cursor, &mut [0u32; 16], &mut [0u64; 16]) {
+                errorf!("batch lookup: {}", e);
+                return 1;
+            }
+        }
+
+        // Buffers not matching the size of the entries are rejected
+        if m.batch_lookup(&mut MapBatchCursor::new(), &mut [0u64; 1], &mut [0u64; 1])
+            .is_ok()
+        {
+            errorf!("batch lookup with wrong key size succeeded");
+            return 1;
+        }
+
+        if !matches!(typ, MapType::Hash) {
+            continue;
+        }
+
+        if let Err(e) = test_batch(&m, true) {
+            errorf!("batch lookup and delete: {}", e);
+            return 1;
+        }
+        let mut key: u32 = 0;
+        if !matches!(m.next_key(None, &mut key), Ok(false)) {
+            errorf!("map not empty after batch lookup and delete");
+            return 1;
+        }
+    }
+
+    0
+}
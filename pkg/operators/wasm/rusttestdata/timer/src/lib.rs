@@ -0,0 +1,84 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+use std::time::Duration;
+
+use api::{
+    datasources::{Data, DataSource, DataSourceType, FieldKind, Packet},
+    timer::Timer,
+    warnf,
+};
+
+// Keep in sync with TestWasmTimers in wasm_test.go
+const KIND_TICKER: u32 = 1;
+const KIND_TIMER: u32 = 2;
+const KIND_STOPPED: u32 = 3;
+
+#[no_mangle]
+#[allow(non_snake_case)]
+fn gadgetInit() -> i32 {
+    let Ok(ds) = DataSource::new_datasource("timer_ds".to_string(), DataSourceType::Single) else {
+        warnf!("failed to create datasource");
+        return 1;
+    };
+
+    let kind_field = match ds.add_field("kind", FieldKind::Uint32) {
+        Ok(field) => field,
+        Err(e) => {
+            warnf!("failed to add field: {:?}", e);
+            return 1;
+        }
+    };
+
+    let emit = move |kind: u32| {
+        let packet = match ds.new_packet_single() {
+            Ok(p) => p,
+            Err(e) => {
+                warnf!("failed to create new packet: {:?}", e);
+                return;
+            }
+        };
+        _ = kind_field.set_data(Data(packet.0), &kind);
+        _ = ds.emit_and_release(Packet(packet.0));
+    };
+
+    // Timers created here are armed when the gadget starts
+    if let Err(e) = Timer::new_ticker(Duration::from_millis(50), move || emit(KIND_TICKER)) {
+        warnf!("failed to create ticker: {}", e);
+        return 1;
+    }
+    if let Err(e) = Timer::new_timer(Duration::from_millis(100), move || emit(KIND_TIMER)) {
+        warnf!("failed to create timer: {}", e);
+        return 1;
+    }
+    let stopped = match Timer::new_timer(Duration::from_millis(100), move || emit(KIND_STOPPED)) {
+        Ok(t) => t,
+        Err(e) => {
+            warnf!("failed to create timer: {}", e);
+            return 1;
+        }
+    };
+    if let Err(e) = stopped.stop() {
+        warnf!("failed to stop timer: {}", e);
+        return 1;
+    }
+
+    // Timers with too small intervals are rejected
+    if Timer::new_ticker(Duration::from_nanos(1), || {}).is_ok() {
+        warnf!("creating a ticker with a small interval should fail");
+        return 1;
+    }
+
+    0
+}
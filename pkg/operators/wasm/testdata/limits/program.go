@@ -0,0 +1,54 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import api "github.com/inspektor-gadget/inspektor-gadget/wasmapi/go"
+
+// Keep in sync with TestWasmLimits in wasm_test.go
+const valueHang = 1
+
+//go:wasmexport gadgetInit
+func gadgetInit() int32 {
+	ds, err := api.GetDataSource("input_ds")
+	if err != nil {
+		api.Warnf("failed to get datasource: %v", err)
+		return 1
+	}
+	valueF, err := ds.GetField("value")
+	if err != nil {
+		api.Warnf("failed to get field: %v", err)
+		return 1
+	}
+
+	err = ds.Subscribe(func(source api.DataSource, data api.Data) {
+		val, err := valueF.Uint32(data)
+		if err != nil {
+			panic("failed to get field")
+		}
+		if val == valueHang {
+			// Never returns, the host has to interrupt it
+			for {
+			}
+		}
+	}, 0)
+	if err != nil {
+		api.Warnf("failed to subscribe: %v", err)
+		return 1
+	}
+
+	return 0
+}
+
+func main() {}
@@ -0,0 +1,150 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	api "github.com/inspektor-gadget/inspektor-gadget/wasmapi/go"
+)
+
+const entries = 100
+
+func fillMap(m api.Map) error {
+	for k := uint32(0); k < entries; k++ {
+		if err := m.Put(k, uint64(k)*2); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func testNextKey(m api.Map) error {
+	var key any
+	var nextKey uint32
+	seen := map[uint32]bool{}
+	for {
+		err := m.NextKey(key, &nextKey)
+		if errors.Is(err, api.ErrKeyNotExist) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		seen[nextKey] = true
+		key = nextKey
+	}
+	if len(seen) != entries {
+		return fmt.Errorf("NextKey returned %d keys, expected %d", len(seen), entries)
+	}
+	return nil
+}
+
+func testBatch(m api.Map, del bool) error {
+	// Smaller than the number of entries to need several calls
+	keys := make([]uint32, 16)
+	values := make([]uint64, 16)
+	var cursor api.MapBatchCursor
+	seen := map[uint32]bool{}
+	for !cursor.Done() {
+		var n int
+		var err error
+		if del {
+			n, err = api.MapBatchLookupAndDelete(m, &cursor, keys, values)
+		} else {
+			n, err = api.MapBatchLookup(m, &cursor, keys, values)
+		}
+		if err != nil {
+			return err
+		}
+		for i := range n {
+			if values[i] != uint64(keys[i])*2 {
+				return fmt.Errorf("expected value %d for key %d, got %d", keys[i]*2, keys[i], values[i])
+			}
+			seen[keys[i]] = true
+		}
+	}
+	if len(seen) != entries {
+		return fmt.Errorf("batch returned %d entries, expected %d", len(seen), entries)
+	}
+	return nil
+}
+
+//go:wasmexport gadgetStart
+func gadgetStart() int32 {
+	for _, typ := range []api.MapType{api.Hash, api.Array} {
+		m, err := api.NewMap(api.MapSpec{
+			Name:       "map_iter",
+			Type:       typ,
+			KeySize:    4,
+			ValueSize:  8,
+			MaxEntries: entries,
+		})
+		if err != nil {
+			api.Errorf("creating map: %v", err)
+			return 1
+		}
+		defer m.Close()
+
+		if err := fillMap(m); err != nil {
+			api.Errorf("filling map: %v", err)
+			return 1
+		}
+		if err := testNextKey(m); err != nil {
+			api.Errorf("iterating over map: %v", err)
+			return 1
+		}
+		if err := testBatch(m, false); err != nil {
+			api.Errorf("batch lookup: %v", err)
+			return 1
+		}
+
+		// A cursor can be closed before reading all entries
+		var cursor api.MapBatchCursor
+		if _, err := api.MapBatchLookup(m, &cursor, make([]uint32, 16), make([]uint64, 16)); err != nil {
+			api.Errorf("batch lookup: %v", err)
+			return 1
+		}
+		if err := cursor.Close(); err != nil {
+			api.Errorf("closing cursor: %v", err)
+			return 1
+		}
+
+		// Buffers not matching the size of the entries are rejected
+		if _, err := api.MapBatchLookup(m, &api.MapBatchCursor{}, make([]uint64, 1), make([]uint64, 1)); err == nil {
+			api.Errorf("batch lookup with wrong key size succeeded")
+			return 1
+		}
+
+		if typ != api.Hash {
+			continue
+		}
+
+		if err := testBatch(m, true); err != nil {
+			api.Errorf("batch lookup and delete: %v", err)
+			return 1
+		}
+		var key uint32
+		if err := m.NextKey(nil, &key); !errors.Is(err, api.ErrKeyNotExist) {
+			api.Errorf("map not empty after batch lookup and delete: %v", err)
+			return 1
+		}
+	}
+
+	return 0
+}
+
+func main() {}
@@ -0,0 +1,82 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"time"
+
+	api "github.com/inspektor-gadget/inspektor-gadget/wasmapi/go"
+)
+
+// Keep in sync with TestWasmTimers in wasm_test.go
+const (
+	kindTicker  = 1
+	kindTimer   = 2
+	kindStopped = 3
+)
+
+//go:wasmexport gadgetInit
+func gadgetInit() int32 {
+	ds, err := api.NewDataSource("timer_ds", api.DataSourceTypeSingle)
+	if err != nil {
+		api.Warnf("failed to create datasource: %s", err)
+		return 1
+	}
+	kindF, err := ds.AddField("kind", api.Kind_Uint32)
+	if err != nil {
+		api.Warnf("failed to add field: %s", err)
+		return 1
+	}
+
+	emit := func(kind uint32) {
+		packet, err := ds.NewPacketSingle()
+		if err != nil {
+			api.Warnf("failed to create new packet: %s", err)
+			return
+		}
+		kindF.SetUint32(api.Data(packet), kind)
+		ds.EmitAndRelease(api.Packet(packet))
+	}
+
+	// Timers created here are armed when the gadget starts
+	if _, err := api.NewTicker(50*time.Millisecond, func() { emit(kindTicker) }); err != nil {
+		api.Warnf("failed to create ticker: %s", err)
+		return 1
+	}
+	if _, err := api.NewTimer(100*time.Millisecond, func() { emit(kindTimer) }); err != nil {
+		api.Warnf("failed to create timer: %s", err)
+		return 1
+	}
+	stopped, err := api.NewTimer(100*time.Millisecond, func() { emit(kindStopped) })
+	if err != nil {
+		api.Warnf("failed to create timer: %s", err)
+		return 1
+	}
+	if err := stopped.Stop(); err != nil {
+		api.Warnf("failed to stop timer: %s", err)
+		return 1
+	}
+
+	// Timers with too small intervals are rejected
+	if _, err := api.NewTicker(time.Nanosecond, func() {}); err == nil {
+		api.Warnf("creating a ticker with a small interval should fail")
+		return 1
+	}
+
+	return 0
+}
+
+// The main function is not used, but it's still required by the compiler
+func main() {}
@@ -0,0 +1,208 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wasm
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/tetratelabs/wazero"
+	wapi "github.com/tetratelabs/wazero/api"
+)
+
+const (
+	// Minimum interval of timers, to avoid the guest keeping the host busy
+	minTimerInterval = 10 * time.Millisecond
+	// Maximum number of timers a gadget can have at the same time
+	maxTimers = 128
+)
+
+type wasmTimer struct {
+	cbID     uint64
+	interval time.Duration
+	periodic bool
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+func (t *wasmTimer) cancel() {
+	t.stopOnce.Do(func() { close(t.stop) })
+}
+
+func (i *wasmOperatorInstance) addTimerFuncs(env wazero.HostModuleBuilder) {
+	exportFunction(env, "newTimer", i.newTimer,
+		[]wapi.ValueType{
+			wapi.ValueTypeI64, // Interval in nanoseconds
+			wapi.ValueTypeI32, // Periodic
+			wapi.ValueTypeI64, // Callback ID
+		},
+		[]wapi.ValueType{wapi.ValueTypeI32}, // Timer
+	)
+
+	exportFunction(env, "timerStop", i.timerStop,
+		[]wapi.ValueType{
+			wapi.ValueTypeI32, // Timer
+		},
+		[]wapi.ValueType{wapi.ValueTypeI32}, // Error
+	)
+}
+
+// newTimer creates a timer that calls timerCallback() of the guest after the given interval, and
+// then every interval if it's periodic. Timers only fire while the gadget is running: timers
+// created before the gadget is started are armed on start, all timers are stopped on stop.
+// Params:
+// - stack[0]: Interval in nanoseconds
+// - stack[1]: Periodic (0: one-shot, 1: periodic)
+// - stack[2]: Callback ID
+// Return value:
+// - Timer handle on success, 0 on error
+func (i *wasmOperatorInstance) newTimer(ctx context.Context, m wapi.Module, stack []uint64) {
+	interval := time.Duration(stack[0])
+	periodic := wapi.DecodeU32(stack[1]) != 0
+	cbID := stack[2]
+
+	if i.timerCallback == nil {
+		i.logger.Warnf("wasm module doesn't export timerCallback")
+		stack[0] = 0
+		return
+	}
+
+	if interval < minTimerInterval {
+		i.logger.Warnf("newTimer: interval %s is smaller than %s", interval, minTimerInterval)
+		stack[0] = 0
+		return
+	}
+
+	t := &wasmTimer{
+		cbID:     cbID,
+		interval: interval,
+		periodic: periodic,
+		stop:     make(chan struct{}),
+	}
+
+	i.timersLock.Lock()
+	defer i.timersLock.Unlock()
+
+	if len(i.timers) == maxTimers {
+		i.logger.Warnf("newTimer: too many timers")
+		stack[0] = 0
+		return
+	}
+
+	handle := i.addHandle(t)
+	if handle == 0 {
+		stack[0] = 0
+		return
+	}
+	i.timers[handle] = t
+
+	if i.timersRunning {
+		i.runTimer(handle, t)
+	}
+
+	stack[0] = wapi.EncodeU32(handle)
+}
+
+// timerStop stops the timer and releases its handle
+// Params:
+// - stack[0]: Timer handle
+// Return value:
+// - 0 on success, 1 on error
+func (i *wasmOperatorInstance) timerStop(ctx context.Context, m wapi.Module, stack []uint64) {
+	handle := wapi.DecodeU32(stack[0])
+
+	t, ok := getHandle[*wasmTimer](i, handle)
+	if !ok {
+		stack[0] = 1
+		return
+	}
+
+	t.cancel()
+
+	i.timersLock.Lock()
+	delete(i.timers, handle)
+	i.timersLock.Unlock()
+	i.delHandle(handle)
+
+	stack[0] = 0
+}
+
+// runTimer starts a goroutine calling the guest when the timer fires. timersLock must be held.
+func (i *wasmOperatorInstance) runTimer(handle uint32, t *wasmTimer) {
+	stats := i.getCallbackStats("timer", "")
+
+	i.timersWg.Add(1)
+	go func() {
+		defer i.timersWg.Done()
+
+		ticker := time.NewTicker(t.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-t.stop:
+				return
+			case <-ticker.C:
+			}
+
+			if i.guestFailed.Load() {
+				return
+			}
+
+			// Don't use a context that is cancelled when the gadget stops: it'd close the module
+			// if the guest is running the callback at that moment. Stop() waits for the
+			// callbacks to finish instead.
+			err := i.callGuestCallbackWithLock(context.Background(), stats, i.timerCallback, t.cbID)
+			if err != nil {
+				i.handleTrap(fmt.Errorf("calling timer callback: %w", err))
+			}
+
+			if !t.periodic {
+				i.timersLock.Lock()
+				delete(i.timers, handle)
+				i.timersLock.Unlock()
+				i.delHandle(handle)
+				return
+			}
+		}
+	}()
+}
+
+// startTimers arms the timers created before the gadget was started
+func (i *wasmOperatorInstance) startTimers() {
+	i.timersLock.Lock()
+	defer i.timersLock.Unlock()
+
+	i.timersRunning = true
+	for handle, t := range i.timers {
+		i.runTimer(handle, t)
+	}
+}
+
+// stopTimers stops all timers and waits for running callbacks to finish
+func (i *wasmOperatorInstance) stopTimers() {
+	i.timersLock.Lock()
+	i.timersRunning = false
+	for handle, t := range i.timers {
+		t.cancel()
+		delete(i.timers, handle)
+	}
+	i.timersLock.Unlock()
+
+	i.timersWg.Wait()
+}
@@ -15,6 +15,7 @@
 package wasm
 
 import (
+	"bytes"
 	"context"
 	_ "embed"
 	"errors"
@@ -22,6 +23,7 @@ import (
 	"io"
 	"os"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/cilium/ebpf"
@@ -55,6 +57,9 @@ const (
 
 	// cache path for the wasm compilation
 	cacheDir = "/var/run/ig/wasm-cache"
+
+	// Prefix of the name of instances running the module of an operator image
+	standaloneNamePrefix = "wasm-operator."
 )
 
 type wasmOperator struct {
@@ -89,27 +94,76 @@ func (w *wasmOperator) InstantiateImageOperator(
 	paramValues api.ParamValues,
 ) (
 	operators.ImageOperatorInstance, error,
+) {
+	var config *viper.Viper
+	if configVar, ok := gadgetCtx.GetVar("config"); ok {
+		config, _ = configVar.(*viper.Viper)
+	}
+
+	return w.instantiate(gadgetCtx, w.Name(), config, target, desc, paramValues)
+}
+
+// InstantiateStandaloneImageOperator instantiates the wasm module of an operator image. Its
+// limits and params are read from the metadata of the operator image instead of the gadget's.
+func (w *wasmOperator) InstantiateStandaloneImageOperator(
+	gadgetCtx operators.GadgetContext,
+	name string,
+	target oras.ReadOnlyTarget,
+	desc ocispec.Descriptor,
+	metadata []byte,
+	paramValues api.ParamValues,
+) (
+	operators.ImageOperatorInstance, error,
+) {
+	imageConfig := viper.New()
+	imageConfig.SetConfigType("yaml")
+	if err := imageConfig.ReadConfig(bytes.NewReader(metadata)); err != nil {
+		return nil, fmt.Errorf("unmarshalling metadata: %w", err)
+	}
+
+	return w.instantiate(gadgetCtx, standaloneNamePrefix+name, imageConfig, target, desc, paramValues)
+}
+
+func (w *wasmOperator) instantiate(
+	gadgetCtx operators.GadgetContext,
+	name string,
+	imageConfig *viper.Viper,
+	target oras.ReadOnlyTarget,
+	desc ocispec.Descriptor,
+	paramValues api.ParamValues,
+) (
+	operators.ImageOperatorInstance, error,
 ) {
 	instance := &wasmOperatorInstance{
+		name:        name,
 		gadgetCtx:   gadgetCtx,
 		handleMap:   map[uint32]any{},
 		logger:      gadgetCtx.Logger(),
 		paramValues: paramValues,
 		createdMap:  map[uint32]struct{}{},
+		timers:      map[uint32]*wasmTimer{},
+
+		callbackStats: map[string]*callbackStats{},
+
+		imageConfig: imageConfig,
 	}
 
 	if configVar, ok := gadgetCtx.GetVar("config"); ok {
 		instance.config, _ = configVar.(*viper.Viper)
 	}
 
+	if err := instance.initLimits(); err != nil {
+		return nil, fmt.Errorf("setting wasm limits: %w", err)
+	}
+
 	if err := instance.init(gadgetCtx, target, desc, w.cache); err != nil {
 		instance.Close(gadgetCtx)
 		return nil, fmt.Errorf("initializing wasm: %w", err)
 	}
 
-	if instance.config != nil {
+	if instance.imageConfig != nil {
 		extraParams := map[string]*api.Param{}
-		err := instance.config.UnmarshalKey("params.wasm", &extraParams)
+		err := instance.imageConfig.UnmarshalKey("params.wasm", &extraParams)
 		if err != nil {
 			return nil, fmt.Errorf("unmarshalling extra params: %w", err)
 		}
@@ -123,6 +177,9 @@ func (w *wasmOperator) InstantiateImageOperator(
 }
 
 type wasmOperatorInstance struct {
+	// "wasm" for the module of the gadget, "wasm-operator.<name>" for the ones of operator images
+	name string
+
 	ctx       context.Context
 	cancel    func()
 	rt        wazero.Runtime
@@ -131,17 +188,43 @@ type wasmOperatorInstance struct {
 
 	logger logger.Logger
 
-	// This mutex ensures dataSourceCallback() is never called in parallel, see:
+	// This mutex ensures the callbacks of the guest (dataSourceCallback(), timerCallback(),
+	// containerCallback()) are never called in parallel, see:
 	// https://github.com/tetratelabs/wazero/blob/610c202ec48f3a7c729f2bf11707330127ab3689/api/wasm.go#L378-L381
-	dataSourceCallbackLock sync.Mutex
-	dataSourceCallback     wapi.Function
+	callbackLock       sync.Mutex
+	dataSourceCallback wapi.Function
+	timerCallback      wapi.Function
+	containerCallback  wapi.Function
+
+	limits limits
+	// Set once the guest failed and mustn't be called anymore, see handleTrap()
+	guestFailed atomic.Bool
+
+	// key: "datasource:<name>", "timer" or "container"
+	callbackStats     map[string]*callbackStats
+	callbackStatsLock sync.Mutex
+
+	// key: timer handle
+	timers        map[uint32]*wasmTimer
+	timersRunning bool
+	timersLock    sync.Mutex
+	timersWg      sync.WaitGroup
+
+	// Callback IDs passed to containerSubscribe()
+	containerSubs     []uint64
+	containerSubsLock sync.Mutex
+	containerSubKey   string
 
 	// Golang objects are exposed to the wasm module by using a handleID
 	handleMap       map[uint32]any
 	lastHandleIndex uint32
 	handleLock      sync.RWMutex
 
+	// config of the gadget
 	config *viper.Viper
+	// metadata of the image the module comes from, where its params and limits are declared.
+	// It's the same as config unless the module comes from an operator image.
+	imageConfig *viper.Viper
 
 	extraParams api.Params
 	paramValues map[string]string
@@ -155,11 +238,11 @@ type wasmOperatorInstance struct {
 }
 
 func (i *wasmOperatorInstance) Name() string {
-	return "wasm"
+	return i.name
 }
 
 func (i *wasmOperatorInstance) ExtraParams(gadgetCtx operators.GadgetContext) api.Params {
-	return i.extraParams
+	return append(i.limitParams(), i.extraParams...)
 }
 
 func (i *wasmOperatorInstance) addHandle(obj any) uint32 {
@@ -233,7 +316,7 @@ func (i *wasmOperatorInstance) init(
 	ctx := gadgetCtx.Context()
 	rtConfig := wazero.NewRuntimeConfig().
 		WithCloseOnContextDone(true).
-		WithMemoryLimitPages(256). // 16MB (64KB per page)
+		WithMemoryLimitPages(i.limits.memoryPages).
 		WithCompilationCache(cache)
 	i.rt = wazero.NewRuntimeWithConfig(ctx, rtConfig)
 
@@ -250,6 +333,8 @@ func (i *wasmOperatorInstance) init(
 	i.addPerfFuncs(igModuleBuilder)
 	i.addKallsymsFuncs(igModuleBuilder)
 	i.addFilterFuncs(igModuleBuilder)
+	i.addTimerFuncs(igModuleBuilder)
+	i.addContainerFuncs(igModuleBuilder)
 
 	if _, err := igModuleBuilder.Instantiate(ctx); err != nil {
 		return fmt.Errorf("instantiating host module: %w", err)
@@ -304,6 +389,8 @@ func (i *wasmOperatorInstance) init(
 	}
 
 	i.dataSourceCallback = mod.ExportedFunction("dataSourceCallback")
+	i.timerCallback = mod.ExportedFunction("timerCallback")
+	i.containerCallback = mod.ExportedFunction("containerCallback")
 
 	if err := i.callGuestFunction(gadgetCtx.Context(), "gadgetInit"); err != nil {
 		return fmt.Errorf("initializing wasm guest: %w", err)
@@ -313,6 +400,10 @@ func (i *wasmOperatorInstance) init(
 }
 
 func (i *wasmOperatorInstance) callGuestFunction(ctx context.Context, name string) error {
+	// The guest isn't called anymore after a callback failed, see handleTrap()
+	if i.guestFailed.Load() {
+		return nil
+	}
 	fn := i.mod.ExportedFunction(name)
 	if fn == nil {
 		return nil
@@ -327,6 +418,33 @@ func (i *wasmOperatorInstance) callGuestFunction(ctx context.Context, name strin
 	return nil
 }
 
+// callGuestCallbackWithLock calls a callback of the guest, making sure no other callback is
+// running at the same time. The callback is interrupted if it exceeds the callback timeout, which
+// terminates the module.
+func (i *wasmOperatorInstance) callGuestCallbackWithLock(
+	ctx context.Context,
+	stats *callbackStats,
+	fn wapi.Function,
+	params ...uint64,
+) error {
+	i.callbackLock.Lock()
+	defer i.callbackLock.Unlock()
+
+	if i.limits.callbackTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, i.limits.callbackTimeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	_, err := fn.Call(ctx, params...)
+	stats.record(time.Since(start), err != nil)
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("callback exceeded the timeout of %s: %w", i.limits.callbackTimeout, err)
+	}
+	return err
+}
+
 func (i *wasmOperatorInstance) PreStart(gadgetCtx operators.GadgetContext) error {
 	// We're creating a new context here that gets cancelled when Stop() is called; it is important to know
 	// that gadgetInit uses the gadgetContext instead, which will be cancelled whenever the gadgetCtx is cancelled
@@ -344,11 +462,19 @@ func (i *wasmOperatorInstance) Start(gadgetCtx operators.GadgetContext) error {
 		i.mntNsIDMap, _ = mntnsVar.(*ebpf.Map)
 	}
 
-	return i.callGuestFunction(i.ctx, "gadgetStart")
+	if err := i.callGuestFunction(i.ctx, "gadgetStart"); err != nil {
+		return err
+	}
+
+	i.startTimers()
+	return i.startContainerSubscription()
 }
 
 func (i *wasmOperatorInstance) Stop(gadgetCtx operators.GadgetContext) error {
 	i.cancel()
+	i.stopContainerSubscription()
+	i.stopTimers()
+	i.logCallbackStats()
 	defer func() {
 		i.handleLock.Lock()
 		i.handleMap = nil
@@ -17,6 +17,8 @@ package wasm_test
 import (
 	"context"
 	"fmt"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -106,6 +108,7 @@ func testWasm(t *testing.T, path string) {
 	}{
 		{"map", false},
 		{"mapofmap", false},
+		{"mapiter", false},
 		{"badguest", false},
 		{"baderrptr", true},
 		{"syscall", false},
@@ -448,6 +451,134 @@ func testWasmDataEmit(t *testing.T, path string) {
 	require.Equal(t, counter, 1) // as only 1 of the two packets emitted by the `old_ds` will be passed on to the `new_ds`
 }
 
+func TestWasmTimers(t *testing.T) {
+	runTestForLanguages(t, testWasmTimers)
+}
+
+func testWasmTimers(t *testing.T, path string) {
+	utils.RequireRoot(t)
+
+	t.Parallel()
+
+	// Keep in sync with testdata/timer/program.go
+	const (
+		kindTicker  = 1
+		kindTimer   = 2
+		kindStopped = 3
+	)
+
+	var mu sync.Mutex
+	counters := map[uint32]int{}
+
+	const opPriority = 50000
+	myOperator := simple.New("myHandler",
+		simple.OnInit(func(gadgetCtx operators.GadgetContext) error {
+			ds, ok := gadgetCtx.GetDataSources()["timer_ds"]
+			require.True(t, ok, "datasource not found")
+
+			acc := ds.GetField("kind")
+			ds.Subscribe(func(source datasource.DataSource, data datasource.Data) error {
+				kind, err := acc.Uint32(data)
+				require.NoError(t, err)
+
+				mu.Lock()
+				counters[kind]++
+				mu.Unlock()
+				return nil
+			}, opPriority)
+			return nil
+		}),
+	)
+
+	gadgetCtx := createGadgetCtx(t, path, "timer", myOperator)
+
+	err := runGadget(t, gadgetCtx, nil)
+	require.NoError(t, err, "running gadget")
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.GreaterOrEqual(t, counters[kindTicker], 2)
+	require.Equal(t, 1, counters[kindTimer])
+	require.Equal(t, 0, counters[kindStopped])
+}
+
+func TestWasmLimits(t *testing.T) {
+	runTestForLanguages(t, testWasmLimits)
+}
+
+func testWasmLimits(t *testing.T, path string) {
+	utils.RequireRoot(t)
+
+	t.Parallel()
+
+	// Keep in sync with testdata/limits/program.go
+	const (
+		valueOk   = 0
+		valueHang = 1
+	)
+
+	tests := []struct {
+		onTrap   string
+		expected int
+	}{
+		// The event making the guest hang and all events after it are dropped, as the module
+		// is terminated
+		{"drop", 1},
+		// Events are passed through without calling the guest anymore
+		{"disable", 3},
+	}
+
+	for _, test := range tests {
+		t.Run(test.onTrap, func(t *testing.T) {
+			t.Parallel()
+
+			var counter atomic.Int32
+
+			const opPriority = 50000
+			myOperator := simple.New("myHandler",
+				simple.OnInit(func(gadgetCtx operators.GadgetContext) error {
+					ds, ok := gadgetCtx.GetDataSources()["input_ds"]
+					require.True(t, ok, "datasource not found")
+
+					ds.Subscribe(func(source datasource.DataSource, data datasource.Data) error {
+						counter.Add(1)
+						return nil
+					}, opPriority)
+					return nil
+				}),
+				simple.OnStart(func(gadgetCtx operators.GadgetContext) error {
+					ds := gadgetCtx.GetDataSources()["input_ds"]
+					acc := ds.GetField("value")
+
+					for _, val := range []uint32{valueOk, valueHang, valueOk} {
+						packet, err := ds.NewPacketSingle()
+						require.NoError(t, err, "creating packet")
+						require.NoError(t, acc.PutUint32(packet, val))
+						require.NoError(t, ds.EmitAndRelease(packet), "emitting data")
+					}
+					return nil
+				}),
+			)
+
+			gadgetCtx := createGadgetCtx(t, path, "limits", myOperator)
+
+			ds, err := gadgetCtx.RegisterDataSource(datasource.TypeSingle, "input_ds")
+			require.NoError(t, err, "registering datasource")
+			_, err = ds.AddField("value", api.Kind_Uint32)
+			require.NoError(t, err)
+
+			params := map[string]string{
+				"operator.oci.wasm.callback-timeout": "100ms",
+				"operator.oci.wasm.on-trap":          test.onTrap,
+			}
+			err = runGadget(t, gadgetCtx, params)
+			require.NoError(t, err, "running gadget")
+
+			require.Equal(t, test.expected, int(counter.Load()))
+		})
+	}
+}
+
 func TestWasmParams(t *testing.T) {
 	runTestForLanguages(t, testWasmParams)
 }
@@ -27,6 +27,7 @@ import (
 	"fmt"
 	"io"
 	"net"
+	"net/netip"
 	"strconv"
 	"strings"
 	"time"
@@ -295,13 +296,13 @@ func (p *Params) CopyToMap(target map[string]string, prefix string) {
 }
 
 // CopyToMapExt works like CopyToMap but additionally can return string slices
-// given the TypeHint is set to TypeStringSlice
+// given the TypeHint is set to TypeStringSlice or another list type
 func (p *Params) CopyToMapExt(target map[string]any, prefix string) {
 	for _, param := range *p {
 		switch param.TypeHint {
 		case TypeBytes:
 			target[prefix+param.Key] = compressAndB64Encode(param.String())
-		case TypeStringSlice:
+		case TypeStringSlice, TypeCIDRList, TypePortRangeList:
 			target[prefix+param.Key] = SplitStringSlice(param.String())
 		default:
 			target[prefix+param.Key] = param.String()
@@ -422,6 +423,10 @@ func (p *Param) AsAny() any {
 		return p.AsDuration()
 	case TypeIP:
 		return p.AsIP()
+	case TypeCIDRList:
+		return p.AsCIDRList()
+	case TypePortRangeList:
+		return p.AsPortRangeList()
 	default:
 		return p.value
 	}
@@ -549,6 +554,36 @@ func (p *Param) AsIP() net.IP {
 	return net.ParseIP(p.value)
 }
 
+func (p *Param) AsCIDRList() []netip.Prefix {
+	strs := p.AsStringSlice()
+	out := make([]netip.Prefix, 0, len(strs))
+
+	for _, entry := range strs {
+		prefix, err := ParseCIDR(entry)
+		if err != nil {
+			continue
+		}
+		out = append(out, prefix)
+	}
+
+	return out
+}
+
+func (p *Param) AsPortRangeList() []PortRange {
+	strs := p.AsStringSlice()
+	out := make([]PortRange, 0, len(strs))
+
+	for _, entry := range strs {
+		portRange, err := ParsePortRange(entry)
+		if err != nil {
+			continue
+		}
+		out = append(out, portRange)
+	}
+
+	return out
+}
+
 // SplitStringSlice splits a string into multiple strings that were
 // delimited by comma characters.
 func SplitStringSlice(s string) []string {
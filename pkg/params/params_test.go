@@ -17,6 +17,7 @@ package params
 import (
 	"errors"
 	"net"
+	"net/netip"
 	"testing"
 	"time"
 
@@ -227,6 +228,24 @@ func TestParamAs(t *testing.T) {
 			expected: net.IP{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1},
 			getter:   func(p *Param) any { return p.AsIP() },
 		},
+		{
+			name:     "CIDRList",
+			value:    "10.1.2.3/8,192.168.1.1,fd00::/8",
+			typeHint: TypeCIDRList,
+			expected: []netip.Prefix{
+				netip.MustParsePrefix("10.0.0.0/8"),
+				netip.MustParsePrefix("192.168.1.1/32"),
+				netip.MustParsePrefix("fd00::/8"),
+			},
+			getter: func(p *Param) any { return p.AsCIDRList() },
+		},
+		{
+			name:     "PortRangeList",
+			value:    "53,8000-8080",
+			typeHint: TypePortRangeList,
+			expected: []PortRange{{Start: 53, End: 53}, {Start: 8000, End: 8080}},
+			getter:   func(p *Param) any { return p.AsPortRangeList() },
+		},
 	}
 
 	for _, test := range tests {
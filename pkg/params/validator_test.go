@@ -539,3 +539,84 @@ func TestValidateIP(t *testing.T) {
 		ValidateIP,
 	)
 }
+
+func TestValidateCIDR(t *testing.T) {
+	testValidate(t,
+		[]validateTest{
+			{
+				name:          "IPv4_CIDR_no_error",
+				value:         "10.0.0.0/8",
+				expectedError: false,
+			},
+			{
+				name:          "IPv6_CIDR_no_error",
+				value:         "fd00::/8",
+				expectedError: false,
+			},
+			{
+				name:          "IPv4_no_error",
+				value:         "192.168.1.1",
+				expectedError: false,
+			},
+			{
+				name:          "bad_prefix_length",
+				value:         "10.0.0.0/33",
+				expectedError: true,
+			},
+			{
+				name:          "bad_input",
+				value:         "foo/8",
+				expectedError: true,
+			},
+			{
+				name:          "empty",
+				value:         "",
+				expectedError: true,
+			},
+		},
+		ValidateCIDR,
+	)
+}
+
+func TestValidatePortRange(t *testing.T) {
+	testValidate(t,
+		[]validateTest{
+			{
+				name:          "port_no_error",
+				value:         "53",
+				expectedError: false,
+			},
+			{
+				name:          "range_no_error",
+				value:         "8000-8080",
+				expectedError: false,
+			},
+			{
+				name:          "single_port_range_no_error",
+				value:         "80-80",
+				expectedError: false,
+			},
+			{
+				name:          "out_of_range",
+				value:         "65536",
+				expectedError: true,
+			},
+			{
+				name:          "reversed_range",
+				value:         "8080-8000",
+				expectedError: true,
+			},
+			{
+				name:          "open_range",
+				value:         "8000-",
+				expectedError: true,
+			},
+			{
+				name:          "negative",
+				value:         "-1",
+				expectedError: true,
+			},
+		},
+		ValidatePortRange,
+	)
+}
@@ -17,6 +17,7 @@ package params
 import (
 	"fmt"
 	"net"
+	"net/netip"
 	"strconv"
 	"strings"
 	"time"
@@ -44,6 +45,13 @@ const (
 	TypeDuration    TypeHint = "duration"
 	TypeIP          TypeHint = "ip"
 	TypeStringSlice TypeHint = "[]string"
+
+	// TypeCIDRList is a comma separated list of IP addresses or CIDRs, e.g.
+	// 10.0.0.0/8,192.168.1.1,fd00::/8
+	TypeCIDRList TypeHint = "[]cidr"
+	// TypePortRangeList is a comma separated list of ports or inclusive port
+	// ranges, e.g. 53,8000-8080
+	TypePortRangeList TypeHint = "[]portrange"
 )
 
 var typeHintValidators = map[TypeHint]ParamValidator{
@@ -62,6 +70,9 @@ var typeHintValidators = map[TypeHint]ParamValidator{
 	TypeFloat64:  ValidateFloat(64),
 	TypeDuration: ValidateDuration,
 	TypeIP:       ValidateIP,
+
+	TypeCIDRList:      ValidateSlice(ValidateCIDR),
+	TypePortRangeList: ValidateSlice(ValidatePortRange),
 }
 
 type ValueHint string
@@ -158,3 +169,58 @@ func ValidateIP(value string) error {
 	}
 	return nil
 }
+
+// ParseCIDR parses a CIDR. A single IP address is handled as a CIDR matching
+// only that address.
+func ParseCIDR(value string) (netip.Prefix, error) {
+	if strings.Contains(value, "/") {
+		prefix, err := netip.ParsePrefix(value)
+		if err != nil {
+			return netip.Prefix{}, fmt.Errorf("%q is not a valid CIDR", value)
+		}
+		return prefix.Masked(), nil
+	}
+
+	addr, err := netip.ParseAddr(value)
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("%q is not a valid IP address or CIDR", value)
+	}
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
+func ValidateCIDR(value string) error {
+	_, err := ParseCIDR(value)
+	return err
+}
+
+// PortRange is an inclusive range of L4 ports.
+type PortRange struct {
+	Start uint16
+	End   uint16
+}
+
+// ParsePortRange parses a single port like "53" or a range like "8000-8080".
+func ParsePortRange(value string) (PortRange, error) {
+	startStr, endStr, isRange := strings.Cut(value, "-")
+	if !isRange {
+		endStr = startStr
+	}
+
+	start, err := strconv.ParseUint(startStr, 10, 16)
+	if err != nil {
+		return PortRange{}, fmt.Errorf("invalid port %q: expected numeric value between 0 and 65535", startStr)
+	}
+	end, err := strconv.ParseUint(endStr, 10, 16)
+	if err != nil {
+		return PortRange{}, fmt.Errorf("invalid port %q: expected numeric value between 0 and 65535", endStr)
+	}
+	if start > end {
+		return PortRange{}, fmt.Errorf("invalid port range %q: start is greater than end", value)
+	}
+	return PortRange{Start: uint16(start), End: uint16(end)}, nil
+}
+
+func ValidatePortRange(value string) error {
+	_, err := ParsePortRange(value)
+	return err
+}
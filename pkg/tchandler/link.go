@@ -0,0 +1,203 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !withoutebpf
+
+package tchandler
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/cilium/ebpf"
+	"github.com/cilium/ebpf/link"
+	"github.com/vishvananda/netlink"
+	"golang.org/x/sys/unix"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadgets"
+)
+
+// pinRoot is the directory where the links of all the handlers are pinned. Each gadget instance
+// uses its own <instance-id> subdirectory, and each handler a <pidns>-<pid>-<n> subdirectory
+// within it, named after the process that created it.
+var pinRoot = filepath.Join(gadgets.PinPath, "tchandler")
+
+var handlerID atomic.Uint64
+
+// ParseOrder parses the position a program should be attached at in the TCX or netkit chain
+// of an interface. Supported values are "first", "last", "before:<prog_id>" and
+// "after:<prog_id>". An empty string is the same as "first".
+func ParseOrder(order string) (link.Anchor, error) {
+	switch order {
+	case "", "first":
+		return link.Head(), nil
+	case "last":
+		return link.Tail(), nil
+	}
+
+	position, idStr, ok := strings.Cut(order, ":")
+	if !ok {
+		return nil, fmt.Errorf("invalid order %q", order)
+	}
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("parsing program id %q: %w", idStr, err)
+	}
+	switch position {
+	case "before":
+		return link.BeforeProgramByID(ebpf.ProgramID(id)), nil
+	case "after":
+		return link.AfterProgramByID(ebpf.ProgramID(id)), nil
+	}
+	return nil, fmt.Errorf("invalid order %q", order)
+}
+
+// isNetkit returns whether the given interface is a netkit device.
+func isNetkit(iface *net.Interface) bool {
+	l, err := netlink.LinkByIndex(iface.Index)
+	if err != nil {
+		return false
+	}
+	return l.Type() == "netkit"
+}
+
+// attachLink attaches prog to the given interface using a netkit link for netkit devices and a
+// TCX link otherwise. It returns an error wrapping ebpf.ErrNotSupported if the kernel doesn't
+// support them.
+func attachLink(prog *ebpf.Program, iface *net.Interface, dir AttachmentDirection, anchor link.Anchor) (link.Link, error) {
+	if isNetkit(iface) {
+		// Host side netkit devices are the primary ones: packets received by the host side are
+		// transmitted by the peer (in the container) and vice versa.
+		var attachType ebpf.AttachType
+		switch dir {
+		case AttachmentDirectionIngress:
+			attachType = ebpf.AttachNetkitPeer
+		case AttachmentDirectionEgress:
+			attachType = ebpf.AttachNetkitPrimary
+		default:
+			return nil, fmt.Errorf("invalid filter direction")
+		}
+		return link.AttachNetkit(link.NetkitOptions{
+			Interface: iface.Index,
+			Program:   prog,
+			Attach:    attachType,
+			Anchor:    anchor,
+		})
+	}
+
+	var attachType ebpf.AttachType
+	switch dir {
+	case AttachmentDirectionIngress:
+		attachType = ebpf.AttachTCXIngress
+	case AttachmentDirectionEgress:
+		attachType = ebpf.AttachTCXEgress
+	default:
+		return nil, fmt.Errorf("invalid filter direction")
+	}
+	return link.AttachTCX(link.TCXOptions{
+		Interface: iface.Index,
+		Program:   prog,
+		Attach:    attachType,
+		Anchor:    anchor,
+	})
+}
+
+func processPinDirPrefix() (string, error) {
+	pidns, err := os.Readlink("/proc/self/ns/pid")
+	if err != nil {
+		return "", err
+	}
+	// pid:[4026531836]
+	pidns = strings.TrimSuffix(strings.TrimPrefix(pidns, "pid:["), "]")
+	return pidns + "-" + strconv.Itoa(os.Getpid()) + "-", nil
+}
+
+// newPinDir creates the directory where the links of a handler of the given gadget instance are
+// pinned. It also removes the stale links, detaching them from the interfaces, see
+// removeStalePinDirs. An empty string is returned if links can't be pinned, e.g. because bpffs
+// isn't mounted.
+func newPinDir(instanceID string) string {
+	if instanceID == "" || instanceID != filepath.Base(instanceID) || strings.HasPrefix(instanceID, ".") {
+		return ""
+	}
+	prefix, err := processPinDirPrefix()
+	if err != nil {
+		return ""
+	}
+
+	removeStalePinDirs(pinRoot, instanceID, prefix)
+
+	dir := filepath.Join(pinRoot, instanceID, prefix+strconv.FormatUint(handlerID.Add(1), 10))
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return ""
+	}
+	return dir
+}
+
+// removeStalePinDirs removes the handler directories below root that were left behind:
+//   - the ones of instanceID created by other processes, i.e. by a previous run of the same gadget
+//     instance, e.g. before the daemon was restarted
+//   - the ones of other gadget instances created by processes that are gone. Only processes in the
+//     same pid namespace as the current one (prefix) are considered.
+//
+// Instance directories left empty are removed too.
+func removeStalePinDirs(root, instanceID, prefix string) {
+	pidns, _, _ := strings.Cut(prefix, "-")
+
+	instances, err := os.ReadDir(root)
+	if err != nil {
+		return
+	}
+	for _, instance := range instances {
+		instanceDir := filepath.Join(root, instance.Name())
+		handlers, err := os.ReadDir(instanceDir)
+		if err != nil {
+			continue
+		}
+		for _, handler := range handlers {
+			if strings.HasPrefix(handler.Name(), prefix) {
+				continue
+			}
+			if instance.Name() != instanceID && !processGone(pidns, handler.Name()) {
+				continue
+			}
+			os.RemoveAll(filepath.Join(instanceDir, handler.Name()))
+		}
+		if instance.Name() != instanceID {
+			// Only succeeds if it's empty
+			os.Remove(instanceDir)
+		}
+	}
+}
+
+// processGone reports whether the process that created the handler directory name is known to
+// be gone
+func processGone(pidns, name string) bool {
+	parts := strings.Split(name, "-")
+	if len(parts) != 3 || parts[0] != pidns {
+		return false
+	}
+	pid, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return false
+	}
+	err = unix.Kill(pid, 0)
+	return err != nil && !errors.Is(err, unix.EPERM)
+}
@@ -0,0 +1,129 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !withoutebpf
+
+package tchandler
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/cilium/ebpf/link"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseOrder(t *testing.T) {
+	t.Parallel()
+
+	type testDefinition struct {
+		order       string
+		expected    link.Anchor
+		expectedErr bool
+	}
+
+	tests := map[string]testDefinition{
+		"default": {
+			order:    "",
+			expected: link.Head(),
+		},
+		"first": {
+			order:    "first",
+			expected: link.Head(),
+		},
+		"last": {
+			order:    "last",
+			expected: link.Tail(),
+		},
+		"before": {
+			order:    "before:42",
+			expected: link.BeforeProgramByID(42),
+		},
+		"after": {
+			order:    "after:42",
+			expected: link.AfterProgramByID(42),
+		},
+		"invalid_position": {
+			order:       "replace:42",
+			expectedErr: true,
+		},
+		"invalid_id": {
+			order:       "before:foo",
+			expectedErr: true,
+		},
+		"invalid": {
+			order:       "middle",
+			expectedErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			anchor, err := ParseOrder(test.order)
+			if test.expectedErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.expected, anchor)
+		})
+	}
+}
+
+func TestRemoveStalePinDirs(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	prefix := "1234-" + strconv.Itoa(os.Getpid()) + "-"
+	// pid 1 is always alive, pid 0x7fffffff is never used
+	dirs := map[string]bool{
+		// current instance: only the handlers of the current process are kept
+		"inst/" + prefix + "1":   true,
+		"inst/1234-1-1":          false,
+		"inst/5678-2147483647-1": false,
+		// other instances: the handlers of processes that are gone are removed
+		"other/1234-1-1":            true,
+		"other/1234-2147483647-1":   false,
+		"gone/1234-2147483647-1":    false,
+		"otherns/5678-2147483647-1": true,
+		"otherns/invalid":           true,
+	}
+	for dir := range dirs {
+		require.NoError(t, os.MkdirAll(filepath.Join(root, dir, "eth0_0"), 0o700))
+	}
+
+	removeStalePinDirs(root, "inst", prefix)
+
+	for dir, kept := range dirs {
+		if kept {
+			require.DirExists(t, filepath.Join(root, dir))
+		} else {
+			require.NoDirExists(t, filepath.Join(root, dir))
+		}
+	}
+	require.DirExists(t, filepath.Join(root, "inst"))
+	require.NoDirExists(t, filepath.Join(root, "gone"))
+}
+
+func TestNewPinDirInvalidInstanceID(t *testing.T) {
+	t.Parallel()
+
+	for _, id := range []string{"", ".", "..", "../foo", "foo/bar"} {
+		require.Empty(t, newPinDir(id), id)
+	}
+}
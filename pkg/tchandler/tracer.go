@@ -23,9 +23,12 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"os"
+	"path/filepath"
 	"sync"
 
 	"github.com/cilium/ebpf"
+	"github.com/cilium/ebpf/link"
 	"github.com/florianl/go-tc"
 	"golang.org/x/sys/unix"
 
@@ -48,8 +51,10 @@ type attachment struct {
 	// attached to.
 	dispatcher dispatcherObjects
 	// filter is the tc ebpf filter we attach to the network interface. This filter will execute
-	// the dispatcher above.
+	// the dispatcher above when TCX isn't supported.
 	filter *tc.Object
+	// link is the TCX or netkit link executing the dispatcher above.
+	link link.Link
 
 	// users keeps track of the users' pid that have called Attach(). This can happen for when
 	// there are several containers in a pod (sharing the netns, and hence the networking
@@ -61,6 +66,10 @@ func (t *Handler) closeAttachment(a *attachment) {
 	if a.filter != nil {
 		t.tcnl.Filter().Delete(a.filter)
 	}
+	if a.link != nil {
+		a.link.Unpin()
+		a.link.Close()
+	}
 	a.dispatcher.Close()
 }
 
@@ -80,12 +89,42 @@ type Handler struct {
 
 	direction AttachmentDirection
 
+	// anchor is the position in the TCX / netkit chain the dispatcher is attached at
+	anchor link.Anchor
+
+	// instanceID is the id of the gadget instance the handler belongs to
+	instanceID string
+
+	// pinDir is the directory where the links of this handler are pinned. Empty if links
+	// aren't pinned.
+	pinDir string
+
 	// mu protects attachments from concurrent access
 	// AttachContainer and DetachContainer can be called in parallel
 	mu sync.Mutex
 }
 
-func NewHandler(direction AttachmentDirection) (*Handler, error) {
+type Option func(*Handler)
+
+// WithAnchor sets the position in the TCX / netkit chain of the interfaces the programs are
+// attached at. By default programs are attached in front of the chain. It's ignored when
+// legacy tc filters are used.
+func WithAnchor(anchor link.Anchor) Option {
+	return func(t *Handler) {
+		t.anchor = anchor
+	}
+}
+
+// WithInstanceID sets the id of the gadget instance the handler belongs to. The TCX and netkit
+// links of the handler are pinned below a directory named after it, so the ones left behind by a
+// previous run of the instance can be removed. Links aren't pinned if it's not set.
+func WithInstanceID(id string) Option {
+	return func(t *Handler) {
+		t.instanceID = id
+	}
+}
+
+func NewHandler(direction AttachmentDirection, opts ...Option) (*Handler, error) {
 	var err error
 	var tcnl *tc.Tc
 
@@ -107,6 +146,13 @@ func NewHandler(direction AttachmentDirection) (*Handler, error) {
 		attachments: make(map[string]*attachment),
 		tcnl:        tcnl,
 		direction:   direction,
+		anchor:      link.Head(),
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	if t.instanceID != "" {
+		t.pinDir = newPinDir(t.instanceID)
 	}
 	defer func() {
 		if err != nil {
@@ -162,12 +208,6 @@ func (t *Handler) newAttachment(pid uint32, iface *net.Interface, netns uint64,
 		return nil, err
 	}
 
-	// We create the clsact qdisc and leak it. We can't remove it because we'll break any other
-	// application (including other ig instances) that are using it.
-	if qdisc, err = createClsActQdisc(t.tcnl, iface); err != nil && !errors.Is(err, unix.EEXIST) {
-		return nil, fmt.Errorf("creating clsact qdisc: %w", err)
-	}
-
 	optsIngress := ebpf.CollectionOptions{
 		MapReplacements: map[string]*ebpf.Map{
 			tailCallMapName: t.dispatcherMap,
@@ -177,6 +217,27 @@ func (t *Handler) newAttachment(pid uint32, iface *net.Interface, netns uint64,
 		return nil, fmt.Errorf("loading ebpf program: %w", err)
 	}
 
+	// Prefer TCX (or netkit) links: they play well with other applications (like CNIs) using
+	// the same interfaces and they are removed automatically when the link is released.
+	a.link, err = attachLink(a.dispatcher.IgNetDisp, iface, direction, t.anchor)
+	if err == nil {
+		if t.pinDir != "" {
+			// Pinning is best effort, the link is still released when the handler is closed.
+			a.link.Pin(filepath.Join(t.pinDir, fmt.Sprintf("%s_%d", iface.Name, direction)))
+		}
+		return a, nil
+	}
+	if !errors.Is(err, ebpf.ErrNotSupported) {
+		return nil, fmt.Errorf("attaching ebpf program to interface %s: %w", iface.Name, err)
+	}
+
+	// Fall back to legacy tc filters on kernels without TCX support (< 6.6).
+	// We create the clsact qdisc and leak it. We can't remove it because we'll break any other
+	// application (including other ig instances) that are using it.
+	if qdisc, err = createClsActQdisc(t.tcnl, iface); err != nil && !errors.Is(err, unix.EEXIST) {
+		return nil, fmt.Errorf("creating clsact qdisc: %w", err)
+	}
+
 	a.filter, err = addTCFilter(t.tcnl, a.dispatcher.IgNetDisp, iface, direction)
 	if err != nil {
 		return nil, fmt.Errorf("attaching ebpf program to interface %s: %w", iface.Name, err)
@@ -302,4 +363,9 @@ func (t *Handler) Close() {
 	if t.tcnl != nil {
 		t.tcnl.Close()
 	}
+	if t.pinDir != "" {
+		os.RemoveAll(t.pinDir)
+		// Remove the directory of the instance too if this was its last handler
+		os.Remove(filepath.Dir(t.pinDir))
+	}
 }
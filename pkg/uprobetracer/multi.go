@@ -0,0 +1,79 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package uprobetracer
+
+import (
+	"debug/elf"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/utils/safeelf"
+)
+
+// matchElfFunctions returns the names of the functions defined in the ELF
+// file that match the given glob pattern, as understood by path.Match
+func matchElfFunctions(filePath string, pattern string) ([]string, error) {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	elfReader, err := safeelf.NewFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("parsing ELF file: %w", err)
+	}
+	defer elfReader.Close()
+
+	var functions []string
+	seen := make(map[string]struct{})
+	found := false
+	for _, symbolsFn := range []func() ([]elf.Symbol, error){elfReader.Symbols, elfReader.DynamicSymbols} {
+		symbols, err := symbolsFn()
+		if errors.Is(err, elf.ErrNoSymbols) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading symbols: %w", err)
+		}
+		found = true
+		for _, sym := range symbols {
+			if elf.ST_TYPE(sym.Info) != elf.STT_FUNC || sym.Section == elf.SHN_UNDEF || sym.Value == 0 {
+				continue
+			}
+			if _, ok := seen[sym.Name]; ok {
+				continue
+			}
+			if ok, _ := path.Match(pattern, sym.Name); !ok {
+				continue
+			}
+			seen[sym.Name] = struct{}{}
+			functions = append(functions, sym.Name)
+		}
+	}
+	if !found {
+		return nil, elf.ErrNoSymbols
+	}
+	if len(functions) == 0 {
+		return nil, fmt.Errorf("no function matches %q", pattern)
+	}
+	return functions, nil
+}
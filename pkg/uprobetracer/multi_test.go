@@ -0,0 +1,37 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package uprobetracer
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchElfFunctions(t *testing.T) {
+	t.Parallel()
+
+	// The test binary itself is an ELF file with symbols
+	functions, err := matchElfFunctions(os.Args[0], "runtime.mai?")
+	require.NoError(t, err)
+	require.Contains(t, functions, "runtime.main")
+
+	_, err = matchElfFunctions(os.Args[0], "no_such_function_*")
+	require.Error(t, err)
+
+	_, err = matchElfFunctions(os.Args[0], "[")
+	require.Error(t, err)
+}
@@ -56,6 +56,10 @@ const (
 	ProgUprobe ProgType = iota
 	ProgUretprobe
 	ProgUSDT
+	// ProgUprobeMulti and ProgUretprobeMulti attach to all the functions
+	// matching a glob pattern using a single uprobe_multi link
+	ProgUprobeMulti
+	ProgUretprobeMulti
 )
 
 // inodeKeeper holds a file object, with the counter representing its
@@ -122,7 +126,9 @@ func NewTracer[Event any](logger logger.Logger) (*Tracer[Event], error) {
 
 // AttachProg loads the ebpf program, and try attaching if there are pending containers
 func (t *Tracer[Event]) AttachProg(progName string, progType ProgType, attachTo string, prog *ebpf.Program) error {
-	if progType != ProgUprobe && progType != ProgUretprobe && progType != ProgUSDT {
+	switch progType {
+	case ProgUprobe, ProgUretprobe, ProgUSDT, ProgUprobeMulti, ProgUretprobeMulti:
+	default:
 		return fmt.Errorf("unsupported uprobe prog type: %q", progType)
 	}
 
@@ -192,6 +198,15 @@ func (t *Tracer[Event]) attachUprobe(file *os.File) (link.Link, error) {
 		return ex.Uprobe(t.attachSymbol, t.prog, nil)
 	case ProgUretprobe:
 		return ex.Uretprobe(t.attachSymbol, t.prog, nil)
+	case ProgUprobeMulti, ProgUretprobeMulti:
+		symbols, err := matchElfFunctions(attachPath, t.attachSymbol)
+		if err != nil {
+			return nil, fmt.Errorf("resolving symbols: %w", err)
+		}
+		if t.progType == ProgUprobeMulti {
+			return ex.UprobeMulti(symbols, t.prog, nil)
+		}
+		return ex.UretprobeMulti(symbols, t.prog, nil)
 	case ProgUSDT:
 		attachInfo, err := getUsdtInfo(attachPath, t.attachSymbol)
 		if err != nil {
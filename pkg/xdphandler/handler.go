@@ -0,0 +1,233 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package xdphandler handles how XDP programs are attached to containers and network interfaces.
+// The behavior is very similar to the tc handler implemented in pkg/tchandler.
+// The main difference is that XDP programs only run on ingress: attached to the host side of the
+// veth pair of a container, they see the packets sent by the container, before they reach the
+// networking stack of the host.
+package xdphandler
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+
+	"github.com/cilium/ebpf"
+	"github.com/cilium/ebpf/link"
+
+	containercollection "github.com/inspektor-gadget/inspektor-gadget/pkg/container-collection"
+	containerutils "github.com/inspektor-gadget/inspektor-gadget/pkg/container-utils"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/utils/nsenter"
+)
+
+// Modes supported by ParseMode
+const (
+	ModeAuto    = "auto"
+	ModeGeneric = "generic"
+	ModeNative  = "native"
+)
+
+// ParseMode returns the attach flags of the given XDP mode. "auto" (or an empty string) lets
+// the kernel use native mode if the driver supports it.
+func ParseMode(mode string) (link.XDPAttachFlags, error) {
+	switch mode {
+	case "", ModeAuto:
+		return 0, nil
+	case ModeGeneric:
+		return link.XDPGenericMode, nil
+	case ModeNative:
+		return link.XDPDriverMode, nil
+	}
+	return 0, fmt.Errorf("invalid XDP mode %q", mode)
+}
+
+type attachment struct {
+	iface *net.Interface
+	// link is nil until the program is attached
+	link link.Link
+
+	// users keeps track of the users' pid that have called Attach(). This can happen for when
+	// there are several containers in a pod (sharing the netns, and hence the networking
+	// interface). In this case we want to attach the program once.
+	users map[uint32]struct{}
+}
+
+type Handler struct {
+	prog  *ebpf.Program
+	flags link.XDPAttachFlags
+
+	// key: network interface name on the host side
+	// value: attachment
+	attachments map[string]*attachment
+
+	// mu protects attachments from concurrent access
+	// AttachContainer and DetachContainer can be called in parallel
+	mu sync.Mutex
+}
+
+func NewHandler() *Handler {
+	return &Handler{
+		attachments: make(map[string]*attachment),
+	}
+}
+
+func (t *Handler) attach(a *attachment) error {
+	// We need to perform this operation from the host network namespace, otherwise we won't
+	// be able to find the network interface.
+	return nsenter.NetnsEnter(1, func() error {
+		l, err := link.AttachXDP(link.XDPOptions{
+			Program:   t.prog,
+			Interface: a.iface.Index,
+			Flags:     t.flags,
+		})
+		if err != nil {
+			return fmt.Errorf("attaching XDP program to interface %s: %w", a.iface.Name, err)
+		}
+		a.link = l
+		return nil
+	})
+}
+
+func closeAttachment(a *attachment) {
+	if a.link != nil {
+		a.link.Close()
+	}
+}
+
+// AttachProg sets the program to attach and attaches it to the interfaces of the containers
+// added before.
+func (t *Handler) AttachProg(prog *ebpf.Program, flags link.XDPAttachFlags) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.prog != nil {
+		return errors.New("program already attached")
+	}
+	t.prog = prog
+	t.flags = flags
+
+	var errs []error
+	for _, a := range t.attachments {
+		if err := t.attach(a); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (t *Handler) addUser(pid uint32, iface *net.Interface) error {
+	if a, ok := t.attachments[iface.Name]; ok {
+		a.users[pid] = struct{}{}
+		return nil
+	}
+
+	a := &attachment{
+		iface: iface,
+		users: map[uint32]struct{}{pid: {}},
+	}
+	if t.prog != nil {
+		if err := t.attach(a); err != nil {
+			return err
+		}
+	}
+	t.attachments[iface.Name] = a
+	return nil
+}
+
+func (t *Handler) AttachContainer(container *containercollection.Container) error {
+	// It's not clear what to do with hostNetwork containers. For now we just ignore them.
+	if container.HostNetwork {
+		return nil
+	}
+
+	pid := container.ContainerPid()
+
+	ifaces, err := containerutils.GetIfacePeers(int(pid))
+	if err != nil {
+		return fmt.Errorf("getting network interfaces on the host side for pid %d: %w", pid, err)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, iface := range ifaces {
+		if err := t.addUser(pid, iface); err != nil {
+			return fmt.Errorf("creating XDP attachment for container %s: %w",
+				container.Runtime.ContainerName, err)
+		}
+	}
+	return nil
+}
+
+func (t *Handler) DetachContainer(container *containercollection.Container) error {
+	// It's not clear what to do with hostNetwork containers. For now we just ignore them.
+	if container.HostNetwork {
+		return nil
+	}
+
+	pid := container.ContainerPid()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	found := false
+	for ifacename, a := range t.attachments {
+		if _, ok := a.users[pid]; !ok {
+			continue
+		}
+		found = true
+		delete(a.users, pid)
+		if len(a.users) == 0 {
+			closeAttachment(a)
+			delete(t.attachments, ifacename)
+		}
+	}
+	if !found {
+		return fmt.Errorf("pid %d is not attached", pid)
+	}
+	return nil
+}
+
+// AttachIface attaches the program to the given interface on the host. See AttachContainer() if
+// you want to attach to a container.
+func (t *Handler) AttachIface(iface *net.Interface) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.addUser(1, iface)
+}
+
+func (t *Handler) DetachIface(iface *net.Interface) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if a, ok := t.attachments[iface.Name]; ok {
+		closeAttachment(a)
+		delete(t.attachments, iface.Name)
+		return nil
+	}
+	return fmt.Errorf("interface %s is not attached", iface.Name)
+}
+
+func (t *Handler) Close() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, a := range t.attachments {
+		closeAttachment(a)
+	}
+	t.attachments = make(map[string]*attachment)
+}
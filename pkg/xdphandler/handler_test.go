@@ -0,0 +1,70 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xdphandler
+
+import (
+	"net"
+	"testing"
+
+	"github.com/cilium/ebpf/link"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMode(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		mode        string
+		expected    link.XDPAttachFlags
+		expectedErr bool
+	}{
+		"empty":   {mode: "", expected: 0},
+		"auto":    {mode: ModeAuto, expected: 0},
+		"generic": {mode: ModeGeneric, expected: link.XDPGenericMode},
+		"native":  {mode: ModeNative, expected: link.XDPDriverMode},
+		"invalid": {mode: "offload", expectedErr: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			flags, err := ParseMode(test.mode)
+			if test.expectedErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.expected, flags)
+		})
+	}
+}
+
+func TestPendingIfaces(t *testing.T) {
+	t.Parallel()
+
+	h := NewHandler()
+	defer h.Close()
+
+	// Interfaces are only tracked until the program is available
+	iface := &net.Interface{Index: 1000, Name: "veth-test"}
+	require.NoError(t, h.AttachIface(iface))
+	require.NoError(t, h.AttachIface(iface))
+	require.Len(t, h.attachments, 1)
+	require.Nil(t, h.attachments[iface.Name].link)
+
+	require.NoError(t, h.DetachIface(iface))
+	require.Error(t, h.DetachIface(iface))
+}
@@ -0,0 +1,202 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	_ "unsafe"
+)
+
+//go:wasmimport ig containerLookupByMntns
+//go:linkname containerLookupByMntns containerLookupByMntns
+func containerLookupByMntns(mntNsID uint64) uint32
+
+//go:wasmimport ig containerLookupByPid
+//go:linkname containerLookupByPid containerLookupByPid
+func containerLookupByPid(pid uint32) uint32
+
+//go:wasmimport ig containersLookupByNetns
+//go:linkname containersLookupByNetns containersLookupByNetns
+func containersLookupByNetns(netNsID uint64, handles uint64) int64
+
+//go:wasmimport ig containerGetMetadata
+//go:linkname containerGetMetadata containerGetMetadata
+func containerGetMetadata(container uint32, dst uint64) int64
+
+//go:wasmimport ig containerSubscribe
+//go:linkname containerSubscribe containerSubscribe
+func containerSubscribe(cbID uint64) uint32
+
+// Keep in sync with pkg/operators/wasm/containers.go
+type ContainerEvent uint32
+
+const (
+	ContainerEventAdd ContainerEvent = iota
+	ContainerEventRemove
+)
+
+type ContainerRuntime struct {
+	RuntimeName          string
+	ContainerID          string
+	ContainerName        string
+	ContainerPID         uint32
+	ContainerImageName   string
+	ContainerImageDigest string
+}
+
+type ContainerK8s struct {
+	Namespace     string
+	PodName       string
+	PodUID        string
+	ContainerName string
+	PodLabels     map[string]string
+	OwnerKind     string
+	OwnerName     string
+	HostNetwork   bool
+}
+
+// Container holds the runtime and Kubernetes metadata of a container
+type Container struct {
+	Runtime    ContainerRuntime
+	K8s        ContainerK8s
+	MntNsID    uint64
+	NetNsID    uint64
+	CgroupID   uint64
+	CgroupPath string
+}
+
+type ContainerFunc func(event ContainerEvent, container *Container)
+
+var (
+	containerCtr           = uint64(0)
+	containerSubscriptions = map[uint64]ContainerFunc{}
+)
+
+//go:wasmexport containerCallback
+func containerCallback(cbID uint64, event uint32, container uint32) {
+	cb, ok := containerSubscriptions[cbID]
+	if !ok {
+		return
+	}
+	c, err := getContainer(container)
+	if err != nil {
+		Warnf("getting container metadata: %v", err)
+		return
+	}
+	cb(ContainerEvent(event), c)
+}
+
+func getContainer(handle uint32) (*Container, error) {
+	buf, ok := getSized(func(dst bufPtr) int64 {
+		return containerGetMetadata(handle, uint64(dst))
+	})
+	if !ok {
+		return nil, errors.New("getting container metadata")
+	}
+	md := stringMapFromBytes(buf)
+
+	c := &Container{
+		Runtime: ContainerRuntime{
+			RuntimeName:          md["runtime.runtimeName"],
+			ContainerID:          md["runtime.containerId"],
+			ContainerName:        md["runtime.containerName"],
+			ContainerImageName:   md["runtime.containerImageName"],
+			ContainerImageDigest: md["runtime.containerImageDigest"],
+		},
+		K8s: ContainerK8s{
+			Namespace:     md["k8s.namespace"],
+			PodName:       md["k8s.podName"],
+			PodUID:        md["k8s.podUID"],
+			ContainerName: md["k8s.containerName"],
+			PodLabels:     map[string]string{},
+			OwnerKind:     md["k8s.owner.kind"],
+			OwnerName:     md["k8s.owner.name"],
+		},
+		CgroupPath: md["cgroupPath"],
+	}
+	pid, _ := strconv.ParseUint(md["runtime.containerPid"], 10, 32)
+	c.Runtime.ContainerPID = uint32(pid)
+	c.K8s.HostNetwork, _ = strconv.ParseBool(md["k8s.hostNetwork"])
+	c.MntNsID, _ = strconv.ParseUint(md["mntns"], 10, 64)
+	c.NetNsID, _ = strconv.ParseUint(md["netns"], 10, 64)
+	c.CgroupID, _ = strconv.ParseUint(md["cgroupId"], 10, 64)
+	for k, v := range md {
+		if label, ok := strings.CutPrefix(k, "k8s.podLabels."); ok {
+			c.K8s.PodLabels[label] = v
+		}
+	}
+	return c, nil
+}
+
+func lookupContainer(handle uint32) (*Container, error) {
+	if handle == 0 {
+		return nil, errors.New("container not found")
+	}
+	defer releaseHandle(handle)
+	return getContainer(handle)
+}
+
+// LookupContainerByMntNsID returns the container running in the given mount namespace. Lookups
+// are only available once the gadget is being started, i.e. not in gadgetInit.
+func LookupContainerByMntNsID(mntNsID uint64) (*Container, error) {
+	return lookupContainer(containerLookupByMntns(mntNsID))
+}
+
+// LookupContainerByPid returns the container the process with the given pid (as seen from the
+// host) runs in.
+func LookupContainerByPid(pid uint32) (*Container, error) {
+	return lookupContainer(containerLookupByPid(pid))
+}
+
+// LookupContainersByNetNsID returns the containers running in the given network namespace.
+func LookupContainersByNetNsID(netNsID uint64) ([]*Container, error) {
+	handles, ok := getHandles(func(dst bufPtr) int64 {
+		return containersLookupByNetns(netNsID, uint64(dst))
+	})
+	if !ok {
+		return nil, errors.New("looking up containers")
+	}
+
+	containers := make([]*Container, 0, len(handles))
+	var err error
+	for _, h := range handles {
+		c, cErr := lookupContainer(h)
+		if cErr != nil {
+			err = cErr
+			continue
+		}
+		containers = append(containers, c)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return containers, nil
+}
+
+// SubscribeContainers calls cb when a container is added or removed while the gadget is running.
+// The containers that exist when the gadget starts are passed as added. It has to be called
+// before the gadget starts (in gadgetInit, gadgetPreStart or gadgetStart).
+func SubscribeContainers(cb ContainerFunc) error {
+	containerCtr++
+	containerSubscriptions[containerCtr] = cb
+	ret := containerSubscribe(containerCtr)
+	if ret != 0 {
+		delete(containerSubscriptions, containerCtr)
+		return errors.New("subscribing to containers")
+	}
+	return nil
+}
@@ -29,6 +29,10 @@ func newDataSource(name uint64, typ uint32) uint32
 //go:linkname getDataSource getDataSource
 func getDataSource(name uint64) uint32
 
+//go:wasmimport ig getDataSourcesByTag
+//go:linkname getDataSourcesByTag getDataSourcesByTag
+func getDataSourcesByTag(tag uint64, handles uint64) int64
+
 //go:wasmimport ig dataSourceSubscribe
 //go:linkname dataSourceSubscribe dataSourceSubscribe
 func dataSourceSubscribe(ds uint32, typ uint32, prio uint32, cb uint64) uint32
@@ -41,6 +45,30 @@ func dataSourceGetField(ds uint32, name uint64) uint32
 //go:linkname dataSourceAddField dataSourceAddField
 func dataSourceAddField(ds uint32, name uint64, kind uint32) uint32
 
+//go:wasmimport ig dataSourceAddFieldWithOptions
+//go:linkname dataSourceAddFieldWithOptions dataSourceAddFieldWithOptions
+func dataSourceAddFieldWithOptions(ds uint32, name uint64, kind uint32, flags uint32, order int32, tags uint64, annotations uint64) uint32
+
+//go:wasmimport ig dataSourceGetFieldsWithTag
+//go:linkname dataSourceGetFieldsWithTag dataSourceGetFieldsWithTag
+func dataSourceGetFieldsWithTag(ds uint32, tags uint64, handles uint64) int64
+
+//go:wasmimport ig dataSourceGetTags
+//go:linkname dataSourceGetTags dataSourceGetTags
+func dataSourceGetTags(ds uint32, dst uint64) int64
+
+//go:wasmimport ig dataSourceAddTag
+//go:linkname dataSourceAddTag dataSourceAddTag
+func dataSourceAddTag(ds uint32, tag uint64) uint32
+
+//go:wasmimport ig dataSourceGetAnnotations
+//go:linkname dataSourceGetAnnotations dataSourceGetAnnotations
+func dataSourceGetAnnotations(ds uint32, dst uint64) int64
+
+//go:wasmimport ig dataSourceAddAnnotation
+//go:linkname dataSourceAddAnnotation dataSourceAddAnnotation
+func dataSourceAddAnnotation(ds uint32, key uint64, value uint64) uint32
+
 //go:wasmimport ig dataSourceNewPacketSingle
 //go:linkname dataSourceNewPacketSingle dataSourceNewPacketSingle
 func dataSourceNewPacketSingle(ds uint32) uint32
@@ -172,6 +200,22 @@ func GetDataSource(name string) (DataSource, error) {
 	return DataSource(ret), nil
 }
 
+// GetDataSourcesByTag returns the data sources having the given tag, sorted by name
+func GetDataSourcesByTag(tag string) ([]DataSource, error) {
+	handles, ok := getHandles(func(dst bufPtr) int64 {
+		return getDataSourcesByTag(uint64(stringToBufPtr(tag)), uint64(dst))
+	})
+	runtime.KeepAlive(tag)
+	if !ok {
+		return nil, fmt.Errorf("getting datasources with tag %q", tag)
+	}
+	dataSources := make([]DataSource, len(handles))
+	for i, h := range handles {
+		dataSources[i] = DataSource(h)
+	}
+	return dataSources, nil
+}
+
 func NewDataSource(name string, typ DataSourceType) (DataSource, error) {
 	ret := newDataSource(uint64(stringToBufPtr(name)), uint32(typ))
 	runtime.KeepAlive(name)
@@ -256,8 +300,24 @@ func (ds DataSource) GetField(name string) (Field, error) {
 	return Field(ret), nil
 }
 
-func (ds DataSource) AddField(name string, kind FieldKind) (Field, error) {
-	ret := dataSourceAddField(uint32(ds), uint64(stringToBufPtr(name)), uint32(kind))
+// AddField adds a field to the data source. Options set the flags, order, tags and annotations of
+// the field.
+func (ds DataSource) AddField(name string, kind FieldKind, opts ...FieldOption) (Field, error) {
+	var ret uint32
+	if len(opts) == 0 {
+		ret = dataSourceAddField(uint32(ds), uint64(stringToBufPtr(name)), uint32(kind))
+	} else {
+		o := &fieldOptions{}
+		for _, opt := range opts {
+			opt(o)
+		}
+		tags := stringsToBytes(o.tags)
+		annotations := stringMapToBytes(o.annotations)
+		ret = dataSourceAddFieldWithOptions(uint32(ds), uint64(stringToBufPtr(name)), uint32(kind),
+			uint32(o.flags), o.order, uint64(bytesToBufPtr(tags)), uint64(bytesToBufPtr(annotations)))
+		runtime.KeepAlive(tags)
+		runtime.KeepAlive(annotations)
+	}
 	runtime.KeepAlive(name)
 	if ret == 0 {
 		return 0, fmt.Errorf("adding field %q", name)
@@ -265,6 +325,68 @@ func (ds DataSource) AddField(name string, kind FieldKind) (Field, error) {
 	return Field(ret), nil
 }
 
+// GetFieldsWithTag returns the fields having any of the given tags
+func (ds DataSource) GetFieldsWithTag(tags ...string) ([]Field, error) {
+	buf := stringsToBytes(tags)
+	handles, ok := getHandles(func(dst bufPtr) int64 {
+		return dataSourceGetFieldsWithTag(uint32(ds), uint64(bytesToBufPtr(buf)), uint64(dst))
+	})
+	runtime.KeepAlive(buf)
+	if !ok {
+		return nil, errors.New("getting fields with tag")
+	}
+	fields := make([]Field, len(handles))
+	for i, h := range handles {
+		fields[i] = Field(h)
+	}
+	return fields, nil
+}
+
+// Tags returns the tags of the data source
+func (ds DataSource) Tags() ([]string, error) {
+	buf, ok := getSized(func(dst bufPtr) int64 {
+		return dataSourceGetTags(uint32(ds), uint64(dst))
+	})
+	if !ok {
+		return nil, errors.New("getting data source tags")
+	}
+	return stringsFromBytes(buf), nil
+}
+
+// AddTags adds tags to the data source
+func (ds DataSource) AddTags(tags ...string) error {
+	for _, tag := range tags {
+		ret := dataSourceAddTag(uint32(ds), uint64(stringToBufPtr(tag)))
+		runtime.KeepAlive(tag)
+		if ret != 0 {
+			return fmt.Errorf("adding tag %q", tag)
+		}
+	}
+	return nil
+}
+
+// Annotations returns the annotations of the data source
+func (ds DataSource) Annotations() (map[string]string, error) {
+	buf, ok := getSized(func(dst bufPtr) int64 {
+		return dataSourceGetAnnotations(uint32(ds), uint64(dst))
+	})
+	if !ok {
+		return nil, errors.New("getting data source annotations")
+	}
+	return stringMapFromBytes(buf), nil
+}
+
+// AddAnnotation sets an annotation of the data source
+func (ds DataSource) AddAnnotation(key, value string) error {
+	ret := dataSourceAddAnnotation(uint32(ds), uint64(stringToBufPtr(key)), uint64(stringToBufPtr(value)))
+	runtime.KeepAlive(key)
+	runtime.KeepAlive(value)
+	if ret != 0 {
+		return fmt.Errorf("adding annotation %q", key)
+	}
+	return nil
+}
+
 func (d DataArray) New() Data {
 	ret := dataArrayNew(uint32(d))
 	return Data(ret)
@@ -16,6 +16,7 @@ package api
 
 import (
 	"errors"
+	"maps"
 	"math"
 	"runtime"
 	"unsafe"
@@ -37,6 +38,93 @@ func fieldSet(field uint32, data uint32, kind uint32, value uint64) uint32
 //go:linkname fieldAddTag fieldAddTag
 func fieldAddTag(field uint32, tag uint64) uint32
 
+//go:wasmimport ig fieldGetName
+//go:linkname fieldGetName fieldGetName
+func fieldGetName(field uint32, dst uint64) int64
+
+//go:wasmimport ig fieldGetKind
+//go:linkname fieldGetKind fieldGetKind
+func fieldGetKind(field uint32) int64
+
+//go:wasmimport ig fieldGetFlags
+//go:linkname fieldGetFlags fieldGetFlags
+func fieldGetFlags(field uint32) int64
+
+//go:wasmimport ig fieldSetHidden
+//go:linkname fieldSetHidden fieldSetHidden
+func fieldSetHidden(field uint32, hidden uint32, recurse uint32) uint32
+
+//go:wasmimport ig fieldGetTags
+//go:linkname fieldGetTags fieldGetTags
+func fieldGetTags(field uint32, dst uint64) int64
+
+//go:wasmimport ig fieldGetAnnotations
+//go:linkname fieldGetAnnotations fieldGetAnnotations
+func fieldGetAnnotations(field uint32, dst uint64) int64
+
+//go:wasmimport ig fieldAddAnnotation
+//go:linkname fieldAddAnnotation fieldAddAnnotation
+func fieldAddAnnotation(field uint32, key uint64, value uint64) uint32
+
+type FieldFlag uint32
+
+// Keep in sync with pkg/datasource/field.go
+const (
+	// FieldFlagEmpty means the field cannot have a value
+	FieldFlagEmpty FieldFlag = 1 << iota
+	// FieldFlagContainer means the field is statically sized and has statically sized members
+	FieldFlagContainer
+	// FieldFlagHidden means the field isn't shown by default
+	FieldFlagHidden
+	// FieldFlagHasParent means the field is a member of another field
+	FieldFlagHasParent
+	// FieldFlagStaticMember means the field is a statically sized member of a container
+	FieldFlagStaticMember
+	// FieldFlagUnreferenced means the field is no longer referenced by its name
+	FieldFlagUnreferenced
+)
+
+type fieldOptions struct {
+	flags       FieldFlag
+	order       int32
+	tags        []string
+	annotations map[string]string
+}
+
+// FieldOption sets options of fields added with DataSource.AddField()
+type FieldOption func(*fieldOptions)
+
+// WithFlags sets flags of the field. Only FieldFlagEmpty and FieldFlagHidden can be set.
+func WithFlags(flags FieldFlag) FieldOption {
+	return func(o *fieldOptions) {
+		o.flags |= flags
+	}
+}
+
+// WithOrder sets the order of the field, by default it's the order fields were added in
+func WithOrder(order int32) FieldOption {
+	return func(o *fieldOptions) {
+		o.order = order
+	}
+}
+
+// WithTags adds tags to the field
+func WithTags(tags ...string) FieldOption {
+	return func(o *fieldOptions) {
+		o.tags = append(o.tags, tags...)
+	}
+}
+
+// WithAnnotations adds annotations to the field, e.g. "columns.width" or "description"
+func WithAnnotations(annotations map[string]string) FieldOption {
+	return func(o *fieldOptions) {
+		if o.annotations == nil {
+			o.annotations = map[string]string{}
+		}
+		maps.Copy(o.annotations, annotations)
+	}
+}
+
 var (
 	errSetField = errors.New("error setting field")
 	errGetField = errors.New("error getting field")
@@ -202,3 +290,82 @@ func (f Field) AddTag(tag string) error {
 	}
 	return nil
 }
+
+// Name returns the name of the field
+func (f Field) Name() (string, error) {
+	buf, ok := getSized(func(dst bufPtr) int64 {
+		return fieldGetName(uint32(f), uint64(dst))
+	})
+	if !ok {
+		return "", errors.New("error getting field name")
+	}
+	return string(buf), nil
+}
+
+// Type returns the kind of the field
+func (f Field) Type() (FieldKind, error) {
+	ret := fieldGetKind(uint32(f))
+	if ret < 0 {
+		return Kind_Invalid, errors.New("error getting field kind")
+	}
+	return FieldKind(ret), nil
+}
+
+// Flags returns the flags of the field
+func (f Field) Flags() (FieldFlag, error) {
+	ret := fieldGetFlags(uint32(f))
+	if ret < 0 {
+		return 0, errors.New("error getting field flags")
+	}
+	return FieldFlag(ret), nil
+}
+
+// SetHidden marks the field as hidden (by default), it can still be requested. With recurse, it's
+// applied to its subfields too.
+func (f Field) SetHidden(hidden bool, recurse bool) error {
+	var hiddenUint32, recurseUint32 uint32
+	if hidden {
+		hiddenUint32 = 1
+	}
+	if recurse {
+		recurseUint32 = 1
+	}
+	ret := fieldSetHidden(uint32(f), hiddenUint32, recurseUint32)
+	if ret != 0 {
+		return errors.New("error setting field hidden")
+	}
+	return nil
+}
+
+// Tags returns the tags of the field
+func (f Field) Tags() ([]string, error) {
+	buf, ok := getSized(func(dst bufPtr) int64 {
+		return fieldGetTags(uint32(f), uint64(dst))
+	})
+	if !ok {
+		return nil, errors.New("error getting field tags")
+	}
+	return stringsFromBytes(buf), nil
+}
+
+// Annotations returns the annotations of the field
+func (f Field) Annotations() (map[string]string, error) {
+	buf, ok := getSized(func(dst bufPtr) int64 {
+		return fieldGetAnnotations(uint32(f), uint64(dst))
+	})
+	if !ok {
+		return nil, errors.New("error getting field annotations")
+	}
+	return stringMapFromBytes(buf), nil
+}
+
+// AddAnnotation sets an annotation of the field, e.g. "columns.width" or "description"
+func (f Field) AddAnnotation(key, value string) error {
+	ret := fieldAddAnnotation(uint32(f), uint64(stringToBufPtr(key)), uint64(stringToBufPtr(value)))
+	runtime.KeepAlive(key)
+	runtime.KeepAlive(value)
+	if ret != 0 {
+		return errors.New("error adding annotation")
+	}
+	return nil
+}
@@ -18,6 +18,7 @@ import (
 	"bytes"
 	"encoding/binary"
 	"fmt"
+	"runtime"
 	"slices"
 	"unsafe"
 )
@@ -87,3 +88,83 @@ func (b bufPtr) bytes() []byte {
 	// clone it
 	return slices.Clone(orig)
 }
+
+// stringsToBytes encodes a list of strings as NUL terminated strings, the format used to pass
+// lists of strings (e.g. tags) to and from the host
+func stringsToBytes(strs []string) []byte {
+	var buf []byte
+	for _, s := range strs {
+		buf = append(buf, s...)
+		buf = append(buf, 0)
+	}
+	return buf
+}
+
+// stringMapToBytes encodes a map as a list of key, value pairs, see stringsToBytes
+func stringMapToBytes(m map[string]string) []byte {
+	strs := make([]string, 0, 2*len(m))
+	for k, v := range m {
+		strs = append(strs, k, v)
+	}
+	return stringsToBytes(strs)
+}
+
+// stringsFromBytes decodes a list of strings encoded by stringsToBytes
+func stringsFromBytes(buf []byte) []string {
+	var strs []string
+	for len(buf) > 0 {
+		idx := bytes.IndexByte(buf, 0)
+		if idx == -1 {
+			idx = len(buf)
+		}
+		strs = append(strs, string(buf[:idx]))
+		buf = buf[min(idx+1, len(buf)):]
+	}
+	return strs
+}
+
+// stringMapFromBytes decodes a map encoded by stringMapToBytes
+func stringMapFromBytes(buf []byte) map[string]string {
+	strs := stringsFromBytes(buf)
+	m := make(map[string]string, len(strs)/2)
+	for i := 0; i+1 < len(strs); i += 2 {
+		m[strs[i]] = strs[i+1]
+	}
+	return m
+}
+
+// getSized calls fn, which writes a result of variable size to dst, with bigger buffers until the
+// result fits. fn has to return the size of the result, or -1 on error.
+func getSized(fn func(dst bufPtr) int64) ([]byte, bool) {
+	buf := make([]byte, 256)
+	for {
+		ret := fn(bytesToBufPtr(buf))
+		runtime.KeepAlive(buf)
+		if ret < 0 {
+			return nil, false
+		}
+		if int(ret) > len(buf) {
+			buf = make([]byte, ret)
+			continue
+		}
+		return buf[:ret], true
+	}
+}
+
+// getHandles is like getSized for functions returning a list of handles. fn has to return the
+// number of handles, or -1 on error.
+func getHandles(fn func(dst bufPtr) int64) ([]uint32, bool) {
+	handles := make([]uint32, 16)
+	for {
+		ret := fn(sliceToBufPtr(handles))
+		runtime.KeepAlive(handles)
+		if ret < 0 {
+			return nil, false
+		}
+		if int(ret) > len(handles) {
+			handles = make([]uint32, ret)
+			continue
+		}
+		return handles[:ret], true
+	}
+}
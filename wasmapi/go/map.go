@@ -42,12 +42,27 @@ func mapUpdate(m uint32, keyptr uint64, valueptr uint64, flags uint64) uint32
 //go:linkname mapDelete mapDelete
 func mapDelete(m uint32, keyptr uint64) uint32
 
+//go:wasmimport ig mapGetNextKey
+//go:linkname mapGetNextKey mapGetNextKey
+func mapGetNextKey(m uint32, keyptr uint64, nextkeyptr uint64) uint32
+
+//go:wasmimport ig mapBatchLookup
+//go:linkname mapBatchLookup mapBatchLookup
+func mapBatchLookup(m uint32, cursorptr uint64, keysptr uint64, valuesptr uint64) int64
+
+//go:wasmimport ig mapBatchLookupAndDelete
+//go:linkname mapBatchLookupAndDelete mapBatchLookupAndDelete
+func mapBatchLookupAndDelete(m uint32, cursorptr uint64, keysptr uint64, valuesptr uint64) int64
+
 //go:wasmimport ig mapRelease
 //go:linkname mapRelease mapRelease
 func mapRelease(m uint32) uint32
 
 type Map uint32
 
+// ErrKeyNotExist is returned by NextKey when there are no more keys
+var ErrKeyNotExist = errors.New("key does not exist")
+
 type MapUpdateFlags uint64
 
 // Taken from:
@@ -192,6 +207,111 @@ func (m Map) Delete(key any) error {
 	return nil
 }
 
+// NextKey writes the key following key into nextKeyOut, which must be a pointer. If key is nil,
+// the first key of the map is returned. ErrKeyNotExist is returned after the last key.
+func (m Map) NextKey(key any, nextKeyOut any) error {
+	if reflect.TypeOf(nextKeyOut).Kind() != reflect.Pointer {
+		return fmt.Errorf("next key expected type *%T, got %T", nextKeyOut, nextKeyOut)
+	}
+
+	var keyPtr bufPtr
+	if key != nil {
+		var err error
+		keyPtr, err = anyToBufPtr(key)
+		if err != nil {
+			return err
+		}
+	}
+
+	nextKeyPtr, err := anyToBufPtr(nextKeyOut)
+	if err != nil {
+		return err
+	}
+
+	ret := mapGetNextKey(uint32(m), uint64(keyPtr), uint64(nextKeyPtr))
+	runtime.KeepAlive(key)
+	runtime.KeepAlive(nextKeyOut)
+	switch ret {
+	case 0:
+	case 2:
+		return ErrKeyNotExist
+	default:
+		return errors.New("getting next key")
+	}
+
+	v := reflect.ValueOf(nextKeyOut)
+	copy(unsafe.Slice((*byte)(v.UnsafePointer()), v.Type().Elem().Size()), nextKeyPtr.bytes())
+
+	return nil
+}
+
+// MapBatchCursor keeps the position of batch operations on a map between calls. The zero value
+// starts at the beginning of the map.
+type MapBatchCursor struct {
+	handle uint32
+	done   bool
+}
+
+// Done returns whether all the entries of the map were read
+func (c *MapBatchCursor) Done() bool {
+	return c.done
+}
+
+// Close releases the cursor. It's only needed when stopping before all entries were read.
+func (c *MapBatchCursor) Close() error {
+	if c.handle == 0 {
+		return nil
+	}
+	err := ReleaseHandle(c.handle)
+	c.handle = 0
+	return err
+}
+
+// sliceToBufPtr is like bytesToBufPtr for slices of any fixed-size type
+func sliceToBufPtr[T any](s []T) bufPtr {
+	var zero T
+	return bytesToBufPtr(unsafe.Slice((*byte)(unsafe.Pointer(unsafe.SliceData(s))), len(s)*int(unsafe.Sizeof(zero))))
+}
+
+func mapBatch[K, V any](
+	fn func(uint32, uint64, uint64, uint64) int64,
+	m Map, cursor *MapBatchCursor, keys []K, values []V,
+) (int, error) {
+	if cursor.done {
+		return 0, nil
+	}
+	if len(keys) == 0 || len(keys) != len(values) {
+		return 0, fmt.Errorf("keys and values must have the same, non-zero length, got %d and %d", len(keys), len(values))
+	}
+
+	cursorPtr := bytesToBufPtr(unsafe.Slice((*byte)(unsafe.Pointer(&cursor.handle)), unsafe.Sizeof(cursor.handle)))
+	ret := fn(uint32(m), uint64(cursorPtr), uint64(sliceToBufPtr(keys)), uint64(sliceToBufPtr(values)))
+	runtime.KeepAlive(keys)
+	runtime.KeepAlive(values)
+	if ret < 0 {
+		// The host releases the cursor on error
+		return 0, errors.New("batch operation on map")
+	}
+	cursor.done = cursor.handle == 0
+	return int(ret), nil
+}
+
+// MapBatchLookup reads up to len(keys) entries of the map into keys and values, starting at the
+// position of cursor. It returns the number of entries read, call it until cursor.Done() returns
+// true to read the whole map. K and V must mimic the kernel representation of the key and value,
+// like for Map.Lookup(). For hash maps, the slices must be large enough to hold a whole hash
+// bucket, a few tens of entries are usually enough. Kernels without batch operations are
+// supported, entries are read one by one by the host then. Per-CPU maps and maps of maps aren't
+// supported.
+func MapBatchLookup[K, V any](m Map, cursor *MapBatchCursor, keys []K, values []V) (int, error) {
+	return mapBatch(mapBatchLookup, m, cursor, keys, values)
+}
+
+// MapBatchLookupAndDelete is like MapBatchLookup, but also deletes the entries read from the map
+func MapBatchLookupAndDelete[K, V any](m Map, cursor *MapBatchCursor, keys []K, values []V) (int, error) {
+	return mapBatch(mapBatchLookupAndDelete, m, cursor, keys, values)
+}
+
 func (m Map) Close() error {
 	ret := mapRelease(uint32(m))
 	if ret != 0 {
@@ -0,0 +1,104 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"errors"
+	"time"
+	_ "unsafe"
+)
+
+//go:wasmimport ig newTimer
+//go:linkname newTimer newTimer
+func newTimer(interval uint64, periodic uint32, cbID uint64) uint32
+
+//go:wasmimport ig timerStop
+//go:linkname timerStop timerStop
+func timerStop(timer uint32) uint32
+
+type (
+	Timer     uint32
+	TimerFunc func()
+)
+
+type timerSubscription struct {
+	cb       TimerFunc
+	periodic bool
+}
+
+var (
+	timerCtr           = uint64(0)
+	timerSubscriptions = map[uint64]timerSubscription{}
+	timerCallbackIDs   = map[Timer]uint64{}
+)
+
+//go:wasmexport timerCallback
+func timerCallback(cbID uint64) {
+	sub, ok := timerSubscriptions[cbID]
+	if !ok {
+		return
+	}
+	if !sub.periodic {
+		delete(timerSubscriptions, cbID)
+		for t, id := range timerCallbackIDs {
+			if id == cbID {
+				delete(timerCallbackIDs, t)
+				break
+			}
+		}
+	}
+	sub.cb()
+}
+
+func addTimer(d time.Duration, cb TimerFunc, periodic bool) (Timer, error) {
+	var periodicUint32 uint32
+	if periodic {
+		periodicUint32 = 1
+	}
+
+	timerCtr++
+	timerSubscriptions[timerCtr] = timerSubscription{cb: cb, periodic: periodic}
+	ret := newTimer(uint64(d), periodicUint32, timerCtr)
+	if ret == 0 {
+		delete(timerSubscriptions, timerCtr)
+		return 0, errors.New("creating timer")
+	}
+	timerCallbackIDs[Timer(ret)] = timerCtr
+	return Timer(ret), nil
+}
+
+// NewTimer calls cb once after d. Timers only fire while the gadget is running: timers created
+// before the gadget is started (e.g. in gadgetInit) are armed when it starts.
+func NewTimer(d time.Duration, cb TimerFunc) (Timer, error) {
+	return addTimer(d, cb, false)
+}
+
+// NewTicker calls cb every d until the timer is stopped or the gadget stops.
+func NewTicker(d time.Duration, cb TimerFunc) (Timer, error) {
+	return addTimer(d, cb, true)
+}
+
+// Stop stops the timer. The callback isn't called anymore afterwards.
+func (t Timer) Stop() error {
+	if cbID, ok := timerCallbackIDs[t]; ok {
+		delete(timerSubscriptions, cbID)
+		delete(timerCallbackIDs, t)
+	}
+	ret := timerStop(uint32(t))
+	if ret != 0 {
+		return errors.New("stopping timer")
+	}
+	return nil
+}
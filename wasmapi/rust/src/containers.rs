@@ -0,0 +1,198 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+use std::{
+    collections::HashMap,
+    sync::{
+        atomic::{AtomicU64, Ordering},
+        Arc, LazyLock, Mutex,
+    },
+};
+
+use crate::{
+    handle::release_handle,
+    helpers::{get_handles, get_sized, string_map_from_bytes},
+};
+
+pub type Result<T> = std::result::Result<T, String>;
+
+#[link(wasm_import_module = "ig")]
+extern "C" {
+    #[link_name = "containerLookupByMntns"]
+    fn _container_lookup_by_mntns(mntns_id: u64) -> u32;
+
+    #[link_name = "containerLookupByPid"]
+    fn _container_lookup_by_pid(pid: u32) -> u32;
+
+    #[link_name = "containersLookupByNetns"]
+    fn _containers_lookup_by_netns(netns_id: u64, handles: u64) -> i64;
+
+    #[link_name = "containerGetMetadata"]
+    fn _container_get_metadata(container: u32, dst: u64) -> i64;
+
+    #[link_name = "containerSubscribe"]
+    fn _container_subscribe(cb_id: u64) -> u32;
+}
+
+// Keep in sync with pkg/operators/wasm/containers.go
+#[derive(Clone, Copy, Debug, PartialEq, Eq)]
+#[repr(u32)]
+pub enum ContainerEvent {
+    Add = 0,
+    Remove = 1,
+}
+
+#[derive(Clone, Debug, Default)]
+pub struct ContainerRuntime {
+    pub runtime_name: String,
+    pub container_id: String,
+    pub container_name: String,
+    pub container_pid: u32,
+    pub container_image_name: String,
+    pub container_image_digest: String,
+}
+
+#[derive(Clone, Debug, Default)]
+pub struct ContainerK8s {
+    pub namespace: String,
+    pub pod_name: String,
+    pub pod_uid: String,
+    pub container_name: String,
+    pub pod_labels: HashMap<String, String>,
+    pub owner_kind: String,
+    pub owner_name: String,
+    pub host_network: bool,
+}
+
+/// Runtime and Kubernetes metadata of a container
+#[derive(Clone, Debug, Default)]
+pub struct Container {
+    pub runtime: ContainerRuntime,
+    pub k8s: ContainerK8s,
+    pub mntns_id: u64,
+    pub netns_id: u64,
+    pub cgroup_id: u64,
+    pub cgroup_path: String,
+}
+
+type ContainerFunc = Arc<dyn Fn(ContainerEvent, &Container) + Send + Sync + 'static>;
+
+static CONTAINER_CTR: AtomicU64 = AtomicU64::new(0);
+static CONTAINER_SUBSCRIPTIONS: LazyLock<Mutex<HashMap<u64, ContainerFunc>>> =
+    LazyLock::new(|| Mutex::new(HashMap::new()));
+
+impl Container {
+    fn from_handle(handle: u32) -> Result<Self> {
+        let buf = get_sized(|dst| unsafe { _container_get_metadata(handle, dst) })
+            .ok_or_else(|| String::from("failed to get container metadata"))?;
+        let mut md = string_map_from_bytes(&buf);
+        let mut take = |key: &str| md.remove(key).unwrap_or_default();
+
+        let mut c = Container {
+            runtime: ContainerRuntime {
+                runtime_name: take("runtime.runtimeName"),
+                container_id: take("runtime.containerId"),
+                container_name: take("runtime.containerName"),
+                container_pid: take("runtime.containerPid").parse().unwrap_or_default(),
+                container_image_name: take("runtime.containerImageName"),
+                container_image_digest: take("runtime.containerImageDigest"),
+            },
+            k8s: ContainerK8s {
+                namespace: take("k8s.namespace"),
+                pod_name: take("k8s.podName"),
+                pod_uid: take("k8s.podUID"),
+                container_name: take("k8s.containerName"),
+                pod_labels: HashMap::new(),
+                owner_kind: take("k8s.owner.kind"),
+                owner_name: take("k8s.owner.name"),
+                host_network: take("k8s.hostNetwork").parse().unwrap_or_default(),
+            },
+            mntns_id: take("mntns").parse().unwrap_or_default(),
+            netns_id: take("netns").parse().unwrap_or_default(),
+            cgroup_id: take("cgroupId").parse().unwrap_or_default(),
+            cgroup_path: take("cgroupPath"),
+        };
+        for (k, v) in md {
+            if let Some(label) = k.strip_prefix("k8s.podLabels.") {
+                c.k8s.pod_labels.insert(label.to_string(), v);
+            }
+        }
+        Ok(c)
+    }
+
+    fn lookup(handle: u32) -> Result<Self> {
+        if handle == 0 {
+            return Err(String::from("container not found"));
+        }
+        let c = Self::from_handle(handle);
+        let _ = release_handle(handle);
+        c
+    }
+
+    /// Returns the container running in the given mount namespace. Lookups are only available
+    /// once the gadget is being started, i.e. not in gadgetInit.
+    pub fn lookup_by_mntns_id(mntns_id: u64) -> Result<Self> {
+        Self::lookup(unsafe { _container_lookup_by_mntns(mntns_id) })
+    }
+
+    /// Returns the container the process with the given pid (as seen from the host) runs in.
+    pub fn lookup_by_pid(pid: u32) -> Result<Self> {
+        Self::lookup(unsafe { _container_lookup_by_pid(pid) })
+    }
+
+    /// Returns the containers running in the given network namespace.
+    pub fn lookup_by_netns_id(netns_id: u64) -> Result<Vec<Self>> {
+        let handles = get_handles(|dst| unsafe { _containers_lookup_by_netns(netns_id, dst) })
+            .ok_or_else(|| String::from("failed to look up containers"))?;
+        handles.into_iter().map(Self::lookup).collect()
+    }
+
+    /// Calls cb when a container is added or removed while the gadget is running. The containers
+    /// that exist when the gadget starts are passed as added. It has to be called before the
+    /// gadget starts (in gadgetInit, gadgetPreStart or gadgetStart).
+    pub fn subscribe<F>(cb: F) -> Result<()>
+    where
+        F: Fn(ContainerEvent, &Container) + Send + Sync + 'static,
+    {
+        let cb_id = CONTAINER_CTR.fetch_add(1, Ordering::SeqCst);
+        CONTAINER_SUBSCRIPTIONS
+            .lock()
+            .unwrap()
+            .insert(cb_id, Arc::new(cb));
+        let ret = unsafe { _container_subscribe(cb_id) };
+        if ret != 0 {
+            CONTAINER_SUBSCRIPTIONS.lock().unwrap().remove(&cb_id);
+            return Err(String::from("failed to subscribe to containers"));
+        }
+        Ok(())
+    }
+}
+
+#[no_mangle]
+#[allow(non_snake_case)]
+fn containerCallback(cb_id: u64, event: u32, container: u32) {
+    let Some(cb) = CONTAINER_SUBSCRIPTIONS.lock().unwrap().get(&cb_id).cloned() else {
+        return;
+    };
+    let event = if event == ContainerEvent::Remove as u32 {
+        ContainerEvent::Remove
+    } else {
+        ContainerEvent::Add
+    };
+    match Container::from_handle(container) {
+        Ok(c) => cb(event, &c),
+        Err(err) => {
+            crate::warnf!("getting container metadata: {}", err);
+        }
+    }
+}
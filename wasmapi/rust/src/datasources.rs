@@ -19,7 +19,10 @@ use std::{
     },
 };
 
-use crate::helpers::string_to_buf_ptr; //relative paths may hinder in testing.
+use crate::helpers::{
+    bytes_to_buf_ptr, get_handles, get_sized, string_map_from_bytes, string_map_to_bytes,
+    string_to_buf_ptr, strings_from_bytes, strings_to_bytes,
+}; //relative paths may hinder in testing.
 
 #[derive(Debug)]
 pub enum DataSourceError {
@@ -74,6 +77,50 @@ pub enum FieldKind {
     Bytes = 14,
 }
 
+impl TryFrom<u32> for FieldKind {
+    type Error = u32;
+
+    fn try_from(kind: u32) -> std::result::Result<Self, Self::Error> {
+        Ok(match kind {
+            0 => FieldKind::Invalid,
+            1 => FieldKind::Bool,
+            2 => FieldKind::Int8,
+            3 => FieldKind::Int16,
+            4 => FieldKind::Int32,
+            5 => FieldKind::Int64,
+            6 => FieldKind::Uint8,
+            7 => FieldKind::Uint16,
+            8 => FieldKind::Uint32,
+            9 => FieldKind::Uint64,
+            10 => FieldKind::Float32,
+            11 => FieldKind::Float64,
+            12 => FieldKind::String,
+            13 => FieldKind::CString,
+            14 => FieldKind::Bytes,
+            _ => return Err(kind),
+        })
+    }
+}
+
+// Keep in sync with pkg/datasource/field.go
+pub const FIELD_FLAG_EMPTY: u32 = 1 << 0;
+pub const FIELD_FLAG_CONTAINER: u32 = 1 << 1;
+pub const FIELD_FLAG_HIDDEN: u32 = 1 << 2;
+pub const FIELD_FLAG_HAS_PARENT: u32 = 1 << 3;
+pub const FIELD_FLAG_STATIC_MEMBER: u32 = 1 << 4;
+pub const FIELD_FLAG_UNREFERENCED: u32 = 1 << 5;
+
+/// Options of fields added with DataSource::add_field_with_options(). Only
+/// FIELD_FLAG_EMPTY and FIELD_FLAG_HIDDEN can be set in flags, an order of 0
+/// keeps the order fields were added in.
+#[derive(Clone, Debug, Default)]
+pub struct FieldOptions {
+    pub flags: u32,
+    pub order: i32,
+    pub tags: Vec<String>,
+    pub annotations: HashMap<String, String>,
+}
+
 pub enum CallBack {
     Data(DataFunc),
     Array(DataArrayFunc),
@@ -92,6 +139,9 @@ extern "C" {
     #[link_name = "getDataSource"]
     fn _get_datasource(name: u64) -> u32;
 
+    #[link_name = "getDataSourcesByTag"]
+    fn _get_datasources_by_tag(tag: u64, handles: u64) -> i64;
+
     #[link_name = "dataSourceSubscribe"]
     fn _datasource_subscribe(ds: u32, typ: u32, prio: u32, cb: u64) -> u32;
 
@@ -101,6 +151,32 @@ extern "C" {
     #[link_name = "dataSourceAddField"]
     fn _datasource_add_field(ds: u32, name: u64, kind: u32) -> u32;
 
+    #[link_name = "dataSourceAddFieldWithOptions"]
+    fn _datasource_add_field_with_options(
+        ds: u32,
+        name: u64,
+        kind: u32,
+        flags: u32,
+        order: i32,
+        tags: u64,
+        annotations: u64,
+    ) -> u32;
+
+    #[link_name = "dataSourceGetFieldsWithTag"]
+    fn _datasource_get_fields_with_tag(ds: u32, tags: u64, handles: u64) -> i64;
+
+    #[link_name = "dataSourceGetTags"]
+    fn _datasource_get_tags(ds: u32, dst: u64) -> i64;
+
+    #[link_name = "dataSourceAddTag"]
+    fn _datasource_add_tag(ds: u32, tag: u64) -> u32;
+
+    #[link_name = "dataSourceGetAnnotations"]
+    fn _datasource_get_annotations(ds: u32, dst: u64) -> i64;
+
+    #[link_name = "dataSourceAddAnnotation"]
+    fn _datasource_add_annotation(ds: u32, key: u64, value: u64) -> u32;
+
     #[link_name = "dataSourceNewPacketSingle"]
     fn _datasource_new_packet_single(ds: u32) -> u32;
 
@@ -175,6 +251,14 @@ impl DataSource {
         }
     }
 
+    /// Returns the data sources having the given tag, sorted by name.
+    pub fn get_datasources_by_tag(tag: &str) -> Result<Vec<Self>> {
+        let tag_ptr = string_to_buf_ptr(tag);
+        let handles = get_handles(|dst| unsafe { _get_datasources_by_tag(tag_ptr.0, dst) })
+            .ok_or_else(|| DataSourceError::NotFound(tag.to_string()))?;
+        Ok(handles.into_iter().map(Self).collect())
+    }
+
     fn _subscribe(&self, typ: SubscriptionType, prio: u32, cb: CallBack) -> Result<()> {
         let ctr = DS_SUBSCRIPTION_CTR.fetch_add(1, Ordering::SeqCst);
         DS_SUBCRIPTION.lock().unwrap().insert(ctr, cb);
@@ -231,6 +315,74 @@ impl DataSource {
         }
     }
 
+    /// Adds a field setting its flags, order, tags and annotations.
+    pub fn add_field_with_options(
+        &self,
+        name: &str,
+        kind: FieldKind,
+        opts: &FieldOptions,
+    ) -> Result<Field> {
+        let ptr = string_to_buf_ptr(name);
+        let tags = strings_to_bytes(&opts.tags);
+        let annotations = string_map_to_bytes(&opts.annotations);
+        let ret = unsafe {
+            _datasource_add_field_with_options(
+                self.0,
+                ptr.0,
+                kind as u32,
+                opts.flags,
+                opts.order,
+                bytes_to_buf_ptr(&tags).0,
+                bytes_to_buf_ptr(&annotations).0,
+            )
+        };
+        if ret == 0 {
+            Err(DataSourceError::AddFieldFailed(name.to_string()))
+        } else {
+            Ok(Field(ret))
+        }
+    }
+
+    /// Returns the fields having any of the given tags.
+    pub fn get_fields_with_tag(&self, tags: &[&str]) -> Result<Vec<Field>> {
+        let buf = strings_to_bytes(tags);
+        let handles = get_handles(|dst| unsafe {
+            _datasource_get_fields_with_tag(self.0, bytes_to_buf_ptr(&buf).0, dst)
+        })
+        .ok_or(DataSourceError::GeneralError)?;
+        Ok(handles.into_iter().map(Field).collect())
+    }
+
+    pub fn tags(&self) -> Result<Vec<String>> {
+        let buf = get_sized(|dst| unsafe { _datasource_get_tags(self.0, dst) })
+            .ok_or(DataSourceError::GeneralError)?;
+        Ok(strings_from_bytes(&buf))
+    }
+
+    pub fn add_tag(&self, tag: &str) -> Result<()> {
+        let ret = unsafe { _datasource_add_tag(self.0, string_to_buf_ptr(tag).0) };
+        if ret != 0 {
+            return Err(DataSourceError::GeneralError);
+        }
+        Ok(())
+    }
+
+    pub fn annotations(&self) -> Result<HashMap<String, String>> {
+        let buf = get_sized(|dst| unsafe { _datasource_get_annotations(self.0, dst) })
+            .ok_or(DataSourceError::GeneralError)?;
+        Ok(string_map_from_bytes(&buf))
+    }
+
+    pub fn add_annotation(&self, key: &str, value: &str) -> Result<()> {
+        let ret = unsafe {
+            _datasource_add_annotation(self.0, string_to_buf_ptr(key).0, string_to_buf_ptr(value).0)
+        };
+        if ret != 0 {
+            return Err(DataSourceError::GeneralError);
+        }
+        Ok(())
+    }
+
     pub fn new_packet_single(&self) -> Result<PacketSingle> {
         let ret = unsafe { _datasource_new_packet_single(self.0) };
         if ret == 0 {
@@ -13,8 +13,12 @@
 // limitations under the License.
 
 use crate::datasources::{Data, Field, FieldKind};
-use crate::helpers::{bytes_to_buf_ptr, from_c_string, string_to_buf_ptr}; //relative paths may hinder in testing.
+use crate::helpers::{
+    bytes_to_buf_ptr, from_c_string, get_sized, string_map_from_bytes, string_to_buf_ptr,
+    strings_from_bytes,
+}; //relative paths may hinder in testing.
 use std::any::Any;
+use std::collections::HashMap;
 
 #[link(wasm_import_module = "ig")]
 extern "C" {
@@ -26,6 +30,20 @@ extern "C" {
     fn _field_set(field: u32, data: u32, kind: u32, value: u64) -> u32;
     #[link_name = "fieldAddTag"]
     fn _field_add_tag(field: u32, tag: u64) -> u32;
+    #[link_name = "fieldGetName"]
+    fn _field_get_name(field: u32, dst: u64) -> i64;
+    #[link_name = "fieldGetKind"]
+    fn _field_get_kind(field: u32) -> i64;
+    #[link_name = "fieldGetFlags"]
+    fn _field_get_flags(field: u32) -> i64;
+    #[link_name = "fieldSetHidden"]
+    fn _field_set_hidden(field: u32, hidden: u32, recurse: u32) -> u32;
+    #[link_name = "fieldGetTags"]
+    fn _field_get_tags(field: u32, dst: u64) -> i64;
+    #[link_name = "fieldGetAnnotations"]
+    fn _field_get_annotations(field: u32, dst: u64) -> i64;
+    #[link_name = "fieldAddAnnotation"]
+    fn _field_add_annotation(field: u32, key: u64, value: u64) -> u32;
 }
 
 pub type Result<T> = std::result::Result<T, String>;
@@ -197,4 +215,58 @@ impl Field {
         }
         Ok(())
     }
+
+    pub fn name(&self) -> Result<String> {
+        let buf = get_sized(|dst| unsafe { _field_get_name(self.0, dst) })
+            .ok_or_else(|| String::from("Error getting field name"))?;
+        Ok(String::from_utf8_lossy(&buf).into_owned())
+    }
+
+    pub fn kind(&self) -> Result<FieldKind> {
+        let ret = unsafe { _field_get_kind(self.0) };
+        if ret < 0 {
+            return Err(String::from("Error getting field kind"));
+        }
+        FieldKind::try_from(ret as u32).map_err(|kind| format!("Unknown field kind {kind}"))
+    }
+
+    pub fn flags(&self) -> Result<u32> {
+        let ret = unsafe { _field_get_flags(self.0) };
+        if ret < 0 {
+            return Err(String::from("Error getting field flags"));
+        }
+        Ok(ret as u32)
+    }
+
+    /// Marks the field as hidden (by default), it can still be requested. With
+    /// recurse, it's applied to its subfields too.
+    pub fn set_hidden(&self, hidden: bool, recurse: bool) -> Result<()> {
+        let ret = unsafe { _field_set_hidden(self.0, hidden as u32, recurse as u32) };
+        if ret != 0 {
+            return Err(String::from("Error setting field hidden"));
+        }
+        Ok(())
+    }
+
+    pub fn tags(&self) -> Result<Vec<String>> {
+        let buf = get_sized(|dst| unsafe { _field_get_tags(self.0, dst) })
+            .ok_or_else(|| String::from("Error getting field tags"))?;
+        Ok(strings_from_bytes(&buf))
+    }
+
+    pub fn annotations(&self) -> Result<HashMap<String, String>> {
+        let buf = get_sized(|dst| unsafe { _field_get_annotations(self.0, dst) })
+            .ok_or_else(|| String::from("Error getting field annotations"))?;
+        Ok(string_map_from_bytes(&buf))
+    }
+
+    pub fn add_annotation(&self, key: &str, value: &str) -> Result<()> {
+        let ret = unsafe {
+            _field_add_annotation(self.0, string_to_buf_ptr(key).0, string_to_buf_ptr(value).0)
+        };
+        if ret != 0 {
+            return Err(String::from("Error adding annotation"));
+        }
+        Ok(())
+    }
 }
@@ -12,7 +12,7 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-use std::{mem, slice};
+use std::{collections::HashMap, mem, slice};
 
 #[derive(Copy, Clone)]
 pub struct BufPtr(pub u64);
@@ -92,3 +92,85 @@ impl BufPtr {
         Some(slice.to_vec())
     }
 }
+
+/// Encodes a list of strings as NUL terminated strings, the format used to pass
+/// lists of strings (e.g. tags) to and from the host.
+pub fn strings_to_bytes<S: AsRef<str>>(strs: &[S]) -> Vec<u8> {
+    let mut buf = Vec::new();
+    for s in strs {
+        buf.extend_from_slice(s.as_ref().as_bytes());
+        buf.push(0);
+    }
+    buf
+}
+
+/// Decodes a list of strings encoded by strings_to_bytes.
+pub fn strings_from_bytes(buf: &[u8]) -> Vec<String> {
+    let buf = buf.strip_suffix(&[0]).unwrap_or(buf);
+    if buf.is_empty() {
+        return Vec::new();
+    }
+    buf.split(|&b| b == 0)
+        .map(|s| String::from_utf8_lossy(s).into_owned())
+        .collect()
+}
+
+/// Encodes a map as a list of key, value pairs, see strings_to_bytes.
+pub fn string_map_to_bytes(m: &HashMap<String, String>) -> Vec<u8> {
+    let strs: Vec<&str> = m
+        .iter()
+        .flat_map(|(k, v)| [k.as_str(), v.as_str()])
+        .collect();
+    strings_to_bytes(&strs)
+}
+
+/// Decodes a map encoded by string_map_to_bytes.
+pub fn string_map_from_bytes(buf: &[u8]) -> HashMap<String, String> {
+    let strs = strings_from_bytes(buf);
+    strs.chunks_exact(2)
+        .map(|kv| (kv[0].clone(), kv[1].clone()))
+        .collect()
+}
+
+/// Calls f, which writes a result of variable size to the given buffer, with
+/// bigger buffers until the result fits. f has to return the size of the
+/// result, or -1 on error.
+pub fn get_sized<F: Fn(u64) -> i64>(f: F) -> Option<Vec<u8>> {
+    let mut buf = vec![0u8; 256];
+    loop {
+        let ret = f(bytes_to_buf_ptr(&buf).0);
+        if ret < 0 {
+            return None;
+        }
+        if ret as usize > buf.len() {
+            buf = vec![0u8; ret as usize];
+            continue;
+        }
+        buf.truncate(ret as usize);
+        return Some(buf);
+    }
+}
+
+/// Like get_sized for functions returning a list of handles. f has to return
+/// the number of handles, or -1 on error.
+pub fn get_handles<F: Fn(u64) -> i64>(f: F) -> Option<Vec<u32>> {
+    let mut handles = vec![0u32; 16];
+    loop {
+        let bytes = unsafe {
+            slice::from_raw_parts(
+                handles.as_ptr() as *const u8,
+                mem::size_of_val(handles.as_slice()),
+            )
+        };
+        let ret = f(bytes_to_buf_ptr(bytes).0);
+        if ret < 0 {
+            return None;
+        }
+        if ret as usize > handles.len() {
+            handles = vec![0u32; ret as usize];
+            continue;
+        }
+        handles.truncate(ret as usize);
+        return Some(handles);
+    }
+}
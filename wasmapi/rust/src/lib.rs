@@ -21,6 +21,7 @@
 //! block lifetime.
 
 pub mod config;
+pub mod containers;
 pub mod datasources;
 pub mod fields;
 pub mod filter;
@@ -32,4 +33,5 @@ pub mod map;
 pub mod params;
 pub mod perf;
 pub mod syscall;
+pub mod timer;
 pub mod version;
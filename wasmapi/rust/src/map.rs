@@ -14,7 +14,8 @@
 
 use crate::{
     error,
-    helpers::{any_to_buf_ptr, string_to_buf_ptr},
+    handle::release_handle,
+    helpers::{any_to_buf_ptr, any_to_buf_ptr_mut, bytes_to_buf_ptr, string_to_buf_ptr},
 };
 
 #[link(wasm_import_module = "ig")]
@@ -29,6 +30,17 @@ extern "C" {
     fn _map_update(map: u32, key_ptr: u64, value_ptr: u64, flags: u64) -> u32;
     #[link_name = "mapDelete"]
     fn _map_delete(map: u32, key_ptr: u64) -> u32;
+    #[link_name = "mapGetNextKey"]
+    fn _map_get_next_key(map: u32, key_ptr: u64, next_key_ptr: u64) -> u32;
+    #[link_name = "mapBatchLookup"]
+    fn _map_batch_lookup(map: u32, cursor_ptr: u64, keys_ptr: u64, values_ptr: u64) -> i64;
+    #[link_name = "mapBatchLookupAndDelete"]
+    fn _map_batch_lookup_and_delete(
+        map: u32,
+        cursor_ptr: u64,
+        keys_ptr: u64,
+        values_ptr: u64,
+    ) -> i64;
     #[link_name = "mapRelease"]
     fn _map_release(map: u32) -> u32;
 }
@@ -98,6 +110,44 @@ pub struct MapSpec {
     pub max_entries: u32,
 }
 
+/// Keeps the position of batch operations on a map between calls. A new cursor starts at the
+/// beginning of the map.
+#[derive(Debug, Default)]
+pub struct MapBatchCursor {
+    handle: u32,
+    done: bool,
+}
+
+impl MapBatchCursor {
+    pub fn new() -> Self {
+        Self::default()
+    }
+
+    /// Returns whether all the entries of the map were read
+    pub fn done(&self) -> bool {
+        self.done
+    }
+}
+
+impl Drop for MapBatchCursor {
+    fn drop(&mut self) {
+        if self.handle == 0 {
+            return;
+        }
+
+        if release_handle(self.handle).is_err() {
+            error!("Failed to release map batch cursor");
+        }
+    }
+}
+
+fn slice_to_buf_ptr<T>(s: &mut [T]) -> u64 {
+    let bytes = unsafe {
+        std::slice::from_raw_parts(s.as_mut_ptr() as *const u8, std::mem::size_of_val(s))
+    };
+    bytes_to_buf_ptr(bytes).0
+}
+
 impl Drop for Map {
     fn drop(&mut self) {
         if !self.created {
@@ -179,4 +229,80 @@ impl Map {
         }
         Ok(())
     }
+
+    /// Writes the key following key into next_key. If key is None, the first key of the map is
+    /// returned. Returns false after the last key.
+    pub fn next_key<T>(&self, key: Option<&T>, next_key: &mut T) -> Result<bool> {
+        let key_ptr = match key {
+            Some(k) => any_to_buf_ptr(k)?.0,
+            None => 0,
+        };
+        let next_key_ptr = any_to_buf_ptr_mut(next_key)?;
+
+        let ret = unsafe { _map_get_next_key(self.handle, key_ptr, next_key_ptr.0) };
+        match ret {
+            0 => Ok(true),
+            2 => Ok(false),
+            _ => Err(String::from("Failed to get next key")),
+        }
+    }
+
+    fn batch<K, V>(
+        &self,
+        f: unsafe extern "C" fn(u32, u64, u64, u64) -> i64,
+        cursor: &mut MapBatchCursor,
+        keys: &mut [K],
+        values: &mut [V],
+    ) -> Result<usize> {
+        if cursor.done {
+            return Ok(0);
+        }
+        if keys.is_empty() || keys.len() != values.len() {
+            return Err(format!(
+                "keys and values must have the same, non-zero length, got {} and {}",
+                keys.len(),
+                values.len()
+            ));
+        }
+
+        let cursor_ptr = any_to_buf_ptr_mut(&mut cursor.handle)?;
+        let ret = unsafe {
+            f(
+                self.handle,
+                cursor_ptr.0,
+                slice_to_buf_ptr(keys),
+                slice_to_buf_ptr(values),
+            )
+        };
+        if ret < 0 {
+            // The host releases the cursor on error
+            return Err(String::from("Failed batch operation on map"));
+        }
+        cursor.done = cursor.handle == 0;
+        Ok(ret as usize)
+    }
+
+    /// Reads up to keys.len() entries of the map into keys and values, starting at the position
+    /// of cursor. Returns the number of entries read, call it until cursor.done() returns true to
+    /// read the whole map. For hash maps, the slices must be large enough to hold a whole hash
+    /// bucket, a few tens of entries are usually enough. Per-CPU maps and maps of maps aren't
+    /// supported.
+    pub fn batch_lookup<K, V>(
+        &self,
+        cursor: &mut MapBatchCursor,
+        keys: &mut [K],
+        values: &mut [V],
+    ) -> Result<usize> {
+        self.batch(_map_batch_lookup, cursor, keys, values)
+    }
+
+    /// Like batch_lookup, but also deletes the entries read from the map
+    pub fn batch_lookup_and_delete<K, V>(
+        &self,
+        cursor: &mut MapBatchCursor,
+        keys: &mut [K],
+        values: &mut [V],
+    ) -> Result<usize> {
+        self.batch(_map_batch_lookup_and_delete, cursor, keys, values)
+    }
 }
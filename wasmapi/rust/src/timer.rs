@@ -0,0 +1,118 @@
+// Copyright 2026 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+use std::{
+    collections::HashMap,
+    sync::{
+        atomic::{AtomicU64, Ordering},
+        Arc, LazyLock, Mutex,
+    },
+    time::Duration,
+};
+
+pub type Result<T> = std::result::Result<T, String>;
+
+type TimerFunc = Arc<dyn Fn() + Send + Sync + 'static>;
+
+struct TimerSubscription {
+    cb: TimerFunc,
+    periodic: bool,
+}
+
+#[link(wasm_import_module = "ig")]
+extern "C" {
+    #[link_name = "newTimer"]
+    fn _new_timer(interval: u64, periodic: u32, cb_id: u64) -> u32;
+
+    #[link_name = "timerStop"]
+    fn _timer_stop(timer: u32) -> u32;
+}
+
+static TIMER_CTR: AtomicU64 = AtomicU64::new(0);
+static TIMER_SUBSCRIPTIONS: LazyLock<Mutex<HashMap<u64, TimerSubscription>>> =
+    LazyLock::new(|| Mutex::new(HashMap::new()));
+
+#[derive(Clone, Copy, Debug)]
+pub struct Timer {
+    pub handle: u32,
+    cb_id: u64,
+}
+
+impl Timer {
+    fn add<F>(interval: Duration, cb: F, periodic: bool) -> Result<Self>
+    where
+        F: Fn() + Send + Sync + 'static,
+    {
+        let cb_id = TIMER_CTR.fetch_add(1, Ordering::SeqCst);
+        TIMER_SUBSCRIPTIONS.lock().unwrap().insert(
+            cb_id,
+            TimerSubscription {
+                cb: Arc::new(cb),
+                periodic,
+            },
+        );
+        let handle = unsafe { _new_timer(interval.as_nanos() as u64, periodic as u32, cb_id) };
+        if handle == 0 {
+            TIMER_SUBSCRIPTIONS.lock().unwrap().remove(&cb_id);
+            return Err(String::from("failed to create timer"));
+        }
+        Ok(Timer { handle, cb_id })
+    }
+
+    /// Calls cb once after interval. Timers only fire while the gadget is running: timers
+    /// created before the gadget is started (e.g. in gadgetInit) are armed when it starts.
+    pub fn new_timer<F>(interval: Duration, cb: F) -> Result<Self>
+    where
+        F: Fn() + Send + Sync + 'static,
+    {
+        Self::add(interval, cb, false)
+    }
+
+    /// Calls cb every interval until the timer is stopped or the gadget stops.
+    pub fn new_ticker<F>(interval: Duration, cb: F) -> Result<Self>
+    where
+        F: Fn() + Send + Sync + 'static,
+    {
+        Self::add(interval, cb, true)
+    }
+
+    /// Stops the timer. The callback isn't called anymore afterwards.
+    pub fn stop(&self) -> Result<()> {
+        TIMER_SUBSCRIPTIONS.lock().unwrap().remove(&self.cb_id);
+        let ret = unsafe { _timer_stop(self.handle) };
+        if ret != 0 {
+            Err(String::from("failed to stop timer"))
+        } else {
+            Ok(())
+        }
+    }
+}
+
+#[no_mangle]
+#[allow(non_snake_case)]
+fn timerCallback(cb_id: u64) {
+    // Don't hold the lock while running the callback, it could create or stop timers
+    let cb = {
+        let mut subscriptions = TIMER_SUBSCRIPTIONS.lock().unwrap();
+        let Some(sub) = subscriptions.get(&cb_id) else {
+            return;
+        };
+        let cb = sub.cb.clone();
+        if !sub.periodic {
+            subscriptions.remove(&cb_id);
+        }
+        cb
+    };
+    cb();
+}